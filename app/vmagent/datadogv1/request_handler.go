@@ -66,6 +66,10 @@ func insertRows(at *auth.Token, series []datadogv1.Series, extraLabels []prompbm
 			if name == "host" {
 				name = "exported_host"
 			}
+			if name == "device" && ss.Device != "" {
+				// Prevent from duplicate device labels if the device is passed both in `device` field and in `device` tag.
+				name = "exported_device"
+			}
 			labels = append(labels, prompbmarshal.Label{
 				Name:  name,
 				Value: value,
@@ -61,6 +61,7 @@ func insertRows(at *auth.Token, rows []newrelic.Row, extraLabels []prompbmarshal
 					Value: bytesutil.ToUnsafeString(t.Value),
 				})
 			}
+			labels = append(labels, extraLabels...)
 			samples = append(samples, prompbmarshal.Sample{
 				Value:     s.Value,
 				Timestamp: r.Timestamp,
@@ -69,7 +70,6 @@ func insertRows(at *auth.Token, rows []newrelic.Row, extraLabels []prompbmarshal
 				Labels:  labels[labelsLen:],
 				Samples: samples[len(samples)-1:],
 			})
-			labels = append(labels, extraLabels...)
 		}
 		samplesCount += len(srcSamples)
 	}
@@ -79,7 +79,7 @@ func insertRows(at *auth.Token, rows []newrelic.Row, extraLabels []prompbmarshal
 	if !remotewrite.TryPush(at, &ctx.WriteRequest) {
 		return remotewrite.ErrQueueFullHTTPRetry
 	}
-	rowsInserted.Add(len(rows))
+	rowsInserted.Add(samplesCount)
 	if at != nil {
 		rowsTenantInserted.Get(at).Add(samplesCount)
 	}
@@ -9,9 +9,11 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/awsapi"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/encoding/zstd"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/flagutil"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/persistentqueue"
@@ -20,6 +22,7 @@ import (
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/timerpool"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/timeutil"
 	"github.com/VictoriaMetrics/metrics"
+	"github.com/golang/snappy"
 )
 
 var (
@@ -82,7 +85,9 @@ type client struct {
 	remoteWriteURL string
 
 	// Whether to use VictoriaMetrics remote write protocol for sending the data to remoteWriteURL
-	useVMProto bool
+	//
+	// It may be switched to false at runtime if remoteWriteURL doesn't support VictoriaMetrics remote write protocol.
+	useVMProto atomic.Bool
 
 	fq *persistentqueue.FastQueue
 	hc *http.Client
@@ -158,7 +163,7 @@ func newHTTPClient(argIdx int, remoteWriteURL, sanitizedURL string, fq *persiste
 	useVMProto := forceVMProto.GetOptionalArg(argIdx)
 	usePromProto := forcePromProto.GetOptionalArg(argIdx)
 	if useVMProto && usePromProto {
-		logger.Fatalf("-remoteWrite.useVMProto and -remoteWrite.usePromProto cannot be set simultaneously for -remoteWrite.url=%s", sanitizedURL)
+		logger.Fatalf("-remoteWrite.forceVMProto and -remoteWrite.forcePromProto cannot be set simultaneously for -remoteWrite.url=%s", sanitizedURL)
 	}
 	if !useVMProto && !usePromProto {
 		// Auto-detect whether the remote storage supports VictoriaMetrics remote write protocol.
@@ -171,7 +176,7 @@ func newHTTPClient(argIdx int, remoteWriteURL, sanitizedURL string, fq *persiste
 				"See https://docs.victoriametrics.com/vmagent.html#victoriametrics-remote-write-protocol", sanitizedURL)
 		}
 	}
-	c.useVMProto = useVMProto
+	c.useVMProto.Store(useVMProto)
 
 	return c
 }
@@ -375,7 +380,7 @@ func (c *client) newRequest(url string, body []byte) (*http.Request, error) {
 	h := req.Header
 	h.Set("User-Agent", "vmagent")
 	h.Set("Content-Type", "application/x-protobuf")
-	if c.useVMProto {
+	if c.useVMProto.Load() {
 		h.Set("Content-Encoding", "zstd")
 		h.Set("X-VictoriaMetrics-Remote-Write-Version", "1")
 	} else {
@@ -397,6 +402,11 @@ func (c *client) newRequest(url string, body []byte) (*http.Request, error) {
 // Otherwise it tries sending the block to remote storage indefinitely.
 func (c *client) sendBlockHTTP(block []byte) bool {
 	c.rl.register(len(block), c.stopCh)
+	if !c.useVMProto.Load() && isZstdBlock(block) {
+		// The block has been compressed with VictoriaMetrics remote write protocol before switching to Prometheus remote write protocol.
+		// For example, it could be buffered at persistent queue before vmagent restart or before the remote storage rejected zstd-encoded data.
+		block = c.mustRepackZstdBlockToSnappy(block)
+	}
 	maxRetryDuration := timeutil.AddJitterToDuration(time.Minute)
 	retryDuration := timeutil.AddJitterToDuration(time.Second)
 	retriesCount := 0
@@ -433,6 +443,16 @@ again:
 		return true
 	}
 	metrics.GetOrCreateCounter(fmt.Sprintf(`vmagent_remotewrite_requests_total{url=%q, status_code="%d"}`, c.sanitizedURL, statusCode)).Inc()
+	if statusCode == 415 && c.useVMProto.Load() {
+		// The remote storage doesn't support VictoriaMetrics remote write protocol.
+		// Permanently switch to Prometheus remote write protocol and re-send the block in this format.
+		_ = resp.Body.Close()
+		logger.Infof("the remote storage at %q responded with 415 Unsupported Media Type to VictoriaMetrics remote write protocol request. "+
+			"Switching to Prometheus remote write protocol. See https://docs.victoriametrics.com/vmagent.html#victoriametrics-remote-write-protocol", c.sanitizedURL)
+		c.useVMProto.Store(false)
+		block = c.mustRepackZstdBlockToSnappy(block)
+		goto again
+	}
 	if statusCode == 409 || statusCode == 400 {
 		body, err := io.ReadAll(resp.Body)
 		_ = resp.Body.Close()
@@ -521,3 +541,24 @@ func (rl *rateLimiter) register(dataLen int, stopCh <-chan struct{}) {
 	}
 	rl.budget -= int64(dataLen)
 }
+
+// zstdMagic is the magic number at the start of every zstd frame.
+//
+// See https://datatracker.ietf.org/doc/html/rfc8878#section-3.1.1
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+func isZstdBlock(block []byte) bool {
+	return bytes.HasPrefix(block, zstdMagic)
+}
+
+// mustRepackZstdBlockToSnappy converts zstd-compressed block to snappy-compressed block.
+//
+// The original block is returned if it cannot be decompressed.
+func (c *client) mustRepackZstdBlockToSnappy(zstdBlock []byte) []byte {
+	plainBlock, err := zstd.Decompress(nil, zstdBlock)
+	if err != nil {
+		logger.Errorf("cannot decompress zstd-encoded block with size %d bytes before sending it to %q: %s; sending the block as is", len(zstdBlock), c.sanitizedURL, err)
+		return zstdBlock
+	}
+	return snappy.Encode(nil, plainBlock)
+}
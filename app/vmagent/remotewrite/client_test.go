@@ -0,0 +1,46 @@
+package remotewrite
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/encoding/zstd"
+	"github.com/golang/snappy"
+)
+
+func TestMustRepackZstdBlockToSnappy(t *testing.T) {
+	c := &client{
+		sanitizedURL: "http://foo/api/v1/write",
+	}
+	data := bytes.Repeat([]byte("foo bar baz "), 100)
+
+	// zstd-compressed block
+	zstdBlock := zstd.CompressLevel(nil, data, 0)
+	if !isZstdBlock(zstdBlock) {
+		t.Fatalf("expecting zstd block")
+	}
+	snappyBlock := c.mustRepackZstdBlockToSnappy(zstdBlock)
+	if isZstdBlock(snappyBlock) {
+		t.Fatalf("unexpected zstd block after repacking")
+	}
+	result, err := snappy.Decode(nil, snappyBlock)
+	if err != nil {
+		t.Fatalf("cannot decode snappy block: %s", err)
+	}
+	if !bytes.Equal(result, data) {
+		t.Fatalf("unexpected data after repacking; got %q; want %q", result, data)
+	}
+
+	// snappy-compressed block
+	snappyBlock = snappy.Encode(nil, data)
+	if isZstdBlock(snappyBlock) {
+		t.Fatalf("snappy block mustn't be detected as zstd block")
+	}
+
+	// invalid zstd block must be returned as is
+	invalidBlock := append(append([]byte{}, zstdMagic...), "foobar"...)
+	result = c.mustRepackZstdBlockToSnappy(invalidBlock)
+	if !bytes.Equal(result, invalidBlock) {
+		t.Fatalf("unexpected result for invalid zstd block; got %q; want %q", result, invalidBlock)
+	}
+}
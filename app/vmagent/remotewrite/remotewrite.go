@@ -3,6 +3,7 @@ package remotewrite
 import (
 	"flag"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -79,6 +80,12 @@ var (
 		"Excess series are logged and dropped. This can be useful for limiting series cardinality. See https://docs.victoriametrics.com/vmagent.html#cardinality-limiter")
 	maxDailySeries = flag.Int("remoteWrite.maxDailySeries", 0, "The maximum number of unique series vmagent can send to remote storage systems during the last 24 hours. "+
 		"Excess series are logged and dropped. This can be useful for limiting series churn rate. See https://docs.victoriametrics.com/vmagent.html#cardinality-limiter")
+	maxPastSampleAge = flag.Duration("remoteWrite.maxPastSampleAge", 0, "The maximum age for samples vmagent can send to remote storage systems. "+
+		"Samples with older timestamps are logged and dropped. There is no limit if the flag is set to 0. "+
+		"See https://docs.victoriametrics.com/vmagent.html#dropping-samples-with-out-of-range-timestamps")
+	maxFutureSampleAge = flag.Duration("remoteWrite.maxFutureSampleAge", 0, "The maximum duration in the future for samples timestamps vmagent can send to remote storage systems. "+
+		"Samples with bigger timestamps are logged and dropped. There is no limit if the flag is set to 0. "+
+		"See https://docs.victoriametrics.com/vmagent.html#dropping-samples-with-out-of-range-timestamps")
 
 	streamAggrConfig = flagutil.NewArrayString("remoteWrite.streamAggr.config", "Optional path to file with stream aggregation config. "+
 		"See https://docs.victoriametrics.com/stream-aggregation.html . "+
@@ -478,6 +485,7 @@ func tryPush(at *auth.Token, wr *prompbmarshal.WriteRequest, dropSamplesOnFailur
 			rowsCountAfterRelabel := getRowsCount(tssBlock)
 			rowsDroppedByGlobalRelabel.Add(rowsCountBeforeRelabel - rowsCountAfterRelabel)
 		}
+		tssBlock = dropSamplesWithOutOfRangeTimestamps(tssBlock)
 		sortLabelsIfNeeded(tssBlock)
 		tssBlock = limitSeriesCardinality(tssBlock)
 		if !tryPushBlockToRemoteStorages(rwctxs, tssBlock) {
@@ -605,6 +613,84 @@ func limitSeriesCardinality(tss []prompbmarshal.TimeSeries) []prompbmarshal.Time
 	return dst
 }
 
+// dropSamplesWithOutOfRangeTimestamps drops samples with timestamps outside the range
+// configured via -remoteWrite.maxPastSampleAge and -remoteWrite.maxFutureSampleAge.
+//
+// tss and its samples aren't modified, since they may be pushed again by the caller
+// if tryPush fails when -remoteWrite.disableOnDiskQueue is set.
+func dropSamplesWithOutOfRangeTimestamps(tss []prompbmarshal.TimeSeries) []prompbmarshal.TimeSeries {
+	if *maxPastSampleAge <= 0 && *maxFutureSampleAge <= 0 {
+		return tss
+	}
+	currentTimestamp := int64(fasttime.UnixTimestamp()) * 1000
+	minTimestamp := int64(math.MinInt64)
+	if *maxPastSampleAge > 0 {
+		minTimestamp = currentTimestamp - maxPastSampleAge.Milliseconds()
+	}
+	maxTimestamp := int64(math.MaxInt64)
+	if *maxFutureSampleAge > 0 {
+		maxTimestamp = currentTimestamp + maxFutureSampleAge.Milliseconds()
+	}
+	return dropSamplesOutsideTimeRange(tss, minTimestamp, maxTimestamp)
+}
+
+func dropSamplesOutsideTimeRange(tss []prompbmarshal.TimeSeries, minTimestamp, maxTimestamp int64) []prompbmarshal.TimeSeries {
+	dst := make([]prompbmarshal.TimeSeries, 0, len(tss))
+	for i := range tss {
+		ts := &tss[i]
+		// samples is allocated only if some samples must be dropped from ts.
+		var samples []prompbmarshal.Sample
+		dropped := false
+		for j, sample := range ts.Samples {
+			if sample.Timestamp >= minTimestamp && sample.Timestamp <= maxTimestamp {
+				if dropped {
+					samples = append(samples, sample)
+				}
+				continue
+			}
+			if sample.Timestamp < minTimestamp {
+				tooOldSamplesDropped.Inc()
+				logOutOfRangeSample(ts.Labels, sample.Timestamp, "-remoteWrite.maxPastSampleAge", *maxPastSampleAge)
+			} else {
+				tooNewSamplesDropped.Inc()
+				logOutOfRangeSample(ts.Labels, sample.Timestamp, "-remoteWrite.maxFutureSampleAge", *maxFutureSampleAge)
+			}
+			if !dropped {
+				samples = append(make([]prompbmarshal.Sample, 0, len(ts.Samples)-1), ts.Samples[:j]...)
+				dropped = true
+			}
+		}
+		if !dropped {
+			samples = ts.Samples
+		}
+		if len(samples) == 0 {
+			continue
+		}
+		dst = append(dst, prompbmarshal.TimeSeries{
+			Labels:  ts.Labels,
+			Samples: samples,
+		})
+	}
+	return dst
+}
+
+func logOutOfRangeSample(labels []prompbmarshal.Label, timestamp int64, flagName string, flagValue time.Duration) {
+	select {
+	case <-logOutOfRangeSampleTicker.C:
+		// Do not use logger.WithThrottler() here for the same reasons as at logSkippedSeries.
+		t := time.UnixMilli(timestamp).UTC().Format(time.RFC3339)
+		logger.Warnf("skip sample for series %s with timestamp %s because it is out of range allowed by %s=%s", labelsToString(labels), t, flagName, flagValue)
+	default:
+	}
+}
+
+var logOutOfRangeSampleTicker = time.NewTicker(5 * time.Second)
+
+var (
+	tooOldSamplesDropped = metrics.NewCounter(`vmagent_remotewrite_samples_dropped_by_timestamp_total{reason="too_old"}`)
+	tooNewSamplesDropped = metrics.NewCounter(`vmagent_remotewrite_samples_dropped_by_timestamp_total{reason="too_new"}`)
+)
+
 var (
 	hourlySeriesLimiter *bloomfilter.Limiter
 	dailySeriesLimiter  *bloomfilter.Limiter
@@ -723,7 +809,7 @@ func newRemoteWriteCtx(argIdx int, remoteWriteURL *url.URL, maxInmemoryBlocks in
 	}
 	pss := make([]*pendingSeries, pssLen)
 	for i := range pss {
-		pss[i] = newPendingSeries(fq, c.useVMProto, sf, rd)
+		pss[i] = newPendingSeries(fq, &c.useVMProto, sf, rd)
 	}
 
 	rwctx := &remoteWriteCtx{
@@ -0,0 +1,75 @@
+package remotewrite
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+)
+
+func TestDropSamplesOutsideTimeRange(t *testing.T) {
+	f := func(tss []prompbmarshal.TimeSeries, minTimestamp, maxTimestamp int64, tssExpected []prompbmarshal.TimeSeries) {
+		t.Helper()
+		tssOrig := make([]prompbmarshal.TimeSeries, len(tss))
+		for i := range tss {
+			tssOrig[i] = prompbmarshal.TimeSeries{
+				Labels:  append([]prompbmarshal.Label{}, tss[i].Labels...),
+				Samples: append([]prompbmarshal.Sample{}, tss[i].Samples...),
+			}
+		}
+		result := dropSamplesOutsideTimeRange(tss, minTimestamp, maxTimestamp)
+		// tss must remain unchanged, since it may be pushed again on retry
+		if len(tss) > 0 && !reflect.DeepEqual(tss, tssOrig) {
+			t.Fatalf("unexpected modification of the original series\ngot\n%v\nwant\n%v", tss, tssOrig)
+		}
+		if len(result) == 0 && len(tssExpected) == 0 {
+			return
+		}
+		if !reflect.DeepEqual(result, tssExpected) {
+			t.Fatalf("unexpected result\ngot\n%v\nwant\n%v", result, tssExpected)
+		}
+	}
+	newSeries := func(name string, timestamps ...int64) prompbmarshal.TimeSeries {
+		samples := make([]prompbmarshal.Sample, 0, len(timestamps))
+		for _, timestamp := range timestamps {
+			samples = append(samples, prompbmarshal.Sample{
+				Value:     1,
+				Timestamp: timestamp,
+			})
+		}
+		return prompbmarshal.TimeSeries{
+			Labels: []prompbmarshal.Label{{
+				Name:  "__name__",
+				Value: name,
+			}},
+			Samples: samples,
+		}
+	}
+
+	// empty series
+	f(nil, 10, 20, nil)
+
+	// all the samples are in range
+	f([]prompbmarshal.TimeSeries{
+		newSeries("foo", 10, 15, 20),
+		newSeries("bar", 12),
+	}, 10, 20, []prompbmarshal.TimeSeries{
+		newSeries("foo", 10, 15, 20),
+		newSeries("bar", 12),
+	})
+
+	// some samples are out of range
+	f([]prompbmarshal.TimeSeries{
+		newSeries("foo", 5, 15, 25),
+		newSeries("bar", 1, 2),
+		newSeries("baz", 30, 20),
+	}, 10, 20, []prompbmarshal.TimeSeries{
+		newSeries("foo", 15),
+		newSeries("baz", 20),
+	})
+
+	// all the samples are out of range
+	f([]prompbmarshal.TimeSeries{
+		newSeries("foo", 5, 25),
+	}, 10, 20, nil)
+}
@@ -0,0 +1,344 @@
+package statsd
+
+import (
+	"flag"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmagent/remotewrite"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+	parser "github.com/VictoriaMetrics/VictoriaMetrics/lib/protoparser/statsd"
+	"github.com/VictoriaMetrics/metrics"
+)
+
+var (
+	flushInterval = flag.Duration("statsd.flushInterval", 10*time.Second, "Interval for aggregating StatsD metrics received via -statsdListenAddr before sending them to remote storage. "+
+		"Set it to zero for sending every received StatsD value as is. See https://docs.victoriametrics.com/vmagent.html#statsd-ingestion")
+	idleTimeout = flag.Duration("statsd.idleTimeout", 5*time.Minute, "StatsD counters, gauges and timers, which didn't receive new values during the given duration, "+
+		"are deleted from the aggregation state. See https://docs.victoriametrics.com/vmagent.html#statsd-ingestion")
+)
+
+// timerQuantiles contains quantiles, which are calculated for timers, histograms and distributions on every flush.
+var timerQuantiles = []float64{0.5, 0.9, 0.99}
+
+var (
+	flushDuration  = metrics.NewSummary(`vmagent_statsd_flush_duration_seconds`)
+	flushedSeries  = metrics.NewCounter(`vmagent_statsd_flushed_series_total`)
+	droppedFlushes = metrics.NewCounter(`vmagent_statsd_dropped_flushes_total`)
+)
+
+var (
+	globalAggregator *aggregator
+	stopCh           chan struct{}
+	flusherWG        sync.WaitGroup
+)
+
+// Init starts aggregation of StatsD metrics over -statsd.flushInterval.
+//
+// Stop must be called when the aggregation is no longer needed.
+func Init() {
+	if *flushInterval <= 0 {
+		return
+	}
+	globalAggregator = newAggregator()
+	stopCh = make(chan struct{})
+	flusherWG.Add(1)
+	go func() {
+		defer flusherWG.Done()
+		runFlusher(globalAggregator, stopCh)
+	}()
+}
+
+// Stop stops aggregation of StatsD metrics and flushes the aggregated state to remote storage.
+func Stop() {
+	if globalAggregator == nil {
+		return
+	}
+	close(stopCh)
+	flusherWG.Wait()
+	globalAggregator = nil
+}
+
+func runFlusher(a *aggregator, stopCh <-chan struct{}) {
+	t := time.NewTicker(*flushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stopCh:
+			flushToRemoteStorage(a, time.Now())
+			return
+		case <-t.C:
+			flushToRemoteStorage(a, time.Now())
+		}
+	}
+}
+
+func flushToRemoteStorage(a *aggregator, now time.Time) {
+	startTime := time.Now()
+	tss := a.flush(nil, now)
+	if len(tss) == 0 {
+		return
+	}
+	wr := &prompbmarshal.WriteRequest{
+		Timeseries: tss,
+	}
+	if !remotewrite.TryPush(nil, wr) {
+		droppedFlushes.Inc()
+		logger.Warnf("cannot push %d aggregated StatsD series to remote storage, since the queue is full", len(tss))
+		return
+	}
+	flushedSeries.Add(len(tss))
+	flushDuration.UpdateDuration(startTime)
+}
+
+// aggregator aggregates StatsD values in the same way as statsd_exporter does:
+//
+//   - counters are converted into cumulative counters;
+//   - gauges contain the last received value;
+//   - timers, histograms and distributions are converted into summaries with cumulative `_count` and `_sum`
+//     and quantiles calculated over values received during the last flush interval.
+//
+// Only series, which received new values since the previous flush, are returned on every flush.
+type aggregator struct {
+	mu       sync.Mutex
+	counters map[string]*counterState
+	gauges   map[string]*gaugeState
+	timers   map[string]*timerState
+}
+
+type seriesState struct {
+	metric string
+	labels []prompbmarshal.Label
+
+	// updated is set to true if the series received new values since the previous flush
+	updated bool
+
+	lastUpdateTime time.Time
+}
+
+type counterState struct {
+	seriesState
+	value float64
+}
+
+type gaugeState struct {
+	seriesState
+	value float64
+}
+
+type timerState struct {
+	seriesState
+	count  uint64
+	sum    float64
+	values []float64
+}
+
+func newAggregator() *aggregator {
+	return &aggregator{
+		counters: make(map[string]*counterState),
+		gauges:   make(map[string]*gaugeState),
+		timers:   make(map[string]*timerState),
+	}
+}
+
+func (a *aggregator) add(rows []parser.Row, now time.Time) {
+	var keyBuf []byte
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i := range rows {
+		r := &rows[i]
+		keyBuf = marshalSeriesKey(keyBuf[:0], r)
+		key := string(keyBuf)
+		switch r.Type {
+		case "c":
+			cs := a.counters[key]
+			if cs == nil {
+				cs = &counterState{
+					seriesState: newSeriesState(r),
+				}
+				a.counters[key] = cs
+			}
+			for _, v := range r.Values {
+				cs.value += v
+			}
+			cs.markUpdated(now)
+		case "g":
+			gs := a.gauges[key]
+			if gs == nil {
+				gs = &gaugeState{
+					seriesState: newSeriesState(r),
+				}
+				a.gauges[key] = gs
+			}
+			for i, v := range r.Values {
+				if r.Relative[i] {
+					gs.value += v
+				} else {
+					gs.value = v
+				}
+			}
+			gs.markUpdated(now)
+		default:
+			// Timers, histograms and distributions
+			ts := a.timers[key]
+			if ts == nil {
+				ts = &timerState{
+					seriesState: newSeriesState(r),
+				}
+				a.timers[key] = ts
+			}
+			for _, v := range r.Values {
+				ts.count++
+				ts.sum += v
+			}
+			ts.values = append(ts.values, r.Values...)
+			ts.markUpdated(now)
+		}
+	}
+}
+
+// flush appends series updated since the previous flush to dst and returns the result.
+//
+// Series without updates during -statsd.idleTimeout are deleted.
+func (a *aggregator) flush(dst []prompbmarshal.TimeSeries, now time.Time) []prompbmarshal.TimeSeries {
+	timestamp := now.UnixMilli()
+	deadline := now.Add(-*idleTimeout)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for key, cs := range a.counters {
+		if cs.isIdle(deadline) {
+			delete(a.counters, key)
+			continue
+		}
+		if cs.updated {
+			dst = appendTimeSeries(dst, cs.metric, cs.labels, nil, cs.value, timestamp)
+			cs.updated = false
+		}
+	}
+	for key, gs := range a.gauges {
+		if gs.isIdle(deadline) {
+			delete(a.gauges, key)
+			continue
+		}
+		if gs.updated {
+			dst = appendTimeSeries(dst, gs.metric, gs.labels, nil, gs.value, timestamp)
+			gs.updated = false
+		}
+	}
+	for key, ts := range a.timers {
+		if ts.isIdle(deadline) {
+			delete(a.timers, key)
+			continue
+		}
+		if !ts.updated {
+			continue
+		}
+		sort.Float64s(ts.values)
+		for _, q := range timerQuantiles {
+			quantileLabel := &prompbmarshal.Label{
+				Name:  "quantile",
+				Value: strconv.FormatFloat(q, 'g', -1, 64),
+			}
+			dst = appendTimeSeries(dst, ts.metric, ts.labels, quantileLabel, getQuantile(ts.values, q), timestamp)
+		}
+		dst = appendTimeSeries(dst, ts.metric+"_count", ts.labels, nil, float64(ts.count), timestamp)
+		dst = appendTimeSeries(dst, ts.metric+"_sum", ts.labels, nil, ts.sum, timestamp)
+		ts.values = ts.values[:0]
+		ts.updated = false
+	}
+	return dst
+}
+
+func newSeriesState(r *parser.Row) seriesState {
+	labels := make([]prompbmarshal.Label, 0, len(r.Tags))
+	for _, tag := range r.Tags {
+		// Clone the strings, since they refer to the request buffer, which is re-used after the row is processed.
+		labels = append(labels, prompbmarshal.Label{
+			Name:  strings.Clone(tag.Key),
+			Value: strings.Clone(tag.Value),
+		})
+	}
+	return seriesState{
+		metric: strings.Clone(r.Metric),
+		labels: labels,
+	}
+}
+
+func (ss *seriesState) markUpdated(now time.Time) {
+	ss.updated = true
+	ss.lastUpdateTime = now
+}
+
+func (ss *seriesState) isIdle(deadline time.Time) bool {
+	return !ss.updated && ss.lastUpdateTime.Before(deadline)
+}
+
+// marshalSeriesKey appends the key for the series from r to dst and returns the result.
+//
+// The key doesn't depend on the order of tags in r.
+func marshalSeriesKey(dst []byte, r *parser.Row) []byte {
+	// Metrics with distinct types are aggregated separately, since they are aggregated in different ways.
+	// Timers, histograms and distributions are aggregated in the same way.
+	switch r.Type {
+	case "c", "g":
+		dst = append(dst, r.Type...)
+	default:
+		dst = append(dst, 't')
+	}
+	dst = append(dst, 0)
+	dst = append(dst, r.Metric...)
+
+	tags := r.Tags
+	if !sort.SliceIsSorted(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key }) {
+		tags = append([]parser.Tag{}, tags...)
+		sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+	}
+	for _, tag := range tags {
+		dst = append(dst, 0)
+		dst = append(dst, tag.Key...)
+		dst = append(dst, 1)
+		dst = append(dst, tag.Value...)
+	}
+	return dst
+}
+
+func appendTimeSeries(dst []prompbmarshal.TimeSeries, metric string, labels []prompbmarshal.Label, extraLabel *prompbmarshal.Label,
+	value float64, timestamp int64) []prompbmarshal.TimeSeries {
+	tsLabels := make([]prompbmarshal.Label, 0, len(labels)+2)
+	tsLabels = append(tsLabels, prompbmarshal.Label{
+		Name:  "__name__",
+		Value: metric,
+	})
+	tsLabels = append(tsLabels, labels...)
+	if extraLabel != nil {
+		tsLabels = append(tsLabels, *extraLabel)
+	}
+	return append(dst, prompbmarshal.TimeSeries{
+		Labels: tsLabels,
+		Samples: []prompbmarshal.Sample{{
+			Value:     value,
+			Timestamp: timestamp,
+		}},
+	})
+}
+
+// getQuantile returns the quantile q for the sorted values.
+func getQuantile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	n := int(math.Ceil(q*float64(len(values)))) - 1
+	if n < 0 {
+		n = 0
+	}
+	return values[n]
+}
@@ -0,0 +1,171 @@
+package statsd
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/bytesutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+	parser "github.com/VictoriaMetrics/VictoriaMetrics/lib/protoparser/statsd"
+)
+
+func TestAggregator(t *testing.T) {
+	now := time.Unix(1000, 0)
+	f := func(a *aggregator, s string, resultExpected string) {
+		t.Helper()
+		var rows parser.Rows
+		rows.Unmarshal(s)
+		a.add(rows.Rows, now)
+		tss := a.flush(nil, now)
+		result := timeSeriessToString(tss)
+		if result != resultExpected {
+			t.Fatalf("unexpected result;\ngot\n%s\nwant\n%s", result, resultExpected)
+		}
+	}
+
+	// counters are cumulative
+	a := newAggregator()
+	f(a, "foo:1|c|#a:b\nfoo:2|c|@0.5|#a:b\nfoo:5|c|#a:c", `foo{a="b"} 5
+foo{a="c"} 5
+`)
+	f(a, "foo:3|c|#a:b", `foo{a="b"} 8
+`)
+	f(a, "", "")
+
+	// tags order doesn't matter
+	a = newAggregator()
+	f(a, "foo:1|c|#a:b,c:d\nfoo:1|c|#c:d,a:b", `foo{a="b",c="d"} 2
+`)
+
+	// gauges contain the last value
+	a = newAggregator()
+	f(a, "foo:1|g\nfoo:3|g\nfoo:2|g", `foo 2
+`)
+	f(a, "", "")
+	f(a, "foo:-5|g", `foo -3
+`)
+
+	// gauge values with sign prefix are added to the current value
+	a = newAggregator()
+	f(a, "foo:10|g\nfoo:+5|g\nfoo:-3|g", `foo 12
+`)
+	f(a, "foo:+1.5:-0.5|g", `foo 13
+`)
+	f(a, "foo:4|g", `foo 4
+`)
+	// negative gauge value can be set only after resetting it to zero
+	f(a, "foo:0|g\nfoo:-7|g", `foo -7
+`)
+	// relative update for missing gauge starts from zero
+	f(a, "bar:-2|g", `bar -2
+`)
+
+	// metrics with the same name and distinct types are aggregated separately
+	a = newAggregator()
+	f(a, "foo:1|g\nfoo:3|c", `foo 1
+foo 3
+`)
+
+	// timers
+	a = newAggregator()
+	f(a, "foo:1:2:3:4:5:6:7:8:9:10|ms|#x:y", `foo_count{x="y"} 10
+foo_sum{x="y"} 55
+foo{x="y",quantile="0.5"} 5
+foo{x="y",quantile="0.9"} 9
+foo{x="y",quantile="0.99"} 10
+`)
+	// quantiles are calculated over the last interval, while count and sum are cumulative
+	f(a, "foo:100|h|#x:y\nfoo:200|d|#x:y", `foo_count{x="y"} 12
+foo_sum{x="y"} 355
+foo{x="y",quantile="0.5"} 100
+foo{x="y",quantile="0.9"} 200
+foo{x="y",quantile="0.99"} 200
+`)
+}
+
+func TestAggregatorIdleTimeout(t *testing.T) {
+	startTime := time.Unix(1000, 0)
+	a := newAggregator()
+
+	var rows parser.Rows
+	rows.Unmarshal("foo:1|c\nbar:1|g\nbaz:1|ms")
+	a.add(rows.Rows, startTime)
+	if tss := a.flush(nil, startTime); len(tss) != 7 {
+		t.Fatalf("unexpected number of series; got %d; want 7", len(tss))
+	}
+
+	// The state must be kept until -statsd.idleTimeout
+	a.flush(nil, startTime.Add(*idleTimeout))
+	if n := len(a.counters) + len(a.gauges) + len(a.timers); n != 3 {
+		t.Fatalf("unexpected number of states; got %d; want 3", n)
+	}
+
+	// The state must be dropped after -statsd.idleTimeout, so the counter starts from zero
+	a.flush(nil, startTime.Add(*idleTimeout+time.Second))
+	if n := len(a.counters) + len(a.gauges) + len(a.timers); n != 0 {
+		t.Fatalf("unexpected number of states; got %d; want 0", n)
+	}
+	rows.Unmarshal("foo:1|c")
+	a.add(rows.Rows, startTime.Add(*idleTimeout+time.Second))
+	result := timeSeriessToString(a.flush(nil, startTime.Add(*idleTimeout+time.Second)))
+	if result != "foo 1\n" {
+		t.Fatalf("unexpected result; got %q; want %q", result, "foo 1\n")
+	}
+}
+
+func TestAggregatorClonesLabels(t *testing.T) {
+	now := time.Unix(1000, 0)
+	a := newAggregator()
+
+	buf := []byte("foo:1|c|#a:b")
+	var rows parser.Rows
+	rows.Unmarshal(bytesutil.ToUnsafeString(buf))
+	a.add(rows.Rows, now)
+
+	// Overwrite the buffer in order to make sure the aggregator doesn't refer to it.
+	for i := range buf {
+		buf[i] = 'x'
+	}
+	tss := a.flush(nil, now)
+	labelsExpected := []prompbmarshal.Label{
+		{Name: "__name__", Value: "foo"},
+		{Name: "a", Value: "b"},
+	}
+	if len(tss) != 1 || !reflect.DeepEqual(tss[0].Labels, labelsExpected) {
+		t.Fatalf("unexpected series; got %+v; want labels %+v", tss, labelsExpected)
+	}
+	if ts := tss[0].Samples[0].Timestamp; ts != now.UnixMilli() {
+		t.Fatalf("unexpected timestamp; got %d; want %d", ts, now.UnixMilli())
+	}
+}
+
+func timeSeriessToString(tss []prompbmarshal.TimeSeries) string {
+	a := make([]string, 0, len(tss))
+	for _, ts := range tss {
+		a = append(a, timeSeriesToString(ts))
+	}
+	sort.Strings(a)
+	return strings.Join(a, "")
+}
+
+func timeSeriesToString(ts prompbmarshal.TimeSeries) string {
+	labels := promutils.NewLabels(len(ts.Labels))
+	metricName := ""
+	for _, label := range ts.Labels {
+		if label.Name == "__name__" {
+			metricName = label.Value
+			continue
+		}
+		labels.Add(label.Name, label.Value)
+	}
+	labelsString := ""
+	if labels.Len() > 0 {
+		labelsString = labels.String()
+	}
+	return fmt.Sprintf("%s%s %v\n", metricName, labelsString, ts.Samples[0].Value)
+}
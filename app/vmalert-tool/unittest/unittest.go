@@ -189,7 +189,7 @@ func setUp() {
 	go httpserver.Serve([]string{httpListenAddr}, &ab, func(w http.ResponseWriter, r *http.Request) bool {
 		switch r.URL.Path {
 		case "/prometheus/api/v1/query":
-			if err := prometheus.QueryHandler(nil, time.Now(), w, r); err != nil {
+			if err := prometheus.QueryHandler(nil, nil, time.Now(), w, r); err != nil {
 				httpserver.Errorf(w, r, "%s", err)
 			}
 			return true
@@ -46,6 +46,11 @@ func TestGetExternalURL(t *testing.T) {
 	if u.String() != expURL {
 		t.Errorf("unexpected url: want %s, got %s", expURL, u.String())
 	}
+
+	// external url cannot be generated from unix socket address
+	if _, err := getHostnameAsExternalURL("unix:/var/run/vmalert.sock", false); err == nil {
+		t.Errorf("expected error, got nil")
+	}
 }
 
 func TestGetAlertURLGenerator(t *testing.T) {
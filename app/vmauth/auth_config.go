@@ -33,6 +33,8 @@ var (
 		"See https://docs.victoriametrics.com/vmauth.html for details on the format of this auth config")
 	configCheckInterval = flag.Duration("configCheckInterval", 0, "interval for config file re-read. "+
 		"Zero value disables config re-reading. By default, refreshing is disabled, send SIGHUP for config refresh.")
+	configWatch = flag.Bool("configWatch", false, "Whether to watch the local -auth.config file for changes and reload it immediately after the change. "+
+		"See https://docs.victoriametrics.com/vmauth.html#config-reload")
 	defaultRetryStatusCodes = flagutil.NewArrayInt("retryStatusCodes", 0, "Comma-separated list of default HTTP response status codes when vmauth re-tries the request on other backends. "+
 		"See https://docs.victoriametrics.com/vmauth.html#load-balancing for details")
 	defaultLoadBalancingPolicy = flag.String("loadBalancingPolicy", "least_loaded", "The default load balancing policy to use for backend urls specified inside url_prefix section. "+
@@ -43,38 +45,62 @@ var (
 type AuthConfig struct {
 	Users            []UserInfo `yaml:"users,omitempty"`
 	UnauthorizedUser *UserInfo  `yaml:"unauthorized_user,omitempty"`
+	IPFilters        *IPFilters `yaml:"ip_filters,omitempty"`
 
 	// ms holds all the metrics for the given AuthConfig
 	ms *metrics.Set
+
+	// jwtUsers contains users with `jwt` section in the order they are defined in the config
+	jwtUsers []*UserInfo
+
+	// mtlsUsers contains users with `mtls` section in the order they are defined in the config
+	mtlsUsers []*UserInfo
+
+	// healthChecker performs active health checks for backends from the given AuthConfig
+	healthChecker *healthChecker
 }
 
 // UserInfo is user information read from authConfigPath
 type UserInfo struct {
-	Name                   string      `yaml:"name,omitempty"`
-	BearerToken            string      `yaml:"bearer_token,omitempty"`
-	Username               string      `yaml:"username,omitempty"`
-	Password               string      `yaml:"password,omitempty"`
-	URLPrefix              *URLPrefix  `yaml:"url_prefix,omitempty"`
-	URLMaps                []URLMap    `yaml:"url_map,omitempty"`
-	HeadersConf            HeadersConf `yaml:",inline"`
-	MaxConcurrentRequests  int         `yaml:"max_concurrent_requests,omitempty"`
-	DefaultURL             *URLPrefix  `yaml:"default_url,omitempty"`
-	RetryStatusCodes       []int       `yaml:"retry_status_codes,omitempty"`
-	LoadBalancingPolicy    string      `yaml:"load_balancing_policy,omitempty"`
-	DropSrcPathPrefixParts *int        `yaml:"drop_src_path_prefix_parts,omitempty"`
-	TLSInsecureSkipVerify  *bool       `yaml:"tls_insecure_skip_verify,omitempty"`
-	TLSCAFile              string      `yaml:"tls_ca_file,omitempty"`
+	Name                   string          `yaml:"name,omitempty"`
+	BearerToken            string          `yaml:"bearer_token,omitempty"`
+	Username               string          `yaml:"username,omitempty"`
+	Password               string          `yaml:"password,omitempty"`
+	JWT                    *JWTConfig      `yaml:"jwt,omitempty"`
+	MTLS                   *MTLSConfig     `yaml:"mtls,omitempty"`
+	URLPrefix              *URLPrefix      `yaml:"url_prefix,omitempty"`
+	URLMaps                []URLMap        `yaml:"url_map,omitempty"`
+	HeadersConf            HeadersConf     `yaml:",inline"`
+	MaxConcurrentRequests  int             `yaml:"max_concurrent_requests,omitempty"`
+	RequestsPerSecond      float64         `yaml:"requests_per_second,omitempty"`
+	MaxRequestBodySize     *flagutil.Bytes `yaml:"max_request_body_size,omitempty"`
+	DefaultURL             *URLPrefix      `yaml:"default_url,omitempty"`
+	RetryStatusCodes       []int           `yaml:"retry_status_codes,omitempty"`
+	LoadBalancingPolicy    string          `yaml:"load_balancing_policy,omitempty"`
+	HealthCheckPath        string          `yaml:"health_check_path,omitempty"`
+	DropSrcPathPrefixParts *int            `yaml:"drop_src_path_prefix_parts,omitempty"`
+	TLSInsecureSkipVerify  *bool           `yaml:"tls_insecure_skip_verify,omitempty"`
+	TLSCAFile              string          `yaml:"tls_ca_file,omitempty"`
+	IPFilters              *IPFilters      `yaml:"ip_filters,omitempty"`
 
 	MetricLabels map[string]string `yaml:"metric_labels,omitempty"`
 
 	concurrencyLimitCh      chan struct{}
 	concurrencyLimitReached *metrics.Counter
 
+	rateLimiter      *rateLimiter
+	rateLimitReached *metrics.Counter
+
 	httpTransport *http.Transport
 
 	requests         *metrics.Counter
 	backendErrors    *metrics.Counter
 	requestsDuration *metrics.Summary
+
+	requestErrors               *metrics.Counter
+	requestBodyBytes            *metrics.Counter
+	responseBodyBytes           *metrics.Counter
+	requestBodySizeLimitReached *metrics.Counter
 }
 
 // HeadersConf represents config for request and response headers.
@@ -97,6 +123,35 @@ func (ui *UserInfo) endConcurrencyLimit() {
 	<-ui.concurrencyLimitCh
 }
 
+// checkRateLimit verifies whether the request from ui doesn't exceed the configured requests_per_second limit.
+//
+// It returns the duration to wait before the next request can be served if the limit is exceeded.
+func (ui *UserInfo) checkRateLimit() (time.Duration, error) {
+	if ui.rateLimiter == nil {
+		return 0, nil
+	}
+	d := ui.rateLimiter.take(time.Now())
+	if d <= 0 {
+		return 0, nil
+	}
+	ui.rateLimitReached.Inc()
+	return d, fmt.Errorf("cannot handle more than %v requests per second from user %s", ui.RequestsPerSecond, ui.name())
+}
+
+func (ui *UserInfo) initRateLimiter(ms *metrics.Set, metricPrefix, metricLabels string) error {
+	if ui.RequestsPerSecond < 0 {
+		return fmt.Errorf("requests_per_second cannot be negative; got %v", ui.RequestsPerSecond)
+	}
+	ui.rateLimitReached = ms.GetOrCreateCounter(metricPrefix + `_requests_rate_limit_reached_total` + metricLabels)
+	_ = ms.GetOrCreateGauge(metricPrefix+`_requests_rate_limit`+metricLabels, func() float64 {
+		return ui.RequestsPerSecond
+	})
+	if ui.RequestsPerSecond > 0 {
+		ui.rateLimiter = newRateLimiter(ui.RequestsPerSecond)
+	}
+	return nil
+}
+
 func (ui *UserInfo) getMaxConcurrentRequests() int {
 	mcr := ui.MaxConcurrentRequests
 	if mcr <= 0 {
@@ -132,6 +187,30 @@ func (h *Header) MarshalYAML() (interface{}, error) {
 	return s, nil
 }
 
+// UnmarshalYAML unmarshals hf from f.
+func (hf *HeaderFilter) UnmarshalYAML(f func(interface{}) error) error {
+	var h Header
+	if err := h.UnmarshalYAML(f); err != nil {
+		return err
+	}
+	if h.Name == "" {
+		return fmt.Errorf("missing header name in `src_headers` entry")
+	}
+	var r Regex
+	if err := r.unmarshalString(h.Value); err != nil {
+		return err
+	}
+	hf.Name = h.Name
+	hf.Value = &r
+	return nil
+}
+
+// MarshalYAML marshals hf to yaml.
+func (hf *HeaderFilter) MarshalYAML() (interface{}, error) {
+	s := fmt.Sprintf("%s: %s", hf.Name, hf.Value.sOriginal)
+	return s, nil
+}
+
 // URLMap is a mapping from source paths to target urls.
 type URLMap struct {
 	// SrcHosts is the list of regular expressions, which match the request hostname.
@@ -140,6 +219,20 @@ type URLMap struct {
 	// SrcPaths is the list of regular expressions, which match the request path.
 	SrcPaths []*Regex `yaml:"src_paths,omitempty"`
 
+	// SrcMethods is the list of HTTP methods, which match the request method.
+	SrcMethods []string `yaml:"src_methods,omitempty"`
+
+	// SrcHeaders is the list of `Name: regex` filters, which must match the request headers.
+	SrcHeaders []*HeaderFilter `yaml:"src_headers,omitempty"`
+
+	// RequiredScopes is the list of scopes, which must be present in JSON Web Token used for authorizing the request.
+	RequiredScopes []string `yaml:"required_scopes,omitempty"`
+
+	// TargetPath is the path for the proxied request.
+	//
+	// It may refer capture groups from the matching SrcPaths regex via $1, $2, etc.
+	TargetPath string `yaml:"target_path,omitempty"`
+
 	// UrlPrefix contains backend url prefixes for the proxied request url.
 	URLPrefix *URLPrefix `yaml:"url_prefix,omitempty"`
 
@@ -152,10 +245,19 @@ type URLMap struct {
 	// LoadBalancingPolicy is load balancing policy among UrlPrefix backends.
 	LoadBalancingPolicy string `yaml:"load_balancing_policy,omitempty"`
 
+	// HealthCheckPath is the path for active health checks of UrlPrefix backends.
+	HealthCheckPath string `yaml:"health_check_path,omitempty"`
+
 	// DropSrcPathPrefixParts is the number of `/`-delimited request path prefix parts to drop before proxying the request to backend.
 	DropSrcPathPrefixParts *int `yaml:"drop_src_path_prefix_parts,omitempty"`
 }
 
+// HeaderFilter represents `Name: regex` filter for http request header.
+type HeaderFilter struct {
+	Name  string
+	Value *Regex
+}
+
 // Regex represents a regex
 type Regex struct {
 	sOriginal string
@@ -175,6 +277,9 @@ type URLPrefix struct {
 	// load balancing policy used
 	loadBalancingPolicy string
 
+	// the path for active health checks of backend urls. Health checks are disabled if it is empty.
+	healthCheckPath string
+
 	// how many request path prefix parts to drop before routing the request to backendURL.
 	dropSrcPathPrefixParts int
 }
@@ -194,10 +299,17 @@ func (up *URLPrefix) setLoadBalancingPolicy(loadBalancingPolicy string) error {
 type backendURL struct {
 	brokenDeadline     uint64
 	concurrentRequests int32
-	url                *url.URL
+
+	// unhealthy is set to 1 if the last active health check for the backend has been failed.
+	unhealthy int32
+
+	url *url.URL
 }
 
 func (bu *backendURL) isBroken() bool {
+	if atomic.LoadInt32(&bu.unhealthy) != 0 {
+		return true
+	}
 	ct := fasttime.UnixTimestamp()
 	return ct < atomic.LoadUint64(&bu.brokenDeadline)
 }
@@ -378,6 +490,10 @@ func (r *Regex) UnmarshalYAML(f func(interface{}) error) error {
 	if err := f(&s); err != nil {
 		return err
 	}
+	return r.unmarshalString(s)
+}
+
+func (r *Regex) unmarshalString(s string) error {
 	sAnchored := "^(?:" + s + ")$"
 	re, err := regexp.Compile(sAnchored)
 	if err != nil {
@@ -394,10 +510,11 @@ func (r *Regex) MarshalYAML() (interface{}, error) {
 }
 
 var (
-	configReloads      = metrics.NewCounter(`vmauth_config_last_reload_total`)
-	configReloadErrors = metrics.NewCounter(`vmauth_config_last_reload_errors_total`)
-	configSuccess      = metrics.NewGauge(`vmauth_config_last_reload_successful`, nil)
-	configTimestamp    = metrics.NewCounter(`vmauth_config_last_reload_success_timestamp_seconds`)
+	configReloads        = metrics.NewCounter(`vmauth_config_last_reload_total`)
+	configReloadErrors   = metrics.NewCounter(`vmauth_config_last_reload_errors_total`)
+	configSuccess        = metrics.NewGauge(`vmauth_config_last_reload_successful`, nil)
+	configTimestamp      = metrics.NewCounter(`vmauth_config_last_reload_success_timestamp_seconds`)
+	configErrorTimestamp = metrics.NewCounter(`vmauth_config_last_reload_error_timestamp_seconds`)
 )
 
 func initAuthConfig() {
@@ -429,6 +546,7 @@ func initAuthConfig() {
 func stopAuthConfig() {
 	close(stopCh)
 	authConfigWG.Wait()
+	authConfig.Load().healthChecker.stop()
 }
 
 func authConfigReloader(sighupCh <-chan os.Signal) {
@@ -440,6 +558,17 @@ func authConfigReloader(sighupCh <-chan os.Signal) {
 		refreshCh = ticker.C
 	}
 
+	var watchCh <-chan struct{}
+	if *configWatch {
+		w, err := newConfigWatcher(*authConfigPath)
+		if err != nil {
+			logger.Errorf("cannot watch -auth.config=%q for changes; the config is re-read only on SIGHUP and every -configCheckInterval; error: %s", *authConfigPath, err)
+		} else {
+			defer w.stop()
+			watchCh = w.ch
+		}
+	}
+
 	updateFn := func() {
 		configReloads.Inc()
 		updated, err := loadAuthConfig()
@@ -447,6 +576,7 @@ func authConfigReloader(sighupCh <-chan os.Signal) {
 			logger.Errorf("failed to load auth config; using the last successfully loaded config; error: %s", err)
 			configSuccess.Set(0)
 			configReloadErrors.Inc()
+			configErrorTimestamp.Set(fasttime.UnixTimestamp())
 			return
 		}
 		configSuccess.Set(1)
@@ -461,6 +591,8 @@ func authConfigReloader(sighupCh <-chan os.Signal) {
 			return
 		case <-refreshCh:
 			updateFn()
+		case <-watchCh:
+			updateFn()
 		case <-sighupCh:
 			logger.Infof("SIGHUP received; loading -auth.config=%q", *authConfigPath)
 			updateFn()
@@ -509,8 +641,13 @@ func loadAuthConfig() (bool, error) {
 	prevAc := authConfig.Load()
 	if prevAc != nil {
 		metrics.UnregisterSet(prevAc.ms)
+		prevAc.healthChecker.stop()
 	}
+	_ = ac.ms.NewGauge(fmt.Sprintf(`vmauth_config_info{hash="%016X"}`, xxhash.Sum64(data)), func() float64 {
+		return 1
+	})
 	metrics.RegisterSet(ac.ms)
+	ac.healthChecker = ac.startHealthChecks()
 	authConfig.Store(ac)
 	authConfigData.Store(&data)
 	authUsers.Store(&m)
@@ -530,6 +667,12 @@ func parseAuthConfig(data []byte) (*AuthConfig, error) {
 		return nil, fmt.Errorf("cannot unmarshal AuthConfig data: %w", err)
 	}
 
+	if ac.IPFilters != nil {
+		if err := ac.IPFilters.init(); err != nil {
+			return nil, fmt.Errorf("cannot parse global ip_filters: %w", err)
+		}
+	}
+
 	ui := ac.UnauthorizedUser
 	if ui != nil {
 		if ui.Username != "" {
@@ -544,9 +687,18 @@ func parseAuthConfig(data []byte) (*AuthConfig, error) {
 		if ui.Name != "" {
 			return nil, fmt.Errorf("field name can't be specified for unauthorized_user section")
 		}
+		if ui.JWT != nil {
+			return nil, fmt.Errorf("field jwt can't be specified for unauthorized_user section")
+		}
+		if ui.MTLS != nil {
+			return nil, fmt.Errorf("field mtls can't be specified for unauthorized_user section")
+		}
 		if err := ui.initURLs(); err != nil {
 			return nil, err
 		}
+		if err := ui.initIPFilters(); err != nil {
+			return nil, fmt.Errorf("cannot parse ip_filters for unauthorized_user: %w", err)
+		}
 
 		metricLabels, err := ui.getMetricLabels()
 		if err != nil {
@@ -563,6 +715,12 @@ func parseAuthConfig(data []byte) (*AuthConfig, error) {
 		_ = ac.ms.NewGauge(`vmauth_unauthorized_user_concurrent_requests_current`+metricLabels, func() float64 {
 			return float64(len(ui.concurrencyLimitCh))
 		})
+		if err := ui.initRateLimiter(ac.ms, `vmauth_unauthorized_user`, metricLabels); err != nil {
+			return nil, fmt.Errorf("cannot initialize rate limiter for unauthorized_user: %w", err)
+		}
+		if err := ui.initRequestStats(ac.ms, `vmauth_unauthorized_user`, metricLabels); err != nil {
+			return nil, fmt.Errorf("invalid config for unauthorized_user: %w", err)
+		}
 
 		tr, err := getTransport(ui.TLSInsecureSkipVerify, ui.TLSCAFile)
 		if err != nil {
@@ -578,7 +736,10 @@ func parseAuthConfigUsers(ac *AuthConfig) (map[string]*UserInfo, error) {
 	if len(uis) == 0 && ac.UnauthorizedUser == nil {
 		return nil, fmt.Errorf("Missing `users` or `unauthorized_user` sections")
 	}
+	ac.jwtUsers = nil
+	ac.mtlsUsers = nil
 	byAuthToken := make(map[string]*UserInfo, len(uis))
+	jwtUserNames := make(map[string]bool)
 	for i := range uis {
 		ui := &uis[i]
 		if ui.Username != "" && ui.Password == "" {
@@ -589,9 +750,33 @@ func parseAuthConfigUsers(ac *AuthConfig) (map[string]*UserInfo, error) {
 				return nil, fmt.Errorf("bearer_token=%q and username=%q cannot be set simultaneously", ui.BearerToken, ui.Username)
 			}
 		}
+		if ui.JWT != nil {
+			if ui.BearerToken != "" || ui.Username != "" {
+				return nil, fmt.Errorf("jwt cannot be set simultaneously with bearer_token or username for user %q", ui.name())
+			}
+			if err := ui.JWT.init(); err != nil {
+				return nil, fmt.Errorf("cannot initialize jwt for user %q: %w", ui.name(), err)
+			}
+			// Multiple users may share the same jwks_url, so the name is required for distinguishing their metrics.
+			if ui.Name == "" {
+				return nil, fmt.Errorf("missing `name` for user with jwt section; it is required for distinguishing metrics of users with jwt sections")
+			}
+			if jwtUserNames[ui.Name] {
+				return nil, fmt.Errorf("duplicate name=%q for users with jwt sections", ui.Name)
+			}
+			jwtUserNames[ui.Name] = true
+		}
+		if ui.MTLS != nil {
+			if ui.BearerToken != "" || ui.Username != "" || ui.JWT != nil {
+				return nil, fmt.Errorf("mtls cannot be set simultaneously with bearer_token, username or jwt for user %q", ui.name())
+			}
+			if err := ui.MTLS.validate(); err != nil {
+				return nil, fmt.Errorf("invalid mtls config for user %q: %w", ui.name(), err)
+			}
+		}
 		ats := getAuthTokens(ui.BearerToken, ui.Username, ui.Password)
-		if len(ats) == 0 {
-			return nil, fmt.Errorf("one of bearer_token, username or mtls must be set")
+		if len(ats) == 0 && ui.JWT == nil && ui.MTLS == nil {
+			return nil, fmt.Errorf("one of bearer_token, username, jwt or mtls must be set")
 		}
 		for _, at := range ats {
 			if uiOld := byAuthToken[at]; uiOld != nil {
@@ -603,6 +788,9 @@ func parseAuthConfigUsers(ac *AuthConfig) (map[string]*UserInfo, error) {
 		if err := ui.initURLs(); err != nil {
 			return nil, err
 		}
+		if err := ui.initIPFilters(); err != nil {
+			return nil, fmt.Errorf("cannot parse ip_filters for user %q: %w", ui.name(), err)
+		}
 
 		if ui.BearerToken != "" && ui.Password != "" {
 			return nil, fmt.Errorf("password shouldn't be set for bearer_token %q", ui.BearerToken)
@@ -624,6 +812,12 @@ func parseAuthConfigUsers(ac *AuthConfig) (map[string]*UserInfo, error) {
 		_ = ac.ms.GetOrCreateGauge(`vmauth_user_concurrent_requests_current`+metricLabels, func() float64 {
 			return float64(len(ui.concurrencyLimitCh))
 		})
+		if err := ui.initRateLimiter(ac.ms, `vmauth_user`, metricLabels); err != nil {
+			return nil, fmt.Errorf("cannot initialize rate limiter for user %q: %w", ui.name(), err)
+		}
+		if err := ui.initRequestStats(ac.ms, `vmauth_user`, metricLabels); err != nil {
+			return nil, fmt.Errorf("invalid config for user %q: %w", ui.name(), err)
+		}
 
 		tr, err := getTransport(ui.TLSInsecureSkipVerify, ui.TLSCAFile)
 		if err != nil {
@@ -634,6 +828,12 @@ func parseAuthConfigUsers(ac *AuthConfig) (map[string]*UserInfo, error) {
 		for _, at := range ats {
 			byAuthToken[at] = ui
 		}
+		if ui.JWT != nil {
+			ac.jwtUsers = append(ac.jwtUsers, ui)
+		}
+		if ui.MTLS != nil {
+			ac.mtlsUsers = append(ac.mtlsUsers, ui)
+		}
 	}
 	return byAuthToken, nil
 }
@@ -664,7 +864,11 @@ func (ui *UserInfo) getMetricLabels() (string, error) {
 func (ui *UserInfo) initURLs() error {
 	retryStatusCodes := defaultRetryStatusCodes.Values()
 	loadBalancingPolicy := *defaultLoadBalancingPolicy
+	healthCheckPath := ui.HealthCheckPath
 	dropSrcPathPrefixParts := 0
+	if err := validateHealthCheckPath(healthCheckPath); err != nil {
+		return err
+	}
 	if ui.URLPrefix != nil {
 		if err := ui.URLPrefix.sanitize(); err != nil {
 			return err
@@ -680,6 +884,7 @@ func (ui *UserInfo) initURLs() error {
 		}
 		ui.URLPrefix.retryStatusCodes = retryStatusCodes
 		ui.URLPrefix.dropSrcPathPrefixParts = dropSrcPathPrefixParts
+		ui.URLPrefix.healthCheckPath = healthCheckPath
 		if err := ui.URLPrefix.setLoadBalancingPolicy(loadBalancingPolicy); err != nil {
 			return err
 		}
@@ -696,11 +901,29 @@ func (ui *UserInfo) initURLs() error {
 		if e.URLPrefix == nil {
 			return fmt.Errorf("missing `url_prefix` in `url_map`")
 		}
+		if len(e.RequiredScopes) > 0 && ui.JWT == nil {
+			return fmt.Errorf("`required_scopes` in `url_map` can be used only for users with `jwt` section")
+		}
+		for i, m := range e.SrcMethods {
+			e.SrcMethods[i] = strings.ToUpper(m)
+		}
+		if e.TargetPath != "" {
+			if len(e.SrcPaths) == 0 {
+				return fmt.Errorf("missing `src_paths` in `url_map` with `target_path: %q`", e.TargetPath)
+			}
+			if !strings.HasPrefix(e.TargetPath, "/") {
+				return fmt.Errorf("`target_path: %q` must start with `/`", e.TargetPath)
+			}
+			if e.DropSrcPathPrefixParts != nil {
+				return fmt.Errorf("`drop_src_path_prefix_parts` cannot be used together with `target_path: %q` in `url_map`", e.TargetPath)
+			}
+		}
 		if err := e.URLPrefix.sanitize(); err != nil {
 			return err
 		}
 		rscs := retryStatusCodes
 		lbp := loadBalancingPolicy
+		hcp := healthCheckPath
 		dsp := dropSrcPathPrefixParts
 		if e.RetryStatusCodes != nil {
 			rscs = e.RetryStatusCodes
@@ -708,14 +931,25 @@ func (ui *UserInfo) initURLs() error {
 		if e.LoadBalancingPolicy != "" {
 			lbp = e.LoadBalancingPolicy
 		}
+		if e.HealthCheckPath != "" {
+			if err := validateHealthCheckPath(e.HealthCheckPath); err != nil {
+				return err
+			}
+			hcp = e.HealthCheckPath
+		}
 		if e.DropSrcPathPrefixParts != nil {
 			dsp = *e.DropSrcPathPrefixParts
 		}
+		if e.TargetPath != "" {
+			// The request path is replaced with target_path, so there is no need in dropping its parts.
+			dsp = 0
+		}
 		e.URLPrefix.retryStatusCodes = rscs
 		if err := e.URLPrefix.setLoadBalancingPolicy(lbp); err != nil {
 			return err
 		}
 		e.URLPrefix.dropSrcPathPrefixParts = dsp
+		e.URLPrefix.healthCheckPath = hcp
 	}
 	if len(ui.URLMaps) == 0 && ui.URLPrefix == nil {
 		return fmt.Errorf("missing `url_prefix`")
@@ -723,6 +957,20 @@ func (ui *UserInfo) initURLs() error {
 	return nil
 }
 
+func validateHealthCheckPath(path string) error {
+	if path != "" && !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("`health_check_path: %q` must start with `/`", path)
+	}
+	return nil
+}
+
+func (ui *UserInfo) initIPFilters() error {
+	if ui.IPFilters == nil {
+		return nil
+	}
+	return ui.IPFilters.init()
+}
+
 func (ui *UserInfo) name() string {
 	if ui.Name != "" {
 		return ui.Name
@@ -734,6 +982,9 @@ func (ui *UserInfo) name() string {
 		h := xxhash.Sum64([]byte(ui.BearerToken))
 		return fmt.Sprintf("bearer_token:hash:%016X", h)
 	}
+	if ui.MTLS != nil {
+		return "mtls:" + ui.MTLS.String()
+	}
 	return ""
 }
 
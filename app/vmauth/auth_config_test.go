@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/flagutil"
 	"gopkg.in/yaml.v2"
 )
 
@@ -238,6 +240,135 @@ users:
   metric_labels:
     not-prometheus-compatible: value
 `)
+
+	// Invalid regexp in src_headers
+	f(`
+users:
+- username: a
+  url_map:
+  - src_paths: ['/foo']
+    src_headers: ['X-Scope-OrgID: fo[obar']
+    url_prefix: http://foobar
+`)
+
+	// Missing header name in src_headers
+	f(`
+users:
+- username: a
+  url_map:
+  - src_paths: ['/foo']
+    src_headers: [': foobar']
+    url_prefix: http://foobar
+`)
+
+	// target_path without src_paths
+	f(`
+users:
+- username: a
+  url_map:
+  - src_hosts: ['foo']
+    target_path: /bar
+    url_prefix: http://foobar
+`)
+
+	// target_path without leading slash
+	f(`
+users:
+- username: a
+  url_map:
+  - src_paths: ['/foo/(.+)']
+    target_path: bar/$1
+    url_prefix: http://foobar
+`)
+
+	// target_path with drop_src_path_prefix_parts
+	f(`
+users:
+- username: a
+  url_map:
+  - src_paths: ['/foo/(.+)']
+    target_path: /bar/$1
+    drop_src_path_prefix_parts: 1
+    url_prefix: http://foobar
+`)
+
+	// Invalid ip_filters
+	f(`
+users:
+- username: foo
+  url_prefix: http://foo.bar
+  ip_filters:
+    allow_list: [foobar]
+`)
+	f(`
+users:
+- username: foo
+  url_prefix: http://foo.bar
+ip_filters:
+  deny_list: [1.2.3.4/99]
+`)
+
+	// Invalid health_check_path
+	f(`
+users:
+- username: foo
+  url_prefix: [http://foo.bar, http://baz]
+  health_check_path: health
+`)
+	f(`
+users:
+- username: foo
+  url_map:
+  - src_paths: ["/api/v1/query"]
+    url_prefix: [http://foo.bar, http://baz]
+    health_check_path: health
+`)
+
+	// Negative requests_per_second
+	f(`
+users:
+- username: foo
+  url_prefix: http://foo.bar
+  requests_per_second: -1
+`)
+
+	// Empty mtls section
+	f(`
+users:
+- mtls: {}
+  url_prefix: http://foo.bar
+`)
+
+	// mtls with username
+	f(`
+users:
+- username: foo
+  mtls:
+    common_name: foo
+  url_prefix: http://foo.bar
+`)
+
+	// mtls in unauthorized_user
+	f(`
+unauthorized_user:
+  mtls:
+    common_name: foo
+  url_prefix: http://foo.bar
+`)
+
+	// Invalid max_request_body_size
+	f(`
+users:
+- username: foo
+  url_prefix: http://foo.bar
+  max_request_body_size: foobar
+`)
+	f(`
+users:
+- username: foo
+  url_prefix: http://foo.bar
+  max_request_body_size: -1
+`)
 }
 
 func TestParseAuthConfigSuccess(t *testing.T) {
@@ -265,6 +396,8 @@ users:
   password: bar
   url_prefix: http://aaa:343/bbb
   max_concurrent_requests: 5
+  requests_per_second: 2.5
+  max_request_body_size: 10MiB
   tls_insecure_skip_verify: true
 `, map[string]*UserInfo{
 		getHTTPAuthBasicToken("foo", "bar"): {
@@ -272,6 +405,8 @@ users:
 			Password:              "bar",
 			URLPrefix:             mustParseURL("http://aaa:343/bbb"),
 			MaxConcurrentRequests: 5,
+			RequestsPerSecond:     2.5,
+			MaxRequestBodySize:    mustParseBytes("10MiB"),
 			TLSInsecureSkipVerify: &insecureSkipVerifyTrue,
 		},
 	})
@@ -395,6 +530,34 @@ users:
 			},
 		},
 	})
+	// URLMap with routing by methods and headers and with path rewriting
+	f(`
+users:
+- username: foo
+  password: bar
+  url_map:
+  - src_paths: ["/select/([0-9]+)/(.+)"]
+    src_methods: [get, POST]
+    src_headers:
+    - "X-Scope-OrgID: team-.+"
+    target_path: /select/$1/prometheus/$2
+    url_prefix: http://vmselect
+`, map[string]*UserInfo{
+		getHTTPAuthBasicToken("foo", "bar"): {
+			Username: "foo",
+			Password: "bar",
+			URLMaps: []URLMap{
+				{
+					SrcPaths:   getRegexs([]string{"/select/([0-9]+)/(.+)"}),
+					SrcMethods: []string{"GET", "POST"},
+					SrcHeaders: getHeaderFilters([]string{"X-Scope-OrgID: team-.+"}),
+					TargetPath: "/select/$1/prometheus/$2",
+					URLPrefix:  mustParseURL("http://vmselect"),
+				},
+			},
+		},
+	})
+
 	// Multiple users with the same name - this should work, since these users have different passwords
 	f(`
 users:
@@ -667,6 +830,18 @@ func getRegexs(paths []string) []*Regex {
 	return sps
 }
 
+func getHeaderFilters(headers []string) []*HeaderFilter {
+	var hfs []*HeaderFilter
+	for _, h := range headers {
+		n := strings.IndexByte(h, ':')
+		hfs = append(hfs, &HeaderFilter{
+			Name:  strings.TrimSpace(h[:n]),
+			Value: getRegexs([]string{strings.TrimSpace(h[n+1:])})[0],
+		})
+	}
+	return hfs
+}
+
 func removeMetrics(m map[string]*UserInfo) {
 	for _, info := range m {
 		info.requests = nil
@@ -688,6 +863,14 @@ func areEqualConfigs(a, b map[string]*UserInfo) error {
 	return nil
 }
 
+func mustParseBytes(s string) *flagutil.Bytes {
+	var b flagutil.Bytes
+	if err := b.Set(s); err != nil {
+		panic(fmt.Errorf("cannot parse %q: %w", s, err))
+	}
+	return &b
+}
+
 func mustParseURL(u string) *URLPrefix {
 	return mustParseURLs([]string{u})
 }
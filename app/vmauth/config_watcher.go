@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+)
+
+// configWatcher notifies about changes of the local file.
+type configWatcher struct {
+	// ch receives a notification on every change of the watched file
+	ch chan struct{}
+
+	path string
+	w    *fsnotify.Watcher
+	wg   sync.WaitGroup
+
+	// fi contains the last seen file info for the watched file
+	fi os.FileInfo
+}
+
+// newConfigWatcher starts watching for changes of the file at the given path.
+//
+// The parent directory is watched instead of the file itself, so atomic file replacements
+// such as Kubernetes ConfigMap updates are detected.
+//
+// configWatcher.stop() must be called when the watcher is no longer needed.
+func newConfigWatcher(path string) (*configWatcher, error) {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return nil, fmt.Errorf("cannot watch http url; use -configCheckInterval instead")
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create file watcher: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if err := w.Add(dir); err != nil {
+		_ = w.Close()
+		return nil, fmt.Errorf("cannot watch directory %q: %w", dir, err)
+	}
+	cw := &configWatcher{
+		ch:   make(chan struct{}, 1),
+		path: path,
+		w:    w,
+	}
+	cw.fi, _ = os.Stat(path)
+	cw.wg.Add(1)
+	go func() {
+		defer cw.wg.Done()
+		cw.run()
+	}()
+	return cw, nil
+}
+
+func (cw *configWatcher) run() {
+	for {
+		select {
+		case _, ok := <-cw.w.Events:
+			if !ok {
+				return
+			}
+			// Do not filter events by file name, since the file may be a symlink updated via other files in the directory.
+			// Verify whether the file has been changed instead, so changes of unrelated files in the directory are ignored.
+			if !cw.isFileChanged() {
+				continue
+			}
+			select {
+			case cw.ch <- struct{}{}:
+			default:
+			}
+		case err, ok := <-cw.w.Errors:
+			if !ok {
+				return
+			}
+			logger.Errorf("error when watching -auth.config for changes: %s", err)
+		}
+	}
+}
+
+func (cw *configWatcher) isFileChanged() bool {
+	fi, err := os.Stat(cw.path)
+	if err != nil {
+		// The file may be temporarily missing during the update. Wait for the next event.
+		return false
+	}
+	prev := cw.fi
+	cw.fi = fi
+	if prev == nil {
+		return true
+	}
+	return !os.SameFile(prev, fi) || !prev.ModTime().Equal(fi.ModTime()) || prev.Size() != fi.Size()
+}
+
+func (cw *configWatcher) stop() {
+	_ = cw.w.Close()
+	cw.wg.Wait()
+}
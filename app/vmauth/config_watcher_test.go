@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.yml")
+	if err := os.WriteFile(path, []byte("users: []"), 0o600); err != nil {
+		t.Fatalf("cannot write %q: %s", path, err)
+	}
+	cw, err := newConfigWatcher(path)
+	if err != nil {
+		t.Fatalf("cannot create config watcher: %s", err)
+	}
+	defer cw.stop()
+
+	waitNotification := func() {
+		t.Helper()
+		select {
+		case <-cw.ch:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout when waiting for config change notification")
+		}
+	}
+
+	// Changes of other files in the directory must be ignored
+	otherPath := filepath.Join(filepath.Dir(path), "other.log")
+	if err := os.WriteFile(otherPath, []byte("foobar"), 0o600); err != nil {
+		t.Fatalf("cannot write %q: %s", otherPath, err)
+	}
+	select {
+	case <-cw.ch:
+		t.Fatalf("unexpected notification for changes of other file")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Update the file in place
+	if err := os.WriteFile(path, []byte("users: [{username: foo}]"), 0o600); err != nil {
+		t.Fatalf("cannot update %q: %s", path, err)
+	}
+	waitNotification()
+
+	// Replace the file atomically
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte("users: [{username: bar}]"), 0o600); err != nil {
+		t.Fatalf("cannot write %q: %s", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		t.Fatalf("cannot rename %q to %q: %s", tmpPath, path, err)
+	}
+	waitNotification()
+}
+
+func TestConfigWatcherFailure(t *testing.T) {
+	f := func(path string) {
+		t.Helper()
+		cw, err := newConfigWatcher(path)
+		if err == nil {
+			cw.stop()
+			t.Fatalf("expecting non-nil error for path=%q", path)
+		}
+	}
+	f("http://foo.bar/auth.yml")
+	f(filepath.Join(t.TempDir(), "missing-dir", "auth.yml"))
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+)
+
+var (
+	healthCheckInterval = flag.Duration("backend.healthCheckInterval", 5*time.Second, "Interval between active health checks for backends with health_check_path option in -auth.config. "+
+		"See https://docs.victoriametrics.com/vmauth.html#health-checks")
+	healthCheckTimeout = flag.Duration("backend.healthCheckTimeout", 3*time.Second, "Timeout for active health checks for backends with health_check_path option in -auth.config. "+
+		"See https://docs.victoriametrics.com/vmauth.html#health-checks")
+)
+
+// healthChecker performs active health checks for backends from the given AuthConfig.
+type healthChecker struct {
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// healthCheckKey identifies a single health check target.
+type healthCheckKey struct {
+	healthURL string
+	tr        http.RoundTripper
+}
+
+// healthCheckTarget is a health check target shared among all the backends with the same health check url and transport.
+type healthCheckTarget struct {
+	healthURL *url.URL
+	c         *http.Client
+
+	// bus contains backends, which are updated with the health check results.
+	//
+	// The same backend may be referred by multiple users, while every user has its own backendURL instances.
+	bus []*backendURL
+}
+
+// startHealthChecks starts active health checks for url_prefix backends with health_check_path option.
+//
+// Every unique backend is checked only once, even if it is referred by multiple users.
+//
+// healthChecker.stop() must be called when the health checks are no longer needed.
+func (ac *AuthConfig) startHealthChecks() *healthChecker {
+	hc := &healthChecker{
+		stopCh: make(chan struct{}),
+	}
+
+	targets := make(map[healthCheckKey]*healthCheckTarget)
+	var keys []healthCheckKey
+	addURLPrefix := func(up *URLPrefix, tr http.RoundTripper) {
+		if up == nil || up.healthCheckPath == "" {
+			return
+		}
+		for _, bu := range up.bus {
+			healthURL := getHealthCheckURL(bu.url, up.healthCheckPath)
+			k := healthCheckKey{
+				healthURL: healthURL.String(),
+				tr:        tr,
+			}
+			t := targets[k]
+			if t == nil {
+				t = &healthCheckTarget{
+					healthURL: healthURL,
+					c: &http.Client{
+						Transport: tr,
+						Timeout:   *healthCheckTimeout,
+					},
+				}
+				targets[k] = t
+				keys = append(keys, k)
+			}
+			t.bus = append(t.bus, bu)
+		}
+	}
+	addUser := func(ui *UserInfo) {
+		addURLPrefix(ui.URLPrefix, ui.httpTransport)
+		for _, e := range ui.URLMaps {
+			addURLPrefix(e.URLPrefix, ui.httpTransport)
+		}
+	}
+	for i := range ac.Users {
+		addUser(&ac.Users[i])
+	}
+	if ac.UnauthorizedUser != nil {
+		addUser(ac.UnauthorizedUser)
+	}
+
+	busByBackend := make(map[string][]*backendURL)
+	var backends []string
+	for _, k := range keys {
+		t := targets[k]
+		for _, bu := range t.bus {
+			backend := bu.url.Redacted()
+			if _, ok := busByBackend[backend]; !ok {
+				backends = append(backends, backend)
+			}
+			busByBackend[backend] = append(busByBackend[backend], bu)
+		}
+		hc.wg.Add(1)
+		go func() {
+			defer hc.wg.Done()
+			t.runHealthChecks(hc.stopCh)
+		}()
+	}
+	for _, backend := range backends {
+		bus := busByBackend[backend]
+		_ = ac.ms.GetOrCreateGauge(fmt.Sprintf(`vmauth_backend_healthy{backend=%q}`, backend), func() float64 {
+			for _, bu := range bus {
+				if atomic.LoadInt32(&bu.unhealthy) != 0 {
+					return 0
+				}
+			}
+			return 1
+		})
+	}
+	return hc
+}
+
+func getHealthCheckURL(backendURL *url.URL, healthCheckPath string) *url.URL {
+	healthURL := *backendURL
+	healthURL.Path = healthCheckPath
+	healthURL.RawPath = ""
+	healthURL.RawQuery = ""
+	return &healthURL
+}
+
+var healthChecksFailed = metrics.NewCounter(`vmauth_backend_health_checks_failed_total`)
+
+func (hc *healthChecker) stop() {
+	close(hc.stopCh)
+	hc.wg.Wait()
+}
+
+func (t *healthCheckTarget) runHealthChecks(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	defer cancel()
+
+	tc := time.NewTicker(*healthCheckInterval)
+	defer tc.Stop()
+	for {
+		err := checkBackendHealth(ctx, t.c, t.healthURL)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if t.setUnhealthy(true) {
+				logger.Warnf("backend %s is marked as unhealthy because of failed health check: %s", t.bus[0].url.Redacted(), err)
+			}
+			healthChecksFailed.Inc()
+		} else if t.setUnhealthy(false) {
+			logger.Infof("backend %s is marked as healthy after successful health check", t.bus[0].url.Redacted())
+		}
+		select {
+		case <-stopCh:
+			return
+		case <-tc.C:
+		}
+	}
+}
+
+// setUnhealthy updates the health state for all the backends of t. It returns true if the state has been changed for any of them.
+func (t *healthCheckTarget) setUnhealthy(unhealthy bool) bool {
+	changed := false
+	for _, bu := range t.bus {
+		if bu.setUnhealthy(unhealthy) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// checkBackendHealth sends health check request to healthURL.
+//
+// The returned error doesn't contain the password from healthURL.
+func checkBackendHealth(ctx context.Context, c *http.Client, healthURL *url.URL) error {
+	// Use the real url with the password for the request, while the redacted url is used for error messages.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("cannot create request to %s: %w", healthURL.Redacted(), err)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code returned from %s: %d; want 2xx", healthURL.Redacted(), resp.StatusCode)
+	}
+	return nil
+}
+
+// setUnhealthy updates the health state of bu. It returns true if the state has been changed.
+func (bu *backendURL) setUnhealthy(unhealthy bool) bool {
+	v := int32(0)
+	if unhealthy {
+		v = 1
+	}
+	return atomic.SwapInt32(&bu.unhealthy, v) != v
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthChecks(t *testing.T) {
+	newBackend := func(statusCode int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/health" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(statusCode)
+		}))
+	}
+	healthy := newBackend(http.StatusOK)
+	defer healthy.Close()
+	unhealthy := newBackend(http.StatusServiceUnavailable)
+	defer unhealthy.Close()
+
+	data := fmt.Sprintf(`
+users:
+- username: foo
+  url_prefix:
+  - %s/select/0/prometheus
+  - %s/select/0/prometheus
+  health_check_path: /health
+`, unhealthy.URL, healthy.URL)
+	ac, err := parseAuthConfig([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	m, err := parseAuthConfigUsers(ac)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ui := m[getHTTPAuthBasicToken("foo", "")]
+	up := ui.URLPrefix
+	if up.healthCheckPath != "/health" {
+		t.Fatalf("unexpected healthCheckPath; got %q; want %q", up.healthCheckPath, "/health")
+	}
+
+	hc := ac.startHealthChecks()
+	defer hc.stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !up.bus[0].isBroken() {
+		if time.Now().After(deadline) {
+			t.Fatalf("the unhealthy backend must be marked as broken")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if up.bus[1].isBroken() {
+		t.Fatalf("the healthy backend mustn't be marked as broken")
+	}
+
+	// All the requests must be sent to the healthy backend
+	for i := 0; i < 10; i++ {
+		bu := up.getBackendURL()
+		bu.put()
+		if bu != up.bus[1] {
+			t.Fatalf("unexpected backend selected: %s; want %s", bu.url, up.bus[1].url)
+		}
+	}
+}
+
+func TestHealthChecksSharedBackend(t *testing.T) {
+	var requests atomic.Int64
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer backend.Close()
+
+	data := fmt.Sprintf(`
+users:
+- username: foo
+  url_prefix: %s/select/0/prometheus
+  health_check_path: /health
+- username: bar
+  url_prefix: %s/select/1/prometheus
+  health_check_path: /health
+`, backend.URL, backend.URL)
+	ac, err := parseAuthConfig([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	m, err := parseAuthConfigUsers(ac)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	buFoo := m[getHTTPAuthBasicToken("foo", "")].URLPrefix.bus[0]
+	buBar := m[getHTTPAuthBasicToken("bar", "")].URLPrefix.bus[0]
+
+	hc := ac.startHealthChecks()
+	defer hc.stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !buFoo.isBroken() || !buBar.isBroken() {
+		if time.Now().After(deadline) {
+			t.Fatalf("the unhealthy backend must be marked as broken for all the users")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// The shared backend must be checked only once per -backend.healthCheckInterval
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("unexpected number of health check requests; got %d; want 1", n)
+	}
+}
+
+func TestCheckBackendHealthWithPassword(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "foo" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	f := func(password string, resultExpected bool) {
+		t.Helper()
+		u, err := url.Parse(backend.URL + "/health")
+		if err != nil {
+			t.Fatalf("cannot parse url: %s", err)
+		}
+		u.User = url.UserPassword("foo", password)
+		err = checkBackendHealth(context.Background(), backend.Client(), u)
+		if result := err == nil; result != resultExpected {
+			t.Fatalf("unexpected result; got %v; want %v; err: %v", result, resultExpected, err)
+		}
+		if err != nil && strings.Contains(err.Error(), password) {
+			t.Fatalf("the error mustn't contain the password: %s", err)
+		}
+	}
+	f("secret", true)
+	f("wrong-password", false)
+}
+
+func TestBackendURLSetUnhealthy(t *testing.T) {
+	bu := &backendURL{}
+	if bu.isBroken() {
+		t.Fatalf("backend mustn't be broken by default")
+	}
+	if !bu.setUnhealthy(true) {
+		t.Fatalf("expecting state change")
+	}
+	if bu.setUnhealthy(true) {
+		t.Fatalf("unexpected state change")
+	}
+	if !bu.isBroken() {
+		t.Fatalf("unhealthy backend must be broken")
+	}
+	if !bu.setUnhealthy(false) {
+		t.Fatalf("expecting state change")
+	}
+	if bu.isBroken() {
+		t.Fatalf("healthy backend mustn't be broken")
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// IPFilters contains lists of IP addresses and networks, which are allowed or denied to access vmauth.
+type IPFilters struct {
+	AllowList []string `yaml:"allow_list,omitempty"`
+	DenyList  []string `yaml:"deny_list,omitempty"`
+
+	allowNets []*net.IPNet
+	denyNets  []*net.IPNet
+}
+
+func (ipf *IPFilters) init() error {
+	allowNets, err := parseIPNets(ipf.AllowList)
+	if err != nil {
+		return fmt.Errorf("cannot parse `allow_list`: %w", err)
+	}
+	denyNets, err := parseIPNets(ipf.DenyList)
+	if err != nil {
+		return fmt.Errorf("cannot parse `deny_list`: %w", err)
+	}
+	ipf.allowNets = allowNets
+	ipf.denyNets = denyNets
+	return nil
+}
+
+// isAllowed returns true if the given ip is allowed by ipf.
+//
+// The ip is denied if it matches deny_list entries or if it doesn't match non-empty allow_list.
+func (ipf *IPFilters) isAllowed(ip net.IP) bool {
+	if ipf == nil {
+		return true
+	}
+	if ip == nil {
+		// Deny requests with unknown remote address if any filters are set.
+		return len(ipf.allowNets) == 0 && len(ipf.denyNets) == 0
+	}
+	if containsIP(ipf.denyNets, ip) {
+		return false
+	}
+	if len(ipf.allowNets) == 0 {
+		return true
+	}
+	return containsIP(ipf.allowNets, ip)
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseIPNets(a []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(a))
+	for _, s := range a {
+		s = strings.TrimSpace(s)
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("cannot parse IP address %q", s)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{
+				IP:   ip,
+				Mask: net.CIDRMask(bits, bits),
+			})
+			continue
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse network %q: %w", s, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// getRemoteIP returns the IP address of the client, which sent the r.
+//
+// nil is returned if the address cannot be determined.
+func getRemoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
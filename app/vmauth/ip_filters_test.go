@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestIPFiltersIsAllowed(t *testing.T) {
+	f := func(ipf *IPFilters, ip string, resultExpected bool) {
+		t.Helper()
+		if ipf != nil {
+			if err := ipf.init(); err != nil {
+				t.Fatalf("cannot initialize ip_filters: %s", err)
+			}
+		}
+		result := ipf.isAllowed(net.ParseIP(ip))
+		if result != resultExpected {
+			t.Fatalf("unexpected result for ip=%q; got %v; want %v", ip, result, resultExpected)
+		}
+	}
+
+	// nil filters allow everything
+	f(nil, "1.2.3.4", true)
+	f(nil, "", true)
+
+	// empty filters allow everything
+	f(&IPFilters{}, "1.2.3.4", true)
+
+	// allow_list
+	ipf := &IPFilters{
+		AllowList: []string{"10.0.0.0/24", "1.2.3.4", "2001:db8::/32"},
+	}
+	f(ipf, "10.0.0.1", true)
+	f(ipf, "1.2.3.4", true)
+	f(ipf, "2001:db8::1", true)
+	f(ipf, "::ffff:10.0.0.5", true)
+	f(ipf, "10.0.1.1", false)
+	f(ipf, "1.2.3.5", false)
+	f(ipf, "", false)
+
+	// deny_list
+	ipf = &IPFilters{
+		DenyList: []string{"10.0.0.42", "192.168.0.0/16"},
+	}
+	f(ipf, "10.0.0.42", false)
+	f(ipf, "192.168.1.1", false)
+	f(ipf, "10.0.0.43", true)
+	f(ipf, "", false)
+
+	// deny_list has priority over allow_list
+	ipf = &IPFilters{
+		AllowList: []string{"10.0.0.0/24"},
+		DenyList:  []string{"10.0.0.42"},
+	}
+	f(ipf, "10.0.0.1", true)
+	f(ipf, "10.0.0.42", false)
+	f(ipf, "127.0.0.1", false)
+}
+
+func TestIPFiltersInitFailure(t *testing.T) {
+	f := func(ipf *IPFilters) {
+		t.Helper()
+		if err := ipf.init(); err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+	f(&IPFilters{
+		AllowList: []string{"foobar"},
+	})
+	f(&IPFilters{
+		AllowList: []string{"1.2.3.4/33"},
+	})
+	f(&IPFilters{
+		DenyList: []string{"1.2.3"},
+	})
+}
+
+func TestGetRemoteIP(t *testing.T) {
+	f := func(remoteAddr, ipExpected string) {
+		t.Helper()
+		r := &http.Request{
+			RemoteAddr: remoteAddr,
+		}
+		ip := getRemoteIP(r)
+		if ip.String() != ipExpected {
+			t.Fatalf("unexpected ip for remoteAddr=%q; got %q; want %q", remoteAddr, ip, ipExpected)
+		}
+	}
+	f("1.2.3.4:5678", "1.2.3.4")
+	f("[2001:db8::1]:80", "2001:db8::1")
+	f("1.2.3.4", "1.2.3.4")
+	f("foobar", "<nil>")
+}
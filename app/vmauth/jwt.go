@@ -0,0 +1,693 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs/fscore"
+)
+
+var (
+	jwksRefreshInterval = flag.Duration("jwt.jwksRefreshInterval", 5*time.Minute, "Interval for re-reading JSON Web Key Sets from jwks_url in jwt sections of -auth.config. "+
+		"JSON Web Key Sets are also re-read when a token signed with an unknown key is received. See https://docs.victoriametrics.com/vmauth.html#jwt-authorization")
+	jwtClockSkew = flag.Duration("jwt.clockSkew", time.Minute, "The maximum allowed clock skew when verifying exp and nbf claims of JSON Web Tokens. "+
+		"See https://docs.victoriametrics.com/vmauth.html#jwt-authorization")
+)
+
+// JWTConfig represents `jwt` section of user config.
+//
+// Requests with bearer JSON Web Token, which is signed by one of the keys from JWKSURL
+// and satisfies the rest of the options, are authorized as the given user.
+type JWTConfig struct {
+	// JWKSURL is the path to local file or http url with JSON Web Key Set for verifying token signatures.
+	JWKSURL string `yaml:"jwks_url"`
+
+	// Issuer is the expected value for `iss` claim.
+	Issuer string `yaml:"issuer,omitempty"`
+
+	// Audience is the expected value for `aud` claim.
+	Audience string `yaml:"audience,omitempty"`
+
+	// MatchClaims contains claims, which must have the given values in the token.
+	MatchClaims map[string]string `yaml:"match_claims,omitempty"`
+
+	// ScopesClaim is the name of the claim with token scopes. By default `scope` claim is used.
+	ScopesClaim string `yaml:"scopes_claim,omitempty"`
+
+	keySet *jwksKeySet
+}
+
+func (jc *JWTConfig) init() error {
+	if jc.JWKSURL == "" {
+		return fmt.Errorf("missing `jwks_url` in `jwt` section")
+	}
+	jc.keySet = getJWKSKeySet(jc.JWKSURL)
+	return nil
+}
+
+// verify verifies whether the given tok is valid according to jc at the given time.
+func (jc *JWTConfig) verify(tok *jwtToken, now time.Time) error {
+	key, err := jc.keySet.getKey(tok.header.Kid)
+	if err != nil {
+		return err
+	}
+	if err := tok.verifySignature(key); err != nil {
+		return err
+	}
+	if err := tok.verifyTimestamps(now); err != nil {
+		return err
+	}
+	if jc.Issuer != "" {
+		if iss, _ := tok.getClaimString("iss"); iss != jc.Issuer {
+			return fmt.Errorf("unexpected `iss` claim %q; want %q", iss, jc.Issuer)
+		}
+	}
+	if jc.Audience != "" && !tok.hasAudience(jc.Audience) {
+		return fmt.Errorf("the token isn't issued for the audience %q", jc.Audience)
+	}
+	for k, v := range jc.MatchClaims {
+		if s, _ := tok.getClaimString(k); s != v {
+			return fmt.Errorf("unexpected value for %q claim: %q; want %q", k, s, v)
+		}
+	}
+	return nil
+}
+
+func (jc *JWTConfig) getScopes(tok *jwtToken) []string {
+	claimName := jc.ScopesClaim
+	if claimName == "" {
+		claimName = "scope"
+	}
+	switch t := tok.getClaim(claimName).(type) {
+	case string:
+		return strings.Fields(t)
+	case []interface{}:
+		scopes := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+		return scopes
+	default:
+		return nil
+	}
+}
+
+// getUserInfoByJWT returns the user from the jwtUsers, for which the bearer token from ats is valid.
+//
+// nil is returned if ats doesn't contain bearer JSON Web Token or if the token isn't valid for any of the jwtUsers.
+func getUserInfoByJWT(jwtUsers []*UserInfo, ats []string) (*UserInfo, *jwtToken, error) {
+	if len(jwtUsers) == 0 {
+		return nil, nil, nil
+	}
+	var tokenStr string
+	for _, at := range ats {
+		if s, ok := strings.CutPrefix(at, "http_auth:Bearer "); ok {
+			tokenStr = s
+			break
+		}
+	}
+	if strings.Count(tokenStr, ".") != 2 {
+		return nil, nil, nil
+	}
+	tok, err := parseJWT(tokenStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	var errs []string
+	for _, ui := range jwtUsers {
+		if err := ui.JWT.verify(tok, now); err != nil {
+			errs = append(errs, fmt.Sprintf("user %q: %s", ui.name(), err))
+			continue
+		}
+		return ui, tok, nil
+	}
+	return nil, nil, fmt.Errorf("cannot authorize JSON Web Token: %s", strings.Join(errs, "; "))
+}
+
+type jwtTokenContextKey struct{}
+
+func withJWTToken(ctx context.Context, tok *jwtToken) context.Context {
+	return context.WithValue(ctx, jwtTokenContextKey{}, tok)
+}
+
+func getJWTTokenFromContext(ctx context.Context) *jwtToken {
+	tok, _ := ctx.Value(jwtTokenContextKey{}).(*jwtToken)
+	return tok
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtToken represents parsed JSON Web Token.
+type jwtToken struct {
+	header jwtHeader
+	claims map[string]interface{}
+
+	// signedData contains `header.payload` part of the token
+	signedData string
+
+	signature []byte
+}
+
+func parseJWT(s string) (*jwtToken, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("unexpected number of dot-delimited parts in JSON Web Token; got %d; want 3", len(parts))
+	}
+	headerData, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode JSON Web Token header: %w", err)
+	}
+	payloadData, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode JSON Web Token payload: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode JSON Web Token signature: %w", err)
+	}
+
+	tok := &jwtToken{
+		signedData: parts[0] + "." + parts[1],
+		signature:  signature,
+	}
+	if err := json.Unmarshal(headerData, &tok.header); err != nil {
+		return nil, fmt.Errorf("cannot parse JSON Web Token header: %w", err)
+	}
+	d := json.NewDecoder(bytes.NewReader(payloadData))
+	d.UseNumber()
+	if err := d.Decode(&tok.claims); err != nil {
+		return nil, fmt.Errorf("cannot parse JSON Web Token payload: %w", err)
+	}
+	return tok, nil
+}
+
+func (tok *jwtToken) verifySignature(key crypto.PublicKey) error {
+	var h crypto.Hash
+	switch tok.header.Alg {
+	case "RS256", "PS256", "ES256":
+		h = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		h = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		h = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported JSON Web Token signing algorithm %q; supported algorithms: RS256, RS384, RS512, PS256, PS384, PS512, ES256, ES384, ES512", tok.header.Alg)
+	}
+	digest := getDigest(h, tok.signedData)
+
+	switch tok.header.Alg[:2] {
+	case "RS", "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("cannot verify %s signature with %T key", tok.header.Alg, key)
+		}
+		var err error
+		if tok.header.Alg[0] == 'R' {
+			err = rsa.VerifyPKCS1v15(pub, h, digest, tok.signature)
+		} else {
+			err = rsa.VerifyPSS(pub, h, digest, tok.signature, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JSON Web Token signature: %w", err)
+		}
+		return nil
+	default:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("cannot verify %s signature with %T key", tok.header.Alg, key)
+		}
+		keySize := (pub.Curve.Params().BitSize + 7) / 8
+		if len(tok.signature) != 2*keySize {
+			return fmt.Errorf("unexpected JSON Web Token signature size; got %d bytes; want %d bytes", len(tok.signature), 2*keySize)
+		}
+		r := new(big.Int).SetBytes(tok.signature[:keySize])
+		s := new(big.Int).SetBytes(tok.signature[keySize:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return fmt.Errorf("invalid JSON Web Token signature")
+		}
+		return nil
+	}
+}
+
+func getDigest(h crypto.Hash, s string) []byte {
+	switch h {
+	case crypto.SHA384:
+		d := sha512.Sum384([]byte(s))
+		return d[:]
+	case crypto.SHA512:
+		d := sha512.Sum512([]byte(s))
+		return d[:]
+	default:
+		d := sha256.Sum256([]byte(s))
+		return d[:]
+	}
+}
+
+func (tok *jwtToken) verifyTimestamps(now time.Time) error {
+	ts := now.Unix()
+	skew := int64(jwtClockSkew.Seconds())
+	exp, ok := tok.getClaimInt("exp")
+	if !ok {
+		// Tokens without expiration time could be used forever if leaked.
+		return fmt.Errorf("missing `exp` claim in JSON Web Token")
+	}
+	if ts > exp+skew {
+		return fmt.Errorf("the token has been expired at %s", time.Unix(exp, 0).UTC().Format(time.RFC3339))
+	}
+	if nbf, ok := tok.getClaimInt("nbf"); ok && ts < nbf-skew {
+		return fmt.Errorf("the token cannot be used before %s", time.Unix(nbf, 0).UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (tok *jwtToken) hasAudience(audience string) bool {
+	switch t := tok.getClaim("aud").(type) {
+	case string:
+		return t == audience
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getClaim returns the claim with the given name.
+//
+// Nested claims can be referred via `.`-delimited names such as `vm_access.tenant_id`.
+func (tok *jwtToken) getClaim(name string) interface{} {
+	var v interface{} = tok.claims
+	for _, part := range strings.Split(name, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[part]
+	}
+	return v
+}
+
+func (tok *jwtToken) getClaimString(name string) (string, bool) {
+	switch t := tok.getClaim(name).(type) {
+	case string:
+		return t, true
+	case json.Number:
+		return t.String(), true
+	case bool:
+		if t {
+			return "true", true
+		}
+		return "false", true
+	default:
+		return "", false
+	}
+}
+
+func (tok *jwtToken) getClaimInt(name string) (int64, bool) {
+	n, ok := tok.getClaim(name).(json.Number)
+	if !ok {
+		return 0, false
+	}
+	if v, err := n.Int64(); err == nil {
+		return v, true
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+var claimPlaceholderRegexp = regexp.MustCompile(`\{\{\.([a-zA-Z0-9_.\-]+)\}\}`)
+
+// expandClaimPlaceholders returns a copy of u with `{{.claim_name}}` placeholders at path and query args
+// substituted with the corresponding claim values from tok.
+//
+// Claim values for path placeholders mustn't be empty and mustn't contain `/`, `\` and `..`, since otherwise they could change the backend path.
+func (tok *jwtToken) expandClaimPlaceholders(uOrig *url.URL) (*url.URL, error) {
+	var missingClaims []string
+	var invalidClaims []string
+	expand := func(s string, isPath bool) string {
+		if !strings.Contains(s, "{{") {
+			return s
+		}
+		return claimPlaceholderRegexp.ReplaceAllStringFunc(s, func(placeholder string) string {
+			name := placeholder[len("{{.") : len(placeholder)-len("}}")]
+			v, ok := tok.getClaimString(name)
+			if !ok {
+				missingClaims = append(missingClaims, name)
+				return ""
+			}
+			if isPath && !isValidPathClaimValue(v) {
+				invalidClaims = append(invalidClaims, name)
+				return ""
+			}
+			return v
+		})
+	}
+
+	u := *uOrig
+	// The path is escaped by url.URL when it is converted to string, while query args are escaped by url.Values.Encode.
+	u.Path = expand(u.Path, true)
+	u.RawPath = ""
+	if strings.Contains(u.RawQuery, "%7B%7B") || strings.Contains(u.RawQuery, "{{") {
+		q := u.Query()
+		for k, vs := range q {
+			for i, v := range vs {
+				vs[i] = expand(v, false)
+			}
+			q[k] = vs
+		}
+		u.RawQuery = q.Encode()
+	}
+	if len(missingClaims) > 0 {
+		return nil, fmt.Errorf("missing claims %q in JSON Web Token", missingClaims)
+	}
+	if len(invalidClaims) > 0 {
+		return nil, fmt.Errorf("claims %q in JSON Web Token cannot be used in url path, since they are empty or contain `/`, `\\` or `..`", invalidClaims)
+	}
+	return &u, nil
+}
+
+func isValidPathClaimValue(v string) bool {
+	return v != "" && !strings.Contains(v, "/") && !strings.Contains(v, "..") && !strings.Contains(v, "\\")
+}
+
+// jwksKeySet holds public keys obtained from JSON Web Key Set at the given path.
+//
+// The key set is fetched in background, so slow or unavailable jwks_url doesn't block requests with already known keys.
+type jwksKeySet struct {
+	path string
+
+	mu   sync.Mutex
+	keys map[string]crypto.PublicKey
+
+	// lastErr contains the error for the last failed fetch
+	lastErr error
+
+	// lastFetchTime is the start time of the last fetch attempt
+	lastFetchTime time.Time
+
+	// lastSuccessTime is the time of the last successful fetch
+	lastSuccessTime time.Time
+
+	// retryInterval is the minimum interval before the next fetch attempt after failed fetches.
+	// It is doubled after every failed fetch up to -jwt.jwksRefreshInterval.
+	retryInterval time.Duration
+
+	// fetchDoneCh is closed when the fetch in progress is finished. It is nil if there is no fetch in progress.
+	fetchDoneCh chan struct{}
+}
+
+// jwksMinRefreshInterval is the minimum interval between JSON Web Key Set refreshes triggered by tokens with unknown key ids.
+//
+// It is also the initial interval between retries after failed fetches.
+const jwksMinRefreshInterval = 10 * time.Second
+
+// jwksFetchTimeout is the maximum duration for fetching JSON Web Key Set from jwks_url.
+var jwksFetchTimeout = 10 * time.Second
+
+// getJWKSKeySet returns key set for the given path.
+//
+// Key sets are shared among users and among config reloads, so the same JSON Web Key Set isn't fetched multiple times.
+func getJWKSKeySet(path string) *jwksKeySet {
+	jwksKeySetsLock.Lock()
+	defer jwksKeySetsLock.Unlock()
+
+	ks := jwksKeySets[path]
+	if ks == nil {
+		ks = &jwksKeySet{
+			path: path,
+		}
+		ks.mu.Lock()
+		// Pre-fetch the key set, so the first requests do not wait for it.
+		ks.startFetchLocked(time.Now())
+		ks.mu.Unlock()
+		jwksKeySets[path] = ks
+	}
+	return ks
+}
+
+var (
+	jwksKeySets     = make(map[string]*jwksKeySet)
+	jwksKeySetsLock sync.Mutex
+)
+
+func (ks *jwksKeySet) getKey(kid string) (crypto.PublicKey, error) {
+	now := time.Now()
+	ks.mu.Lock()
+	key := ks.findKeyLocked(kid)
+	var fetchDoneCh chan struct{}
+	if key == nil || now.Sub(ks.lastSuccessTime) > *jwksRefreshInterval {
+		// The key may be missing because of key rotation, so try re-reading the JSON Web Key Set.
+		fetchDoneCh = ks.startFetchLocked(now)
+	}
+	ks.mu.Unlock()
+
+	if key != nil {
+		// Do not wait for the refresh of the stale key set, since the key is already known.
+		return key, nil
+	}
+	if fetchDoneCh != nil {
+		// The wait time is limited by jwksFetchTimeout.
+		<-fetchDoneCh
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if key := ks.findKeyLocked(kid); key != nil {
+		return key, nil
+	}
+	if ks.lastErr != nil {
+		return nil, ks.lastErr
+	}
+	return nil, fmt.Errorf("cannot find key with kid=%q at jwks_url=%q", kid, ks.path)
+}
+
+func (ks *jwksKeySet) findKeyLocked(kid string) crypto.PublicKey {
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key
+		}
+	}
+	return ks.keys[kid]
+}
+
+// startFetchLocked starts fetching the key set in background and returns a channel, which is closed when the fetch is finished.
+//
+// nil is returned if the fetch cannot be started now because of too frequent fetches.
+func (ks *jwksKeySet) startFetchLocked(now time.Time) chan struct{} {
+	if ks.fetchDoneCh != nil {
+		// The fetch is already in progress
+		return ks.fetchDoneCh
+	}
+	minInterval := jwksMinRefreshInterval
+	if ks.lastErr != nil {
+		minInterval = ks.retryInterval
+	}
+	if !ks.lastFetchTime.IsZero() && now.Sub(ks.lastFetchTime) < minInterval {
+		return nil
+	}
+	ks.lastFetchTime = now
+	fetchDoneCh := make(chan struct{})
+	ks.fetchDoneCh = fetchDoneCh
+	go ks.fetch(fetchDoneCh)
+	return fetchDoneCh
+}
+
+func (ks *jwksKeySet) fetch(fetchDoneCh chan struct{}) {
+	data, err := readJWKS(ks.path)
+	var keys map[string]crypto.PublicKey
+	if err == nil {
+		keys, err = parseJWKS(data)
+	}
+
+	ks.mu.Lock()
+	if err == nil {
+		ks.keys = keys
+		ks.lastErr = nil
+		ks.lastSuccessTime = time.Now()
+		ks.retryInterval = 0
+	} else {
+		jwksFetchErrors.Inc()
+		// Continue using the previously fetched keys on error.
+		ks.lastErr = fmt.Errorf("cannot read JSON Web Key Set from jwks_url=%q: %w", ks.path, err)
+		ks.retryInterval = getNextJWKSRetryInterval(ks.retryInterval)
+	}
+	ks.fetchDoneCh = nil
+	ks.mu.Unlock()
+
+	close(fetchDoneCh)
+}
+
+func getNextJWKSRetryInterval(d time.Duration) time.Duration {
+	if d < jwksMinRefreshInterval {
+		return jwksMinRefreshInterval
+	}
+	d *= 2
+	if d > *jwksRefreshInterval {
+		d = *jwksRefreshInterval
+	}
+	return d
+}
+
+// readJWKS reads JSON Web Key Set from the given path to local file or http url during jwksFetchTimeout.
+func readJWKS(path string) ([]byte, error) {
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return fscore.ReadFileOrHTTP(path)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create request for %q: %w", path, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch %q: %w", path, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code when fetching %q: %d, expecting %d", path, resp.StatusCode, http.StatusOK)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %q: %w", path, err)
+	}
+	return data, nil
+}
+
+var jwksFetchErrors = metrics.NewCounter(`vmauth_jwks_fetch_errors_total`)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+
+	// RSA keys
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC keys
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS parses JSON Web Key Set from data and returns public keys from it by key id.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("cannot parse JSON Web Key Set: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse key with kid=%q: %w", jwk.Kid, err)
+		}
+		if key == nil {
+			// Unsupported key type
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JSON Web Key Set doesn't contain RSA or EC keys for signature verification")
+	}
+	return keys, nil
+}
+
+func (jwk *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode `n`: %w", err)
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode `e`: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("too big `e`")
+		}
+		return &rsa.PublicKey{
+			N: n,
+			E: int(e.Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q; supported curves: P-256, P-384, P-521", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode `x`: %w", err)
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode `y`: %w", err)
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     x,
+			Y:     y,
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,570 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJWTConfigVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate RSA key: %s", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate EC key: %s", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate RSA key: %s", err)
+	}
+	jwksPath := writeTestJWKS(t, map[string]crypto.PublicKey{
+		"rsa": &rsaKey.PublicKey,
+		"ec":  &ecKey.PublicKey,
+	})
+
+	jc := &JWTConfig{
+		JWKSURL:  jwksPath,
+		Issuer:   "https://issuer",
+		Audience: "vmauth",
+		MatchClaims: map[string]string{
+			"vm_access.tenant_id": "42",
+		},
+	}
+	if err := jc.init(); err != nil {
+		t.Fatalf("cannot init jwt config: %s", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	f := func(alg, kid string, key crypto.Signer, claims map[string]interface{}, resultExpected bool) {
+		t.Helper()
+		s := newTestJWT(t, alg, kid, key, claims)
+		tok, err := parseJWT(s)
+		if err != nil {
+			t.Fatalf("cannot parse token: %s", err)
+		}
+		err = jc.verify(tok, now)
+		if result := err == nil; result != resultExpected {
+			t.Fatalf("unexpected result; got %v; want %v; err: %v", result, resultExpected, err)
+		}
+	}
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss": "https://issuer",
+			"aud": []string{"foo", "vmauth"},
+			"exp": now.Unix() + 60,
+			"vm_access": map[string]interface{}{
+				"tenant_id": 42,
+			},
+		}
+	}
+
+	// valid tokens
+	f("RS256", "rsa", rsaKey, validClaims(), true)
+	f("RS512", "rsa", rsaKey, validClaims(), true)
+	f("PS256", "rsa", rsaKey, validClaims(), true)
+	f("ES256", "ec", ecKey, validClaims(), true)
+
+	// expired token
+	claims := validClaims()
+	claims["exp"] = now.Unix() - 3600
+	f("RS256", "rsa", rsaKey, claims, false)
+
+	// expired token within the allowed clock skew
+	claims["exp"] = now.Unix() - 10
+	f("RS256", "rsa", rsaKey, claims, true)
+
+	// token without expiration time
+	claims = validClaims()
+	delete(claims, "exp")
+	f("RS256", "rsa", rsaKey, claims, false)
+
+	// token isn't valid yet
+	claims = validClaims()
+	claims["nbf"] = now.Unix() + 3600
+	f("RS256", "rsa", rsaKey, claims, false)
+
+	// invalid issuer
+	claims = validClaims()
+	claims["iss"] = "https://other-issuer"
+	f("RS256", "rsa", rsaKey, claims, false)
+
+	// invalid audience
+	claims = validClaims()
+	claims["aud"] = "foo"
+	f("RS256", "rsa", rsaKey, claims, false)
+
+	// mismatched claim
+	claims = validClaims()
+	claims["vm_access"] = map[string]interface{}{
+		"tenant_id": 43,
+	}
+	f("RS256", "rsa", rsaKey, claims, false)
+
+	// missing claim
+	claims = validClaims()
+	delete(claims, "vm_access")
+	f("RS256", "rsa", rsaKey, claims, false)
+
+	// token signed by unknown key
+	f("RS256", "rsa", otherKey, validClaims(), false)
+	f("RS256", "unknown-kid", rsaKey, validClaims(), false)
+
+	// mismatched algorithm and key type
+	f("ES256", "rsa", rsaKey, validClaims(), false)
+}
+
+func TestJWKSKeySetFetchFailure(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	ks := &jwksKeySet{
+		path: srv.URL,
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := ks.getKey("foo"); err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+	// The key set mustn't be re-fetched on every request after the failed fetch
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("unexpected number of requests to jwks_url; got %d; want 1", n)
+	}
+	ks.mu.Lock()
+	retryInterval := ks.retryInterval
+	ks.mu.Unlock()
+	if retryInterval != jwksMinRefreshInterval {
+		t.Fatalf("unexpected retry interval; got %s; want %s", retryInterval, jwksMinRefreshInterval)
+	}
+
+	// The retry interval must be increased after subsequent failures
+	for _, want := range []time.Duration{2 * jwksMinRefreshInterval, 4 * jwksMinRefreshInterval} {
+		retryInterval = getNextJWKSRetryInterval(retryInterval)
+		if retryInterval != want {
+			t.Fatalf("unexpected retry interval; got %s; want %s", retryInterval, want)
+		}
+	}
+	if d := getNextJWKSRetryInterval(*jwksRefreshInterval); d != *jwksRefreshInterval {
+		t.Fatalf("unexpected retry interval; got %s; want %s", d, *jwksRefreshInterval)
+	}
+}
+
+func TestJWKSKeySetRefreshDoesNotBlock(t *testing.T) {
+	origTimeout := jwksFetchTimeout
+	jwksFetchTimeout = 100 * time.Millisecond
+	defer func() {
+		jwksFetchTimeout = origTimeout
+	}()
+
+	unblockCh := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-unblockCh:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(unblockCh)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate RSA key: %s", err)
+	}
+	ks := &jwksKeySet{
+		path: srv.URL,
+		keys: map[string]crypto.PublicKey{
+			"rsa": &rsaKey.PublicKey,
+		},
+		// The key set is stale, so it must be refreshed in background
+		lastSuccessTime: time.Now().Add(-2 * *jwksRefreshInterval),
+		lastFetchTime:   time.Now().Add(-2 * *jwksRefreshInterval),
+	}
+
+	// The known key must be returned without waiting for the hanging jwks_url
+	startTime := time.Now()
+	key, err := ks.getKey("rsa")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if key == nil {
+		t.Fatalf("expecting non-nil key")
+	}
+	if d := time.Since(startTime); d >= jwksFetchTimeout {
+		t.Fatalf("too long duration for getting the known key: %s", d)
+	}
+
+	// The unknown key must be returned with an error after the fetch timeout
+	if _, err := ks.getKey("unknown"); err == nil {
+		t.Fatalf("expecting non-nil error for unknown key")
+	}
+}
+
+func TestJWTConfigVerifyUnsupportedAlg(t *testing.T) {
+	jwksPath := writeTestJWKS(t, nil)
+	data, err := os.ReadFile(jwksPath)
+	if err != nil {
+		t.Fatalf("cannot read %q: %s", jwksPath, err)
+	}
+	if _, err := parseJWKS(data); err == nil {
+		t.Fatalf("expecting non-nil error for empty JSON Web Key Set")
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate RSA key: %s", err)
+	}
+	for _, alg := range []string{"none", "HS256"} {
+		header := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"alg":%q}`, alg)))
+		payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"foo"}`))
+		tok, err := parseJWT(header + "." + payload + ".")
+		if err != nil {
+			t.Fatalf("cannot parse token: %s", err)
+		}
+		if err := tok.verifySignature(&rsaKey.PublicKey); err == nil {
+			t.Fatalf("expecting non-nil error for alg=%q", alg)
+		}
+	}
+}
+
+func TestParseJWTFailure(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		if _, err := parseJWT(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+	f("")
+	f("foo.bar")
+	f("foo.bar.baz")
+	f("e30.e30.***")
+	f("e30.W10.")
+}
+
+func TestJWTConfigGetScopes(t *testing.T) {
+	f := func(scopesClaim string, claims map[string]interface{}, scopesExpected []string) {
+		t.Helper()
+		jc := &JWTConfig{
+			ScopesClaim: scopesClaim,
+		}
+		tok := &jwtToken{
+			claims: claims,
+		}
+		scopes := jc.getScopes(tok)
+		if !reflect.DeepEqual(scopes, scopesExpected) {
+			t.Fatalf("unexpected scopes; got %q; want %q", scopes, scopesExpected)
+		}
+	}
+	f("", map[string]interface{}{}, nil)
+	f("", map[string]interface{}{
+		"scope": "read write",
+	}, []string{"read", "write"})
+	f("scp", map[string]interface{}{
+		"scp": []interface{}{"read", "write"},
+	}, []string{"read", "write"})
+	f("vm_access.scopes", map[string]interface{}{
+		"vm_access": map[string]interface{}{
+			"scopes": []interface{}{"read"},
+		},
+	}, []string{"read"})
+}
+
+func TestJWTTokenExpandClaimPlaceholders(t *testing.T) {
+	tok := &jwtToken{
+		claims: map[string]interface{}{
+			"tenant_id": json.Number("42"),
+			"vm_access": map[string]interface{}{
+				"team": "dev",
+			},
+			"path_traversal":  "../other-tenant",
+			"slash":           "1/2",
+			"dots":            "..",
+			"empty":           "",
+			"spaces":          "foo bar?",
+			"query_injection": "dev&extra_label=team=admin",
+		},
+	}
+	f := func(urlPrefix, resultExpected string) {
+		t.Helper()
+		u, err := url.Parse(urlPrefix)
+		if err != nil {
+			t.Fatalf("cannot parse %q: %s", urlPrefix, err)
+		}
+		result, err := tok.expandClaimPlaceholders(u)
+		if err != nil {
+			if resultExpected != "" {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			return
+		}
+		if resultExpected == "" {
+			t.Fatalf("expecting non-nil error")
+		}
+		if result.String() != resultExpected {
+			t.Fatalf("unexpected result; got %q; want %q", result, resultExpected)
+		}
+	}
+	f("http://vmselect/select/0/prometheus/api/v1/query", "http://vmselect/select/0/prometheus/api/v1/query")
+	f("http://vmselect/select/{{.tenant_id}}/prometheus/api/v1/query?query=up", "http://vmselect/select/42/prometheus/api/v1/query?query=up")
+	f("http://vmselect/api/v1/query?extra_label=team={{.vm_access.team}}", "http://vmselect/api/v1/query?extra_label=team%3Ddev")
+	f("http://vmselect/api/v1/query?extra_label=team%3D%7B%7B.vm_access.team%7D%7D", "http://vmselect/api/v1/query?extra_label=team%3Ddev")
+
+	// claim values are escaped
+	f("http://vmselect/select/{{.spaces}}/prometheus", "http://vmselect/select/foo%20bar%3F/prometheus")
+	f("http://vmselect/api/v1/query?extra_label=team={{.query_injection}}", "http://vmselect/api/v1/query?extra_label=team%3Ddev%26extra_label%3Dteam%3Dadmin")
+
+	// missing claim
+	f("http://vmselect/select/{{.project_id}}/prometheus", "")
+
+	// claim values, which may change the path
+	f("http://vmselect/select/{{.path_traversal}}/prometheus", "")
+	f("http://vmselect/select/{{.slash}}/prometheus", "")
+	f("http://vmselect/select/{{.dots}}/prometheus", "")
+	f("http://vmselect/select/{{.empty}}/prometheus", "")
+}
+
+func TestParseAuthConfigJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate RSA key: %s", err)
+	}
+	jwksPath := writeTestJWKS(t, map[string]crypto.PublicKey{
+		"rsa": &rsaKey.PublicKey,
+	})
+	data := fmt.Sprintf(`
+users:
+- name: team-a
+  jwt:
+    jwks_url: %q
+    match_claims:
+      team: a
+  url_map:
+  - src_paths: ["/api/v1/write"]
+    required_scopes: [write]
+    url_prefix: http://vminsert/insert/{{.tenant_id}}/prometheus
+- name: team-b
+  jwt:
+    jwks_url: %q
+    match_claims:
+      team: b
+  url_prefix: http://vmselect/select/{{.tenant_id}}/prometheus
+`, jwksPath, jwksPath)
+	ac, err := parseAuthConfig([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := parseAuthConfigUsers(ac); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ac.jwtUsers) != 2 {
+		t.Fatalf("unexpected number of jwt users; got %d; want 2", len(ac.jwtUsers))
+	}
+
+	f := func(claims map[string]interface{}, userExpected string) {
+		t.Helper()
+		claims["exp"] = time.Now().Unix() + 60
+		s := newTestJWT(t, "RS256", "rsa", rsaKey, claims)
+		ui, tok, err := getUserInfoByJWT(ac.jwtUsers, []string{getHTTPAuthBearerToken(s)})
+		if ui == nil {
+			if userExpected != "" {
+				t.Fatalf("cannot find user %q: %v", userExpected, err)
+			}
+			return
+		}
+		if ui.name() != userExpected {
+			t.Fatalf("unexpected user; got %q; want %q", ui.name(), userExpected)
+		}
+		if tok == nil {
+			t.Fatalf("expecting non-nil token")
+		}
+	}
+	f(map[string]interface{}{"team": "a"}, "team-a")
+	f(map[string]interface{}{"team": "b"}, "team-b")
+	f(map[string]interface{}{"team": "c"}, "")
+
+	// non-jwt bearer token
+	ui, _, err := getUserInfoByJWT(ac.jwtUsers, []string{getHTTPAuthBearerToken("foobar")})
+	if ui != nil || err != nil {
+		t.Fatalf("unexpected result for non-jwt bearer token; ui=%v, err=%v", ui, err)
+	}
+
+	// invalid configs
+	fFailure := func(s string) {
+		t.Helper()
+		ac, err := parseAuthConfig([]byte(s))
+		if err != nil {
+			return
+		}
+		if _, err := parseAuthConfigUsers(ac); err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+
+	// missing jwks_url
+	fFailure(`
+users:
+- name: foo
+  jwt: {}
+  url_prefix: http://foo.bar
+`)
+
+	// jwt with bearer_token
+	fFailure(`
+users:
+- bearer_token: foo
+  jwt:
+    jwks_url: /path/to/jwks.json
+  url_prefix: http://foo.bar
+`)
+
+	// required_scopes without jwt
+	fFailure(`
+users:
+- username: foo
+  url_map:
+  - src_paths: ["/api/v1/write"]
+    required_scopes: [write]
+    url_prefix: http://foo.bar
+`)
+
+	// jwt without name
+	fFailure(`
+users:
+- jwt:
+    jwks_url: /path/to/jwks.json
+  url_prefix: http://foo.bar
+`)
+
+	// duplicate names for jwt users
+	fFailure(`
+users:
+- name: foo
+  jwt:
+    jwks_url: /path/to/jwks.json
+  url_prefix: http://foo.bar
+- name: foo
+  jwt:
+    jwks_url: /path/to/jwks.json
+    match_claims:
+      team: a
+  url_prefix: http://foo.bar
+`)
+
+	// jwt in unauthorized_user
+	fFailure(`
+unauthorized_user:
+  jwt:
+    jwks_url: /path/to/jwks.json
+  url_prefix: http://foo.bar
+`)
+}
+
+func newTestJWT(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{
+		"alg": alg,
+		"kid": kid,
+		"typ": "JWT",
+	})
+	if err != nil {
+		t.Fatalf("cannot marshal header: %s", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("cannot marshal claims: %s", err)
+	}
+	signedData := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var h crypto.Hash
+	switch alg[2:] {
+	case "384":
+		h = crypto.SHA384
+	case "512":
+		h = crypto.SHA512
+	default:
+		h = crypto.SHA256
+	}
+	digest := getDigest(h, signedData)
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if alg[0] == 'P' {
+			sig, err = rsa.SignPSS(rand.Reader, k, h, digest, nil)
+		} else {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, k, h, digest)
+		}
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest)
+		if err == nil {
+			keySize := (k.Curve.Params().BitSize + 7) / 8
+			sig = make([]byte, 2*keySize)
+			r.FillBytes(sig[:keySize])
+			s.FillBytes(sig[keySize:])
+		}
+	default:
+		t.Fatalf("unsupported key type %T", key)
+	}
+	if err != nil {
+		t.Fatalf("cannot sign token: %s", err)
+	}
+	return signedData + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func writeTestJWKS(t *testing.T, keys map[string]crypto.PublicKey) string {
+	t.Helper()
+	var jwks []map[string]string
+	for kid, key := range keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			jwks = append(jwks, map[string]string{
+				"kty": "EC",
+				"kid": kid,
+				"crv": k.Curve.Params().Name,
+				"x":   base64.RawURLEncoding.EncodeToString(k.X.Bytes()),
+				"y":   base64.RawURLEncoding.EncodeToString(k.Y.Bytes()),
+			})
+		default:
+			t.Fatalf("unsupported key type %T", key)
+		}
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"keys": jwks,
+	})
+	if err != nil {
+		t.Fatalf("cannot marshal JSON Web Key Set: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("cannot write JSON Web Key Set: %s", err)
+	}
+	return path
+}
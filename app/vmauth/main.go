@@ -8,11 +8,13 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/http"
 	"net/textproto"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -33,7 +35,7 @@ import (
 )
 
 var (
-	httpListenAddrs  = flagutil.NewArrayString("httpListenAddr", "TCP address to listen for incoming http requests. See also -tls and -httpListenAddr.useProxyProtocol")
+	httpListenAddrs  = flagutil.NewArrayString("httpListenAddr", "TCP address to listen for incoming http requests. It may be set to unix:/path/to/socket for listening at unix socket. See also -tls and -httpListenAddr.useProxyProtocol")
 	useProxyProtocol = flagutil.NewArrayBool("httpListenAddr.useProxyProtocol", "Whether to use proxy protocol for connections accepted at the corresponding -httpListenAddr . "+
 		"See https://www.haproxy.org/download/1.8/doc/proxy-protocol.txt . "+
 		"With enabled proxy protocol http server cannot serve regular /metrics endpoint. Use -pushmetrics.url for metrics pushing")
@@ -102,8 +104,20 @@ func requestHandler(w http.ResponseWriter, r *http.Request) bool {
 		return true
 	}
 
+	// Global ip_filters are verified before the authorization.
+	if !authConfig.Load().IPFilters.isAllowed(getRemoteIP(r)) {
+		handleIPFiltersError(w, r, "global ip_filters")
+		return true
+	}
+
 	ats := getAuthTokensFromRequest(r)
 	if len(ats) == 0 {
+		// Process requests authorized via client TLS certificates
+		if ui := getUserInfoByMTLS(authConfig.Load().mtlsUsers, r.TLS); ui != nil {
+			processUserRequest(w, r, ui)
+			return true
+		}
+
 		// Process requests for unauthorized users
 		ui := authConfig.Load().UnauthorizedUser
 		if ui != nil {
@@ -118,9 +132,22 @@ func requestHandler(w http.ResponseWriter, r *http.Request) bool {
 
 	ui := getUserInfoByAuthTokens(ats)
 	if ui == nil {
+		uiJWT, tok, jwtErr := getUserInfoByJWT(authConfig.Load().jwtUsers, ats)
+		if uiJWT != nil {
+			r = r.WithContext(withJWTToken(r.Context(), tok))
+			processUserRequest(w, r, uiJWT)
+			return true
+		}
+		if uiMTLS := getUserInfoByMTLS(authConfig.Load().mtlsUsers, r.TLS); uiMTLS != nil {
+			processUserRequest(w, r, uiMTLS)
+			return true
+		}
 		invalidAuthTokenRequests.Inc()
 		if *logInvalidAuthTokens {
 			err := fmt.Errorf("cannot authorize request with auth tokens %q", ats)
+			if jwtErr != nil {
+				err = fmt.Errorf("%w: %s", err, jwtErr)
+			}
 			err = &httpserver.ErrorWithStatusCode{
 				Err:        err,
 				StatusCode: http.StatusUnauthorized,
@@ -153,6 +180,47 @@ func processUserRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo) {
 
 	ui.requests.Inc()
 
+	// Track per-user request statistics.
+	sw := &statsResponseWriter{
+		ResponseWriter: w,
+	}
+	w = sw
+	var cb *countingBody
+	if r.Body != nil && r.Body != http.NoBody {
+		cb = &countingBody{
+			r: r.Body,
+		}
+		r.Body = cb
+	}
+	defer ui.updateRequestStats(sw, cb)
+
+	if !ui.IPFilters.isAllowed(getRemoteIP(r)) {
+		handleIPFiltersError(w, r, fmt.Sprintf("ip_filters for user %q", ui.name()))
+		return
+	}
+
+	// Limit the rate of requests per user
+	if d, err := ui.checkRateLimit(); err != nil {
+		handleRateLimitError(w, r, err, d)
+
+		// Requests rejected because of rate limit aren't counted as backend errors,
+		// since they are caused by the client exceeding the configured requests_per_second.
+		return
+	}
+
+	// Limit the request body size per user
+	if n := ui.getMaxRequestBodySize(); n > 0 {
+		if r.ContentLength > n {
+			ui.requestBodySizeLimitReached.Inc()
+			err := fmt.Errorf("request body size %d exceeds max_request_body_size=%d for user %q", r.ContentLength, n, ui.name())
+			handleRequestBodySizeLimitError(w, r, err)
+			return
+		}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+		}
+	}
+
 	// Limit the concurrency of requests to backends
 	concurrencyLimitOnce.Do(concurrencyLimitInit)
 	select {
@@ -183,18 +251,33 @@ func processUserRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo) {
 
 func processRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo) {
 	u := normalizeURL(r.URL)
-	up, hc := ui.getURLPrefixAndHeaders(u)
+	tok := getJWTTokenFromContext(r.Context())
+	var scopes []string
+	if tok != nil {
+		scopes = ui.JWT.getScopes(tok)
+	}
+	up, hc, uTarget := ui.getURLPrefixAndHeaders(u, r.Method, r.Header, scopes)
 	isDefault := false
 	if up == nil {
 		if ui.DefaultURL == nil {
 			// Authorization should be requested for http requests without credentials
 			// to a route that is not in the configuration for unauthorized user.
 			// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/5236
-			if ui.BearerToken == "" && ui.Username == "" && len(*authUsers.Load()) > 0 {
+			if ui.BearerToken == "" && ui.Username == "" && ui.JWT == nil && ui.MTLS == nil && len(*authUsers.Load()) > 0 {
 				w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
 				http.Error(w, "missing `Authorization` request header", http.StatusUnauthorized)
 				return
 			}
+			if tok != nil {
+				// The route may be missing because of insufficient scopes in JSON Web Token.
+				missingRouteRequests.Inc()
+				err := &httpserver.ErrorWithStatusCode{
+					Err:        fmt.Errorf("missing route for %q with scopes %q", u.String(), scopes),
+					StatusCode: http.StatusForbidden,
+				}
+				httpserver.Errorf(w, r, "%s", err)
+				return
+			}
 			missingRouteRequests.Inc()
 			httpserver.Errorf(w, r, "missing route for %q", u.String())
 			return
@@ -217,7 +300,21 @@ func processRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo) {
 			query.Set("request_path", u.String())
 			targetURL.RawQuery = query.Encode()
 		} else { // Update path for regular routes.
-			targetURL = mergeURLs(targetURL, u, up.dropSrcPathPrefixParts)
+			targetURL = mergeURLs(targetURL, uTarget, up.dropSrcPathPrefixParts)
+		}
+		if tok != nil {
+			// Substitute {{.claim_name}} placeholders with the claims from JSON Web Token.
+			tu, err := tok.expandClaimPlaceholders(targetURL)
+			if err != nil {
+				bu.put()
+				err = &httpserver.ErrorWithStatusCode{
+					Err:        err,
+					StatusCode: http.StatusForbidden,
+				}
+				httpserver.Errorf(w, r, "%s", err)
+				return
+			}
+			targetURL = tu
 		}
 		ok := tryProcessingRequest(w, r, targetURL, hc, up.retryStatusCodes, ui)
 		bu.put()
@@ -254,6 +351,14 @@ func tryProcessingRequest(w http.ResponseWriter, r *http.Request, targetURL *url
 			}
 			return true
 		}
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			// Request body exceeds max_request_body_size. Do not retry it at other backends.
+			ui.requestBodySizeLimitReached.Inc()
+			err = fmt.Errorf("request body exceeds max_request_body_size=%d for user %q", mbe.Limit, ui.name())
+			handleRequestBodySizeLimitError(w, r, err)
+			return true
+		}
 		if !rtbOK || !rtb.canRetry() {
 			// Request body cannot be re-sent to another backend. Return the error to the client then.
 			err = &httpserver.ErrorWithStatusCode{
@@ -399,6 +504,7 @@ var (
 	configReloadRequests     = metrics.NewCounter(`vmauth_http_requests_total{path="/-/reload"}`)
 	invalidAuthTokenRequests = metrics.NewCounter(`vmauth_http_request_errors_total{reason="invalid_auth_token"}`)
 	missingRouteRequests     = metrics.NewCounter(`vmauth_http_request_errors_total{reason="missing_route"}`)
+	ipFiltersDeniedRequests  = metrics.NewCounter(`vmauth_http_request_errors_total{reason="ip_filters"}`)
 )
 
 func getTransport(insecureSkipVerifyP *bool, caFile string) (*http.Transport, error) {
@@ -511,6 +617,36 @@ func handleConcurrencyLimitError(w http.ResponseWriter, r *http.Request, err err
 	httpserver.Errorf(w, r, "%s", err)
 }
 
+func handleIPFiltersError(w http.ResponseWriter, r *http.Request, filtersName string) {
+	ipFiltersDeniedRequests.Inc()
+	err := &httpserver.ErrorWithStatusCode{
+		Err:        fmt.Errorf("access from %s is denied by %s", httpserver.GetQuotedRemoteAddr(r), filtersName),
+		StatusCode: http.StatusForbidden,
+	}
+	httpserver.Errorf(w, r, "%s", err)
+}
+
+func handleRateLimitError(w http.ResponseWriter, r *http.Request, err error, retryAfter time.Duration) {
+	secs := int(math.Ceil(retryAfter.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	w.Header().Add("Retry-After", strconv.Itoa(secs))
+	err = &httpserver.ErrorWithStatusCode{
+		Err:        err,
+		StatusCode: http.StatusTooManyRequests,
+	}
+	httpserver.Errorf(w, r, "%s", err)
+}
+
+func handleRequestBodySizeLimitError(w http.ResponseWriter, r *http.Request, err error) {
+	err = &httpserver.ErrorWithStatusCode{
+		Err:        err,
+		StatusCode: http.StatusRequestEntityTooLarge,
+	}
+	httpserver.Errorf(w, r, "%s", err)
+}
+
 type readTrackingBody struct {
 	// r contains reader for initial data reading
 	r io.ReadCloser
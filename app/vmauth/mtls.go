@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+)
+
+// MTLSConfig represents `mtls` section of user config.
+//
+// Requests with verified client TLS certificate matching all the non-empty options are authorized as the given user.
+//
+// See https://docs.victoriametrics.com/vmauth.html#mtls-based-request-routing
+type MTLSConfig struct {
+	// CommonName is the expected subject common name (aka CN) of the client certificate.
+	CommonName string `yaml:"common_name,omitempty"`
+
+	// Organization is the expected subject organization (aka O) of the client certificate.
+	Organization string `yaml:"organization,omitempty"`
+
+	// OrganizationalUnit is the expected subject organizational unit (aka OU) of the client certificate.
+	OrganizationalUnit string `yaml:"organizational_unit,omitempty"`
+
+	// SubjectAltName is the expected subject alternative name (aka SAN) of the client certificate.
+	//
+	// It is matched against DNS names, email addresses, IP addresses and URIs from the certificate.
+	SubjectAltName string `yaml:"subject_alt_name,omitempty"`
+}
+
+func (mc *MTLSConfig) validate() error {
+	if mc.CommonName == "" && mc.Organization == "" && mc.OrganizationalUnit == "" && mc.SubjectAltName == "" {
+		return fmt.Errorf("at least one of common_name, organization, organizational_unit or subject_alt_name must be set in `mtls` section")
+	}
+	return nil
+}
+
+// match returns true if the given client certificate matches mc.
+func (mc *MTLSConfig) match(cert *x509.Certificate) bool {
+	if mc.CommonName != "" && cert.Subject.CommonName != mc.CommonName {
+		return false
+	}
+	if mc.Organization != "" && !hasString(cert.Subject.Organization, mc.Organization) {
+		return false
+	}
+	if mc.OrganizationalUnit != "" && !hasString(cert.Subject.OrganizationalUnit, mc.OrganizationalUnit) {
+		return false
+	}
+	if mc.SubjectAltName != "" && !hasSubjectAltName(cert, mc.SubjectAltName) {
+		return false
+	}
+	return true
+}
+
+func (mc *MTLSConfig) String() string {
+	var a []string
+	if mc.CommonName != "" {
+		a = append(a, "CN="+mc.CommonName)
+	}
+	if mc.Organization != "" {
+		a = append(a, "O="+mc.Organization)
+	}
+	if mc.OrganizationalUnit != "" {
+		a = append(a, "OU="+mc.OrganizationalUnit)
+	}
+	if mc.SubjectAltName != "" {
+		a = append(a, "SAN="+mc.SubjectAltName)
+	}
+	return strings.Join(a, ",")
+}
+
+func hasSubjectAltName(cert *x509.Certificate, san string) bool {
+	if hasString(cert.DNSNames, san) || hasString(cert.EmailAddresses, san) {
+		return true
+	}
+	for _, ip := range cert.IPAddresses {
+		if ip.String() == san {
+			return true
+		}
+	}
+	for _, u := range cert.URIs {
+		if u.String() == san {
+			return true
+		}
+	}
+	return false
+}
+
+func hasString(a []string, s string) bool {
+	for _, x := range a {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// getUserInfoByMTLS returns the first user from mtlsUsers, which matches the verified client certificate from cs.
+//
+// nil is returned if cs doesn't contain verified client certificate or if the certificate doesn't match any of the mtlsUsers.
+func getUserInfoByMTLS(mtlsUsers []*UserInfo, cs *tls.ConnectionState) *UserInfo {
+	if len(mtlsUsers) == 0 || cs == nil || len(cs.VerifiedChains) == 0 || len(cs.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	cert := cs.VerifiedChains[0][0]
+	for _, ui := range mtlsUsers {
+		if ui.MTLS.match(cert) {
+			return ui
+		}
+	}
+	return nil
+}
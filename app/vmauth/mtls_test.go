@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"testing"
+)
+
+func TestMTLSConfigMatch(t *testing.T) {
+	u, err := url.Parse("spiffe://cluster.local/ns/default/sa/vmagent")
+	if err != nil {
+		t.Fatalf("cannot parse url: %s", err)
+	}
+	cert := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "vmagent",
+			Organization:       []string{"acme"},
+			OrganizationalUnit: []string{"finance", "ops"},
+		},
+		DNSNames:       []string{"vmagent.local"},
+		EmailAddresses: []string{"ops@acme.local"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		URIs:           []*url.URL{u},
+	}
+	f := func(mc *MTLSConfig, resultExpected bool) {
+		t.Helper()
+		result := mc.match(cert)
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %v; want %v", mc, result, resultExpected)
+		}
+	}
+
+	f(&MTLSConfig{CommonName: "vmagent"}, true)
+	f(&MTLSConfig{Organization: "acme"}, true)
+	f(&MTLSConfig{OrganizationalUnit: "ops"}, true)
+	f(&MTLSConfig{CommonName: "vmagent", OrganizationalUnit: "finance"}, true)
+	f(&MTLSConfig{SubjectAltName: "vmagent.local"}, true)
+	f(&MTLSConfig{SubjectAltName: "ops@acme.local"}, true)
+	f(&MTLSConfig{SubjectAltName: "10.0.0.1"}, true)
+	f(&MTLSConfig{SubjectAltName: "spiffe://cluster.local/ns/default/sa/vmagent"}, true)
+
+	f(&MTLSConfig{CommonName: "vmalert"}, false)
+	f(&MTLSConfig{Organization: "foo"}, false)
+	f(&MTLSConfig{CommonName: "vmagent", OrganizationalUnit: "dev"}, false)
+	f(&MTLSConfig{SubjectAltName: "vmagent"}, false)
+}
+
+func TestGetUserInfoByMTLS(t *testing.T) {
+	ac, err := parseAuthConfig([]byte(`
+users:
+- name: finance
+  mtls:
+    organizational_unit: finance
+  url_prefix: http://victoriametrics-finance:8428
+- mtls:
+    common_name: vmagent
+  url_prefix: http://victoriametrics:8428
+- username: foo
+  password: bar
+  url_prefix: http://victoriametrics:8428
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := parseAuthConfigUsers(ac); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ac.mtlsUsers) != 2 {
+		t.Fatalf("unexpected number of mtls users; got %d; want 2", len(ac.mtlsUsers))
+	}
+
+	f := func(cs *tls.ConnectionState, userExpected string) {
+		t.Helper()
+		ui := getUserInfoByMTLS(ac.mtlsUsers, cs)
+		user := ""
+		if ui != nil {
+			user = ui.name()
+		}
+		if user != userExpected {
+			t.Fatalf("unexpected user; got %q; want %q", user, userExpected)
+		}
+	}
+	newConnectionState := func(subject pkix.Name) *tls.ConnectionState {
+		return &tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{{Subject: subject}}},
+		}
+	}
+
+	f(newConnectionState(pkix.Name{OrganizationalUnit: []string{"finance"}, CommonName: "vmagent"}), "finance")
+	f(newConnectionState(pkix.Name{CommonName: "vmagent"}), "mtls:CN=vmagent")
+	f(newConnectionState(pkix.Name{CommonName: "vmalert"}), "")
+
+	// missing TLS connection
+	f(nil, "")
+
+	// unverified client certificates must be ignored
+	f(&tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "vmagent"}}},
+	}, "")
+}
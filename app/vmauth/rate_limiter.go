@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter limits the rate of requests with token bucket algorithm.
+//
+// The bucket size equals to the number of requests allowed per second,
+// so short bursts up to this number of requests are allowed.
+type rateLimiter struct {
+	// limit is the number of requests allowed per second
+	limit float64
+
+	// burst is the maximum number of tokens in the bucket
+	burst float64
+
+	mu       sync.Mutex
+	tokens   float64
+	lastTime time.Time
+}
+
+func newRateLimiter(limit float64) *rateLimiter {
+	burst := math.Ceil(limit)
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		limit:  limit,
+		burst:  burst,
+		tokens: burst,
+	}
+}
+
+// take tries obtaining a token for a single request at the given time.
+//
+// It returns zero if the request is allowed. Otherwise it returns the duration
+// after which the next request can be allowed.
+func (rl *rateLimiter) take(now time.Time) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if !rl.lastTime.IsZero() {
+		if d := now.Sub(rl.lastTime).Seconds(); d > 0 {
+			rl.tokens = math.Min(rl.burst, rl.tokens+d*rl.limit)
+		}
+	}
+	rl.lastTime = now
+	if rl.tokens >= 1 {
+		rl.tokens--
+		return 0
+	}
+	secs := (1 - rl.tokens) / rl.limit
+	return time.Duration(secs * float64(time.Second))
+}
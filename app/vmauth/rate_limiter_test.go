@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	f := func(limit float64, offsets []time.Duration, resultsExpected []time.Duration) {
+		t.Helper()
+		rl := newRateLimiter(limit)
+		startTime := time.Unix(1700000000, 0)
+		for i, offset := range offsets {
+			d := rl.take(startTime.Add(offset))
+			if d != resultsExpected[i] {
+				t.Fatalf("unexpected result at request #%d for limit=%v; got %s; want %s", i, limit, d, resultsExpected[i])
+			}
+		}
+	}
+
+	// burst up to the limit is allowed
+	f(2, []time.Duration{0, 0, 0}, []time.Duration{0, 0, 500 * time.Millisecond})
+
+	// tokens are refilled over time
+	f(2, []time.Duration{0, 0, 250 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond},
+		[]time.Duration{0, 0, 250 * time.Millisecond, 0, 500 * time.Millisecond})
+
+	// the bucket isn't refilled above the burst
+	f(1, []time.Duration{0, 10 * time.Second, 10 * time.Second}, []time.Duration{0, 0, time.Second})
+
+	// limits below one request per second
+	f(0.5, []time.Duration{0, time.Second, 2 * time.Second}, []time.Duration{0, time.Second, 0})
+}
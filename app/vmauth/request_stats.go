@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+// initRequestStats initializes per-user metrics for request statistics and validates max_request_body_size.
+func (ui *UserInfo) initRequestStats(ms *metrics.Set, metricPrefix, metricLabels string) error {
+	if ui.MaxRequestBodySize != nil && ui.MaxRequestBodySize.N < 0 {
+		return fmt.Errorf("max_request_body_size cannot be negative; got %d", ui.MaxRequestBodySize.N)
+	}
+	ui.requestErrors = ms.GetOrCreateCounter(metricPrefix + `_request_errors_total` + metricLabels)
+	ui.requestBodyBytes = ms.GetOrCreateCounter(metricPrefix + `_request_body_bytes_total` + metricLabels)
+	ui.responseBodyBytes = ms.GetOrCreateCounter(metricPrefix + `_response_body_bytes_total` + metricLabels)
+	ui.requestBodySizeLimitReached = ms.GetOrCreateCounter(metricPrefix + `_request_body_size_limit_reached_total` + metricLabels)
+	return nil
+}
+
+func (ui *UserInfo) getMaxRequestBodySize() int64 {
+	if ui.MaxRequestBodySize == nil {
+		return 0
+	}
+	return ui.MaxRequestBodySize.N
+}
+
+// statsResponseWriter tracks the response status code and the number of bytes sent to the client.
+type statsResponseWriter struct {
+	http.ResponseWriter
+
+	statusCode   int
+	bytesWritten int
+}
+
+// WriteHeader implements http.ResponseWriter interface.
+func (sw *statsResponseWriter) WriteHeader(statusCode int) {
+	if sw.statusCode == 0 {
+		sw.statusCode = statusCode
+	}
+	sw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implements http.ResponseWriter interface.
+func (sw *statsResponseWriter) Write(p []byte) (int, error) {
+	if sw.statusCode == 0 {
+		sw.statusCode = http.StatusOK
+	}
+	n, err := sw.ResponseWriter.Write(p)
+	sw.bytesWritten += n
+	return n, err
+}
+
+// Flush implements http.Flusher interface.
+func (sw *statsResponseWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+//
+// This is used by http.ResponseController.
+func (sw *statsResponseWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// countingBody counts the number of bytes read from the request body.
+type countingBody struct {
+	r io.ReadCloser
+
+	bytesRead int
+}
+
+// Read implements io.Reader interface.
+func (cb *countingBody) Read(p []byte) (int, error) {
+	n, err := cb.r.Read(p)
+	cb.bytesRead += n
+	return n, err
+}
+
+// Close implements io.Closer interface.
+func (cb *countingBody) Close() error {
+	return cb.r.Close()
+}
+
+// updateRequestStats updates per-user request statistics after the request is processed.
+func (ui *UserInfo) updateRequestStats(sw *statsResponseWriter, cb *countingBody) {
+	if sw.statusCode >= 400 {
+		ui.requestErrors.Inc()
+	}
+	ui.responseBodyBytes.Add(sw.bytesWritten)
+	if cb != nil {
+		ui.requestBodyBytes.Add(cb.bytesRead)
+	}
+}
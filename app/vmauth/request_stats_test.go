@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProcessUserRequestStats(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		fmt.Fprintf(w, "got %d bytes", len(data))
+	}))
+	defer backend.Close()
+
+	ac, err := parseAuthConfig([]byte(fmt.Sprintf(`
+users:
+- username: foo
+  url_prefix: %q
+  max_request_body_size: 10
+`, backend.URL)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	m, err := parseAuthConfigUsers(ac)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ui := m[getHTTPAuthBasicToken("foo", "")]
+
+	f := func(path, body string, chunked bool, statusCodeExpected int) {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if chunked {
+			r.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		processUserRequest(w, r, ui)
+		if w.Code != statusCodeExpected {
+			t.Fatalf("unexpected status code for path=%q, body=%q; got %d; want %d; response: %q", path, body, w.Code, statusCodeExpected, w.Body.String())
+		}
+	}
+
+	f("/foo", "abc", false, http.StatusOK)
+	f("/foo", "0123456789", true, http.StatusOK)
+	f("/error", "", false, http.StatusBadRequest)
+
+	// request body exceeds max_request_body_size
+	f("/foo", "0123456789a", false, http.StatusRequestEntityTooLarge)
+	f("/foo", "0123456789a", true, http.StatusRequestEntityTooLarge)
+
+	checkCounter := func(name string, n, nExpected uint64) {
+		t.Helper()
+		if n != nExpected {
+			t.Fatalf("unexpected value for %s; got %d; want %d", name, n, nExpected)
+		}
+	}
+	checkCounter("requests", ui.requests.Get(), 5)
+	checkCounter("requestErrors", ui.requestErrors.Get(), 3)
+	checkCounter("requestBodySizeLimitReached", ui.requestBodySizeLimitReached.Get(), 2)
+
+	// Response bytes include error messages generated by vmauth, while request bytes include the bytes read before rejecting the request.
+	if n := ui.responseBodyBytes.Get(); n <= uint64(len("got 3 bytes")+len("got 10 bytes")+len("got 0 bytes")) {
+		t.Fatalf("unexpected responseBodyBytes; got %d; want more than 34", n)
+	}
+	if n := ui.requestBodyBytes.Get(); n < 13 {
+		t.Fatalf("unexpected requestBodyBytes; got %d; want at least 13", n)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"net/http"
 	"net/url"
 	"path"
 	"strings"
@@ -49,16 +50,97 @@ func dropPrefixParts(path string, parts int) string {
 	return path
 }
 
-func (ui *UserInfo) getURLPrefixAndHeaders(u *url.URL) (*URLPrefix, HeadersConf) {
-	for _, e := range ui.URLMaps {
-		if matchAnyRegex(e.SrcHosts, u.Host) && matchAnyRegex(e.SrcPaths, u.Path) {
-			return e.URLPrefix, e.HeadersConf
+// getURLPrefixAndHeaders returns url prefix and headers config for the request with the given u, method and headers.
+//
+// scopes must contain the scopes from JSON Web Token if the request is authorized via `jwt` section.
+//
+// It also returns the request url, which must be merged with the returned url prefix.
+// This url may differ from u if the matching `url_map` entry contains `target_path`.
+func (ui *UserInfo) getURLPrefixAndHeaders(u *url.URL, method string, h http.Header, scopes []string) (*URLPrefix, HeadersConf, *url.URL) {
+	for i := range ui.URLMaps {
+		e := &ui.URLMaps[i]
+		if !matchAnyRegex(e.SrcHosts, u.Host) || !matchAnyRegex(e.SrcPaths, u.Path) {
+			continue
+		}
+		if !matchAnyMethod(e.SrcMethods, method) || !matchAllHeaders(e.SrcHeaders, h) {
+			continue
+		}
+		if !hasAllScopes(scopes, e.RequiredScopes) {
+			continue
 		}
+		if e.TargetPath != "" {
+			u = e.rewritePath(u)
+		}
+		return e.URLPrefix, e.HeadersConf, u
 	}
 	if ui.URLPrefix != nil {
-		return ui.URLPrefix, ui.HeadersConf
+		return ui.URLPrefix, ui.HeadersConf, u
+	}
+	return nil, HeadersConf{}, u
+}
+
+// rewritePath returns a copy of u with the path substituted by e.TargetPath.
+//
+// Capture groups from the first matching e.SrcPaths regex are expanded in e.TargetPath.
+func (e *URLMap) rewritePath(uOrig *url.URL) *url.URL {
+	u := *uOrig
+	for _, r := range e.SrcPaths {
+		match := r.re.FindStringSubmatchIndex(u.Path)
+		if match == nil {
+			continue
+		}
+		dst := r.re.ExpandString(nil, e.TargetPath, u.Path, match)
+		u.Path = string(dst)
+		return &u
+	}
+	u.Path = e.TargetPath
+	return &u
+}
+
+func matchAnyMethod(methods []string, method string) bool {
+	if len(methods) == 0 {
+		return true
+	}
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAllScopes(scopes, requiredScopes []string) bool {
+	for _, rs := range requiredScopes {
+		found := false
+		for _, s := range scopes {
+			if s == rs {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAllHeaders(hfs []*HeaderFilter, h http.Header) bool {
+	for _, hf := range hfs {
+		if !matchAnyHeaderValue(hf, h.Values(hf.Name)) {
+			return false
+		}
 	}
-	return nil, HeadersConf{}
+	return true
+}
+
+func matchAnyHeaderValue(hf *HeaderFilter, values []string) bool {
+	for _, v := range values {
+		if hf.Value.match(v) {
+			return true
+		}
+	}
+	return false
 }
 
 func matchAnyRegex(rs []*Regex, s string) bool {
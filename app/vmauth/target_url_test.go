@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -89,12 +91,12 @@ func TestCreateTargetURLSuccess(t *testing.T) {
 			t.Fatalf("cannot parse %q: %s", requestURI, err)
 		}
 		u = normalizeURL(u)
-		up, hc := ui.getURLPrefixAndHeaders(u)
+		up, hc, uTarget := ui.getURLPrefixAndHeaders(u, http.MethodGet, nil, nil)
 		if up == nil {
 			t.Fatalf("cannot determie backend: %s", err)
 		}
 		bu := up.getLeastLoadedBackendURL()
-		target := mergeURLs(bu.url, u, up.dropSrcPathPrefixParts)
+		target := mergeURLs(bu.url, uTarget, up.dropSrcPathPrefixParts)
 		bu.put()
 		if target.String() != expectedTarget {
 			t.Fatalf("unexpected target; got %q; want %q", target, expectedTarget)
@@ -235,6 +237,79 @@ func TestCreateTargetURLSuccess(t *testing.T) {
 	}, "/api/v1/query?extra_label=team=dev", "http://foo.bar/api/v1/query?extra_label=team%3Dmobile", "[]", "[]", nil, "least_loaded", 0)
 }
 
+func TestCreateTargetURLRouting(t *testing.T) {
+	f := func(ui *UserInfo, method, requestURI string, headers []string, expectedTarget string) {
+		t.Helper()
+		if err := ui.initURLs(); err != nil {
+			t.Fatalf("cannot initialize urls inside UserInfo: %s", err)
+		}
+		u, err := url.Parse(requestURI)
+		if err != nil {
+			t.Fatalf("cannot parse %q: %s", requestURI, err)
+		}
+		u = normalizeURL(u)
+		h := make(http.Header)
+		for _, s := range headers {
+			n := strings.IndexByte(s, ':')
+			h.Add(s[:n], strings.TrimSpace(s[n+1:]))
+		}
+		up, _, uTarget := ui.getURLPrefixAndHeaders(u, method, h, nil)
+		if up == nil {
+			if expectedTarget != "" {
+				t.Fatalf("cannot determine backend for %s %q", method, requestURI)
+			}
+			return
+		}
+		bu := up.getLeastLoadedBackendURL()
+		target := mergeURLs(bu.url, uTarget, up.dropSrcPathPrefixParts)
+		bu.put()
+		if target.String() != expectedTarget {
+			t.Fatalf("unexpected target; got %q; want %q", target, expectedTarget)
+		}
+	}
+
+	ui := &UserInfo{
+		URLMaps: []URLMap{
+			{
+				SrcPaths:   getRegexs([]string{"/api/v1/write"}),
+				SrcMethods: []string{"post", "PUT"},
+				SrcHeaders: getHeaderFilters([]string{"X-Scope-OrgID: team-.+"}),
+				URLPrefix:  mustParseURL("http://vminsert-teams"),
+			},
+			{
+				SrcPaths:   getRegexs([]string{"/api/v1/write"}),
+				SrcMethods: []string{"POST"},
+				URLPrefix:  mustParseURL("http://vminsert"),
+			},
+			{
+				SrcPaths:   getRegexs([]string{"/select/([0-9]+)/(.+)"}),
+				URLPrefix:  mustParseURL("http://vmselect/select"),
+				TargetPath: "/$1/prometheus/$2",
+			},
+			{
+				SrcPaths:   getRegexs([]string{"/alerts/(?P<path>.*)"}),
+				URLPrefix:  mustParseURL("http://vmalert"),
+				TargetPath: "/vmalert/${path}",
+			},
+		},
+	}
+
+	// routing by method
+	f(ui, http.MethodPost, "/api/v1/write", nil, "http://vminsert/api/v1/write")
+	f(ui, http.MethodGet, "/api/v1/write", nil, "")
+
+	// routing by headers
+	f(ui, http.MethodPut, "/api/v1/write", []string{"X-Scope-OrgID: team-a"}, "http://vminsert-teams/api/v1/write")
+	f(ui, http.MethodPost, "/api/v1/write", []string{"X-Scope-OrgID: foo", "X-Scope-OrgID: team-b"}, "http://vminsert-teams/api/v1/write")
+	f(ui, http.MethodPost, "/api/v1/write", []string{"X-Scope-OrgID: foo"}, "http://vminsert/api/v1/write")
+	f(ui, http.MethodPut, "/api/v1/write", []string{"X-Scope-OrgID: foo"}, "")
+
+	// rewriting the path with capture groups
+	f(ui, http.MethodGet, "/select/42/api/v1/query?query=up", nil, "http://vmselect/select/42/prometheus/api/v1/query?query=up")
+	f(ui, http.MethodGet, "/alerts/api/v1/rules", nil, "http://vmalert/vmalert/api/v1/rules")
+	f(ui, http.MethodGet, "/select/foo/api/v1/query", nil, "")
+}
+
 func TestCreateTargetURLFailure(t *testing.T) {
 	f := func(ui *UserInfo, requestURI string) {
 		t.Helper()
@@ -243,7 +318,7 @@ func TestCreateTargetURLFailure(t *testing.T) {
 			t.Fatalf("cannot parse %q: %s", requestURI, err)
 		}
 		u = normalizeURL(u)
-		up, hc := ui.getURLPrefixAndHeaders(u)
+		up, hc, _ := ui.getURLPrefixAndHeaders(u, http.MethodGet, nil, nil)
 		if up != nil {
 			t.Fatalf("unexpected non-empty up=%#v", up)
 		}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/VictoriaMetrics/metrics"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/backupnames"
+)
+
+var (
+	listBackupsRequests  = metrics.NewCounter(`vmbackupmanager_http_requests_total{path="/api/v1/backups"}`)
+	getBackupRequests    = metrics.NewCounter(`vmbackupmanager_http_requests_total{path="/api/v1/backups/*", method="GET"}`)
+	updateBackupRequests = metrics.NewCounter(`vmbackupmanager_http_requests_total{path="/api/v1/backups/*", method="PUT"}`)
+)
+
+// validateBackupName verifies whether name is a valid name of backup made by vmbackupmanager.
+func validateBackupName(name string) error {
+	if name == latestBackupName {
+		return nil
+	}
+	n := strings.IndexByte(name, '/')
+	if n < 0 {
+		return fmt.Errorf("unexpected backup name %q; it must be either %q or have <type>/<name> form", name, latestBackupName)
+	}
+	typeName, backupName := name[:n], name[n+1:]
+	if backupName == "" || strings.Contains(backupName, "/") || strings.Contains(backupName, "..") {
+		return fmt.Errorf("invalid backup name %q", name)
+	}
+	for _, bt := range backupTypes {
+		if bt.name == typeName {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected backup type %q in backup name %q; supported types: hourly, daily, weekly, monthly", typeName, name)
+}
+
+func writeBackupsList(w http.ResponseWriter) error {
+	bis, err := listAllBackups()
+	if err != nil {
+		return err
+	}
+	if bis == nil {
+		bis = []backupInfo{}
+	}
+	return writeJSON(w, bis)
+}
+
+func writeBackupInfo(w http.ResponseWriter, name string) error {
+	bi, err := getBackupInfoByName(name)
+	if err != nil {
+		return err
+	}
+	if bi == nil {
+		return fmt.Errorf("backup doesn't exist")
+	}
+	return writeJSON(w, bi)
+}
+
+// updateBackup updates the `locked` attribute of the backup with the given name according to the request body.
+func updateBackup(w http.ResponseWriter, r *http.Request, name string) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read request body: %w", err)
+	}
+	var req struct {
+		Locked *bool `json:"locked"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return fmt.Errorf("cannot parse request body: %w", err)
+	}
+	if req.Locked == nil {
+		return fmt.Errorf("missing `locked` field in request body")
+	}
+	bi, err := getBackupInfoByName(name)
+	if err != nil {
+		return err
+	}
+	if bi == nil {
+		return fmt.Errorf("backup doesn't exist")
+	}
+
+	fs, err := newBackupFS(name)
+	if err != nil {
+		return err
+	}
+	defer fs.MustStop()
+	if *req.Locked {
+		err = fs.CreateFile(backupnames.ProtectMarkFileName, nil)
+	} else {
+		err = fs.DeleteFile(backupnames.ProtectMarkFileName)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot update `locked` attribute: %w", err)
+	}
+	bi.Locked = *req.Locked
+	return writeJSON(w, bi)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("cannot marshal response: %w", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+	return nil
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/actions"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/backupnames"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/common"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/fslocal"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/snapshot"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/snapshot/snapshotutil"
+)
+
+// latestBackupName is the name of the backup, which is updated on every backup run
+// and is used as a source for server-side copying to hourly, daily, weekly and monthly backups.
+const latestBackupName = "latest"
+
+// backupType describes backups made with the given interval.
+type backupType struct {
+	// name is the name of the folder at -dst for backups of the given type.
+	name string
+
+	// layout must return the backup name for the given time.
+	//
+	// Names returned by layout must be sorted lexicographically in the chronological order.
+	layout func(t time.Time) string
+
+	// disabled disables making backups of the given type.
+	disabled *bool
+
+	// keepLast is the number of backups of the given type to keep. Retention is disabled for negative values.
+	keepLast *int
+}
+
+var backupTypes = []*backupType{
+	{
+		name: "hourly",
+		layout: func(t time.Time) string {
+			return t.Format("2006-01-02:15")
+		},
+		disabled: disableHourly,
+		keepLast: keepLastHourly,
+	},
+	{
+		name: "daily",
+		layout: func(t time.Time) string {
+			return t.Format("2006-01-02")
+		},
+		disabled: disableDaily,
+		keepLast: keepLastDaily,
+	},
+	{
+		name: "weekly",
+		layout: func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%02d", year, week)
+		},
+		disabled: disableWeekly,
+		keepLast: keepLastWeekly,
+	},
+	{
+		name: "monthly",
+		layout: func(t time.Time) string {
+			return t.Format("2006-01")
+		},
+		disabled: disableMonthly,
+		keepLast: keepLastMonthly,
+	},
+}
+
+// backupName returns the name of bt backup for the given t.
+func (bt *backupType) backupName(t time.Time) string {
+	return bt.name + "/" + bt.layout(t.UTC())
+}
+
+// runBackupsService makes backups at the start of every hour until stopCh is closed.
+//
+// The schedule isn't configurable, since hourly, daily, weekly and monthly backups are made by copying the latest backup,
+// which must be made at least once per hour.
+func runBackupsService(stopCh <-chan struct{}) {
+	if *runOnStart {
+		runBackupCycle(time.Now())
+	}
+	for {
+		t := time.NewTimer(timeUntilNextHour(time.Now()))
+		select {
+		case <-stopCh:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		runBackupCycle(time.Now())
+	}
+}
+
+// timeUntilNextHour returns the duration from t until the start of the next hour.
+func timeUntilNextHour(t time.Time) time.Duration {
+	return t.Truncate(time.Hour).Add(time.Hour).Sub(t)
+}
+
+// runBackupCycle makes backups for the given t and then applies retention policy.
+func runBackupCycle(t time.Time) {
+	startTime := time.Now()
+	if err := makeBackups(t); err != nil {
+		backupErrorsTotal.Inc()
+		backupLastRunFailed.Set(1)
+		logger.Errorf("cannot make backup: %s", err)
+	} else {
+		backupsTotal.Inc()
+		backupLastRunFailed.Set(0)
+		atomic.StoreInt64(&lastSuccessfulBackupTime, time.Now().Unix())
+		logger.Infof("backup has been successfully made in %.3f seconds", time.Since(startTime).Seconds())
+	}
+
+	if err := applyRetentionPolicy(); err != nil {
+		retentionErrorsTotal.Inc()
+		logger.Errorf("cannot apply retention policy: %s", err)
+	}
+}
+
+// makeBackups backs up a fresh snapshot to the latest backup and then copies it to hourly, daily, weekly and monthly backups for the given t.
+func makeBackups(t time.Time) error {
+	snapshotName, err := snapshot.Create(*snapshotCreateURL)
+	if err != nil {
+		return fmt.Errorf("cannot create snapshot: %w", err)
+	}
+	defer func() {
+		if err := snapshot.Delete(*snapshotDeleteURL, snapshotName); err != nil {
+			logger.Errorf("cannot delete snapshot %q: %s", snapshotName, err)
+		}
+	}()
+
+	srcFS, err := newSrcFS(snapshotName)
+	if err != nil {
+		return err
+	}
+	defer srcFS.MustStop()
+	latestFS, err := newBackupFS(latestBackupName)
+	if err != nil {
+		return err
+	}
+	defer latestFS.MustStop()
+
+	a := &actions.Backup{
+		Concurrency: *concurrency,
+		Src:         srcFS,
+		Dst:         latestFS,
+	}
+	if err := a.Run(); err != nil {
+		return fmt.Errorf("cannot make %s backup: %w", latestBackupName, err)
+	}
+
+	for _, bt := range backupTypes {
+		if *bt.disabled {
+			continue
+		}
+		name := bt.backupName(t)
+		if err := copyBackup(latestFS, name); err != nil {
+			return fmt.Errorf("cannot make %s backup: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func copyBackup(src common.RemoteFS, name string) error {
+	dstFS, err := newBackupFS(name)
+	if err != nil {
+		return err
+	}
+	defer dstFS.MustStop()
+	a := &actions.RemoteBackupCopy{
+		Concurrency: *concurrency,
+		Src:         src,
+		Dst:         dstFS,
+	}
+	return a.Run()
+}
+
+func newSrcFS(snapshotName string) (*fslocal.FS, error) {
+	if err := snapshotutil.Validate(snapshotName); err != nil {
+		return nil, fmt.Errorf("invalid snapshot name %q: %w", snapshotName, err)
+	}
+	snapshotPath := filepath.Join(*storageDataPath, "snapshots", snapshotName)
+	fi, err := os.Stat(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot stat snapshot %q: %w", snapshotPath, err)
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("snapshot %q must be a directory", snapshotPath)
+	}
+	fs := &fslocal.FS{
+		Dir:               snapshotPath,
+		MaxBytesPerSecond: maxBytesPerSecond.IntN(),
+	}
+	if err := fs.Init(); err != nil {
+		return nil, fmt.Errorf("cannot initialize fs: %w", err)
+	}
+	return fs, nil
+}
+
+// newBackupFS returns RemoteFS for the given path relative to -dst.
+func newBackupFS(path string) (common.RemoteFS, error) {
+	fullPath := strings.TrimSuffix(*dst, "/") + "/" + path
+	fs, err := actions.NewRemoteFS(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize remote fs for %q: %w", fullPath, err)
+	}
+	return fs, nil
+}
+
+// initLastSuccessfulBackupTime initializes lastSuccessfulBackupTime from the metadata of the latest backup,
+// so the age of the last successful backup is properly reported after the restart.
+func initLastSuccessfulBackupTime() {
+	fs, err := newBackupFS(latestBackupName)
+	if err != nil {
+		logger.Errorf("cannot obtain the last backup time: %s", err)
+		return
+	}
+	defer fs.MustStop()
+	ok, err := fs.HasFile(backupnames.BackupCompleteFilename)
+	if err != nil {
+		logger.Errorf("cannot obtain the last backup time: %s", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	data, err := fs.ReadFile(backupnames.BackupMetadataFilename)
+	if err != nil {
+		logger.Errorf("cannot obtain the last backup time: %s", err)
+		return
+	}
+	var md actions.BackupMetadata
+	if err := json.Unmarshal(data, &md); err != nil {
+		logger.Errorf("cannot parse metadata for %s backup: %s", latestBackupName, err)
+		return
+	}
+	t, err := time.Parse(time.RFC3339, md.CompletedAt)
+	if err != nil {
+		logger.Errorf("cannot parse completed_at=%q for %s backup: %s", md.CompletedAt, latestBackupName, err)
+		return
+	}
+	atomic.StoreInt64(&lastSuccessfulBackupTime, t.Unix())
+}
+
+// lastSuccessfulBackupTime is the unix timestamp in seconds of the last successful backup.
+var lastSuccessfulBackupTime int64
+
+var startTime = time.Now()
+
+var (
+	backupsTotal         = metrics.NewCounter(`vmbackupmanager_backups_total`)
+	backupErrorsTotal    = metrics.NewCounter(`vmbackupmanager_backup_errors_total`)
+	backupLastRunFailed  = metrics.NewGauge(`vmbackupmanager_backup_last_run_failed`, nil)
+	retentionDeleted     = metrics.NewCounter(`vmbackupmanager_retention_deleted_backups_total`)
+	retentionErrorsTotal = metrics.NewCounter(`vmbackupmanager_retention_errors_total`)
+
+	_ = metrics.NewGauge(`vmbackupmanager_backup_last_success_timestamp_seconds`, func() float64 {
+		return float64(atomic.LoadInt64(&lastSuccessfulBackupTime))
+	})
+	_ = metrics.NewGauge(`vmbackupmanager_backup_last_success_age_seconds`, func() float64 {
+		t := startTime
+		if n := atomic.LoadInt64(&lastSuccessfulBackupTime); n > 0 {
+			t = time.Unix(n, 0)
+		}
+		// The age is counted since the vmbackupmanager start if there are no successful backups yet.
+		return time.Since(t).Seconds()
+	})
+)
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/backupnames"
+)
+
+func TestRunBackupCycle(t *testing.T) {
+	dataPath := t.TempDir()
+	dstPath := t.TempDir()
+
+	// Emulate VictoriaMetrics snapshot API.
+	var snapshotsCreated, snapshotsDeleted int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/snapshot/create":
+			snapshotsCreated++
+			name := fmt.Sprintf("20230407110000-%016X", snapshotsCreated)
+			// Parts are immutable, so every snapshot contains a part with distinct name.
+			partPath := filepath.Join(dataPath, "snapshots", name, "data", "small", "2023_04", fmt.Sprintf("part%d", snapshotsCreated))
+			if err := os.MkdirAll(partPath, 0755); err != nil {
+				t.Errorf("cannot create snapshot dir: %s", err)
+			}
+			data := fmt.Sprintf("snapshot %d", snapshotsCreated)
+			if err := os.WriteFile(filepath.Join(partPath, "values.bin"), []byte(data), 0644); err != nil {
+				t.Errorf("cannot write snapshot file: %s", err)
+			}
+			fmt.Fprintf(w, `{"status":"ok","snapshot":%q}`, name)
+		case "/snapshot/delete":
+			snapshotsDeleted++
+			name := r.FormValue("snapshot")
+			if err := os.RemoveAll(filepath.Join(dataPath, "snapshots", name)); err != nil {
+				t.Errorf("cannot delete snapshot: %s", err)
+			}
+			fmt.Fprintf(w, `{"status":"ok"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	defer setFlagValues(map[*string]string{
+		storageDataPath:   dataPath,
+		dst:               "fs://" + dstPath,
+		snapshotCreateURL: srv.URL + "/snapshot/create",
+		snapshotDeleteURL: srv.URL + "/snapshot/delete",
+	})()
+	keepLastHourlyOrig := *keepLastHourly
+	*keepLastHourly = 1
+	defer func() {
+		*keepLastHourly = keepLastHourlyOrig
+	}()
+
+	backupsTotalOrig := backupsTotal.Get()
+	ts := time.Date(2023, 4, 7, 11, 0, 0, 0, time.UTC)
+	runBackupCycle(ts)
+	runBackupCycle(ts.Add(time.Hour))
+
+	if snapshotsCreated != 2 || snapshotsDeleted != 2 {
+		t.Fatalf("unexpected number of snapshots; created %d; deleted %d; want 2 created and 2 deleted", snapshotsCreated, snapshotsDeleted)
+	}
+	if n := backupsTotal.Get() - backupsTotalOrig; n != 2 {
+		t.Fatalf("unexpected number of successful backups; got %d; want 2", n)
+	}
+	if backupLastRunFailed.Get() != 0 {
+		t.Fatalf("the last backup run must be successful")
+	}
+	if atomic.LoadInt64(&lastSuccessfulBackupTime) == 0 {
+		t.Fatalf("the last successful backup time must be set")
+	}
+
+	bis, err := listAllBackups()
+	if err != nil {
+		t.Fatalf("cannot list backups: %s", err)
+	}
+	var names []string
+	for _, bi := range bis {
+		names = append(names, bi.Name)
+	}
+	// The first hourly backup must be deleted by -keepLastHourly=1
+	namesExpected := []string{"daily/2023-04-07", "hourly/2023-04-07:12", "latest", "monthly/2023-04", "weekly/2023-14"}
+	if !reflect.DeepEqual(names, namesExpected) {
+		t.Fatalf("unexpected backups; got %q; want %q", names, namesExpected)
+	}
+
+	// Every backup must contain only the part from the last snapshot.
+	for _, name := range names {
+		fs, err := newBackupFS(name)
+		if err != nil {
+			t.Fatalf("cannot initialize fs for backup %q: %s", name, err)
+		}
+		parts, err := fs.ListParts()
+		if err != nil {
+			t.Fatalf("cannot list parts for backup %q: %s", name, err)
+		}
+		ok, err := fs.HasFile(backupnames.BackupCompleteFilename)
+		if err != nil {
+			t.Fatalf("cannot check `backup complete` file for backup %q: %s", name, err)
+		}
+		fs.MustStop()
+		if len(parts) != 1 || parts[0].Path != "data/small/2023_04/part2/values.bin" {
+			t.Fatalf("unexpected parts for backup %q: %+v", name, parts)
+		}
+		if !ok {
+			t.Fatalf("missing `backup complete` file for backup %q", name)
+		}
+	}
+}
+
+func TestRestoreMark(t *testing.T) {
+	defer setFlagValues(map[*string]string{
+		storageDataPath: t.TempDir(),
+		dst:             "fs:///backups",
+	})()
+
+	rm, err := readRestoreMark()
+	if err != nil {
+		t.Fatalf("cannot read restore mark: %s", err)
+	}
+	if rm != nil {
+		t.Fatalf("unexpected restore mark; got %+v; want nil", rm)
+	}
+	// Restore must be no-op without restore mark
+	if err := runRestore(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	f := func(backup, srcExpected string) {
+		t.Helper()
+		if err := writeRestoreMark(&restoreMark{Backup: backup}); err != nil {
+			t.Fatalf("cannot write restore mark: %s", err)
+		}
+		rm, err := readRestoreMark()
+		if err != nil {
+			t.Fatalf("cannot read restore mark: %s", err)
+		}
+		if rm == nil || rm.Backup != backup {
+			t.Fatalf("unexpected restore mark; got %+v; want %q", rm, backup)
+		}
+		src, err := getRestoreSrc(rm)
+		if err != nil {
+			t.Fatalf("cannot get restore src: %s", err)
+		}
+		if src != srcExpected {
+			t.Fatalf("unexpected restore src; got %q; want %q", src, srcExpected)
+		}
+	}
+	f("daily/2023-04-07", "fs:///backups/daily/2023-04-07")
+	f("latest", "fs:///backups/latest")
+	f("gs://bucket/vmstorage-0/daily/2023-04-07", "gs://bucket/vmstorage-0/daily/2023-04-07")
+
+	if err := deleteRestoreMark(); err != nil {
+		t.Fatalf("cannot delete restore mark: %s", err)
+	}
+	// Deleting the missing restore mark mustn't fail
+	if err := deleteRestoreMark(); err != nil {
+		t.Fatalf("cannot delete missing restore mark: %s", err)
+	}
+	if _, err := getRestoreSrc(&restoreMark{Backup: "daily/../latest"}); err == nil {
+		t.Fatalf("expecting non-nil error for invalid backup name")
+	}
+}
+
+func TestSplitCommandArgs(t *testing.T) {
+	f := func(args, cmdArgsExpected, flagArgsExpected []string) {
+		t.Helper()
+		cmdArgs, flagArgs := splitCommandArgs(args)
+		if !reflect.DeepEqual(cmdArgs, cmdArgsExpected) {
+			t.Fatalf("unexpected command args; got %q; want %q", cmdArgs, cmdArgsExpected)
+		}
+		if !reflect.DeepEqual(flagArgs, flagArgsExpected) {
+			t.Fatalf("unexpected flag args; got %q; want %q", flagArgs, flagArgsExpected)
+		}
+	}
+	f([]string{}, []string{}, []string{})
+	f([]string{"-dst=fs:///foo"}, []string{}, []string{"-dst=fs:///foo"})
+	f([]string{"backup", "list"}, []string{"backup", "list"}, []string{})
+	f([]string{"backup", "lock", "daily/2023-04-07", "-apiURL=http://foo:8300"}, []string{"backup", "lock", "daily/2023-04-07"}, []string{"-apiURL=http://foo:8300"})
+	f([]string{"restore", "-storageDataPath=/data"}, []string{"restore"}, []string{"-storageDataPath=/data"})
+}
+
+// setFlagValues sets the given flag values and returns a function, which restores the original values.
+func setFlagValues(m map[*string]string) func() {
+	orig := make(map[*string]string, len(m))
+	for p, v := range m {
+		orig[p] = *p
+		*p = v
+	}
+	return func() {
+		for p, v := range orig {
+			*p = v
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+var apiURL = flag.String("apiURL", "http://127.0.0.1:8300", "vmbackupmanager address to perform API requests to from CLI commands. See https://docs.victoriametrics.com/vmbackupmanager.html#cli")
+
+// runCommand runs the CLI command from args.
+//
+// See https://docs.victoriametrics.com/vmbackupmanager.html#cli
+func runCommand(args []string) error {
+	switch args[0] {
+	case "backup":
+		return runBackupCommand(args[1:])
+	case "restore":
+		return runRestoreCommand(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q; supported commands: backup, restore", args[0])
+	}
+}
+
+func runBackupCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subcommand for `backup` command; supported subcommands: list, lock, unlock")
+	}
+	switch args[0] {
+	case "list":
+		if err := expectArgs("backup list", args[1:], 0); err != nil {
+			return err
+		}
+		return doAPIRequest(http.MethodGet, "/api/v1/backups", nil)
+	case "lock", "unlock":
+		if err := expectArgs("backup "+args[0], args[1:], 1); err != nil {
+			return err
+		}
+		name := args[1]
+		if err := validateBackupName(name); err != nil {
+			return err
+		}
+		body := fmt.Sprintf(`{"locked":%v}`, args[0] == "lock")
+		return doAPIRequest(http.MethodPut, "/api/v1/backups/"+name, []byte(body))
+	default:
+		return fmt.Errorf("unknown subcommand %q for `backup` command; supported subcommands: list, lock, unlock", args[0])
+	}
+}
+
+func runRestoreCommand(args []string) error {
+	if len(args) == 0 {
+		return runRestore()
+	}
+	switch args[0] {
+	case "get":
+		if err := expectArgs("restore get", args[1:], 0); err != nil {
+			return err
+		}
+		return doAPIRequest(http.MethodGet, "/api/v1/restore", nil)
+	case "delete":
+		if err := expectArgs("restore delete", args[1:], 0); err != nil {
+			return err
+		}
+		return doAPIRequest(http.MethodDelete, "/api/v1/restore", nil)
+	case "create":
+		if err := expectArgs("restore create", args[1:], 1); err != nil {
+			return err
+		}
+		body, err := json.Marshal(&restoreMark{
+			Backup: args[1],
+		})
+		if err != nil {
+			return fmt.Errorf("cannot marshal request body: %w", err)
+		}
+		return doAPIRequest(http.MethodPost, "/api/v1/restore", body)
+	default:
+		return fmt.Errorf("unknown subcommand %q for `restore` command; supported subcommands: get, create, delete", args[0])
+	}
+}
+
+func expectArgs(command string, args []string, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("`%s` command expects %d args; got %d args: %q", command, n, len(args), args)
+	}
+	return nil
+}
+
+// doAPIRequest performs the request to vmbackupmanager API at -apiURL and writes the response body to stdout.
+func doAPIRequest(method, path string, body []byte) error {
+	requestURL := strings.TrimSuffix(*apiURL, "/") + path
+	req, err := http.NewRequest(method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot create request to %q: %w", requestURL, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	hc := &http.Client{
+		Timeout: time.Minute,
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot perform request to %q: %w", requestURL, err)
+	}
+	data, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("cannot read response from %q: %w", requestURL, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code returned from %q: %d; response body: %q", requestURL, resp.StatusCode, data)
+	}
+	if len(data) > 0 {
+		fmt.Fprintf(os.Stdout, "%s\n", bytes.TrimSpace(data))
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/buildinfo"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/envflag"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/flagutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/procutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/pushmetrics"
+)
+
+var (
+	httpListenAddr    = flag.String("httpListenAddr", ":8300", "Address to listen for http connections")
+	storageDataPath   = flag.String("storageDataPath", "victoria-metrics-data", "Path to VictoriaMetrics data. Must match -storageDataPath from VictoriaMetrics or vmstorage")
+	snapshotCreateURL = flag.String("snapshot.createURL", "", "VictoriaMetrics create snapshot url. When this is given a snapshot will automatically be created during backup. "+
+		"Example: http://victoriametrics:8428/snapshot/create")
+	snapshotDeleteURL = flag.String("snapshot.deleteURL", "", "VictoriaMetrics delete snapshot url. Optional. Will be generated from -snapshot.createURL if not provided. "+
+		"All created snapshots will be automatically deleted. Example: http://victoriametrics:8428/snapshot/delete")
+	dst = flag.String("dst", "", "The root folder of VictoriaMetrics backups. "+
+		"Example: gs://bucket/path/to/backup/dir, s3://bucket/path/to/backup/dir, azblob://container/path/to/backup/dir, sftp://user@host/path/to/backup/dir or fs:///path/to/local/backup/dir")
+	concurrency       = flag.Int("concurrency", 10, "The number of concurrent workers. Higher concurrency may reduce backup duration")
+	maxBytesPerSecond = flagutil.NewBytes("maxBytesPerSecond", 0, "The maximum upload speed. There is no limit if it is set to 0")
+	runOnStart        = flag.Bool("runOnStart", false, "Upload backups immediately after start of the service. Otherwise the backup starts on new hour")
+
+	disableHourly  = flag.Bool("disableHourly", false, "Disable hourly run. Default false")
+	disableDaily   = flag.Bool("disableDaily", false, "Disable daily run. Default false")
+	disableWeekly  = flag.Bool("disableWeekly", false, "Disable weekly run. Default false")
+	disableMonthly = flag.Bool("disableMonthly", false, "Disable monthly run. Default false")
+
+	keepLastHourly  = flag.Int("keepLastHourly", -1, "Keep last N hourly backups. If 0 is specified next retention cycle removes all backups for given time period.")
+	keepLastDaily   = flag.Int("keepLastDaily", -1, "Keep last N daily backups. If 0 is specified next retention cycle removes all backups for given time period.")
+	keepLastWeekly  = flag.Int("keepLastWeekly", -1, "Keep last N weekly backups. If 0 is specified next retention cycle removes all backups for given time period.")
+	keepLastMonthly = flag.Int("keepLastMonthly", -1, "Keep last N monthly backups. If 0 is specified next retention cycle removes all backups for given time period.")
+)
+
+func main() {
+	// Write flags and help message to stdout, since it is easier to grep or pipe.
+	flag.CommandLine.SetOutput(os.Stdout)
+	flag.Usage = usage
+	flagutil.RegisterSecretFlag("snapshot.createURL")
+	flagutil.RegisterSecretFlag("snapshot.deleteURL")
+	cmdArgs, flagArgs := splitCommandArgs(os.Args[1:])
+	envflag.ParseFlagSet(flag.CommandLine, flagArgs)
+	buildinfo.Init()
+	logger.Init()
+
+	if len(cmdArgs) > 0 {
+		if err := runCommand(cmdArgs); err != nil {
+			logger.Fatalf("%s", err)
+		}
+		return
+	}
+
+	if err := validateFlags(); err != nil {
+		logger.Fatalf("%s", err)
+	}
+
+	listenAddrs := []string{*httpListenAddr}
+	go httpserver.Serve(listenAddrs, nil, requestHandler)
+
+	pushmetrics.Init()
+	initLastSuccessfulBackupTime()
+
+	stopCh := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		runBackupsService(stopCh)
+	}()
+
+	sig := procutil.WaitForSigterm()
+	logger.Infof("received signal %s; waiting for the currently running backup to finish", sig)
+	close(stopCh)
+	wg.Wait()
+	pushmetrics.Stop()
+
+	startTime := time.Now()
+	logger.Infof("gracefully shutting down http server at %q", listenAddrs)
+	if err := httpserver.Stop(listenAddrs); err != nil {
+		logger.Fatalf("cannot stop http server: %s", err)
+	}
+	logger.Infof("successfully shut down http server in %.3f seconds", time.Since(startTime).Seconds())
+}
+
+func requestHandler(w http.ResponseWriter, r *http.Request) bool {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == "/api/v1/restore":
+		handleRestoreRequest(w, r)
+		return true
+	case path == "/api/v1/backups":
+		if r.Method != http.MethodGet {
+			httpserver.Errorf(w, r, "unsupported method %s; only GET is supported", r.Method)
+			return true
+		}
+		listBackupsRequests.Inc()
+		if err := writeBackupsList(w); err != nil {
+			httpserver.Errorf(w, r, "cannot list backups: %s", err)
+		}
+		return true
+	case strings.HasPrefix(path, "/api/v1/backups/"):
+		name := path[len("/api/v1/backups/"):]
+		if err := validateBackupName(name); err != nil {
+			httpserver.Errorf(w, r, "%s", err)
+			return true
+		}
+		switch r.Method {
+		case http.MethodGet:
+			getBackupRequests.Inc()
+			if err := writeBackupInfo(w, name); err != nil {
+				httpserver.Errorf(w, r, "cannot obtain backup %q: %s", name, err)
+			}
+		case http.MethodPut:
+			updateBackupRequests.Inc()
+			if err := updateBackup(w, r, name); err != nil {
+				httpserver.Errorf(w, r, "cannot update backup %q: %s", name, err)
+			}
+		default:
+			httpserver.Errorf(w, r, "unsupported method %s; only GET and PUT are supported", r.Method)
+		}
+		return true
+	}
+	return false
+}
+
+// splitCommandArgs splits args into CLI command args such as `backup list` and the remaining flag args.
+func splitCommandArgs(args []string) ([]string, []string) {
+	n := 0
+	for n < len(args) && !strings.HasPrefix(args[n], "-") {
+		n++
+	}
+	return args[:n], args[n:]
+}
+
+func usage() {
+	const s = `
+vmbackupmanager performs regular backups for VictoriaMetrics data according to the provided configs.
+
+Usage:
+  vmbackupmanager [flags]                            - run backups at the start of every hour
+  vmbackupmanager backup list|lock|unlock [name]     - list, lock or unlock backups via -apiURL
+  vmbackupmanager restore get|create|delete [name]   - get, create or delete restore mark via -apiURL
+  vmbackupmanager restore [flags]                    - restore -storageDataPath from the backup in restore mark
+
+See the docs at https://docs.victoriametrics.com/vmbackupmanager.html .
+`
+	flagutil.Usage(s)
+}
+
+func validateFlags() error {
+	if len(*dst) == 0 {
+		return fmt.Errorf("-dst cannot be empty")
+	}
+	if hasFilepathPrefix(*dst, *storageDataPath) {
+		return fmt.Errorf("-dst=%q can not point to the directory with VictoriaMetrics data (aka -storageDataPath=%q)", *dst, *storageDataPath)
+	}
+	if len(*snapshotCreateURL) == 0 {
+		return fmt.Errorf("-snapshot.createURL cannot be empty")
+	}
+	createURL, err := url.Parse(*snapshotCreateURL)
+	if err != nil {
+		return fmt.Errorf("cannot parse -snapshot.createURL: %w", err)
+	}
+	logger.Infof("Snapshot create url %s", createURL.Redacted())
+	if len(*snapshotDeleteURL) == 0 {
+		if err := flag.Set("snapshot.deleteURL", strings.Replace(*snapshotCreateURL, "/create", "/delete", 1)); err != nil {
+			return fmt.Errorf("cannot set -snapshot.deleteURL flag: %w", err)
+		}
+	}
+	deleteURL, err := url.Parse(*snapshotDeleteURL)
+	if err != nil {
+		return fmt.Errorf("cannot parse -snapshot.deleteURL: %w", err)
+	}
+	logger.Infof("Snapshot delete url %s", deleteURL.Redacted())
+	return nil
+}
+
+func hasFilepathPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, "fs://") {
+		return false
+	}
+	path = path[len("fs://"):]
+	pathAbs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	prefixAbs, err := filepath.Abs(prefix)
+	if err != nil {
+		return false
+	}
+	if prefixAbs == pathAbs {
+		return true
+	}
+	rel, err := filepath.Rel(prefixAbs, pathAbs)
+	if err != nil {
+		// if paths can't be related - they don't match
+		return false
+	}
+	if i := strings.Index(rel, "."); i == 0 {
+		// if path can be related only with . as first char - they still don't match
+		return false
+	}
+	// if paths are related - it is a match
+	return true
+}
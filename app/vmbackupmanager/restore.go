@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VictoriaMetrics/metrics"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/actions"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/backupnames"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/fslocal"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+)
+
+var (
+	getRestoreMarkRequests    = metrics.NewCounter(`vmbackupmanager_http_requests_total{path="/api/v1/restore", method="GET"}`)
+	createRestoreMarkRequests = metrics.NewCounter(`vmbackupmanager_http_requests_total{path="/api/v1/restore", method="POST"}`)
+	deleteRestoreMarkRequests = metrics.NewCounter(`vmbackupmanager_http_requests_total{path="/api/v1/restore", method="DELETE"}`)
+)
+
+// restoreMark contains the name of the backup to restore by `vmbackupmanager restore` command.
+//
+// It is stored at backupnames.RestoreMarkFileName file inside -storageDataPath.
+type restoreMark struct {
+	// Backup is either a backup name relative to -dst such as `daily/2023-04-07`
+	// or a full path to the backup such as `gs://bucket/path/to/backup`.
+	Backup string `json:"backup"`
+}
+
+func getRestoreMarkPath() string {
+	return filepath.Join(*storageDataPath, backupnames.RestoreMarkFileName)
+}
+
+// readRestoreMark reads the restore mark from -storageDataPath.
+//
+// nil is returned if the restore mark doesn't exist.
+func readRestoreMark() (*restoreMark, error) {
+	path := getRestoreMarkPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot read restore mark: %w", err)
+	}
+	var rm restoreMark
+	if err := json.Unmarshal(data, &rm); err != nil {
+		return nil, fmt.Errorf("cannot parse restore mark %q: %w", path, err)
+	}
+	return &rm, nil
+}
+
+func writeRestoreMark(rm *restoreMark) error {
+	data, err := json.Marshal(rm)
+	if err != nil {
+		return fmt.Errorf("cannot marshal restore mark: %w", err)
+	}
+	fs.MustMkdirIfNotExist(*storageDataPath)
+	fs.MustWriteAtomic(getRestoreMarkPath(), data, true)
+	return nil
+}
+
+func deleteRestoreMark() error {
+	if err := os.Remove(getRestoreMarkPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot delete restore mark: %w", err)
+	}
+	return nil
+}
+
+// getRestoreSrc returns the full path to the backup from rm.
+func getRestoreSrc(rm *restoreMark) (string, error) {
+	if strings.Contains(rm.Backup, "://") {
+		return rm.Backup, nil
+	}
+	if err := validateBackupName(rm.Backup); err != nil {
+		return "", err
+	}
+	if len(*dst) == 0 {
+		return "", fmt.Errorf("-dst cannot be empty when restoring from the backup %q relative to -dst", rm.Backup)
+	}
+	return strings.TrimSuffix(*dst, "/") + "/" + rm.Backup, nil
+}
+
+func handleRestoreRequest(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		getRestoreMarkRequests.Inc()
+		rm, err := readRestoreMark()
+		if err != nil {
+			httpserver.Errorf(w, r, "%s", err)
+			return
+		}
+		if rm == nil {
+			err := &httpserver.ErrorWithStatusCode{
+				Err:        fmt.Errorf("restore mark doesn't exist"),
+				StatusCode: http.StatusNotFound,
+			}
+			httpserver.Errorf(w, r, "%s", err)
+			return
+		}
+		if err := writeJSON(w, rm); err != nil {
+			httpserver.Errorf(w, r, "%s", err)
+		}
+	case http.MethodPost:
+		createRestoreMarkRequests.Inc()
+		if err := createRestoreMark(w, r); err != nil {
+			httpserver.Errorf(w, r, "cannot create restore mark: %s", err)
+		}
+	case http.MethodDelete:
+		deleteRestoreMarkRequests.Inc()
+		if err := deleteRestoreMark(); err != nil {
+			httpserver.Errorf(w, r, "%s", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		httpserver.Errorf(w, r, "unsupported method %s; only GET, POST and DELETE are supported", r.Method)
+	}
+}
+
+// createRestoreMark creates the restore mark for the backup from the request body.
+func createRestoreMark(w http.ResponseWriter, r *http.Request) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read request body: %w", err)
+	}
+	var rm restoreMark
+	if err := json.Unmarshal(data, &rm); err != nil {
+		return fmt.Errorf("cannot parse request body: %w", err)
+	}
+	if rm.Backup == "" {
+		return fmt.Errorf("missing `backup` field in request body")
+	}
+	if !strings.Contains(rm.Backup, "://") {
+		// Verify the backup exists at -dst, so typos in the backup name are detected before the restore.
+		if err := validateBackupName(rm.Backup); err != nil {
+			return err
+		}
+		bi, err := getBackupInfoByName(rm.Backup)
+		if err != nil {
+			return err
+		}
+		if bi == nil {
+			return fmt.Errorf("backup %q doesn't exist", rm.Backup)
+		}
+	}
+	if err := writeRestoreMark(&rm); err != nil {
+		return err
+	}
+	return writeJSON(w, &rm)
+}
+
+// runRestore restores -storageDataPath from the backup specified in the restore mark.
+//
+// It does nothing if the restore mark doesn't exist. The restore mark is deleted after the successful restore.
+// VictoriaMetrics must be stopped during the restore.
+func runRestore() error {
+	rm, err := readRestoreMark()
+	if err != nil {
+		return err
+	}
+	if rm == nil {
+		logger.Infof("restore mark doesn't exist at -storageDataPath=%q; nothing to restore", *storageDataPath)
+		return nil
+	}
+	src, err := getRestoreSrc(rm)
+	if err != nil {
+		return err
+	}
+	srcFS, err := actions.NewRemoteFS(src)
+	if err != nil {
+		return fmt.Errorf("cannot initialize remote fs for %q: %w", src, err)
+	}
+	defer srcFS.MustStop()
+	dstFS := &fslocal.FS{
+		Dir:               *storageDataPath,
+		MaxBytesPerSecond: maxBytesPerSecond.IntN(),
+	}
+	if err := dstFS.Init(); err != nil {
+		return fmt.Errorf("cannot initialize local fs: %w", err)
+	}
+	defer dstFS.MustStop()
+
+	logger.Infof("restoring -storageDataPath=%q from backup %q", *storageDataPath, src)
+	a := &actions.Restore{
+		Concurrency: *concurrency,
+		Src:         srcFS,
+		Dst:         dstFS,
+	}
+	if err := a.Run(); err != nil {
+		return fmt.Errorf("cannot restore from backup %q: %w", src, err)
+	}
+	return deleteRestoreMark()
+}
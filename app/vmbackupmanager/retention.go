@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/actions"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/backupnames"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/common"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+)
+
+// backupInfo contains information about a backup at -dst.
+type backupInfo struct {
+	Name      string `json:"name"`
+	SizeBytes uint64 `json:"size_bytes"`
+	CreatedAt string `json:"created_at,omitempty"`
+	Locked    bool   `json:"locked"`
+}
+
+// applyRetentionPolicy deletes backups exceeding -keepLast* limits.
+func applyRetentionPolicy() error {
+	for _, bt := range backupTypes {
+		keepLast := *bt.keepLast
+		if keepLast < 0 {
+			continue
+		}
+		bis, err := listBackups(bt.name)
+		if err != nil {
+			return fmt.Errorf("cannot list %s backups: %w", bt.name, err)
+		}
+		names := getBackupsToDelete(bis, keepLast)
+		if len(names) == 0 {
+			continue
+		}
+		logger.Infof("%s backups to delete %s", bt.name, names)
+		for _, name := range names {
+			if err := deleteBackup(name); err != nil {
+				return fmt.Errorf("cannot delete backup %q: %w", name, err)
+			}
+			retentionDeleted.Inc()
+		}
+	}
+	return nil
+}
+
+// getBackupsToDelete returns the names of bis backups, which must be deleted in order to keep only keepLast backups.
+//
+// Locked backups are never deleted and they aren't counted in keepLast.
+func getBackupsToDelete(bis []backupInfo, keepLast int) []string {
+	var names []string
+	for _, bi := range bis {
+		if !bi.Locked {
+			names = append(names, bi.Name)
+		}
+	}
+	if len(names) <= keepLast {
+		return nil
+	}
+	// Backup names are sorted in the chronological order, so the most recent backups are at the start after the reverse sort.
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names[keepLast:]
+}
+
+// listBackups returns backups stored in the given folder at -dst.
+//
+// The returned backups are sorted by name.
+func listBackups(folder string) ([]backupInfo, error) {
+	fs, err := newBackupFS(folder)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := fs.ListParts()
+	fs.MustStop()
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]uint64)
+	for _, p := range parts {
+		n := strings.IndexByte(p.Path, '/')
+		if n <= 0 {
+			continue
+		}
+		name := folder + "/" + p.Path[:n]
+		sizes[name] += p.Size
+	}
+	bis := make([]backupInfo, 0, len(sizes))
+	for name, size := range sizes {
+		bi, err := getBackupInfo(name, size)
+		if err != nil {
+			return nil, err
+		}
+		bis = append(bis, *bi)
+	}
+	sort.Slice(bis, func(i, j int) bool {
+		return bis[i].Name < bis[j].Name
+	})
+	return bis, nil
+}
+
+// listAllBackups returns all the backups at -dst sorted by name.
+func listAllBackups() ([]backupInfo, error) {
+	bi, err := getBackupInfoByName(latestBackupName)
+	if err != nil {
+		return nil, err
+	}
+	var bis []backupInfo
+	if bi != nil {
+		bis = append(bis, *bi)
+	}
+	for _, bt := range backupTypes {
+		a, err := listBackups(bt.name)
+		if err != nil {
+			return nil, err
+		}
+		bis = append(bis, a...)
+	}
+	sort.Slice(bis, func(i, j int) bool {
+		return bis[i].Name < bis[j].Name
+	})
+	return bis, nil
+}
+
+// getBackupInfoByName returns information about the backup with the given name.
+//
+// nil is returned if the backup doesn't exist.
+func getBackupInfoByName(name string) (*backupInfo, error) {
+	fs, err := newBackupFS(name)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := fs.ListParts()
+	fs.MustStop()
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	size := uint64(0)
+	for _, p := range parts {
+		size += p.Size
+	}
+	return getBackupInfo(name, size)
+}
+
+func getBackupInfo(name string, size uint64) (*backupInfo, error) {
+	fs, err := newBackupFS(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.MustStop()
+	bi := &backupInfo{
+		Name:      name,
+		SizeBytes: size,
+	}
+	locked, err := fs.HasFile(backupnames.ProtectMarkFileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot check whether backup %q is locked: %w", name, err)
+	}
+	bi.Locked = locked
+	ok, err := fs.HasFile(backupnames.BackupMetadataFilename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot check metadata for backup %q: %w", name, err)
+	}
+	if ok {
+		data, err := fs.ReadFile(backupnames.BackupMetadataFilename)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read metadata for backup %q: %w", name, err)
+		}
+		var md actions.BackupMetadata
+		if err := json.Unmarshal(data, &md); err != nil {
+			return nil, fmt.Errorf("cannot parse metadata for backup %q: %w", name, err)
+		}
+		bi.CreatedAt = md.CreatedAt
+	}
+	return bi, nil
+}
+
+// deleteBackup deletes the backup with the given name from -dst.
+func deleteBackup(name string) error {
+	fs, err := newBackupFS(name)
+	if err != nil {
+		return err
+	}
+	defer fs.MustStop()
+
+	// Delete `backup complete` file at first, so the backup isn't used for restore if the deletion is interrupted.
+	if err := fs.DeleteFile(backupnames.BackupCompleteFilename); err != nil {
+		return fmt.Errorf("cannot delete `backup complete` file: %w", err)
+	}
+	parts, err := fs.ListParts()
+	if err != nil {
+		return fmt.Errorf("cannot list parts: %w", err)
+	}
+	if err := deleteParts(fs, parts); err != nil {
+		return err
+	}
+	if err := fs.DeleteFile(backupnames.BackupMetadataFilename); err != nil {
+		return fmt.Errorf("cannot delete metadata file: %w", err)
+	}
+	if err := fs.RemoveEmptyDirs(); err != nil {
+		return fmt.Errorf("cannot remove empty directories: %w", err)
+	}
+	return nil
+}
+
+func deleteParts(fs common.RemoteFS, parts []common.Part) error {
+	n := *concurrency
+	if n <= 0 {
+		n = 1
+	}
+	workCh := make(chan common.Part, len(parts))
+	for _, p := range parts {
+		workCh <- p
+	}
+	close(workCh)
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range workCh {
+				if err := fs.DeletePart(p); err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("cannot delete %s: %w", &p, err)
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
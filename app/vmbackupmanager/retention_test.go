@@ -0,0 +1,91 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackupName(t *testing.T) {
+	f := func(typeName string, ts time.Time, nameExpected string) {
+		t.Helper()
+		for _, bt := range backupTypes {
+			if bt.name != typeName {
+				continue
+			}
+			name := bt.backupName(ts)
+			if name != nameExpected {
+				t.Fatalf("unexpected %s backup name; got %q; want %q", typeName, name, nameExpected)
+			}
+			return
+		}
+		t.Fatalf("unknown backup type %q", typeName)
+	}
+	ts := time.Date(2023, 4, 7, 11, 15, 0, 0, time.UTC)
+	f("hourly", ts, "hourly/2023-04-07:11")
+	f("daily", ts, "daily/2023-04-07")
+	f("weekly", ts, "weekly/2023-14")
+	f("monthly", ts, "monthly/2023-04")
+
+	// ISO week belongs to the previous year
+	f("weekly", time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), "weekly/2020-53")
+}
+
+func TestGetBackupsToDelete(t *testing.T) {
+	f := func(bis []backupInfo, keepLast int, namesExpected []string) {
+		t.Helper()
+		names := getBackupsToDelete(bis, keepLast)
+		if !reflect.DeepEqual(names, namesExpected) {
+			t.Fatalf("unexpected backups to delete; got %q; want %q", names, namesExpected)
+		}
+	}
+	bis := []backupInfo{
+		{Name: "daily/2021-02-11"},
+		{Name: "daily/2021-02-13"},
+		{Name: "daily/2021-02-10", Locked: true},
+		{Name: "daily/2021-02-12"},
+		{Name: "daily/2021-02-09"},
+	}
+
+	f(nil, 3, nil)
+	f(bis, 10, nil)
+	f(bis, 4, nil)
+	f(bis, 3, []string{"daily/2021-02-09"})
+	f(bis, 1, []string{"daily/2021-02-12", "daily/2021-02-11", "daily/2021-02-09"})
+	f(bis, 0, []string{"daily/2021-02-13", "daily/2021-02-12", "daily/2021-02-11", "daily/2021-02-09"})
+}
+
+func TestValidateBackupName(t *testing.T) {
+	f := func(name string, resultExpected bool) {
+		t.Helper()
+		err := validateBackupName(name)
+		if result := err == nil; result != resultExpected {
+			t.Fatalf("unexpected result for validateBackupName(%q); got %v; want %v; err: %v", name, result, resultExpected, err)
+		}
+	}
+	f("latest", true)
+	f("daily/2023-04-07", true)
+	f("hourly/2023-04-07:11", true)
+	f("weekly/2023-14", true)
+	f("monthly/2023-04", true)
+
+	f("", false)
+	f("foo", false)
+	f("yearly/2023", false)
+	f("daily/", false)
+	f("daily/../latest", false)
+	f("daily/2023-04-07/data", false)
+}
+
+func TestTimeUntilNextHour(t *testing.T) {
+	f := func(ts time.Time, dExpected time.Duration) {
+		t.Helper()
+		d := timeUntilNextHour(ts)
+		if d != dExpected {
+			t.Fatalf("unexpected duration for %s; got %s; want %s", ts, d, dExpected)
+		}
+	}
+	f(time.Date(2023, 4, 7, 11, 0, 0, 0, time.UTC), time.Hour)
+	f(time.Date(2023, 4, 7, 11, 15, 0, 0, time.UTC), 45*time.Minute)
+	f(time.Date(2023, 4, 7, 23, 59, 30, 0, time.UTC), 30*time.Second)
+}
@@ -38,6 +38,24 @@ func New() *Backoff {
 	}
 }
 
+// NewWithParams initializes backoff object with the given retries, factor and minDuration
+func NewWithParams(retries int, factor float64, minDuration time.Duration) (*Backoff, error) {
+	if retries <= 0 {
+		return nil, fmt.Errorf("retries must be greater than 0; got %d", retries)
+	}
+	if factor <= 1 {
+		return nil, fmt.Errorf("factor must be greater than 1; got %v", factor)
+	}
+	if minDuration <= 0 {
+		return nil, fmt.Errorf("minDuration must be greater than 0; got %s", minDuration)
+	}
+	return &Backoff{
+		retries:     retries,
+		factor:      factor,
+		minDuration: minDuration,
+	}, nil
+}
+
 // Retry process retries until all attempts are completed
 func (b *Backoff) Retry(ctx context.Context, cb retryableFunc) (uint64, error) {
 	var attempt uint64
@@ -107,3 +107,20 @@ func TestRetry_Do(t *testing.T) {
 		})
 	}
 }
+
+func TestNewWithParams(t *testing.T) {
+	f := func(retries int, factor float64, minDuration time.Duration, wantErr bool) {
+		t.Helper()
+		_, err := NewWithParams(retries, factor, minDuration)
+		if (err != nil) != wantErr {
+			t.Fatalf("unexpected error for NewWithParams(%d, %v, %s): %v; wantErr: %v", retries, factor, minDuration, err, wantErr)
+		}
+	}
+	f(10, 1.8, time.Second, false)
+	f(1, 1.1, time.Millisecond, false)
+	f(0, 1.8, time.Second, true)
+	f(-1, 1.8, time.Second, true)
+	f(10, 1, time.Second, true)
+	f(10, 0.5, time.Second, true)
+	f(10, 1.8, 0, true)
+}
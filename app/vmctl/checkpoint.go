@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmctl/native"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+)
+
+// checkpoint persists the list of already migrated requests to a local file,
+// so interrupted migration could be resumed from the point where it stopped.
+//
+// The file contains a JSON line per every successfully migrated request.
+// All the methods are safe to call on nil checkpoint.
+type checkpoint struct {
+	path string
+
+	mu   sync.Mutex
+	f    *os.File
+	done map[checkpointEntry]struct{}
+}
+
+// checkpointEntry identifies a single migration request
+type checkpointEntry struct {
+	Src       string `json:"src"`
+	Dst       string `json:"dst"`
+	Match     string `json:"match"`
+	TimeStart string `json:"start"`
+	TimeEnd   string `json:"end"`
+}
+
+func newCheckpointEntry(srcURL, dstURL string, f native.Filter) checkpointEntry {
+	return checkpointEntry{
+		Src:       srcURL,
+		Dst:       dstURL,
+		Match:     f.Match,
+		TimeStart: f.TimeStart,
+		TimeEnd:   f.TimeEnd,
+	}
+}
+
+// openCheckpoint opens checkpoint file at path and loads already migrated requests from it.
+//
+// The file is created if it doesn't exist.
+func openCheckpoint(path string) (*checkpoint, error) {
+	done := make(map[checkpointEntry]struct{})
+	var tail []byte
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("cannot read checkpoint file: %w", err)
+	}
+	if len(data) > 0 {
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		sc.Buffer(nil, 1024*1024)
+		line := 0
+		for sc.Scan() {
+			line++
+			var e checkpointEntry
+			if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+				// The last line may be incomplete if vmctl was killed while writing it
+				logger.Warnf("skipping invalid line #%d at checkpoint file %q: %s", line, path, err)
+				continue
+			}
+			done[e] = struct{}{}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("cannot parse checkpoint file %q: %w", path, err)
+		}
+		if data[len(data)-1] != '\n' {
+			// Terminate the incomplete line, so new entries are written from the new line
+			tail = []byte("\n")
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open checkpoint file: %w", err)
+	}
+	if len(tail) > 0 {
+		if _, err := f.Write(tail); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("cannot write to checkpoint file %q: %w", path, err)
+		}
+	}
+	return &checkpoint{
+		path: path,
+		f:    f,
+		done: done,
+	}, nil
+}
+
+// len returns the number of already migrated requests
+func (cp *checkpoint) len() int {
+	if cp == nil {
+		return 0
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return len(cp.done)
+}
+
+// isDone returns true if the given request was already migrated
+func (cp *checkpoint) isDone(e checkpointEntry) bool {
+	if cp == nil {
+		return false
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	_, ok := cp.done[e]
+	return ok
+}
+
+// markDone persists the given request as migrated
+func (cp *checkpoint) markDone(e checkpointEntry) error {
+	if cp == nil {
+		return nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("cannot marshal checkpoint entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if _, err := cp.f.Write(data); err != nil {
+		return fmt.Errorf("cannot write to checkpoint file %q: %w", cp.path, err)
+	}
+	if err := cp.f.Sync(); err != nil {
+		return fmt.Errorf("cannot sync checkpoint file %q: %w", cp.path, err)
+	}
+	cp.done[e] = struct{}{}
+	return nil
+}
+
+// close closes the underlying checkpoint file
+func (cp *checkpoint) close() error {
+	if cp == nil {
+		return nil
+	}
+	return cp.f.Close()
+}
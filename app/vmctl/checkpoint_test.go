@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmctl/native"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	e1 := newCheckpointEntry("http://src/api/v1/export/native", "http://dst/api/v1/import/native", native.Filter{
+		Match:     `{__name__="foo"}`,
+		TimeStart: "2023-01-01T00:00:00Z",
+		TimeEnd:   "2023-01-02T00:00:00Z",
+	})
+	e2 := e1
+	e2.TimeStart, e2.TimeEnd = "2023-01-02T00:00:00Z", "2023-01-03T00:00:00Z"
+
+	cp, err := openCheckpoint(path)
+	if err != nil {
+		t.Fatalf("cannot open checkpoint: %s", err)
+	}
+	if n := cp.len(); n != 0 {
+		t.Fatalf("unexpected number of entries in new checkpoint; got %d; want 0", n)
+	}
+	if err := cp.markDone(e1); err != nil {
+		t.Fatalf("cannot mark entry as done: %s", err)
+	}
+	if !cp.isDone(e1) {
+		t.Fatalf("expecting entry to be done")
+	}
+	if cp.isDone(e2) {
+		t.Fatalf("unexpected done entry")
+	}
+	if err := cp.close(); err != nil {
+		t.Fatalf("cannot close checkpoint: %s", err)
+	}
+
+	// simulate incomplete line written before vmctl was killed
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatalf("cannot open checkpoint file: %s", err)
+	}
+	if _, err := f.WriteString(`{"src":"http://src`); err != nil {
+		t.Fatalf("cannot write to checkpoint file: %s", err)
+	}
+	_ = f.Close()
+
+	cp, err = openCheckpoint(path)
+	if err != nil {
+		t.Fatalf("cannot reopen checkpoint: %s", err)
+	}
+	if n := cp.len(); n != 1 {
+		t.Fatalf("unexpected number of entries in reopened checkpoint; got %d; want 1", n)
+	}
+	if !cp.isDone(e1) || cp.isDone(e2) {
+		t.Fatalf("unexpected entries in reopened checkpoint")
+	}
+	if err := cp.markDone(e2); err != nil {
+		t.Fatalf("cannot mark entry as done: %s", err)
+	}
+	_ = cp.close()
+
+	cp, err = openCheckpoint(path)
+	if err != nil {
+		t.Fatalf("cannot reopen checkpoint: %s", err)
+	}
+	defer func() { _ = cp.close() }()
+	if !cp.isDone(e1) || !cp.isDone(e2) {
+		t.Fatalf("expecting both entries to be done after reopening")
+	}
+}
+
+func TestCheckpointNil(t *testing.T) {
+	var cp *checkpoint
+	e := checkpointEntry{Match: "foo"}
+	if cp.isDone(e) {
+		t.Fatalf("nil checkpoint mustn't contain entries")
+	}
+	if err := cp.markDone(e); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := cp.close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
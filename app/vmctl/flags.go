@@ -44,6 +44,8 @@ const (
 	// also used in vm-native
 	vmExtraLabel = "vm-extra-label"
 	vmRateLimit  = "vm-rate-limit"
+	// also used in vm-native
+	vmRequestRateLimit = "vm-request-rate-limit"
 
 	vmInterCluster = "vm-intercluster"
 )
@@ -112,9 +114,14 @@ var (
 		},
 		&cli.Int64Flag{
 			Name: vmRateLimit,
-			Usage: "Optional data transfer rate limit in bytes per second.\n" +
+			Usage: "Optional data transfer rate limit in bytes per second. The limit is shared between all the --vm-concurrency workers.\n" +
 				"By default, the rate limit is disabled. It can be useful for limiting load on configured via '--vmAddr' destination.",
 		},
+		&cli.Int64Flag{
+			Name: vmRequestRateLimit,
+			Usage: "Optional limit on the number of import requests per second. The limit is shared between all the --vm-concurrency workers.\n" +
+				"By default, the limit is disabled. It can be useful for limiting load on configured via '--vmAddr' destination.",
+		},
 		&cli.BoolFlag{
 			Name:  vmDisableProgressBar,
 			Usage: "Whether to disable progress bar per each worker during the import.",
@@ -331,20 +338,35 @@ var (
 )
 
 const (
-	promSnapshot         = "prom-snapshot"
-	promConcurrency      = "prom-concurrency"
-	promFilterTimeStart  = "prom-filter-time-start"
-	promFilterTimeEnd    = "prom-filter-time-end"
-	promFilterLabel      = "prom-filter-label"
-	promFilterLabelValue = "prom-filter-label-value"
+	promSnapshot          = "prom-snapshot"
+	promBucket            = "prom-bucket"
+	promAddExternalLabels = "prom-add-external-labels"
+	promConcurrency       = "prom-concurrency"
+	promFilterTimeStart   = "prom-filter-time-start"
+	promFilterTimeEnd     = "prom-filter-time-end"
+	promFilterLabel       = "prom-filter-label"
+	promFilterLabelValue  = "prom-filter-label-value"
 )
 
 var (
 	promFlags = []cli.Flag{
 		&cli.StringFlag{
-			Name:     promSnapshot,
-			Usage:    "Path to Prometheus snapshot. Pls see for details https://www.robustperception.io/taking-snapshots-of-prometheus-data",
-			Required: true,
+			Name:    promSnapshot,
+			Aliases: []string{"prom-block-dir"},
+			Usage: "Path to Prometheus snapshot or to local directory with TSDB blocks, e.g. Prometheus or Thanos data directory. " +
+				"Pls see for details https://www.robustperception.io/taking-snapshots-of-prometheus-data",
+		},
+		&cli.StringFlag{
+			Name: promBucket,
+			Usage: "Path to object storage with TSDB blocks uploaded by Thanos or other Prometheus-compatible systems. " +
+				"It is used instead of --prom-snapshot if set. Supported schemes: s3://bucket/path/to/blocks, fs:///path/to/blocks. " +
+				"Blocks marked for deletion and downsampled blocks are skipped. See also --bucket-* flags",
+		},
+		&cli.BoolFlag{
+			Name: promAddExternalLabels,
+			Usage: "Whether to add external labels from Thanos meta of blocks in --prom-bucket to the imported time series. " +
+				"Labels with `__` prefix are never added",
+			Value: true,
 		},
 		&cli.IntFlag{
 			Name:  promConcurrency,
@@ -371,6 +393,106 @@ var (
 	}
 )
 
+const (
+	mimirBucket            = "mimir-bucket"
+	mimirTenant            = "mimir-tenant"
+	mimirTenantAccountID   = "mimir-tenant-account-id"
+	mimirAddExternalLabels = "mimir-add-external-labels"
+	mimirConcurrency       = "mimir-concurrency"
+	mimirFilterTimeStart   = "mimir-filter-time-start"
+	mimirFilterTimeEnd     = "mimir-filter-time-end"
+	mimirFilterLabel       = "mimir-filter-label"
+	mimirFilterLabelValue  = "mimir-filter-label-value"
+)
+
+var (
+	mimirFlags = []cli.Flag{
+		&cli.StringFlag{
+			Name: mimirBucket,
+			Usage: "Path to Cortex or Mimir blocks storage with per-tenant directories. " +
+				"Supported schemes: s3://bucket/path/to/blocks, fs:///path/to/blocks. See also --bucket-* flags",
+			Required: true,
+		},
+		&cli.StringSliceFlag{
+			Name:  mimirTenant,
+			Usage: "Tenant to migrate. All the tenants found at --mimir-bucket are migrated if not set. The flag can be set multiple times",
+		},
+		&cli.StringSliceFlag{
+			Name: mimirTenantAccountID,
+			Usage: "Mapping of tenant to VictoriaMetrics cluster tenant in the form `tenant=accountID[:projectID]`, e.g. `team-a=1` or `team-b=2:3`. " +
+				"Tenants without mapping must be numeric, e.g. `42` or `42:1`, and are imported into the tenant with the same ID. The flag can be set multiple times",
+		},
+		&cli.BoolFlag{
+			Name: mimirAddExternalLabels,
+			Usage: "Whether to add external labels from blocks meta to the imported time series. " +
+				"Internal labels with `__` prefix such as `__org_id__` are never added",
+			Value: true,
+		},
+		&cli.IntFlag{
+			Name:  mimirConcurrency,
+			Usage: "Number of concurrently running block readers",
+			Value: 1,
+		},
+		&cli.StringFlag{
+			Name:  mimirFilterTimeStart,
+			Usage: "The time filter in RFC3339 format to select timeseries with timestamp equal or higher than provided value. E.g. '2020-01-01T20:07:00Z'",
+		},
+		&cli.StringFlag{
+			Name:  mimirFilterTimeEnd,
+			Usage: "The time filter in RFC3339 format to select timeseries with timestamp equal or lower than provided value. E.g. '2020-01-01T20:07:00Z'",
+		},
+		&cli.StringFlag{
+			Name:  mimirFilterLabel,
+			Usage: "Prometheus label name to filter timeseries by. E.g. '__name__' will filter timeseries by name.",
+		},
+		&cli.StringFlag{
+			Name:  mimirFilterLabelValue,
+			Usage: fmt.Sprintf("Prometheus regular expression to filter label from %q flag.", mimirFilterLabel),
+			Value: ".*",
+		},
+	}
+)
+
+const (
+	bucketCredsFilePath    = "bucket-creds-file-path"
+	bucketConfigFilePath   = "bucket-config-file-path"
+	bucketConfigProfile    = "bucket-config-profile"
+	bucketS3Endpoint       = "bucket-s3-endpoint"
+	bucketS3ForcePathStyle = "bucket-s3-force-path-style"
+	bucketDownloadDir      = "bucket-download-dir"
+)
+
+var (
+	bucketFlags = []cli.Flag{
+		&cli.StringFlag{
+			Name:  bucketCredsFilePath,
+			Usage: "Path to file with S3 credentials for accessing the bucket. Credentials are loaded from default locations if not set. See https://docs.aws.amazon.com/general/latest/gr/aws-security-credentials.html",
+		},
+		&cli.StringFlag{
+			Name:  bucketConfigFilePath,
+			Usage: "Path to file with S3 configs for accessing the bucket. Configs are loaded from default location if not set. See https://docs.aws.amazon.com/general/latest/gr/aws-security-credentials.html",
+		},
+		&cli.StringFlag{
+			Name:  bucketConfigProfile,
+			Usage: "Profile name for S3 configs. If not set, the value of the environment variable will be loaded (AWS_PROFILE or AWS_DEFAULT_PROFILE), or if both not set, DefaultSharedConfigProfile is used",
+		},
+		&cli.StringFlag{
+			Name:  bucketS3Endpoint,
+			Usage: "Custom S3 endpoint for use with S3-compatible storages (e.g. MinIO). S3 is used if not set",
+		},
+		&cli.BoolFlag{
+			Name:  bucketS3ForcePathStyle,
+			Usage: "Prefixing endpoint with bucket name when set false, true by default",
+			Value: true,
+		},
+		&cli.StringFlag{
+			Name: bucketDownloadDir,
+			Usage: "Path to local directory for temporary storing blocks downloaded from the bucket. " +
+				"Blocks are removed from this directory after the import. The system temporary directory is used if not set",
+		},
+	}
+)
+
 const (
 	vmNativeFilterMatch       = "vm-native-filter-match"
 	vmNativeFilterTimeStart   = "vm-native-filter-time-start"
@@ -381,6 +503,10 @@ const (
 	vmNativeDisableBinaryProtocol     = "vm-native-disable-binary-protocol"
 	vmNativeDisableHTTPKeepAlive      = "vm-native-disable-http-keep-alive"
 	vmNativeDisablePerMetricMigration = "vm-native-disable-per-metric-migration"
+	vmNativeBackoffRetries            = "vm-native-backoff-retries"
+	vmNativeBackoffFactor             = "vm-native-backoff-factor"
+	vmNativeBackoffMinDuration        = "vm-native-backoff-min-duration"
+	vmNativeCheckpointFile            = "vm-native-checkpoint-file"
 
 	vmNativeSrcAddr               = "vm-native-src-addr"
 	vmNativeSrcUser               = "vm-native-src-user"
@@ -493,9 +619,14 @@ var (
 		},
 		&cli.Int64Flag{
 			Name: vmRateLimit,
-			Usage: "Optional data transfer rate limit in bytes per second.\n" +
+			Usage: "Optional data transfer rate limit in bytes per second. The limit is shared between all the --vm-concurrency workers.\n" +
 				"By default, the rate limit is disabled. It can be useful for limiting load on source or destination databases.",
 		},
+		&cli.Int64Flag{
+			Name: vmRequestRateLimit,
+			Usage: "Optional limit on the number of export/import requests per second. The limit is shared between all the --vm-concurrency workers.\n" +
+				"By default, the limit is disabled. It can be useful for limiting load on source or destination databases.",
+		},
 		&cli.BoolFlag{
 			Name: vmInterCluster,
 			Usage: "Enables cluster-to-cluster migration mode with automatic tenants data migration.\n" +
@@ -512,6 +643,27 @@ var (
 			Usage: "Defines whether to disable per-metric migration and migrate all data via one connection. In this mode, vmctl makes less export/import requests, but can't provide a progress bar or retry failed requests.",
 			Value: false,
 		},
+		&cli.StringFlag{
+			Name: vmNativeCheckpointFile,
+			Usage: "Optional path to local file for persisting migration progress. Every successfully migrated request is recorded to the file, " +
+				"so an interrupted migration can be resumed by running vmctl with the same flags and the same checkpoint file. " +
+				"Already migrated requests are skipped in this case. Requires --vm-native-filter-time-end to be set",
+		},
+		&cli.IntFlag{
+			Name:  vmNativeBackoffRetries,
+			Usage: "How many export/import retries to perform before giving up",
+			Value: 10,
+		},
+		&cli.Float64Flag{
+			Name:  vmNativeBackoffFactor,
+			Usage: "Factor to multiply the base duration after each failed retry. Must be greater than 1.0",
+			Value: 1.8,
+		},
+		&cli.DurationFlag{
+			Name:  vmNativeBackoffMinDuration,
+			Usage: "Minimum duration to wait before the first retry. Each subsequent retry will be multiplied by the --vm-native-backoff-factor",
+			Value: time.Second * 2,
+		},
 		&cli.BoolFlag{
 			Name: vmNativeDisableBinaryProtocol,
 			Usage: "Whether to use https://docs.victoriametrics.com/#how-to-export-data-in-json-line-format" +
@@ -542,6 +694,7 @@ const (
 	remoteReadFilterTimeReverse  = "remote-read-filter-time-reverse"
 	remoteReadFilterLabel        = "remote-read-filter-label"
 	remoteReadFilterLabelValue   = "remote-read-filter-label-value"
+	remoteReadFilterMatch        = "remote-read-filter-match"
 	remoteReadStepInterval       = "remote-read-step-interval"
 	remoteReadSrcAddr            = "remote-read-src-addr"
 	remoteReadUser               = "remote-read-user"
@@ -584,6 +737,11 @@ var (
 			Usage: fmt.Sprintf("Prometheus regular expression to filter label from %q flag.", remoteReadFilterLabelValue),
 			Value: ".*",
 		},
+		&cli.StringFlag{
+			Name: remoteReadFilterMatch,
+			Usage: "Series selector to filter timeseries by, e.g. '{job=\"node\",instance=~\"host-.*\"}' or '{__name__=~\"foo|bar\" or job=\"baz\"}'. " +
+				fmt.Sprintf("Every 'or' filter is sent as a separate query in remote read request. Series matching multiple 'or' filters are migrated only once. Flags %q and %q are ignored if this flag is set.", remoteReadFilterLabel, remoteReadFilterLabelValue),
+		},
 		&cli.BoolFlag{
 			Name:  remoteRead,
 			Usage: "Use Prometheus remote read protocol",
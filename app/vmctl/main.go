@@ -162,6 +162,7 @@ func main() {
 						Headers:            c.String(remoteReadHeaders),
 						LabelName:          c.String(remoteReadFilterLabel),
 						LabelValue:         c.String(remoteReadFilterLabelValue),
+						Match:              c.String(remoteReadFilterMatch),
 						CertFile:           c.String(remoteReadCertFile),
 						KeyFile:            c.String(remoteReadKeyFile),
 						CAFile:             c.String(remoteReadCAFile),
@@ -199,10 +200,14 @@ func main() {
 			{
 				Name:  "prometheus",
 				Usage: "Migrate time series from Prometheus",
-				Flags: mergeFlags(globalFlags, promFlags, vmFlags),
+				Flags: mergeFlags(globalFlags, promFlags, bucketFlags, vmFlags),
 				Action: func(c *cli.Context) error {
 					fmt.Println("Prometheus import mode")
 
+					if c.String(promSnapshot) == "" && c.String(promBucket) == "" {
+						return fmt.Errorf("either %q or %q flag must be set", promSnapshot, promBucket)
+					}
+
 					vmCfg := initConfigVM(c)
 					importer, err = vm.NewImporter(ctx, vmCfg)
 					if err != nil {
@@ -210,7 +215,10 @@ func main() {
 					}
 
 					promCfg := prometheus.Config{
-						Snapshot: c.String(promSnapshot),
+						Snapshot:     c.String(promSnapshot),
+						Bucket:       c.String(promBucket),
+						BucketConfig: initBucketConfig(c),
+						DownloadDir:  c.String(bucketDownloadDir),
 						Filter: prometheus.Filter{
 							TimeMin:    c.String(promFilterTimeStart),
 							TimeMax:    c.String(promFilterTimeEnd),
@@ -223,13 +231,71 @@ func main() {
 						return fmt.Errorf("failed to create prometheus client: %s", err)
 					}
 					pp := prometheusProcessor{
-						cl: cl,
-						im: importer,
-						cc: c.Int(promConcurrency),
+						cl:                cl,
+						im:                importer,
+						cc:                c.Int(promConcurrency),
+						addExternalLabels: c.Bool(promAddExternalLabels),
 					}
 					return pp.run(c.Bool(globalSilent), c.Bool(globalVerbose))
 				},
 			},
+			{
+				Name:  "mimir",
+				Usage: "Migrate time series from Cortex or Mimir blocks storage",
+				Flags: mergeFlags(globalFlags, mimirFlags, bucketFlags, vmFlags),
+				Action: func(c *cli.Context) error {
+					fmt.Println("Mimir import mode")
+
+					accountIDs, err := parseTenantAccountIDs(c.StringSlice(mimirTenantAccountID))
+					if err != nil {
+						return err
+					}
+					bucketPath := c.String(mimirBucket)
+					bucketCfg := initBucketConfig(c)
+					tenants := c.StringSlice(mimirTenant)
+					if len(tenants) == 0 {
+						tenants, err = prometheus.ListTenants(bucketPath, bucketCfg)
+						if err != nil {
+							return err
+						}
+					}
+					if len(tenants) == 0 {
+						return fmt.Errorf("found no tenants at %q", bucketPath)
+					}
+					mp := mimirProcessor{
+						vmCfg:             initConfigVM(c),
+						cc:                c.Int(mimirConcurrency),
+						addExternalLabels: c.Bool(mimirAddExternalLabels),
+					}
+					for _, tenant := range tenants {
+						accountID, err := getTenantAccountID(tenant, accountIDs)
+						if err != nil {
+							return err
+						}
+						promCfg := prometheus.Config{
+							Bucket:       prometheus.TenantBucket(bucketPath, tenant),
+							BucketConfig: bucketCfg,
+							DownloadDir:  c.String(bucketDownloadDir),
+							Filter: prometheus.Filter{
+								TimeMin:    c.String(mimirFilterTimeStart),
+								TimeMax:    c.String(mimirFilterTimeEnd),
+								Label:      c.String(mimirFilterLabel),
+								LabelValue: c.String(mimirFilterLabelValue),
+							},
+						}
+						cl, err := prometheus.NewClient(promCfg)
+						if err != nil {
+							return fmt.Errorf("failed to create client for tenant %q: %s", tenant, err)
+						}
+						mp.tenants = append(mp.tenants, &tenantBlocks{
+							name:      tenant,
+							accountID: accountID,
+							cl:        cl,
+						})
+					}
+					return mp.run(ctx, c.Bool(globalSilent), c.Bool(globalVerbose))
+				},
+			},
 			{
 				Name:  "vm-native",
 				Usage: "Migrate time series between VictoriaMetrics installations via native binary format",
@@ -277,9 +343,32 @@ func main() {
 						},
 					}}
 
+					bf, err := backoff.NewWithParams(c.Int(vmNativeBackoffRetries), c.Float64(vmNativeBackoffFactor), c.Duration(vmNativeBackoffMinDuration))
+					if err != nil {
+						return fmt.Errorf("failed to create backoff object: %s", err)
+					}
+
+					var cp *checkpoint
+					if path := c.String(vmNativeCheckpointFile); path != "" {
+						if c.String(vmNativeFilterTimeEnd) == "" {
+							// The end of the last time range depends on the current time otherwise,
+							// so requests from the checkpoint file don't match requests of the next run.
+							return fmt.Errorf("flag %q must be set when %q is set", vmNativeFilterTimeEnd, vmNativeCheckpointFile)
+						}
+						cp, err = openCheckpoint(path)
+						if err != nil {
+							return err
+						}
+						defer func() { _ = cp.close() }()
+						if n := cp.len(); n > 0 {
+							log.Printf("Loaded %d already migrated requests from checkpoint file %q", n, path)
+						}
+					}
+
 					p := vmNativeProcessor{
-						rateLimit:    c.Int64(vmRateLimit),
-						interCluster: c.Bool(vmInterCluster),
+						rateLimit:        c.Int64(vmRateLimit),
+						requestRateLimit: c.Int64(vmRequestRateLimit),
+						interCluster:     c.Bool(vmInterCluster),
 						filter: native.Filter{
 							Match:       c.String(vmNativeFilterMatch),
 							TimeStart:   c.String(vmNativeFilterTimeStart),
@@ -299,11 +388,12 @@ func main() {
 							ExtraLabels: dstExtraLabels,
 							HTTPClient:  dstHTTPClient,
 						},
-						backoff:                  backoff.New(),
+						backoff:                  bf,
 						cc:                       c.Int(vmConcurrency),
 						disablePerMetricRequests: c.Bool(vmNativeDisablePerMetricMigration),
 						isSilent:                 c.Bool(globalSilent),
 						isNative:                 !c.Bool(vmNativeDisableBinaryProtocol),
+						cp:                       cp,
 					}
 					return p.run(ctx)
 				},
@@ -375,6 +465,17 @@ func initConfigVM(c *cli.Context) vm.Config {
 		RoundDigits:        c.Int(vmRoundDigits),
 		ExtraLabels:        c.StringSlice(vmExtraLabel),
 		RateLimit:          c.Int64(vmRateLimit),
+		RequestRateLimit:   c.Int64(vmRequestRateLimit),
 		DisableProgressBar: c.Bool(vmDisableProgressBar),
 	}
 }
+
+func initBucketConfig(c *cli.Context) prometheus.BucketConfig {
+	return prometheus.BucketConfig{
+		CredsFilePath:    c.String(bucketCredsFilePath),
+		ConfigFilePath:   c.String(bucketConfigFilePath),
+		ConfigProfile:    c.String(bucketConfigProfile),
+		S3Endpoint:       c.String(bucketS3Endpoint),
+		S3ForcePathStyle: c.Bool(bucketS3ForcePathStyle),
+	}
+}
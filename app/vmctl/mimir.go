@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmctl/barpool"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmctl/prometheus"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmctl/vm"
+)
+
+// tenantBlocks contains blocks of a single Cortex or Mimir tenant
+type tenantBlocks struct {
+	name      string
+	accountID string
+	cl        *prometheus.Client
+	blocks    []*prometheus.Block
+}
+
+type mimirProcessor struct {
+	// tenants to migrate with already
+	// initialized prometheus clients
+	tenants []*tenantBlocks
+	// vmCfg is used for creating importer
+	// per every tenant
+	vmCfg vm.Config
+	// cc stands for concurrency
+	// and defines number of concurrently
+	// running block readers per tenant
+	cc int
+	// addExternalLabels defines whether to add
+	// external labels from blocks meta
+	// to the imported series
+	addExternalLabels bool
+}
+
+func (mp *mimirProcessor) run(ctx context.Context, silent, verbose bool) error {
+	var tenants []*tenantBlocks
+	blocksTotal := 0
+	for _, t := range mp.tenants {
+		fmt.Printf("Tenant %q (accountID %q):\n", t.name, t.accountID)
+		blocks, err := t.cl.Explore()
+		if err != nil {
+			return fmt.Errorf("explore failed for tenant %q: %s", t.name, err)
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		t.blocks = blocks
+		blocksTotal += len(blocks)
+		tenants = append(tenants, t)
+	}
+	if blocksTotal < 1 {
+		return fmt.Errorf("found no blocks to import")
+	}
+	question := fmt.Sprintf("Found %d blocks for %d tenants to import. Continue?", blocksTotal, len(tenants))
+	if !silent && !prompt(question) {
+		return nil
+	}
+
+	bar := barpool.AddWithTemplate(fmt.Sprintf(barTpl, "Processing blocks"), blocksTotal)
+	if err := barpool.Start(); err != nil {
+		return err
+	}
+	defer barpool.Stop()
+
+	for _, t := range tenants {
+		vmCfg := mp.vmCfg
+		vmCfg.AccountID = t.accountID
+		// do not add progress bars per every tenant to the already started pool
+		vmCfg.DisableProgressBar = true
+		im, err := vm.NewImporter(ctx, vmCfg)
+		if err != nil {
+			return fmt.Errorf("failed to create VM importer for tenant %q: %s", t.name, err)
+		}
+		pp := &prometheusProcessor{
+			cl:                t.cl,
+			im:                im,
+			cc:                mp.cc,
+			addExternalLabels: mp.addExternalLabels,
+		}
+		if err := pp.importBlocks(t.blocks, bar, verbose); err != nil {
+			return fmt.Errorf("tenant %q: %s", t.name, err)
+		}
+		log.Printf("Import finished for tenant %q!", t.name)
+		log.Print(im.Stats())
+	}
+	return nil
+}
+
+// accountIDRegexp matches VictoriaMetrics cluster tenant in the form `accountID[:projectID]`
+var accountIDRegexp = regexp.MustCompile(`^[0-9]+(:[0-9]+)?$`)
+
+// parseTenantAccountIDs parses `tenant=accountID[:projectID]` items into a map.
+func parseTenantAccountIDs(items []string) (map[string]string, error) {
+	m := make(map[string]string, len(items))
+	for _, item := range items {
+		n := strings.LastIndex(item, "=")
+		if n <= 0 {
+			return nil, fmt.Errorf("bad format for %s flag, it must be `tenant=accountID[:projectID]`; got %q", mimirTenantAccountID, item)
+		}
+		tenant, accountID := item[:n], item[n+1:]
+		if !accountIDRegexp.MatchString(accountID) {
+			return nil, fmt.Errorf("bad accountID %q for tenant %q; it must be in the form `accountID[:projectID]`", accountID, tenant)
+		}
+		if _, ok := m[tenant]; ok {
+			return nil, fmt.Errorf("duplicate mapping for tenant %q in %s flag", tenant, mimirTenantAccountID)
+		}
+		m[tenant] = accountID
+	}
+	return m, nil
+}
+
+// getTenantAccountID returns VictoriaMetrics accountID for the given tenant.
+//
+// Tenants without explicit mapping in m must be numeric.
+func getTenantAccountID(tenant string, m map[string]string) (string, error) {
+	if accountID, ok := m[tenant]; ok {
+		return accountID, nil
+	}
+	if accountIDRegexp.MatchString(tenant) {
+		return tenant, nil
+	}
+	return "", fmt.Errorf("missing accountID for non-numeric tenant %q; set it via %s flag", tenant, mimirTenantAccountID)
+}
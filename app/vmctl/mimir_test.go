@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTenantAccountIDs(t *testing.T) {
+	f := func(items []string, expected map[string]string) {
+		t.Helper()
+		m, err := parseTenantAccountIDs(items)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(m, expected) {
+			t.Fatalf("unexpected result; got %v; want %v", m, expected)
+		}
+	}
+	f(nil, map[string]string{})
+	f([]string{"team-a=1", "team-b=2:3", "a=b=4"}, map[string]string{
+		"team-a": "1",
+		"team-b": "2:3",
+		"a=b":    "4",
+	})
+}
+
+func TestParseTenantAccountIDsFailure(t *testing.T) {
+	f := func(items []string) {
+		t.Helper()
+		if _, err := parseTenantAccountIDs(items); err == nil {
+			t.Fatalf("expecting non-nil error for %q", items)
+		}
+	}
+	f([]string{"team-a"})
+	f([]string{"=1"})
+	f([]string{"team-a="})
+	f([]string{"team-a=foo"})
+	f([]string{"team-a=1:"})
+	f([]string{"team-a=-1"})
+	f([]string{"team-a=1", "team-a=2"})
+}
+
+func TestGetTenantAccountID(t *testing.T) {
+	m := map[string]string{
+		"team-a": "1",
+		"42":     "2:3",
+	}
+	f := func(tenant, expected string) {
+		t.Helper()
+		accountID, err := getTenantAccountID(tenant, m)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if accountID != expected {
+			t.Fatalf("unexpected accountID for tenant %q; got %q; want %q", tenant, accountID, expected)
+		}
+	}
+	f("team-a", "1")
+	f("42", "2:3")
+	f("7", "7")
+	f("7:8", "7:8")
+
+	if _, err := getTenantAccountID("anonymous", m); err == nil {
+		t.Fatalf("expecting non-nil error for non-numeric tenant without mapping")
+	}
+}
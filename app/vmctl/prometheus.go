@@ -3,12 +3,15 @@ package main
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmctl/barpool"
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmctl/prometheus"
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmctl/vm"
-	"github.com/prometheus/prometheus/tsdb"
+	"github.com/cheggaaa/pb/v3"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb/chunkenc"
 )
 
@@ -24,6 +27,10 @@ type prometheusProcessor struct {
 	// and defines number of concurrently
 	// running snapshot block readers
 	cc int
+	// addExternalLabels defines whether to add
+	// external labels from Thanos blocks meta
+	// to the imported series
+	addExternalLabels bool
 }
 
 func (pp *prometheusProcessor) run(silent, verbose bool) error {
@@ -46,7 +53,19 @@ func (pp *prometheusProcessor) run(silent, verbose bool) error {
 	}
 	defer barpool.Stop()
 
-	blockReadersCh := make(chan tsdb.BlockReader)
+	if err := pp.importBlocks(blocks, bar, verbose); err != nil {
+		return err
+	}
+	log.Println("Import finished!")
+	log.Print(pp.im.Stats())
+	return nil
+}
+
+// importBlocks imports the given blocks via pp.im and closes pp.im when all the blocks are imported.
+//
+// bar is incremented per every imported block.
+func (pp *prometheusProcessor) importBlocks(blocks []*prometheus.Block, bar *pb.ProgressBar, verbose bool) error {
+	blockReadersCh := make(chan *prometheus.Block)
 	errCh := make(chan error, pp.cc)
 	pp.im.ResetStats()
 
@@ -57,7 +76,7 @@ func (pp *prometheusProcessor) run(silent, verbose bool) error {
 			defer wg.Done()
 			for br := range blockReadersCh {
 				if err := pp.do(br); err != nil {
-					errCh <- fmt.Errorf("read failed for block %q: %s", br.Meta().ULID, err)
+					errCh <- fmt.Errorf("read failed for block %q: %s", br.Meta.ULID, err)
 					return
 				}
 				bar.Increment()
@@ -91,65 +110,89 @@ func (pp *prometheusProcessor) run(silent, verbose bool) error {
 	for err := range errCh {
 		return fmt.Errorf("import process failed: %s", err)
 	}
-
-	log.Println("Import finished!")
-	log.Print(pp.im.Stats())
 	return nil
 }
 
-func (pp *prometheusProcessor) do(b tsdb.BlockReader) error {
-	ss, err := pp.cl.Read(b)
-	if err != nil {
-		return fmt.Errorf("failed to read block: %s", err)
+func (pp *prometheusProcessor) do(b *prometheus.Block) error {
+	var extraLabels []vm.LabelPair
+	if pp.addExternalLabels {
+		extraLabels = getExternalLabels(b.ExternalLabels)
 	}
-	var it chunkenc.Iterator
-	for ss.Next() {
-		var name string
-		var labels []vm.LabelPair
-		series := ss.At()
+	return pp.cl.Read(b, func(ss storage.SeriesSet) error {
+		var it chunkenc.Iterator
+		for ss.Next() {
+			var name string
+			var labels []vm.LabelPair
+			series := ss.At()
 
-		for _, label := range series.Labels() {
-			if label.Name == "__name__" {
-				name = label.Value
-				continue
+			for _, label := range series.Labels() {
+				if label.Name == "__name__" {
+					name = label.Value
+					continue
+				}
+				labels = append(labels, vm.LabelPair{
+					Name:  label.Name,
+					Value: label.Value,
+				})
+			}
+			if name == "" {
+				return fmt.Errorf("failed to find `__name__` label in labelset for block %v", b.Meta.ULID)
+			}
+			for _, lp := range extraLabels {
+				if !series.Labels().Has(lp.Name) {
+					labels = append(labels, lp)
+				}
 			}
-			labels = append(labels, vm.LabelPair{
-				Name:  label.Name,
-				Value: label.Value,
-			})
-		}
-		if name == "" {
-			return fmt.Errorf("failed to find `__name__` label in labelset for block %v", b.Meta().ULID)
-		}
 
-		var timestamps []int64
-		var values []float64
-		it = series.Iterator(it)
-		for {
-			typ := it.Next()
-			if typ == chunkenc.ValNone {
-				break
+			var timestamps []int64
+			var values []float64
+			it = series.Iterator(it)
+			for {
+				typ := it.Next()
+				if typ == chunkenc.ValNone {
+					break
+				}
+				if typ != chunkenc.ValFloat {
+					// Skip unsupported values
+					continue
+				}
+				t, v := it.At()
+				timestamps = append(timestamps, t)
+				values = append(values, v)
 			}
-			if typ != chunkenc.ValFloat {
-				// Skip unsupported values
-				continue
+			if err := it.Err(); err != nil {
+				return err
+			}
+			ts := vm.TimeSeries{
+				Name:       name,
+				LabelPairs: labels,
+				Timestamps: timestamps,
+				Values:     values,
+			}
+			if err := pp.im.Input(&ts); err != nil {
+				return err
 			}
-			t, v := it.At()
-			timestamps = append(timestamps, t)
-			values = append(values, v)
-		}
-		if err := it.Err(); err != nil {
-			return err
-		}
-		ts := vm.TimeSeries{
-			Name:       name,
-			LabelPairs: labels,
-			Timestamps: timestamps,
-			Values:     values,
 		}
-		if err := pp.im.Input(&ts); err != nil {
-			return err
+		return ss.Err()
+	})
+}
+
+// getExternalLabels returns sorted external labels from Thanos block meta.
+//
+// Internal labels with `__` prefix such as `__org_id__` set by Cortex and Mimir are skipped.
+func getExternalLabels(m map[string]string) []vm.LabelPair {
+	var lps []vm.LabelPair
+	for k, v := range m {
+		if strings.HasPrefix(k, "__") {
+			continue
 		}
+		lps = append(lps, vm.LabelPair{
+			Name:  k,
+			Value: v,
+		})
 	}
-	return ss.Err()
+	sort.Slice(lps, func(i, j int) bool {
+		return lps[i].Name < lps[j].Name
+	})
+	return lps
 }
@@ -0,0 +1,441 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/prometheus/prometheus/tsdb"
+)
+
+// BucketConfig contains params for accessing object storage with TSDB blocks
+type BucketConfig struct {
+	// Path to file with S3 credentials
+	CredsFilePath string
+	// Path to file with S3 configs
+	ConfigFilePath string
+	// The name of S3 config profile to use
+	ConfigProfile string
+	// Custom S3 endpoint for S3-compatible storages such as MinIO
+	S3Endpoint string
+	// Whether to use path style for S3 requests
+	S3ForcePathStyle bool
+}
+
+// bucket is an object storage with TSDB blocks.
+//
+// All the paths are relative to the bucket root and use `/` as directory delimiter.
+type bucket interface {
+	// String returns human-readable representation of the bucket.
+	String() string
+
+	// listDirs returns names of sub-directories at the given dir.
+	listDirs(dir string) ([]string, error)
+
+	// listFiles returns paths relative to dir for all the files under the given dir.
+	listFiles(dir string) ([]string, error)
+
+	// readFile returns the contents of the given file.
+	//
+	// errNotFound is returned if the file doesn't exist.
+	readFile(filePath string) ([]byte, error)
+
+	// download writes the contents of the given file to w.
+	download(filePath string, w io.Writer) error
+}
+
+var errNotFound = errors.New("not found")
+
+// newBucket returns bucket for the given path.
+//
+// Supported paths are `s3://bucket/path/to/dir` and `fs:///path/to/dir`.
+func newBucket(bucketPath string, cfg BucketConfig) (bucket, error) {
+	n := strings.Index(bucketPath, "://")
+	if n < 0 {
+		return nil, fmt.Errorf("missing scheme in bucket path %q. Supported schemes: `s3://`, `fs://`", bucketPath)
+	}
+	scheme := bucketPath[:n]
+	dir := bucketPath[n+len("://"):]
+	switch scheme {
+	case "fs":
+		if !filepath.IsAbs(dir) {
+			return nil, fmt.Errorf("dir must be absolute; got %q", dir)
+		}
+		return &fsBucket{
+			dir: filepath.Clean(dir),
+		}, nil
+	case "s3":
+		n := strings.Index(dir, "/")
+		if n < 0 {
+			// The whole bucket is used
+			return newS3Bucket(dir, "", cfg)
+		}
+		return newS3Bucket(dir[:n], dir[n+1:], cfg)
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q in bucket path %q. Supported schemes: `s3://`, `fs://`", scheme, bucketPath)
+	}
+}
+
+type fsBucket struct {
+	dir string
+}
+
+func (fb *fsBucket) String() string {
+	return fmt.Sprintf("fs://%s", fb.dir)
+}
+
+func (fb *fsBucket) path(p string) string {
+	return filepath.Join(fb.dir, filepath.FromSlash(p))
+}
+
+func (fb *fsBucket) listDirs(dir string) ([]string, error) {
+	des, err := os.ReadDir(fb.path(dir))
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, de := range des {
+		if de.IsDir() {
+			dirs = append(dirs, de.Name())
+		}
+	}
+	return dirs, nil
+}
+
+func (fb *fsBucket) listFiles(dir string) ([]string, error) {
+	root := fb.path(dir)
+	var files []string
+	err := filepath.WalkDir(root, func(p string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if de.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+func (fb *fsBucket) readFile(filePath string) ([]byte, error) {
+	data, err := os.ReadFile(fb.path(filePath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errNotFound
+	}
+	return data, err
+}
+
+func (fb *fsBucket) download(filePath string, w io.Writer) error {
+	f, err := os.Open(fb.path(filePath))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+type s3Bucket struct {
+	bucket string
+	dir    string
+	client *s3.Client
+}
+
+func newS3Bucket(bucketName, dir string, cfg BucketConfig) (*s3Bucket, error) {
+	dir = strings.Trim(dir, "/")
+	if dir != "" {
+		dir += "/"
+	}
+	awsCfg, err := config.LoadDefaultConfig(context.Background(), getS3ConfigOpts(cfg)...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load S3 config: %w", err)
+	}
+	var outerErr error
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.S3Endpoint != "" {
+			o.UsePathStyle = cfg.S3ForcePathStyle
+			o.EndpointResolver = s3.EndpointResolverFromURL(cfg.S3Endpoint)
+			return
+		}
+		region, err := manager.GetBucketRegion(context.Background(), s3.NewFromConfig(awsCfg), bucketName)
+		if err != nil {
+			outerErr = fmt.Errorf("cannot determine region for bucket %q: %w", bucketName, err)
+			return
+		}
+		o.Region = region
+	})
+	if outerErr != nil {
+		return nil, outerErr
+	}
+	return &s3Bucket{
+		bucket: bucketName,
+		dir:    dir,
+		client: client,
+	}, nil
+}
+
+// getS3ConfigOpts returns options for loading S3 config according to cfg.
+//
+// CredsFilePath and ConfigFilePath are applied independently of each other,
+// so the default location is used only for the file, which isn't set.
+func getS3ConfigOpts(cfg BucketConfig) []func(*config.LoadOptions) error {
+	configOpts := []func(*config.LoadOptions) error{
+		config.WithSharedConfigProfile(cfg.ConfigProfile),
+		config.WithDefaultRegion("us-east-1"),
+	}
+	if cfg.ConfigFilePath != "" {
+		configOpts = append(configOpts, config.WithSharedConfigFiles([]string{cfg.ConfigFilePath}))
+	}
+	if cfg.CredsFilePath != "" {
+		configOpts = append(configOpts, config.WithSharedCredentialsFiles([]string{cfg.CredsFilePath}))
+	}
+	return configOpts
+}
+
+func (sb *s3Bucket) String() string {
+	return fmt.Sprintf("s3://%s/%s", sb.bucket, sb.dir)
+}
+
+func (sb *s3Bucket) key(p string) string {
+	return sb.dir + strings.TrimPrefix(p, "/")
+}
+
+func (sb *s3Bucket) prefix(dir string) string {
+	prefix := sb.key(dir)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+func (sb *s3Bucket) listDirs(dir string) ([]string, error) {
+	prefix := sb.prefix(dir)
+	input := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(sb.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	}
+	var dirs []string
+	paginator := s3.NewListObjectsV2Paginator(sb.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("cannot list %q at %s: %w", dir, sb, err)
+		}
+		for _, cp := range page.CommonPrefixes {
+			name := strings.TrimSuffix(strings.TrimPrefix(*cp.Prefix, prefix), "/")
+			if name != "" {
+				dirs = append(dirs, name)
+			}
+		}
+	}
+	return dirs, nil
+}
+
+func (sb *s3Bucket) listFiles(dir string) ([]string, error) {
+	prefix := sb.prefix(dir)
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(sb.bucket),
+		Prefix: aws.String(prefix),
+	}
+	var files []string
+	paginator := s3.NewListObjectsV2Paginator(sb.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("cannot list %q at %s: %w", dir, sb, err)
+		}
+		for _, o := range page.Contents {
+			name := strings.TrimPrefix(*o.Key, prefix)
+			if name != "" && !strings.HasSuffix(name, "/") {
+				files = append(files, name)
+			}
+		}
+	}
+	return files, nil
+}
+
+func (sb *s3Bucket) getObject(filePath string) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(sb.bucket),
+		Key:    aws.String(sb.key(filePath)),
+	}
+	o, err := sb.client.GetObject(context.Background(), input)
+	if err != nil {
+		var nsk *s3types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, errNotFound
+		}
+		return nil, fmt.Errorf("cannot open %q at %s: %w", filePath, sb, err)
+	}
+	return o.Body, nil
+}
+
+func (sb *s3Bucket) readFile(filePath string) ([]byte, error) {
+	r, err := sb.getObject(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	return io.ReadAll(r)
+}
+
+func (sb *s3Bucket) download(filePath string, w io.Writer) error {
+	r, err := sb.getObject(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("cannot download %q from %s: %w", filePath, sb, err)
+	}
+	return nil
+}
+
+// thanosMeta contains Thanos-specific fields from block meta.json file.
+//
+// See https://thanos.io/tip/thanos/storage.md/#metadata-file-metajson
+type thanosMeta struct {
+	Thanos struct {
+		Labels     map[string]string `json:"labels"`
+		Downsample struct {
+			Resolution int64 `json:"resolution"`
+		} `json:"downsample"`
+	} `json:"thanos"`
+}
+
+// exploreBucket returns blocks found at c.bkt root.
+//
+// Blocks marked for deletion and downsampled blocks are skipped,
+// since they cannot be imported. The number of such blocks is returned as unsupported.
+func (c *Client) exploreBucket() ([]*Block, int, error) {
+	dirs, err := c.bkt.listDirs("")
+	if err != nil {
+		return nil, 0, err
+	}
+	var blocks []*Block
+	unsupported := 0
+	for _, dir := range dirs {
+		data, err := c.bkt.readFile(joinPath(dir, "meta.json"))
+		if err != nil {
+			if errors.Is(err, errNotFound) {
+				// Skip directories without blocks such as `markers` or `debug` at Thanos and Mimir buckets
+				continue
+			}
+			return nil, 0, fmt.Errorf("cannot read meta.json for block %q: %s", dir, err)
+		}
+		var meta tsdb.BlockMeta
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, 0, fmt.Errorf("cannot parse meta.json for block %q: %s", dir, err)
+		}
+		var tm thanosMeta
+		if err := json.Unmarshal(data, &tm); err != nil {
+			return nil, 0, fmt.Errorf("cannot parse meta.json for block %q: %s", dir, err)
+		}
+		if tm.Thanos.Downsample.Resolution > 0 {
+			unsupported++
+			continue
+		}
+		if _, err := c.bkt.readFile(joinPath(dir, "deletion-mark.json")); err == nil {
+			unsupported++
+			continue
+		} else if !errors.Is(err, errNotFound) {
+			return nil, 0, fmt.Errorf("cannot check deletion mark for block %q: %s", dir, err)
+		}
+		blocks = append(blocks, &Block{
+			Meta:           meta,
+			ExternalLabels: tm.Thanos.Labels,
+			dir:            dir,
+		})
+	}
+	return blocks, unsupported, nil
+}
+
+// downloadBlock downloads the given block from c.bkt to c.downloadDir.
+//
+// It returns the path to the downloaded block. The caller must remove it when no longer needed.
+func (c *Client) downloadBlock(b *Block) (string, error) {
+	files, err := c.bkt.listFiles(b.dir)
+	if err != nil {
+		return "", err
+	}
+	dstDir, err := os.MkdirTemp(c.downloadDir, "vmctl-block-"+b.Meta.ULID.String()+"-")
+	if err != nil {
+		return "", fmt.Errorf("cannot create directory for block: %s", err)
+	}
+	for _, file := range files {
+		if err := c.downloadFile(joinPath(b.dir, file), filepath.Join(dstDir, filepath.FromSlash(file))); err != nil {
+			_ = os.RemoveAll(dstDir)
+			return "", err
+		}
+	}
+	return dstDir, nil
+}
+
+func (c *Client) downloadFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("cannot create directory for %q: %s", dst, err)
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("cannot create %q: %s", dst, err)
+	}
+	if err := c.bkt.download(src, f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("cannot download %q: %s", src, err)
+	}
+	return f.Close()
+}
+
+// joinPath joins the given bucket path parts with `/`.
+func joinPath(parts ...string) string {
+	return strings.TrimPrefix(path.Join(parts...), "/")
+}
+
+// ListTenants returns tenants stored at Cortex or Mimir blocks storage at bucketPath.
+//
+// Blocks for every tenant are stored at `<bucketPath>/<tenant>/`, so TenantBucket may be used
+// for building Config.Bucket for the particular tenant.
+// Internal directories with `__` prefix are skipped.
+func ListTenants(bucketPath string, cfg BucketConfig) ([]string, error) {
+	bkt, err := newBucket(bucketPath, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init bucket %q: %s", bucketPath, err)
+	}
+	dirs, err := bkt.listDirs("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants at %s: %s", bkt, err)
+	}
+	var tenants []string
+	for _, dir := range dirs {
+		if strings.HasPrefix(dir, "__") {
+			continue
+		}
+		tenants = append(tenants, dir)
+	}
+	sort.Strings(tenants)
+	return tenants, nil
+}
+
+// TenantBucket returns path to blocks of the given tenant at Cortex or Mimir blocks storage at bucketPath.
+func TenantBucket(bucketPath, tenant string) string {
+	return strings.TrimRight(bucketPath, "/") + "/" + tenant
+}
@@ -0,0 +1,208 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Log(_ ...interface{}) error { return nil }
+
+// writeTestBlock writes TSDB block with the given series names at dir and returns the block ULID.
+func writeTestBlock(t *testing.T, dir string, minTime int64, names ...string) string {
+	t.Helper()
+	w, err := tsdb.NewBlockWriter(nopLogger{}, dir, tsdb.DefaultBlockDuration)
+	if err != nil {
+		t.Fatalf("cannot create block writer: %s", err)
+	}
+	defer func() { _ = w.Close() }()
+	app := w.Appender(context.Background())
+	for _, name := range names {
+		for i := int64(0); i < 10; i++ {
+			if _, err := app.Append(0, labels.FromStrings("__name__", name, "job", "test"), minTime+i*1000, float64(i)); err != nil {
+				t.Fatalf("cannot append sample: %s", err)
+			}
+		}
+	}
+	if err := app.Commit(); err != nil {
+		t.Fatalf("cannot commit samples: %s", err)
+	}
+	id, err := w.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("cannot flush block: %s", err)
+	}
+	return id.String()
+}
+
+func setThanosMeta(t *testing.T, blockDir, thanosMeta string) {
+	t.Helper()
+	metaPath := filepath.Join(blockDir, "meta.json")
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		t.Fatalf("cannot read meta.json: %s", err)
+	}
+	data = append(data[:len(data)-2], fmt.Sprintf(`,"thanos":%s}`, thanosMeta)...)
+	if err := os.WriteFile(metaPath, data, 0644); err != nil {
+		t.Fatalf("cannot write meta.json: %s", err)
+	}
+}
+
+func TestClientBucket(t *testing.T) {
+	bucketDir := t.TempDir()
+	id1 := writeTestBlock(t, bucketDir, 1700000000000, "foo", "bar")
+	setThanosMeta(t, filepath.Join(bucketDir, id1), `{"labels":{"cluster":"c1","__org_id__":"tenant"},"downsample":{"resolution":0}}`)
+	id2 := writeTestBlock(t, bucketDir, 1700010000000, "baz")
+
+	// downsampled blocks must be skipped
+	id3 := writeTestBlock(t, bucketDir, 1700020000000, "downsampled")
+	setThanosMeta(t, filepath.Join(bucketDir, id3), `{"downsample":{"resolution":300000}}`)
+
+	// blocks marked for deletion must be skipped
+	id4 := writeTestBlock(t, bucketDir, 1700030000000, "deleted")
+	if err := os.WriteFile(filepath.Join(bucketDir, id4, "deletion-mark.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatalf("cannot write deletion mark: %s", err)
+	}
+
+	// directories without blocks must be skipped
+	if err := os.MkdirAll(filepath.Join(bucketDir, "markers"), 0755); err != nil {
+		t.Fatalf("cannot create markers dir: %s", err)
+	}
+
+	downloadDir := t.TempDir()
+	c, err := NewClient(Config{
+		Bucket:      "fs://" + bucketDir,
+		DownloadDir: downloadDir,
+		Filter: Filter{
+			Label:      "__name__",
+			LabelValue: "foo|baz|downsampled|deleted",
+		},
+	})
+	if err != nil {
+		t.Fatalf("cannot create client: %s", err)
+	}
+	blocks, err := c.Explore()
+	if err != nil {
+		t.Fatalf("cannot explore blocks: %s", err)
+	}
+	var ids []string
+	for _, b := range blocks {
+		ids = append(ids, b.Meta.ULID.String())
+	}
+	sort.Strings(ids)
+	idsExpected := []string{id1, id2}
+	sort.Strings(idsExpected)
+	if !reflect.DeepEqual(ids, idsExpected) {
+		t.Fatalf("unexpected blocks; got %q; want %q", ids, idsExpected)
+	}
+
+	var names []string
+	for _, b := range blocks {
+		if b.Meta.ULID.String() == id1 {
+			externalLabelsExpected := map[string]string{"cluster": "c1", "__org_id__": "tenant"}
+			if !reflect.DeepEqual(b.ExternalLabels, externalLabelsExpected) {
+				t.Fatalf("unexpected external labels; got %v; want %v", b.ExternalLabels, externalLabelsExpected)
+			}
+		}
+		err := c.Read(b, func(ss storage.SeriesSet) error {
+			for ss.Next() {
+				names = append(names, ss.At().Labels().Get("__name__"))
+			}
+			return ss.Err()
+		})
+		if err != nil {
+			t.Fatalf("cannot read block: %s", err)
+		}
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"baz", "foo"}) {
+		t.Fatalf("unexpected series names; got %q; want %q", names, []string{"baz", "foo"})
+	}
+
+	// downloaded blocks must be removed after reading
+	des, err := os.ReadDir(downloadDir)
+	if err != nil {
+		t.Fatalf("cannot read download dir: %s", err)
+	}
+	if len(des) != 0 {
+		t.Fatalf("expecting empty download dir; got %d entries", len(des))
+	}
+}
+
+func TestNewBucketFailure(t *testing.T) {
+	f := func(path string) {
+		t.Helper()
+		if _, err := newBucket(path, BucketConfig{}); err == nil {
+			t.Fatalf("expecting non-nil error for %q", path)
+		}
+	}
+	f("")
+	f("/foo/bar")
+	f("fs://relative/path")
+	f("gs://bucket/path")
+}
+
+func TestGetS3ConfigOpts(t *testing.T) {
+	f := func(cfg BucketConfig, configFilesExpected, credsFilesExpected []string) {
+		t.Helper()
+		var opts config.LoadOptions
+		for _, opt := range getS3ConfigOpts(cfg) {
+			if err := opt(&opts); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		}
+		if !reflect.DeepEqual(opts.SharedConfigFiles, configFilesExpected) {
+			t.Fatalf("unexpected config files; got %q; want %q", opts.SharedConfigFiles, configFilesExpected)
+		}
+		if !reflect.DeepEqual(opts.SharedCredentialsFiles, credsFilesExpected) {
+			t.Fatalf("unexpected credentials files; got %q; want %q", opts.SharedCredentialsFiles, credsFilesExpected)
+		}
+		if opts.SharedConfigProfile != cfg.ConfigProfile {
+			t.Fatalf("unexpected config profile; got %q; want %q", opts.SharedConfigProfile, cfg.ConfigProfile)
+		}
+	}
+	f(BucketConfig{}, nil, nil)
+	f(BucketConfig{ConfigFilePath: "/etc/s3/config"}, []string{"/etc/s3/config"}, nil)
+	f(BucketConfig{CredsFilePath: "/etc/s3/creds"}, nil, []string{"/etc/s3/creds"})
+	f(BucketConfig{
+		ConfigFilePath: "/etc/s3/config",
+		CredsFilePath:  "/etc/s3/creds",
+		ConfigProfile:  "foo",
+	}, []string{"/etc/s3/config"}, []string{"/etc/s3/creds"})
+}
+
+func TestListTenants(t *testing.T) {
+	bucketDir := t.TempDir()
+	for _, dir := range []string{"team-b", "42", "anonymous", "__mimir_cluster"} {
+		if err := os.MkdirAll(filepath.Join(bucketDir, dir), 0755); err != nil {
+			t.Fatalf("cannot create tenant dir: %s", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(bucketDir, "file"), []byte("foo"), 0644); err != nil {
+		t.Fatalf("cannot create file: %s", err)
+	}
+	bucketPath := "fs://" + bucketDir + "/"
+	tenants, err := ListTenants(bucketPath, BucketConfig{})
+	if err != nil {
+		t.Fatalf("cannot list tenants: %s", err)
+	}
+	tenantsExpected := []string{"42", "anonymous", "team-b"}
+	if !reflect.DeepEqual(tenants, tenantsExpected) {
+		t.Fatalf("unexpected tenants; got %q; want %q", tenants, tenantsExpected)
+	}
+
+	tenantBucket := TenantBucket(bucketPath, "42")
+	if tenantBucketExpected := "fs://" + bucketDir + "/42"; tenantBucket != tenantBucketExpected {
+		t.Fatalf("unexpected tenant bucket; got %q; want %q", tenantBucket, tenantBucketExpected)
+	}
+}
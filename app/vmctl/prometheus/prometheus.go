@@ -3,6 +3,7 @@ package prometheus
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/prometheus/prometheus/model/labels"
@@ -16,6 +17,16 @@ type Config struct {
 	// Path to snapshot directory
 	Snapshot string
 
+	// Path to object storage with TSDB blocks,
+	// e.g. s3://bucket/path/to/blocks.
+	// It is used instead of Snapshot if set.
+	Bucket string
+	// BucketConfig contains params for accessing Bucket
+	BucketConfig BucketConfig
+	// Path to local directory for temporary storing blocks
+	// downloaded from Bucket. os.TempDir() is used if empty.
+	DownloadDir string
+
 	Filter Filter
 }
 
@@ -28,9 +39,14 @@ type Filter struct {
 	LabelValue string
 }
 
-// Client is a wrapper over Prometheus tsdb.DBReader
+// Client reads TSDB blocks from Prometheus snapshot
+// or from object storage bucket
 type Client struct {
-	*tsdb.DBReadOnly
+	db *tsdb.DBReadOnly
+
+	bkt         bucket
+	downloadDir string
+
 	filter filter
 }
 
@@ -51,14 +67,41 @@ func (f filter) inRange(min, max int64) bool {
 	return min <= fmax && fmin <= max
 }
 
+// Block represents TSDB block to import
+type Block struct {
+	// Meta contains block meta info
+	Meta tsdb.BlockMeta
+	// ExternalLabels contains external labels
+	// for blocks uploaded to the bucket by Thanos
+	ExternalLabels map[string]string
+
+	// br is set for blocks from snapshot
+	br tsdb.BlockReader
+	// dir is set for blocks from bucket
+	dir string
+}
+
 // NewClient creates and validates new Client
 // with given Config
 func NewClient(cfg Config) (*Client, error) {
-	db, err := tsdb.OpenDBReadOnly(cfg.Snapshot, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open snapshot %q: %s", cfg.Snapshot, err)
+	c := &Client{}
+	if cfg.Bucket != "" {
+		bkt, err := newBucket(cfg.Bucket, cfg.BucketConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init bucket %q: %s", cfg.Bucket, err)
+		}
+		c.bkt = bkt
+		c.downloadDir = cfg.DownloadDir
+		if c.downloadDir == "" {
+			c.downloadDir = os.TempDir()
+		}
+	} else {
+		db, err := tsdb.OpenDBReadOnly(cfg.Snapshot, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open snapshot %q: %s", cfg.Snapshot, err)
+		}
+		c.db = db
 	}
-	c := &Client{DBReadOnly: db}
 	min, max, err := parseTime(cfg.Filter.TimeMin, cfg.Filter.TimeMax)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse time in filter: %s", err)
@@ -73,22 +116,38 @@ func NewClient(cfg Config) (*Client, error) {
 }
 
 // Explore fetches all available blocks from a snapshot
-// and collects the Meta() data from each block.
+// or bucket and collects the Meta() data from each block.
 // Explore does initial filtering by time-range
-// for snapshot blocks but does not take into account
+// for blocks but does not take into account
 // label filters.
-func (c *Client) Explore() ([]tsdb.BlockReader, error) {
-	blocks, err := c.Blocks()
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch blocks: %s", err)
-	}
+func (c *Client) Explore() ([]*Block, error) {
+	var blocks []*Block
 	s := &Stats{
 		Filtered: c.filter.min != 0 || c.filter.max != 0 || c.filter.label != "",
-		Blocks:   len(blocks),
 	}
-	var blocksToImport []tsdb.BlockReader
+	if c.bkt != nil {
+		bs, unsupported, err := c.exploreBucket()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch blocks from %s: %s", c.bkt, err)
+		}
+		blocks = bs
+		s.UnsupportedBlocks = unsupported
+	} else {
+		brs, err := c.db.Blocks()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch blocks: %s", err)
+		}
+		for _, br := range brs {
+			blocks = append(blocks, &Block{
+				Meta: br.Meta(),
+				br:   br,
+			})
+		}
+	}
+	s.Blocks = len(blocks)
+	var blocksToImport []*Block
 	for _, block := range blocks {
-		meta := block.Meta()
+		meta := block.Meta
 		if !c.filter.inRange(meta.MinTime, meta.MaxTime) {
 			s.SkippedBlocks++
 			continue
@@ -107,22 +166,40 @@ func (c *Client) Explore() ([]tsdb.BlockReader, error) {
 	return blocksToImport, nil
 }
 
-// Read reads the given BlockReader according to configured
-// time and label filters.
-func (c *Client) Read(block tsdb.BlockReader) (storage.SeriesSet, error) {
-	minTime, maxTime := block.Meta().MinTime, block.Meta().MaxTime
+// Read reads the given block according to configured
+// time and label filters and passes the matching series to f.
+//
+// Blocks from bucket are downloaded to the local directory
+// before reading and are removed after f returns.
+func (c *Client) Read(block *Block, f func(ss storage.SeriesSet) error) error {
+	br := block.br
+	if br == nil {
+		dir, err := c.downloadBlock(block)
+		if err != nil {
+			return fmt.Errorf("failed to download block: %s", err)
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+		b, err := tsdb.OpenBlock(nil, dir, nil)
+		if err != nil {
+			return fmt.Errorf("failed to open downloaded block: %s", err)
+		}
+		defer func() { _ = b.Close() }()
+		br = b
+	}
+	minTime, maxTime := block.Meta.MinTime, block.Meta.MaxTime
 	if c.filter.min != 0 {
 		minTime = c.filter.min
 	}
 	if c.filter.max != 0 {
 		maxTime = c.filter.max
 	}
-	q, err := tsdb.NewBlockQuerier(block, minTime, maxTime)
+	q, err := tsdb.NewBlockQuerier(br, minTime, maxTime)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer func() { _ = q.Close() }()
 	ss := q.Select(context.Background(), false, nil, labels.MustNewMatcher(labels.MatchRegexp, c.filter.label, c.filter.labelValue))
-	return ss, nil
+	return f(ss)
 }
 
 func parseTime(start, end string) (int64, int64, error) {
@@ -14,6 +14,9 @@ type Stats struct {
 	Series        uint64
 	Blocks        int
 	SkippedBlocks int
+	// UnsupportedBlocks is the number of blocks in the bucket
+	// skipped because they are marked for deletion or downsampled
+	UnsupportedBlocks int
 }
 
 // String returns string representation for s.
@@ -30,6 +33,9 @@ func (s Stats) String() string {
 		s.MaxTime, time.Unix(s.MaxTime/1e3, 0).Format(time.RFC3339),
 		s.Samples, s.Series)
 
+	if s.UnsupportedBlocks > 0 {
+		str += fmt.Sprintf("\n* %d blocks marked for deletion or downsampled were skipped.", s.UnsupportedBlocks)
+	}
 	if s.Filtered {
 		str += "\n* Stats numbers are based on blocks meta info and don't account for applied filters."
 	}
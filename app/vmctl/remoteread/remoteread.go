@@ -14,6 +14,7 @@ import (
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmctl/vm"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/bytesutil"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httputils"
+	"github.com/VictoriaMetrics/metricsql"
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
 	"github.com/prometheus/prometheus/prompb"
@@ -39,7 +40,8 @@ type Client struct {
 	password          string
 	useStream         bool
 	headers           []keyValue
-	matchers          []*prompb.LabelMatcher
+	// matchers contains a list of matchers per every query in read request
+	matchers [][]*prompb.LabelMatcher
 }
 
 // Config is config for remote read.
@@ -64,6 +66,10 @@ type Config struct {
 	// LabelName, LabelValue stands for label=~value pair used for read requests.
 	// Is optional.
 	LabelName, LabelValue string
+	// Match is a series selector used for read requests, e.g. `{job="foo",instance=~"bar.*"}`.
+	// `or` filters are sent as separate queries in a single read request.
+	// Is optional. LabelName and LabelValue are ignored if set.
+	Match string
 
 	// Optional cert file, key file, CA file and server name for client side TLS configuration
 	CertFile   string
@@ -101,13 +107,22 @@ func NewClient(cfg Config) (*Client, error) {
 		return nil, err
 	}
 
-	var m *prompb.LabelMatcher
-	if cfg.LabelName != "" && cfg.LabelValue != "" {
-		m = &prompb.LabelMatcher{
-			Type:  prompb.LabelMatcher_RE,
-			Name:  cfg.LabelName,
-			Value: cfg.LabelValue,
+	var matchers [][]*prompb.LabelMatcher
+	if cfg.Match != "" {
+		matchers, err = parseMatch(cfg.Match)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse match %q: %w", cfg.Match, err)
+		}
+	} else {
+		var m *prompb.LabelMatcher
+		if cfg.LabelName != "" && cfg.LabelValue != "" {
+			m = &prompb.LabelMatcher{
+				Type:  prompb.LabelMatcher_RE,
+				Name:  cfg.LabelName,
+				Value: cfg.LabelValue,
+			}
 		}
+		matchers = [][]*prompb.LabelMatcher{{m}}
 	}
 
 	tr, err := httputils.Transport(cfg.Addr, cfg.CertFile, cfg.KeyFile, cfg.CAFile, cfg.ServerName, cfg.InsecureSkipVerify)
@@ -126,7 +141,7 @@ func NewClient(cfg Config) (*Client, error) {
 		password:          cfg.Password,
 		useStream:         cfg.UseStream,
 		headers:           headers,
-		matchers:          []*prompb.LabelMatcher{m},
+		matchers:          matchers,
 	}
 
 	return c, nil
@@ -134,14 +149,13 @@ func NewClient(cfg Config) (*Client, error) {
 
 // Read fetch data from remote read source
 func (c *Client) Read(ctx context.Context, filter *Filter, streamCb StreamCallback) error {
-	req := &prompb.ReadRequest{
-		Queries: []*prompb.Query{
-			{
-				StartTimestampMs: filter.StartTimestampMs,
-				EndTimestampMs:   filter.EndTimestampMs - 1,
-				Matchers:         c.matchers,
-			},
-		},
+	req := &prompb.ReadRequest{}
+	for _, matchers := range c.matchers {
+		req.Queries = append(req.Queries, &prompb.Query{
+			StartTimestampMs: filter.StartTimestampMs,
+			EndTimestampMs:   filter.EndTimestampMs - 1,
+			Matchers:         matchers,
+		})
 	}
 	if c.useStream {
 		req.AcceptedResponseTypes = []prompb.ReadRequest_ResponseType{prompb.ReadRequest_STREAMED_XOR_CHUNKS}
@@ -215,6 +229,76 @@ func (c *Client) fetch(ctx context.Context, data []byte, streamCb StreamCallback
 	return processResponse(resp.Body, streamCb)
 }
 
+// seriesDeduplicator detects series returned by multiple queries of a single read request.
+//
+// Every `or` filter from Config.Match is sent as a separate query, so series matching multiple filters
+// are returned multiple times - once per every matching query.
+type seriesDeduplicator struct {
+	// queryIdxs contains the index of the first query, which returned the series with the given labels.
+	queryIdxs map[string]int64
+
+	buf []byte
+}
+
+// isDuplicate returns true if the series with the given labels was already returned by another query.
+//
+// The series returned by the same query isn't considered as duplicate, since big series
+// may be split into multiple frames in STREAMED_XOR_CHUNKS mode.
+func (sd *seriesDeduplicator) isDuplicate(labels []prompb.Label, queryIdx int64) bool {
+	if sd.queryIdxs == nil {
+		sd.queryIdxs = make(map[string]int64)
+	}
+	sd.buf = sd.buf[:0]
+	for _, label := range labels {
+		sd.buf = append(sd.buf, label.Name...)
+		sd.buf = append(sd.buf, 0)
+		sd.buf = append(sd.buf, label.Value...)
+		sd.buf = append(sd.buf, 0)
+	}
+	if idx, ok := sd.queryIdxs[string(sd.buf)]; ok {
+		return idx != queryIdx
+	}
+	sd.queryIdxs[string(sd.buf)] = queryIdx
+	return false
+}
+
+// parseMatch converts the given series selector into a list of matchers per every `or` filter.
+func parseMatch(s string) ([][]*prompb.LabelMatcher, error) {
+	expr, err := metricsql.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	me, ok := expr.(*metricsql.MetricExpr)
+	if !ok {
+		return nil, fmt.Errorf("expecting series selector; got %q", expr.AppendString(nil))
+	}
+	if len(me.LabelFilterss) == 0 {
+		return nil, fmt.Errorf("series selector cannot be empty")
+	}
+	matcherss := make([][]*prompb.LabelMatcher, 0, len(me.LabelFilterss))
+	for _, lfs := range me.LabelFilterss {
+		matchers := make([]*prompb.LabelMatcher, 0, len(lfs))
+		for _, lf := range lfs {
+			m := &prompb.LabelMatcher{
+				Type:  prompb.LabelMatcher_EQ,
+				Name:  lf.Label,
+				Value: lf.Value,
+			}
+			switch {
+			case lf.IsRegexp && lf.IsNegative:
+				m.Type = prompb.LabelMatcher_NRE
+			case lf.IsRegexp:
+				m.Type = prompb.LabelMatcher_RE
+			case lf.IsNegative:
+				m.Type = prompb.LabelMatcher_NEQ
+			}
+			matchers = append(matchers, m)
+		}
+		matcherss = append(matcherss, matchers)
+	}
+	return matcherss, nil
+}
+
 func processResponse(body io.ReadCloser, callback StreamCallback) error {
 	d, err := io.ReadAll(body)
 	if err != nil {
@@ -231,8 +315,12 @@ func processResponse(body io.ReadCloser, callback StreamCallback) error {
 	}
 	// response could have no results for the given filter, but that
 	// shouldn't be accounted as an error.
-	for _, res := range readResp.Results {
+	var sd seriesDeduplicator
+	for i, res := range readResp.Results {
 		for _, ts := range res.Timeseries {
+			if sd.isDuplicate(ts.Labels, int64(i)) {
+				continue
+			}
 			vmTs := convertSamples(ts.Samples, ts.Labels)
 			if err := callback(vmTs); err != nil {
 				return err
@@ -250,6 +338,7 @@ func processStreamResponse(body io.ReadCloser, callback StreamCallback) error {
 	defer func() { bbPool.Put(bb) }()
 
 	stream := remote.NewChunkedReader(body, remote.DefaultChunkedReadLimit, bb.B)
+	var sd seriesDeduplicator
 	for {
 		res := &prompb.ChunkedReadResponse{}
 		err := stream.NextProto(res)
@@ -261,6 +350,9 @@ func processStreamResponse(body io.ReadCloser, callback StreamCallback) error {
 		}
 
 		for _, series := range res.ChunkedSeries {
+			if sd.isDuplicate(series.Labels, res.QueryIndex) {
+				continue
+			}
 			samples := make([]prompb.Sample, 0)
 			for _, chunk := range series.Chunks {
 				s, err := parseSamples(chunk.Data)
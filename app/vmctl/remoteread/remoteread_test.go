@@ -0,0 +1,71 @@
+package remoteread
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+)
+
+func TestParseMatch(t *testing.T) {
+	f := func(s string, expected [][]*prompb.LabelMatcher) {
+		t.Helper()
+		matchers, err := parseMatch(s)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(matchers, expected) {
+			t.Fatalf("unexpected matchers for %q;\ngot\n%v\nwant\n%v", s, matchers, expected)
+		}
+	}
+	f(`foo`, [][]*prompb.LabelMatcher{{
+		{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "foo"},
+	}})
+	f(`{job="node",instance=~"host-.*",env!="dev",dc!~"us-.*"}`, [][]*prompb.LabelMatcher{{
+		{Type: prompb.LabelMatcher_EQ, Name: "job", Value: "node"},
+		{Type: prompb.LabelMatcher_RE, Name: "instance", Value: "host-.*"},
+		{Type: prompb.LabelMatcher_NEQ, Name: "env", Value: "dev"},
+		{Type: prompb.LabelMatcher_NRE, Name: "dc", Value: "us-.*"},
+	}})
+	f(`{__name__=~"foo|bar" or job="baz"}`, [][]*prompb.LabelMatcher{
+		{{Type: prompb.LabelMatcher_RE, Name: "__name__", Value: "foo|bar"}},
+		{{Type: prompb.LabelMatcher_EQ, Name: "job", Value: "baz"}},
+	})
+}
+
+func TestParseMatchFailure(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		if _, err := parseMatch(s); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	f(`{}`)
+	f(`{foo`)
+	f(`rate(foo[5m])`)
+	f(`foo + bar`)
+}
+
+func TestSeriesDeduplicator(t *testing.T) {
+	var sd seriesDeduplicator
+	f := func(labels []prompb.Label, queryIdx int64, resultExpected bool) {
+		t.Helper()
+		result := sd.isDuplicate(labels, queryIdx)
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %v at query #%d; got %v; want %v", labels, queryIdx, result, resultExpected)
+		}
+	}
+	foo := []prompb.Label{{Name: "__name__", Value: "foo"}, {Name: "job", Value: "baz"}}
+	bar := []prompb.Label{{Name: "__name__", Value: "bar"}, {Name: "job", Value: "baz"}}
+
+	f(foo, 0, false)
+	// The next frame of the same series from the same query
+	f(foo, 0, false)
+	f(bar, 0, false)
+	// The same series returned by another `or` filter
+	f(foo, 1, true)
+	f(bar, 1, true)
+	f([]prompb.Label{{Name: "__name__", Value: "qux"}, {Name: "job", Value: "baz"}}, 1, false)
+	// Label names and values mustn't be mixed up
+	f([]prompb.Label{{Name: "__name__", Value: "foo\x00job"}, {Name: "baz", Value: ""}}, 1, false)
+}
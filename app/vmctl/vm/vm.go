@@ -51,8 +51,11 @@ type Config struct {
 	// ExtraLabels that will be added to all imported series. Must be in label=value format.
 	ExtraLabels []string
 	// RateLimit defines a data transfer speed in bytes per second.
-	// Is applied to each worker (see Concurrency) independently.
+	// Is shared between all the workers (see Concurrency).
 	RateLimit int64
+	// RequestRateLimit defines the maximum number of import requests per second.
+	// Is shared between all the workers (see Concurrency).
+	RequestRateLimit int64
 	// Whether to disable progress bar per VM worker
 	DisableProgressBar bool
 }
@@ -71,7 +74,8 @@ type Importer struct {
 	input  chan *TimeSeries
 	errors chan *ImportError
 
-	rl *limiter.Limiter
+	rl  *limiter.Limiter
+	rrl *limiter.Limiter
 
 	wg   sync.WaitGroup
 	once sync.Once
@@ -135,6 +139,7 @@ func NewImporter(ctx context.Context, cfg Config) (*Importer, error) {
 		user:       cfg.User,
 		password:   cfg.Password,
 		rl:         limiter.NewLimiter(cfg.RateLimit),
+		rrl:        limiter.NewLimiter(cfg.RequestRateLimit),
 		close:      make(chan struct{}),
 		input:      make(chan *TimeSeries, cfg.Concurrency*4),
 		errors:     make(chan *ImportError, cfg.Concurrency),
@@ -306,6 +311,7 @@ func (im *Importer) Import(tsBatch []*TimeSeries) error {
 	if len(tsBatch) < 1 {
 		return nil
 	}
+	im.rrl.Register(1)
 
 	pr, pw := io.Pipe()
 	req, err := http.NewRequest(http.MethodPost, im.importPath, pr)
@@ -28,14 +28,23 @@ type vmNativeProcessor struct {
 	src     *native.Client
 	backoff *backoff.Backoff
 
-	s            *stats
-	rateLimit    int64
+	s                *stats
+	rateLimit        int64
+	requestRateLimit int64
+	// rl and rrl are shared between all the concurrent requests,
+	// so rateLimit and requestRateLimit are applied to all of them
+	rl           *limiter.Limiter
+	rrl          *limiter.Limiter
 	interCluster bool
 	cc           int
 	isSilent     bool
 	isNative     bool
 
 	disablePerMetricRequests bool
+
+	// cp contains already migrated requests.
+	// It is nil if checkpointing is disabled.
+	cp *checkpoint
 }
 
 const (
@@ -52,6 +61,8 @@ func (p *vmNativeProcessor) run(ctx context.Context) error {
 	p.s = &stats{
 		startTime: time.Now(),
 	}
+	p.rl = limiter.NewLimiter(p.rateLimit)
+	p.rrl = limiter.NewLimiter(p.requestRateLimit)
 
 	start, err := parseTime(p.filter.TimeStart)
 	if err != nil {
@@ -114,6 +125,8 @@ func (p *vmNativeProcessor) do(ctx context.Context, f native.Filter, srcURL, dst
 }
 
 func (p *vmNativeProcessor) runSingle(ctx context.Context, f native.Filter, srcURL, dstURL string, bar *pb.ProgressBar) error {
+	p.rrl.Register(1)
+
 	reader, err := p.src.ExportPipe(ctx, srcURL, f)
 	if err != nil {
 		return fmt.Errorf("failed to init export pipe: %w", err)
@@ -133,8 +146,7 @@ func (p *vmNativeProcessor) runSingle(ctx context.Context, f native.Filter, srcU
 
 	w := io.Writer(pw)
 	if p.rateLimit > 0 {
-		rl := limiter.NewLimiter(p.rateLimit)
-		w = limiter.NewWriteLimiter(pw, rl)
+		w = limiter.NewWriteLimiter(pw, p.rl)
 	}
 
 	written, err := io.Copy(w, reader)
@@ -218,15 +230,40 @@ func (p *vmNativeProcessor) runBackfilling(ctx context.Context, tenantID string,
 		log.Print(foundSeriesMsg)
 	}
 
-	processingMsg := fmt.Sprintf("Requests to make: %d", len(metrics)*len(ranges))
+	var filters []native.Filter
+	skipped := 0
+	for _, s := range metrics {
+		match, err := buildMatchWithFilter(p.filter.Match, s)
+		if err != nil {
+			logger.Errorf("failed to build export filters: %s", err)
+			continue
+		}
+		for _, times := range ranges {
+			f := native.Filter{
+				Match:     match,
+				TimeStart: times[0].Format(time.RFC3339),
+				TimeEnd:   times[1].Format(time.RFC3339),
+			}
+			if p.cp.isDone(newCheckpointEntry(srcURL, dstURL, f)) {
+				skipped++
+				continue
+			}
+			filters = append(filters, f)
+		}
+	}
+
+	processingMsg := fmt.Sprintf("Requests to make: %d", len(filters))
 	if len(ranges) > 1 {
 		processingMsg = fmt.Sprintf("Selected time range will be split into %d ranges according to %q step. %s", len(ranges), p.filter.Chunk, processingMsg)
 	}
+	if skipped > 0 {
+		processingMsg = fmt.Sprintf("%s. Skipped %d requests already migrated according to checkpoint file %q", processingMsg, skipped, p.cp.path)
+	}
 	log.Print(processingMsg)
 
 	var bar *pb.ProgressBar
 	if !silent {
-		bar = barpool.NewSingleProgress(fmt.Sprintf(nativeWithBackoffTpl, barPrefix), len(metrics)*len(ranges))
+		bar = barpool.NewSingleProgress(fmt.Sprintf(nativeWithBackoffTpl, barPrefix), len(filters))
 		if p.disablePerMetricRequests {
 			bar = barpool.NewSingleProgress(nativeSingleProcessTpl, 0)
 		}
@@ -257,31 +294,22 @@ func (p *vmNativeProcessor) runBackfilling(ctx context.Context, tenantID string,
 						return
 					}
 				}
+				if err := p.cp.markDone(newCheckpointEntry(srcURL, dstURL, f)); err != nil {
+					errCh <- err
+					return
+				}
 			}
 		}()
 	}
 
 	// any error breaks the import
-	for _, s := range metrics {
-
-		match, err := buildMatchWithFilter(p.filter.Match, s)
-		if err != nil {
-			logger.Errorf("failed to build export filters: %s", err)
-			continue
-		}
-
-		for _, times := range ranges {
-			select {
-			case <-ctx.Done():
-				return fmt.Errorf("context canceled")
-			case infErr := <-errCh:
-				return fmt.Errorf("native error: %s", infErr)
-			case filterCh <- native.Filter{
-				Match:     match,
-				TimeStart: times[0].Format(time.RFC3339),
-				TimeEnd:   times[1].Format(time.RFC3339),
-			}:
-			}
+	for _, f := range filters {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context canceled")
+		case infErr := <-errCh:
+			return fmt.Errorf("native error: %s", infErr)
+		case filterCh <- f:
 		}
 	}
 
@@ -57,6 +57,10 @@ func insertRows(series []datadogv1.Series, extraLabels []prompbmarshal.Label) er
 			if name == "host" {
 				name = "exported_host"
 			}
+			if name == "device" && ss.Device != "" {
+				// Prevent from duplicate device labels if the device is passed both in `device` field and in `device` tag.
+				name = "exported_device"
+			}
 			ctx.AddLabel(name, value)
 		}
 		for j := range extraLabels {
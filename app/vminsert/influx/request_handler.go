@@ -4,6 +4,7 @@ import (
 	"flag"
 	"io"
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vminsert/common"
@@ -24,6 +25,7 @@ var (
 	skipSingleField           = flag.Bool("influxSkipSingleField", false, "Uses '{measurement}' instead of '{measurement}{separator}{field_name}' for metric name if InfluxDB line contains only a single field")
 	skipMeasurement           = flag.Bool("influxSkipMeasurement", false, "Uses '{field_name}' as a metric name while ignoring '{measurement}' and '-influxMeasurementFieldSeparator'")
 	dbLabel                   = flag.String("influxDBLabel", "db", "Default label for the DB name sent over '?db={db_name}' query parameter")
+	mapBucketToDB             = flag.Bool("influxMapBucketToDB", false, "Whether to use 'bucket' query arg from InfluxDB v2 requests sent to /api/v2/write as the value for the label set via -influxDBLabel if 'db' query arg is missing. Enabling this flag adds the label to series, which were previously ingested via /api/v2/write without it")
 )
 
 var (
@@ -53,6 +55,11 @@ func InsertHandlerForHTTP(req *http.Request) error {
 	precision := q.Get("precision")
 	// Read db tag from https://docs.influxdata.com/influxdb/v1.7/tools/api/#write-http-endpoint
 	db := q.Get("db")
+	if db == "" && *mapBucketToDB && strings.HasSuffix(req.URL.Path, "/api/v2/write") {
+		// Read bucket from https://docs.influxdata.com/influxdb/v2/api/#operation/PostWrite
+		// and store it in db tag in the same way as InfluxDB v2 maps db to bucket for v1-compatible writes.
+		db = q.Get("bucket")
+	}
 	return stream.Parse(req.Body, isGzipped, precision, db, func(db string, rows []parser.Row) error {
 		return insertRows(db, rows, extraLabels)
 	})
@@ -142,14 +142,22 @@ func RequestHandler(w http.ResponseWriter, r *http.Request) bool {
 	}
 	if strings.HasPrefix(path, "/prometheus/api/v1/import/prometheus") || strings.HasPrefix(path, "/api/v1/import/prometheus") {
 		prometheusimportRequests.Inc()
+		isPushgateway := strings.HasPrefix(path, "/prometheus/api/v1/import/prometheus/metrics/job/") ||
+			strings.HasPrefix(path, "/api/v1/import/prometheus/metrics/job/")
+		if isPushgateway {
+			if err := common.CheckPushgatewayMethod(w, r); err != nil {
+				prometheusimportErrors.Inc()
+				httpserver.Errorf(w, r, "%s", err)
+				return true
+			}
+		}
 		if err := prometheusimport.InsertHandler(r); err != nil {
 			prometheusimportErrors.Inc()
 			httpserver.Errorf(w, r, "%s", err)
 			return true
 		}
 		statusCode := http.StatusNoContent
-		if strings.HasPrefix(path, "/prometheus/api/v1/import/prometheus/metrics/job/") ||
-			strings.HasPrefix(path, "/api/v1/import/prometheus/metrics/job/") {
+		if isPushgateway {
 			// Return 200 status code for pushgateway requests.
 			// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/3636
 			statusCode = http.StatusOK
@@ -249,6 +257,15 @@ func RequestHandler(w http.ResponseWriter, r *http.Request) bool {
 		w.WriteHeader(202)
 		fmt.Fprintf(w, `{"status":"ok"}`)
 		return true
+	case "/opentsdb/api/put":
+		opentsdbhttpWriteRequests.Inc()
+		if err := opentsdbhttp.InsertHandler(r); err != nil {
+			opentsdbhttpWriteErrors.Inc()
+			httpserver.Errorf(w, r, "%s", err)
+			return true
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return true
 	case "/datadog/api/v1/series":
 		datadogv1WriteRequests.Inc()
 		if err := datadogv1.InsertHandlerForHTTP(r); err != nil {
@@ -316,7 +333,8 @@ func RequestHandler(w http.ResponseWriter, r *http.Request) bool {
 		promscrapeAPIV1TargetsRequests.Inc()
 		w.Header().Set("Content-Type", "application/json")
 		state := r.FormValue("state")
-		promscrape.WriteAPIV1Targets(w, state)
+		scrapePool := r.FormValue("scrapePool")
+		promscrape.WriteAPIV1Targets(w, state, scrapePool)
 		return true
 	case "/prometheus/target_response", "/target_response":
 		promscrapeTargetResponseRequests.Inc()
@@ -421,6 +439,9 @@ var (
 	newrelicInventoryRequests = metrics.NewCounter(`vm_http_requests_total{path="/newrelic/inventory/deltas", protocol="newrelic"}`)
 	newrelicCheckRequest      = metrics.NewCounter(`vm_http_requests_total{path="/newrelic", protocol="newrelic"}`)
 
+	opentsdbhttpWriteRequests = metrics.NewCounter(`vm_http_requests_total{path="/opentsdb/api/put", protocol="opentsdbhttp"}`)
+	opentsdbhttpWriteErrors   = metrics.NewCounter(`vm_http_request_errors_total{path="/opentsdb/api/put", protocol="opentsdbhttp"}`)
+
 	promscrapeTargetsRequests          = metrics.NewCounter(`vm_http_requests_total{path="/targets"}`)
 	promscrapeServiceDiscoveryRequests = metrics.NewCounter(`vm_http_requests_total{path="/service-discovery"}`)
 
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"sync/atomic"
+	"time"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
@@ -17,7 +18,9 @@ import (
 var (
 	relabelConfig = flag.String("relabelConfig", "", "Optional path to a file with relabeling rules, which are applied to all the ingested metrics. "+
 		"The path can point either to local file or to http url. "+
-		"See https://docs.victoriametrics.com/#relabeling for details. The config is reloaded on SIGHUP signal")
+		"See https://docs.victoriametrics.com/#relabeling for details. The config is reloaded on SIGHUP signal. See also -relabelConfigCheckInterval")
+	relabelConfigCheckInterval = flag.Duration("relabelConfigCheckInterval", 0, "Interval for checking for changes in -relabelConfig file. "+
+		"By default, the checking is disabled. Send SIGHUP signal or request /-/reload endpoint in order to force config reload")
 
 	usePromCompatibleNaming = flag.Bool("usePromCompatibleNaming", false, "Whether to replace characters unsupported by Prometheus with underscores "+
 		"in the ingested metric names and label names. For example, foo.bar{a.b='c'} is transformed into foo_bar{a_b='c'} during data ingestion if this flag is set. "+
@@ -42,25 +45,48 @@ func Init() {
 	if len(*relabelConfig) == 0 {
 		return
 	}
+	var tickerCh <-chan time.Time
+	if *relabelConfigCheckInterval > 0 {
+		ticker := time.NewTicker(*relabelConfigCheckInterval)
+		tickerCh = ticker.C
+	}
 	go func() {
-		for range sighupCh {
-			configReloads.Inc()
-			logger.Infof("received SIGHUP; reloading -relabelConfig=%q...", *relabelConfig)
-			pcs, err := loadRelabelConfig()
-			if err != nil {
-				configReloadErrors.Inc()
-				configSuccess.Set(0)
-				logger.Errorf("cannot load the updated relabelConfig: %s; preserving the previous config", err)
-				continue
+		for {
+			select {
+			case <-sighupCh:
+				logger.Infof("received SIGHUP; reloading -relabelConfig=%q...", *relabelConfig)
+				reloadRelabelConfig(true)
+			case <-tickerCh:
+				reloadRelabelConfig(false)
 			}
-			pcsGlobal.Store(pcs)
-			configSuccess.Set(1)
-			configTimestamp.Set(fasttime.UnixTimestamp())
-			logger.Infof("successfully reloaded -relabelConfig=%q", *relabelConfig)
 		}
 	}()
 }
 
+// reloadRelabelConfig reloads -relabelConfig.
+//
+// If force is false, then the config is updated only if it has been changed since the previous load.
+func reloadRelabelConfig(force bool) {
+	pcs, err := loadRelabelConfig()
+	if err != nil {
+		configReloads.Inc()
+		configReloadErrors.Inc()
+		configSuccess.Set(0)
+		logger.Errorf("cannot load the updated relabelConfig: %s; preserving the previous config", err)
+		return
+	}
+	if !force && pcs.String() == pcsGlobal.Load().String() {
+		// Nothing changed since the previous load.
+		configSuccess.Set(1)
+		return
+	}
+	configReloads.Inc()
+	pcsGlobal.Store(pcs)
+	configSuccess.Set(1)
+	configTimestamp.Set(fasttime.UnixTimestamp())
+	logger.Infof("successfully reloaded -relabelConfig=%q", *relabelConfig)
+}
+
 var (
 	configReloads      = metrics.NewCounter(`vm_relabel_config_reloads_total`)
 	configReloadErrors = metrics.NewCounter(`vm_relabel_config_reloads_errors_total`)
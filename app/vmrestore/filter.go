@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/common"
+)
+
+var filterTimeRange = flag.String("filter.timeRange", "", "Optional time range for the data to restore in the form 'start..end', where start and end are dates in YYYY-MM-DD format or RFC3339 timestamps. "+
+	"The end is exclusive. Only monthly partitions overlapping the given time range are restored, while the index data is restored in full. "+
+	"By default, all the data is restored. See https://docs.victoriametrics.com/vmrestore.html#partial-restore")
+
+// newPartFilter returns a filter for the restored parts according to -filter.timeRange.
+//
+// nil is returned if all the parts must be restored.
+func newPartFilter() (func(p common.Part) bool, error) {
+	if *filterTimeRange == "" {
+		return nil, nil
+	}
+	start, end, err := parseTimeRange(*filterTimeRange)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse -filter.timeRange=%q: %w", *filterTimeRange, err)
+	}
+	f := func(p common.Part) bool {
+		return partitionOverlapsTimeRange(p.Path, start, end)
+	}
+	return f, nil
+}
+
+// parseTimeRange parses time range in the form 'start..end'.
+func parseTimeRange(s string) (time.Time, time.Time, error) {
+	n := strings.Index(s, "..")
+	if n < 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("missing '..' delimiter between start and end")
+	}
+	start, err := parseTime(s[:n])
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("cannot parse start: %w", err)
+	}
+	end, err := parseTime(s[n+len(".."):])
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("cannot parse end: %w", err)
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start=%s must be smaller than end=%s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	return start, end, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// partitionOverlapsTimeRange returns true if the file at the given path belongs to a partition overlapping [start, end) time range.
+//
+// Files outside partitions such as index data and metadata always match.
+func partitionOverlapsTimeRange(path string, start, end time.Time) bool {
+	name, ok := getPartitionName(path)
+	if !ok {
+		return true
+	}
+	ptStart, err := time.Parse("2006_01", name)
+	if err != nil {
+		// Unknown directory, so restore it.
+		return true
+	}
+	ptEnd := ptStart.AddDate(0, 1, 0)
+	return ptStart.Before(end) && ptEnd.After(start)
+}
+
+// getPartitionName returns the name of partition for the file at the given path.
+func getPartitionName(path string) (string, bool) {
+	for _, prefix := range []string{"data/small/", "data/big/"} {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		tail := path[len(prefix):]
+		n := strings.IndexByte(tail, '/')
+		if n < 0 {
+			return "", false
+		}
+		return tail[:n], true
+	}
+	return "", false
+}
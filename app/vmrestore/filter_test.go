@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeRangeFailure(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		if _, _, err := parseTimeRange(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+	f("")
+	f("2023-01-01")
+	f("2023-01-01..")
+	f("..2023-01-01")
+	f("foo..2023-01-01")
+	f("2023-02-01..2023-01-01")
+	f("2023-01-01..2023-01-01")
+}
+
+func TestParseTimeRangeSuccess(t *testing.T) {
+	f := func(s string, startExpected, endExpected time.Time) {
+		t.Helper()
+		start, end, err := parseTimeRange(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if !start.Equal(startExpected) || !end.Equal(endExpected) {
+			t.Fatalf("unexpected time range for %q; got %s..%s; want %s..%s", s, start, end, startExpected, endExpected)
+		}
+	}
+	f("2023-01-01..2023-02-01", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+	f("2023-01-01T10:00:00Z..2023-01-02", time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+}
+
+func TestPartitionOverlapsTimeRange(t *testing.T) {
+	f := func(timeRange, path string, resultExpected bool) {
+		t.Helper()
+		start, end, err := parseTimeRange(timeRange)
+		if err != nil {
+			t.Fatalf("cannot parse time range %q: %s", timeRange, err)
+		}
+		result := partitionOverlapsTimeRange(path, start, end)
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %q at %q; got %v; want %v", path, timeRange, result, resultExpected)
+		}
+	}
+
+	// Files outside partitions must be always restored
+	f("2023-01-01..2023-02-01", "indexdb/17A8B7E3C1A5F6D2/parts.json", true)
+	f("2023-01-01..2023-02-01", "metadata/minTimestampForCompositeIndex", true)
+	f("2023-01-01..2023-02-01", "data/small/parts.json", true)
+
+	// The end is exclusive
+	f("2023-01-01..2023-02-01", "data/small/2023_01/17A8B7E3C1A5F6D2/index.bin", true)
+	f("2023-01-01..2023-02-01", "data/big/2023_01/parts.json", true)
+	f("2023-01-01..2023-02-01", "data/small/2023_02/17A8B7E3C1A5F6D2/index.bin", false)
+	f("2023-01-01..2023-02-01", "data/big/2022_12/17A8B7E3C1A5F6D2/index.bin", false)
+
+	// Partial overlap
+	f("2023-01-15..2023-03-10", "data/small/2023_01/parts.json", true)
+	f("2023-01-15..2023-03-10", "data/small/2023_02/parts.json", true)
+	f("2023-01-15..2023-03-10", "data/small/2023_03/parts.json", true)
+	f("2023-01-15..2023-03-10", "data/small/2023_04/parts.json", false)
+	f("2023-01-31T23:00:00Z..2023-02-01T00:00:00Z", "data/small/2023_01/parts.json", true)
+	f("2023-01-31T23:00:00Z..2023-02-01T00:00:00Z", "data/small/2023_02/parts.json", false)
+}
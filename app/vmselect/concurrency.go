@@ -0,0 +1,331 @@
+package vmselect
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmselect/searchutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/querytracer"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/timerpool"
+	"github.com/VictoriaMetrics/metrics"
+)
+
+var maxConcurrentBatchRequests = flag.Int("search.maxConcurrentBatchRequests", 0, "The maximum number of concurrent search requests with batch priority. "+
+	"Batch requests occupy slots from -search.maxConcurrentRequests only when there are no queued interactive requests. "+
+	"By default, up to half of -search.maxConcurrentRequests can be used by batch requests. "+
+	"See https://docs.victoriametrics.com/#query-priority")
+
+const (
+	queryPriorityInteractive = "interactive"
+	queryPriorityBatch       = "batch"
+)
+
+// getQueryPriority returns query priority for the request r to the given path.
+//
+// The priority is obtained from priority query arg or from X-Query-Priority request header.
+// It is applied only to query endpoints, since obtaining the query arg requires parsing request body for other endpoints.
+// Requests without explicitly set priority are considered interactive.
+func getQueryPriority(path string, r *http.Request) (string, error) {
+	if !isQueryPriorityPath(path) {
+		return queryPriorityInteractive, nil
+	}
+	priority := r.FormValue("priority")
+	if priority == "" {
+		priority = r.Header.Get("X-Query-Priority")
+	}
+	switch priority {
+	case "", queryPriorityInteractive:
+		return queryPriorityInteractive, nil
+	case queryPriorityBatch:
+		return queryPriorityBatch, nil
+	default:
+		return "", &httpserver.ErrorWithStatusCode{
+			Err:        fmt.Errorf("unsupported query priority %q; supported values: %q, %q", priority, queryPriorityInteractive, queryPriorityBatch),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+}
+
+func isQueryPriorityPath(path string) bool {
+	switch path {
+	case "/api/v1/query", "/api/v1/query_range", "/api/v1/series", "/api/v1/export", "/api/v1/export/csv", "/api/v1/export/native",
+		"/api/v1/read", "/federate", "/render":
+		return true
+	default:
+		return false
+	}
+}
+
+func initConcurrencyLimits() {
+	concurrencyLimiter = newPriorityLimiter(*maxConcurrentRequests)
+	n := *maxConcurrentBatchRequests
+	if n <= 0 {
+		n = *maxConcurrentRequests / 2
+		if n < 1 {
+			n = 1
+		}
+	}
+	batchConcurrencyLimitCh = make(chan struct{}, n)
+}
+
+var (
+	concurrencyLimiter      *priorityLimiter
+	batchConcurrencyLimitCh chan struct{}
+)
+
+var (
+	concurrencyLimitReached = metrics.NewCounter(`vm_concurrent_select_limit_reached_total`)
+	concurrencyLimitTimeout = metrics.NewCounter(`vm_concurrent_select_limit_timeout_total`)
+
+	_ = metrics.NewGauge(`vm_concurrent_select_capacity`, func() float64 {
+		return float64(concurrencyLimiter.capacity)
+	})
+	_ = metrics.NewGauge(`vm_concurrent_select_current`, func() float64 {
+		return float64(concurrencyLimiter.Current())
+	})
+
+	batchConcurrencyLimitReached = metrics.NewCounter(`vm_concurrent_select_batch_limit_reached_total`)
+	batchConcurrencyLimitTimeout = metrics.NewCounter(`vm_concurrent_select_batch_limit_timeout_total`)
+
+	_ = metrics.NewGauge(`vm_concurrent_select_batch_capacity`, func() float64 {
+		return float64(cap(batchConcurrencyLimitCh))
+	})
+	_ = metrics.NewGauge(`vm_concurrent_select_batch_current`, func() float64 {
+		return float64(len(batchConcurrencyLimitCh))
+	})
+)
+
+// priorityLimiter limits the number of concurrently executed requests.
+//
+// Queued interactive requests obtain free slots before queued batch requests.
+// Requests with the same priority obtain free slots in the order they were queued.
+type priorityLimiter struct {
+	capacity int
+
+	mu      sync.Mutex
+	current int
+
+	// Queued requests wait for the closing of their channels.
+	// The slot is passed to the request when its channel is closed.
+	interactiveQueue []chan struct{}
+	batchQueue       []chan struct{}
+}
+
+func newPriorityLimiter(capacity int) *priorityLimiter {
+	return &priorityLimiter{
+		capacity: capacity,
+	}
+}
+
+// Current returns the number of occupied slots at pl.
+func (pl *priorityLimiter) Current() int {
+	pl.mu.Lock()
+	n := pl.current
+	pl.mu.Unlock()
+	return n
+}
+
+// queuedInteractive returns the number of queued interactive requests.
+func (pl *priorityLimiter) queuedInteractive() int {
+	pl.mu.Lock()
+	n := len(pl.interactiveQueue)
+	pl.mu.Unlock()
+	return n
+}
+
+// queuedBatch returns the number of queued batch requests.
+func (pl *priorityLimiter) queuedBatch() int {
+	pl.mu.Lock()
+	n := len(pl.batchQueue)
+	pl.mu.Unlock()
+	return n
+}
+
+// TryAcquire obtains a free slot at pl if it is available and there are no queued requests, which must obtain it first.
+func (pl *priorityLimiter) TryAcquire(priority string) bool {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	return pl.tryAcquireLocked(priority)
+}
+
+func (pl *priorityLimiter) tryAcquireLocked(priority string) bool {
+	if pl.current >= pl.capacity || len(pl.interactiveQueue) > 0 {
+		return false
+	}
+	if priority == queryPriorityBatch && len(pl.batchQueue) > 0 {
+		return false
+	}
+	pl.current++
+	return true
+}
+
+// Acquire waits for a free slot at pl until stopCh is closed or timeoutCh receives a value.
+//
+// It returns false if the slot couldn't be obtained.
+func (pl *priorityLimiter) Acquire(priority string, stopCh <-chan struct{}, timeoutCh <-chan time.Time) bool {
+	pl.mu.Lock()
+	if pl.tryAcquireLocked(priority) {
+		pl.mu.Unlock()
+		return true
+	}
+	ch := make(chan struct{})
+	if priority == queryPriorityInteractive {
+		pl.interactiveQueue = append(pl.interactiveQueue, ch)
+	} else {
+		pl.batchQueue = append(pl.batchQueue, ch)
+	}
+	pl.mu.Unlock()
+
+	select {
+	case <-ch:
+		return true
+	case <-stopCh:
+	case <-timeoutCh:
+	}
+
+	pl.mu.Lock()
+	removed := removeWaiter(&pl.interactiveQueue, ch) || removeWaiter(&pl.batchQueue, ch)
+	pl.mu.Unlock()
+	if !removed {
+		// The slot has been already passed to the request. Pass it to the next queued request.
+		pl.Release()
+	}
+	return false
+}
+
+// Release releases the slot obtained via TryAcquire or Acquire.
+func (pl *priorityLimiter) Release() {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if ch := popWaiter(&pl.interactiveQueue); ch != nil {
+		close(ch)
+		return
+	}
+	if ch := popWaiter(&pl.batchQueue); ch != nil {
+		close(ch)
+		return
+	}
+	pl.current--
+}
+
+func popWaiter(queue *[]chan struct{}) chan struct{} {
+	q := *queue
+	if len(q) == 0 {
+		return nil
+	}
+	ch := q[0]
+	q[0] = nil
+	*queue = q[1:]
+	return ch
+}
+
+func removeWaiter(queue *[]chan struct{}, ch chan struct{}) bool {
+	q := *queue
+	for i := range q {
+		if q[i] == ch {
+			*queue = append(q[:i], q[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// acquireConcurrencySlot waits until the request r to the given path can be executed according to -search.maxConcurrentRequests
+// and -search.maxConcurrentBatchRequests limits.
+//
+// It returns a function, which must be called after the request is executed, and true on success.
+// It returns false if the request cannot be executed. The error is already sent to w in this case.
+func acquireConcurrencySlot(qt *querytracer.Tracer, startTime time.Time, path string, w http.ResponseWriter, r *http.Request) (func(), bool) {
+	priority, err := getQueryPriority(path, r)
+	if err != nil {
+		httpserver.Errorf(w, r, "%s", err)
+		return nil, false
+	}
+	if priority == queryPriorityInteractive && concurrencyLimiter.TryAcquire(priority) {
+		return releaseConcurrencySlot, true
+	}
+
+	// Sleep for a while until giving up. This should resolve short bursts in requests.
+	d := searchutils.GetMaxQueryDuration(r)
+	if d > *maxQueueDuration {
+		d = *maxQueueDuration
+	}
+	t := timerpool.Get(d)
+	defer timerpool.Put(t)
+
+	if priority == queryPriorityBatch {
+		// Batch requests must obtain a slot at batchConcurrencyLimitCh at first.
+		select {
+		case batchConcurrencyLimitCh <- struct{}{}:
+		default:
+			batchConcurrencyLimitReached.Inc()
+			select {
+			case batchConcurrencyLimitCh <- struct{}{}:
+				qt.Printf("wait in queue because -search.maxConcurrentBatchRequests=%d concurrent batch requests are executed", cap(batchConcurrencyLimitCh))
+			case <-r.Context().Done():
+				logRequestCancelled(startTime, r)
+				return nil, false
+			case <-t.C:
+				batchConcurrencyLimitTimeout.Inc()
+				sendQueueTimeoutError(w, r, d, "search.maxConcurrentBatchRequests", cap(batchConcurrencyLimitCh))
+				return nil, false
+			}
+		}
+		if concurrencyLimiter.TryAcquire(priority) {
+			return releaseBatchConcurrencySlot, true
+		}
+	}
+
+	// Wait for a free slot at concurrencyLimiter. Queued interactive requests overtake queued batch requests.
+	concurrencyLimitReached.Inc()
+	if concurrencyLimiter.Acquire(priority, r.Context().Done(), t.C) {
+		qt.Printf("wait in queue because -search.maxConcurrentRequests=%d concurrent requests are executed", *maxConcurrentRequests)
+		if priority == queryPriorityBatch {
+			return releaseBatchConcurrencySlot, true
+		}
+		return releaseConcurrencySlot, true
+	}
+	if priority == queryPriorityBatch {
+		<-batchConcurrencyLimitCh
+	}
+	if r.Context().Err() != nil {
+		logRequestCancelled(startTime, r)
+		return nil, false
+	}
+	concurrencyLimitTimeout.Inc()
+	sendQueueTimeoutError(w, r, d, "search.maxConcurrentRequests", *maxConcurrentRequests)
+	return nil, false
+}
+
+func releaseConcurrencySlot() {
+	concurrencyLimiter.Release()
+}
+
+func releaseBatchConcurrencySlot() {
+	concurrencyLimiter.Release()
+	<-batchConcurrencyLimitCh
+}
+
+func logRequestCancelled(startTime time.Time, r *http.Request) {
+	remoteAddr := httpserver.GetQuotedRemoteAddr(r)
+	requestURI := httpserver.GetRequestURI(r)
+	logger.Infof("client has cancelled the request after %.3f seconds: remoteAddr=%s, requestURI: %q",
+		time.Since(startTime).Seconds(), remoteAddr, requestURI)
+}
+
+func sendQueueTimeoutError(w http.ResponseWriter, r *http.Request, d time.Duration, limitFlagName string, limit int) {
+	err := &httpserver.ErrorWithStatusCode{
+		Err: fmt.Errorf("couldn't start executing the request in %.3f seconds, since -%s=%d concurrent requests "+
+			"are executed. Possible solutions: to reduce query load; to add more compute resources to the server; "+
+			"to increase -search.maxQueueDuration=%s; to increase -search.maxQueryDuration; to increase -%s",
+			d.Seconds(), limitFlagName, limit, maxQueueDuration, limitFlagName),
+		StatusCode: http.StatusTooManyRequests,
+	}
+	w.Header().Add("Retry-After", "10")
+	httpserver.Errorf(w, r, "%s", err)
+}
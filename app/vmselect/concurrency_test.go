@@ -0,0 +1,163 @@
+package vmselect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetQueryPrioritySuccess(t *testing.T) {
+	f := func(requestURI, header, priorityExpected string) {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, requestURI, nil)
+		if header != "" {
+			r.Header.Set("X-Query-Priority", header)
+		}
+		priority, err := getQueryPriority(r.URL.Path, r)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if priority != priorityExpected {
+			t.Fatalf("unexpected priority; got %q; want %q", priority, priorityExpected)
+		}
+	}
+
+	f("/api/v1/query", "", queryPriorityInteractive)
+	f("/api/v1/query?priority=interactive", "", queryPriorityInteractive)
+	f("/api/v1/query?priority=batch", "", queryPriorityBatch)
+	f("/api/v1/query", "batch", queryPriorityBatch)
+
+	// query arg has priority over the header
+	f("/api/v1/query?priority=interactive", "batch", queryPriorityInteractive)
+
+	// priority is ignored for non-query endpoints
+	f("/api/v1/labels?priority=batch", "", queryPriorityInteractive)
+	f("/api/v1/status/tsdb", "foo", queryPriorityInteractive)
+}
+
+func TestGetQueryPriorityFailure(t *testing.T) {
+	f := func(requestURI, header string) {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, requestURI, nil)
+		if header != "" {
+			r.Header.Set("X-Query-Priority", header)
+		}
+		if _, err := getQueryPriority(r.URL.Path, r); err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+
+	f("/api/v1/query?priority=foo", "")
+	f("/api/v1/query", "high")
+}
+
+func TestAcquireConcurrencySlotInteractivePreemptsBatch(t *testing.T) {
+	concurrencyLimiterOrig := concurrencyLimiter
+	batchConcurrencyLimitChOrig := batchConcurrencyLimitCh
+	defer func() {
+		concurrencyLimiter = concurrencyLimiterOrig
+		batchConcurrencyLimitCh = batchConcurrencyLimitChOrig
+	}()
+	concurrencyLimiter = newPriorityLimiter(1)
+	batchConcurrencyLimitCh = make(chan struct{}, 1)
+
+	// Occupy the only slot.
+	if !concurrencyLimiter.TryAcquire(queryPriorityInteractive) {
+		t.Fatalf("cannot occupy the only slot")
+	}
+
+	var order []string
+	var orderLock sync.Mutex
+	var wg sync.WaitGroup
+	acquire := func(requestURI string) {
+		defer wg.Done()
+		r := httptest.NewRequest(http.MethodGet, requestURI, nil)
+		w := httptest.NewRecorder()
+		release, ok := acquireConcurrencySlot(nil, time.Now(), r.URL.Path, w, r)
+		orderLock.Lock()
+		if ok {
+			order = append(order, r.URL.Query().Get("priority"))
+		} else {
+			order = append(order, "error: "+w.Body.String())
+		}
+		orderLock.Unlock()
+		if ok {
+			release()
+		}
+	}
+
+	wg.Add(2)
+	go acquire("/api/v1/query?priority=batch")
+	for concurrencyLimiter.queuedBatch() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	go acquire("/api/v1/query?priority=interactive")
+	for concurrencyLimiter.queuedInteractive() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Free up the slot. It must be obtained by interactive request despite it was queued after the batch request.
+	concurrencyLimiter.Release()
+	wg.Wait()
+	if len(order) != 2 || order[0] != queryPriorityInteractive || order[1] != queryPriorityBatch {
+		t.Fatalf("unexpected order of executed requests; got %q; want %q", order, []string{queryPriorityInteractive, queryPriorityBatch})
+	}
+	if n := concurrencyLimiter.Current(); n != 0 {
+		t.Fatalf("unexpected number of occupied slots; got %d; want 0", n)
+	}
+	if n := len(batchConcurrencyLimitCh); n != 0 {
+		t.Fatalf("unexpected number of occupied batch slots; got %d; want 0", n)
+	}
+}
+
+func TestPriorityLimiterAcquireTimeout(t *testing.T) {
+	pl := newPriorityLimiter(1)
+	if !pl.TryAcquire(queryPriorityBatch) {
+		t.Fatalf("cannot obtain a free slot")
+	}
+	if pl.TryAcquire(queryPriorityInteractive) {
+		t.Fatalf("unexpected slot obtained above the capacity")
+	}
+
+	// Queued request must give up on timeout.
+	timeoutCh := make(chan time.Time, 1)
+	timeoutCh <- time.Now()
+	if pl.Acquire(queryPriorityInteractive, nil, timeoutCh) {
+		t.Fatalf("unexpected slot obtained after the timeout")
+	}
+	if n := pl.queuedInteractive(); n != 0 {
+		t.Fatalf("unexpected number of queued interactive requests; got %d; want 0", n)
+	}
+
+	// Queued request must give up on cancel.
+	stopCh := make(chan struct{})
+	close(stopCh)
+	if pl.Acquire(queryPriorityBatch, stopCh, nil) {
+		t.Fatalf("unexpected slot obtained after the cancel")
+	}
+	if n := pl.queuedBatch(); n != 0 {
+		t.Fatalf("unexpected number of queued batch requests; got %d; want 0", n)
+	}
+
+	// The released slot must be passed to the queued request.
+	resultCh := make(chan bool)
+	go func() {
+		resultCh <- pl.Acquire(queryPriorityBatch, nil, nil)
+	}()
+	for pl.queuedBatch() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	pl.Release()
+	if !<-resultCh {
+		t.Fatalf("cannot obtain the released slot")
+	}
+	if n := pl.Current(); n != 1 {
+		t.Fatalf("unexpected number of occupied slots; got %d; want 1", n)
+	}
+	pl.Release()
+	if n := pl.Current(); n != 0 {
+		t.Fatalf("unexpected number of occupied slots; got %d; want 0", n)
+	}
+}
@@ -2674,6 +2674,42 @@ func TestExecExprSuccess(t *testing.T) {
 			pathExpression: "foo.baz.host;tag1=value1;tag2=value2",
 		},
 	})
+	f(`toLowerCase(time('Foo.BaR'))`, []*series{
+		{
+			Timestamps:     []int64{120000, 180000},
+			Values:         []float64{120, 180},
+			Name:           "foo.bar",
+			Tags:           map[string]string{"name": "Foo.BaR"},
+			pathExpression: "Foo.BaR",
+		},
+	})
+	f(`lower(time('FOO.BAR'),0,-1,100)`, []*series{
+		{
+			Timestamps:     []int64{120000, 180000},
+			Values:         []float64{120, 180},
+			Name:           "fOO.BAr",
+			Tags:           map[string]string{"name": "FOO.BAR"},
+			pathExpression: "FOO.BAR",
+		},
+	})
+	f(`toUpperCase(time('foo.bar'))`, []*series{
+		{
+			Timestamps:     []int64{120000, 180000},
+			Values:         []float64{120, 180},
+			Name:           "FOO.BAR",
+			Tags:           map[string]string{"name": "foo.bar"},
+			pathExpression: "foo.bar",
+		},
+	})
+	f(`upper(time('foo.bar'),4,-2)`, []*series{
+		{
+			Timestamps:     []int64{120000, 180000},
+			Values:         []float64{120, 180},
+			Name:           "foo.BAr",
+			Tags:           map[string]string{"name": "foo.bar"},
+			pathExpression: "foo.bar",
+		},
+	})
 	f(`stdev(time('foo.baz',20),3,0.1)`, []*series{
 		{
 			Timestamps:     []int64{120000, 140000, 160000, 180000, 200000},
@@ -3818,6 +3854,10 @@ func TestExecExprFailure(t *testing.T) {
 	f(`substr()`)
 	f(`substr(time('a'),'foo')`)
 	f(`substr(time('a'),1,'foo')`)
+	f(`toLowerCase()`)
+	f(`toLowerCase(time('a'),'foo')`)
+	f(`toUpperCase()`)
+	f(`toUpperCase(time('a'),'foo')`)
 
 	f(`sumSeries(1)`)
 	f("sumSeries(time('a'),1)")
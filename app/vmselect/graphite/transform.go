@@ -12,6 +12,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmselect/graphiteql"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/cgroup"
@@ -103,6 +104,7 @@ func init() {
 		"log":                         transformLogarithm,
 		"logarithm":                   transformLogarithm,
 		"logit":                       transformLogit,
+		"lower":                       transformToLowerCase,
 		"lowest":                      transformLowest,
 		"lowestAverage":               transformLowestAverage,
 		"lowestCurrent":               transformLowestCurrent,
@@ -180,8 +182,11 @@ func init() {
 		"timeShift":               transformTimeShift,
 		"timeSlice":               transformTimeSlice,
 		"timeStack":               transformTimeStack,
+		"toLowerCase":             transformToLowerCase,
+		"toUpperCase":             transformToUpperCase,
 		"transformNull":           transformTransformNull,
 		"unique":                  transformUnique,
+		"upper":                   transformToUpperCase,
 		"useSeriesAbove":          transformUseSeriesAbove,
 		"verticalLine":            transformVerticalLine,
 		"weightedAverage":         transformWeightedAverage,
@@ -4563,6 +4568,58 @@ func transformSumSeries(ec *evalConfig, fe *graphiteql.FuncExpr) (nextSeriesFunc
 	return aggregateSeriesGeneric(ec, fe, "sum")
 }
 
+// https://graphite.readthedocs.io/en/stable/functions.html#graphite.render.functions.toLowerCase
+func transformToLowerCase(ec *evalConfig, fe *graphiteql.FuncExpr) (nextSeriesFunc, error) {
+	return transformChangeCase(ec, fe, unicode.ToLower)
+}
+
+// https://graphite.readthedocs.io/en/stable/functions.html#graphite.render.functions.toUpperCase
+func transformToUpperCase(ec *evalConfig, fe *graphiteql.FuncExpr) (nextSeriesFunc, error) {
+	return transformChangeCase(ec, fe, unicode.ToUpper)
+}
+
+func transformChangeCase(ec *evalConfig, fe *graphiteql.FuncExpr, changeCase func(r rune) rune) (nextSeriesFunc, error) {
+	args := fe.Args
+	if len(args) < 1 {
+		return nil, fmt.Errorf("unexpected number of args; got %d; want at least 1", len(args))
+	}
+	positions, err := getInts(args[1:], "pos")
+	if err != nil {
+		return nil, err
+	}
+	nextSeries, err := evalSeriesList(ec, args, "seriesList", 0)
+	if err != nil {
+		return nil, err
+	}
+	f := nextSeriesSerialWrapper(nextSeries, func(s *series) (*series, error) {
+		s.Name = changeNameCase(s.Name, positions, changeCase)
+		s.expr = fe
+		return s, nil
+	})
+	return f, nil
+}
+
+// changeNameCase applies changeCase to name letters at the given positions.
+//
+// changeCase is applied to all the letters if positions are empty.
+// Negative positions are counted from the end of name. Positions outside name are ignored.
+func changeNameCase(name string, positions []int, changeCase func(r rune) rune) string {
+	if len(positions) == 0 {
+		return strings.Map(changeCase, name)
+	}
+	rs := []rune(name)
+	for _, pos := range positions {
+		if pos < 0 {
+			pos += len(rs)
+		}
+		if pos < 0 || pos >= len(rs) {
+			continue
+		}
+		rs[pos] = changeCase(rs[pos])
+	}
+	return string(rs)
+}
+
 // https://graphite.readthedocs.io/en/stable/functions.html#graphite.render.functions.substr
 func transformSubstr(ec *evalConfig, fe *graphiteql.FuncExpr) (nextSeriesFunc, error) {
 	args := fe.Args
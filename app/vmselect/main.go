@@ -8,15 +8,17 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmselect/graphite"
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmselect/netstorage"
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmselect/prometheus"
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmselect/promql"
-	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmselect/searchutils"
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmstorage"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/buildinfo"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/cgroup"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/flagutil"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
@@ -25,7 +27,6 @@ import (
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/querytracer"
-	"github.com/VictoriaMetrics/VictoriaMetrics/lib/timerpool"
 	"github.com/VictoriaMetrics/metrics"
 )
 
@@ -37,6 +38,7 @@ var (
 	maxQueueDuration = flag.Duration("search.maxQueueDuration", 10*time.Second, "The maximum time the request waits for execution when -search.maxConcurrentRequests "+
 		"limit is reached; see also -search.maxQueryDuration")
 	resetCacheAuthKey    = flagutil.NewPassword("search.resetCacheAuthKey", "Optional authKey for resetting rollup cache via /internal/resetRollupResultCache call")
+	cancelQueryAuthKey   = flagutil.NewPassword("search.cancelQueryAuthKey", "Optional authKey for canceling active queries via /api/v1/status/active_queries/cancel call")
 	logSlowQueryDuration = flag.Duration("search.logSlowQueryDuration", 5*time.Second, "Log queries with execution time exceeding this value. Zero disables slow query logging. "+
 		"See also -search.logQueryMemoryUsage")
 	vmalertProxyURL = flag.String("vmalert.proxyURL", "", "Optional URL for proxying requests to vmalert. For example, if -vmalert.proxyURL=http://vmalert:8880 , then alerting API requests such as /api/v1/rules from Grafana will be proxied to http://vmalert:8880/api/v1/rules")
@@ -65,7 +67,7 @@ func Init() {
 	netstorage.InitTmpBlocksDir(tmpDirPath)
 	promql.InitRollupResultCache(*vmstorage.DataPath + "/cache/rollupResult")
 
-	concurrencyLimitCh = make(chan struct{}, *maxConcurrentRequests)
+	initConcurrencyLimits()
 	initVMAlertProxy()
 }
 
@@ -74,20 +76,6 @@ func Stop() {
 	promql.StopRollupResultCache()
 }
 
-var concurrencyLimitCh chan struct{}
-
-var (
-	concurrencyLimitReached = metrics.NewCounter(`vm_concurrent_select_limit_reached_total`)
-	concurrencyLimitTimeout = metrics.NewCounter(`vm_concurrent_select_limit_timeout_total`)
-
-	_ = metrics.NewGauge(`vm_concurrent_select_capacity`, func() float64 {
-		return float64(cap(concurrencyLimitCh))
-	})
-	_ = metrics.NewGauge(`vm_concurrent_select_current`, func() float64 {
-		return float64(len(concurrencyLimitCh))
-	})
-)
-
 //go:embed vmui
 var vmuiFiles embed.FS
 
@@ -118,45 +106,14 @@ func RequestHandler(w http.ResponseWriter, r *http.Request) bool {
 	qt := querytracer.New(tracerEnabled, r.URL.Path)
 
 	// Limit the number of concurrent queries.
-	select {
-	case concurrencyLimitCh <- struct{}{}:
-		defer func() { <-concurrencyLimitCh }()
-	default:
-		// Sleep for a while until giving up. This should resolve short bursts in requests.
-		concurrencyLimitReached.Inc()
-		d := searchutils.GetMaxQueryDuration(r)
-		if d > *maxQueueDuration {
-			d = *maxQueueDuration
-		}
-		t := timerpool.Get(d)
-		select {
-		case concurrencyLimitCh <- struct{}{}:
-			timerpool.Put(t)
-			qt.Printf("wait in queue because -search.maxConcurrentRequests=%d concurrent requests are executed", *maxConcurrentRequests)
-			defer func() { <-concurrencyLimitCh }()
-		case <-r.Context().Done():
-			timerpool.Put(t)
-			remoteAddr := httpserver.GetQuotedRemoteAddr(r)
-			requestURI := httpserver.GetRequestURI(r)
-			logger.Infof("client has cancelled the request after %.3f seconds: remoteAddr=%s, requestURI: %q",
-				time.Since(startTime).Seconds(), remoteAddr, requestURI)
-			return true
-		case <-t.C:
-			timerpool.Put(t)
-			concurrencyLimitTimeout.Inc()
-			err := &httpserver.ErrorWithStatusCode{
-				Err: fmt.Errorf("couldn't start executing the request in %.3f seconds, since -search.maxConcurrentRequests=%d concurrent requests "+
-					"are executed. Possible solutions: to reduce query load; to add more compute resources to the server; "+
-					"to increase -search.maxQueueDuration=%s; to increase -search.maxQueryDuration; to increase -search.maxConcurrentRequests",
-					d.Seconds(), *maxConcurrentRequests, maxQueueDuration),
-				StatusCode: http.StatusTooManyRequests,
-			}
-			w.Header().Add("Retry-After", "10")
-			httpserver.Errorf(w, r, "%s", err)
-			return true
-		}
+	release, ok := acquireConcurrencySlot(qt, startTime, path, w, r)
+	if !ok {
+		return true
 	}
+	defer release()
 
+	// qs is populated by /api/v1/query and /api/v1/query_range handlers.
+	qs := &promql.QueryStats{}
 	if *logSlowQueryDuration > 0 {
 		actualStartTime := time.Now()
 		defer func() {
@@ -164,15 +121,18 @@ func RequestHandler(w http.ResponseWriter, r *http.Request) bool {
 			if d >= *logSlowQueryDuration {
 				remoteAddr := httpserver.GetQuotedRemoteAddr(r)
 				requestURI := httpserver.GetRequestURI(r)
-				logger.Warnf("slow query according to -search.logSlowQueryDuration=%s: remoteAddr=%s, duration=%.3f seconds; requestURI: %q",
-					*logSlowQueryDuration, remoteAddr, d.Seconds(), requestURI)
+				seriesFetched := atomic.LoadInt64(&qs.SeriesFetched)
+				samplesScanned := atomic.LoadInt64(&qs.SamplesScanned)
+				logger.Warnf("slow query according to -search.logSlowQueryDuration=%s: remoteAddr=%s, duration=%.3f seconds, seriesFetched=%d, samplesScanned=%d; requestURI: %q",
+					*logSlowQueryDuration, remoteAddr, d.Seconds(), seriesFetched, samplesScanned, requestURI)
 				slowQueries.Inc()
 			}
 		}()
 	}
 
 	if path == "/internal/resetRollupResultCache" {
-		if !httpserver.CheckAuthFlag(w, r, resetCacheAuthKey.Get(), "resetCacheAuthKey") {
+		resetRollupResultCacheRequests.Inc()
+		if !httpserver.CheckAuthFlag(w, r, resetCacheAuthKey.Get(), "search.resetCacheAuthKey") {
 			return true
 		}
 		promql.ResetRollupResultCache()
@@ -208,7 +168,7 @@ func RequestHandler(w http.ResponseWriter, r *http.Request) bool {
 	case "/api/v1/query":
 		queryRequests.Inc()
 		httpserver.EnableCORS(w, r)
-		if err := prometheus.QueryHandler(qt, startTime, w, r); err != nil {
+		if err := prometheus.QueryHandler(qs, qt, startTime, w, r); err != nil {
 			queryErrors.Inc()
 			sendPrometheusError(w, r, err)
 			return true
@@ -217,7 +177,7 @@ func RequestHandler(w http.ResponseWriter, r *http.Request) bool {
 	case "/api/v1/query_range":
 		queryRangeRequests.Inc()
 		httpserver.EnableCORS(w, r)
-		if err := prometheus.QueryRangeHandler(qt, startTime, w, r); err != nil {
+		if err := prometheus.QueryRangeHandler(qs, qt, startTime, w, r); err != nil {
 			queryRangeErrors.Inc()
 			sendPrometheusError(w, r, err)
 			return true
@@ -235,7 +195,7 @@ func RequestHandler(w http.ResponseWriter, r *http.Request) bool {
 	case "/api/v1/series/count":
 		seriesCountRequests.Inc()
 		httpserver.EnableCORS(w, r)
-		if err := prometheus.SeriesCountHandler(startTime, w, r); err != nil {
+		if err := prometheus.SeriesCountHandler(qt, startTime, w, r); err != nil {
 			seriesCountErrors.Inc()
 			sendPrometheusError(w, r, err)
 			return true
@@ -283,6 +243,14 @@ func RequestHandler(w http.ResponseWriter, r *http.Request) bool {
 			return true
 		}
 		return true
+	case "/api/v1/read":
+		remoteReadRequests.Inc()
+		if err := prometheus.RemoteReadHandler(startTime, w, r); err != nil {
+			remoteReadErrors.Inc()
+			httpserver.Errorf(w, r, "%s", err)
+			return true
+		}
+		return true
 	case "/federate":
 		federateRequests.Inc()
 		if err := prometheus.FederateHandler(startTime, w, r); err != nil {
@@ -392,12 +360,11 @@ func RequestHandler(w http.ResponseWriter, r *http.Request) bool {
 			return true
 		}
 		deleteRequests.Inc()
-		if err := prometheus.DeleteHandler(startTime, r); err != nil {
+		if err := prometheus.DeleteHandler(startTime, w, r); err != nil {
 			deleteErrors.Inc()
 			httpserver.Errorf(w, r, "%s", err)
 			return true
 		}
-		w.WriteHeader(http.StatusNoContent)
 		return true
 	default:
 		return false
@@ -493,6 +460,28 @@ func handleStaticAndSimpleRequests(w http.ResponseWriter, r *http.Request, path
 		httpserver.EnableCORS(w, r)
 		promql.ActiveQueriesHandler(w, r)
 		return true
+	case "/api/v1/status/active_queries/cancel":
+		statusActiveQueriesCancelRequests.Inc()
+		// Canceling the query changes the state, so accept only POST requests without CORS
+		// in order to prevent from canceling queries by cross-origin pages.
+		if r.Method != http.MethodPost {
+			statusActiveQueriesCancelErrors.Inc()
+			w.Header().Set("Allow", http.MethodPost)
+			sendPrometheusError(w, r, &httpserver.ErrorWithStatusCode{
+				Err:        fmt.Errorf("unsupported method %q; use POST", r.Method),
+				StatusCode: http.StatusMethodNotAllowed,
+			})
+			return true
+		}
+		if !httpserver.CheckAuthFlag(w, r, cancelQueryAuthKey.Get(), "search.cancelQueryAuthKey") {
+			return true
+		}
+		if err := promql.CancelActiveQueryHandler(w, r); err != nil {
+			statusActiveQueriesCancelErrors.Inc()
+			sendPrometheusError(w, r, err)
+			return true
+		}
+		return true
 	case "/api/v1/status/top_queries":
 		topQueriesRequests.Inc()
 		httpserver.EnableCORS(w, r)
@@ -544,13 +533,18 @@ func handleStaticAndSimpleRequests(w http.ResponseWriter, r *http.Request, path
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprintf(w, "%s", `{"status":"success","data":{}}`)
 		return true
-	case "/api/v1/status/buildinfo":
-		buildInfoRequests.Inc()
+	case "/api/v1/status/buildinfo", "/api/v1/buildinfo":
+		if path == "/api/v1/buildinfo" {
+			buildInfoLegacyRequests.Inc()
+		} else {
+			buildInfoRequests.Inc()
+		}
 		w.Header().Set("Content-Type", "application/json")
 		// prometheus version is used here, which affects what API Grafana uses when retrieving label values.
 		// as new Grafana features are added that are customized for the Prometheus version, maybe the version will need to be increased.
 		// see this issue for more info: https://github.com/VictoriaMetrics/VictoriaMetrics/issues/5370
-		fmt.Fprintf(w, "%s", `{"status":"success","data":{"version":"2.24.0"}}`)
+		fmt.Fprintf(w, `{"status":"success","data":{"version":"2.24.0","revision":%q,"buildDate":%q,"goVersion":%q}}`,
+			buildinfo.Revision(), buildinfo.BuildDate(), runtime.Version())
 		return true
 	case "/api/v1/query_exemplars":
 		// Return dumb placeholder for https://prometheus.io/docs/prometheus/latest/querying/api/#querying-exemplars
@@ -617,8 +611,13 @@ var (
 	statusTSDBRequests = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/status/tsdb"}`)
 	statusTSDBErrors   = metrics.NewCounter(`vm_http_request_errors_total{path="/api/v1/status/tsdb"}`)
 
+	resetRollupResultCacheRequests = metrics.NewCounter(`vm_http_requests_total{path="/internal/resetRollupResultCache"}`)
+
 	statusActiveQueriesRequests = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/status/active_queries"}`)
 
+	statusActiveQueriesCancelRequests = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/status/active_queries/cancel"}`)
+	statusActiveQueriesCancelErrors   = metrics.NewCounter(`vm_http_request_errors_total{path="/api/v1/status/active_queries/cancel"}`)
+
 	topQueriesRequests = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/status/top_queries"}`)
 	topQueriesErrors   = metrics.NewCounter(`vm_http_request_errors_total{path="/api/v1/status/top_queries"}`)
 
@@ -634,6 +633,9 @@ var (
 	exportNativeRequests = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/export/native"}`)
 	exportNativeErrors   = metrics.NewCounter(`vm_http_request_errors_total{path="/api/v1/export/native"}`)
 
+	remoteReadRequests = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/read"}`)
+	remoteReadErrors   = metrics.NewCounter(`vm_http_request_errors_total{path="/api/v1/read"}`)
+
 	federateRequests = metrics.NewCounter(`vm_http_requests_total{path="/federate"}`)
 	federateErrors   = metrics.NewCounter(`vm_http_request_errors_total{path="/federate"}`)
 
@@ -690,8 +692,12 @@ var (
 	alertsRequests  = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/alerts"}`)
 
 	metadataRequests       = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/metadata"}`)
-	buildInfoRequests      = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/buildinfo"}`)
+	buildInfoRequests      = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/status/buildinfo"}`)
 	queryExemplarsRequests = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/query_exemplars"}`)
+
+	// buildInfoLegacyRequests counts requests to the legacy /api/v1/buildinfo path,
+	// which is served in the same way as /api/v1/status/buildinfo.
+	buildInfoLegacyRequests = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/buildinfo"}`)
 )
 
 func proxyVMAlertRequests(w http.ResponseWriter, r *http.Request) {
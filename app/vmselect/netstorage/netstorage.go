@@ -437,7 +437,7 @@ func (pts *packedTimeseries) Unpack(dst *Result, tbf *tmpBlocksFile, tr storage.
 		putSortBlocksHeap(sbh)
 		return err
 	}
-	dedupInterval := storage.GetDedupInterval()
+	dedupInterval := storage.GetDedupIntervalForTimeRange(tr)
 	mergeSortBlocks(dst, sbh, dedupInterval)
 	putSortBlocksHeap(sbh)
 	return nil
@@ -1000,7 +1000,7 @@ func ExportBlocks(qt *querytracer.Tracer, sq *storage.SearchQuery, deadline sear
 	sr := getStorageSearch()
 	defer putStorageSearch(sr)
 	startTime := time.Now()
-	sr.Init(qt, vmstorage.Storage, tfss, tr, sq.MaxMetrics, deadline.Deadline())
+	sr.Init(qt, vmstorage.Storage, tfss, tr, sq.MaxMetrics, deadline.Deadline(), deadline.CancelFlag())
 	indexSearchDuration.UpdateDuration(startTime)
 
 	// Start workers that call f in parallel on available CPU cores.
@@ -1115,6 +1115,60 @@ func SearchMetricNames(qt *querytracer.Tracer, sq *storage.SearchQuery, deadline
 	return metricNames, nil
 }
 
+// GetSeriesStats returns the number of series and samples matching sq until the given deadline.
+//
+// The number of samples is obtained from block headers without reading the blocks' data,
+// so it may include samples outside the time range from sq.
+func GetSeriesStats(qt *querytracer.Tracer, sq *storage.SearchQuery, deadline searchutils.Deadline) (int, int, error) {
+	qt = qt.NewChild("obtain stats for matching series: %s", sq)
+	defer qt.Done()
+	if deadline.Exceeded() {
+		return 0, 0, fmt.Errorf("timeout exceeded before starting the query processing: %s", deadline.String())
+	}
+
+	// Setup search.
+	tr := sq.GetTimeRange()
+	if err := vmstorage.CheckTimeRange(tr); err != nil {
+		return 0, 0, err
+	}
+	tfss, err := setupTfss(qt, tr, sq.TagFilterss, sq.MaxMetrics, deadline)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	vmstorage.WG.Add(1)
+	defer vmstorage.WG.Done()
+
+	sr := getStorageSearch()
+	defer putStorageSearch(sr)
+	maxSeriesCount := sr.Init(qt, vmstorage.Storage, tfss, tr, sq.MaxMetrics, deadline.Deadline(), deadline.CancelFlag())
+
+	blocksRead := 0
+	samples := 0
+	m := make(map[string]struct{}, maxSeriesCount)
+	var metricNamePrev []byte
+	for sr.NextMetricBlock() {
+		blocksRead++
+		if deadline.Exceeded() {
+			return 0, 0, fmt.Errorf("timeout exceeded while fetching data block #%d from storage: %s", blocksRead, deadline.String())
+		}
+		samples += sr.MetricBlockRef.BlockRef.RowsCount()
+		metricName := sr.MetricBlockRef.MetricName
+		if metricNamePrev == nil || string(metricName) != string(metricNamePrev) {
+			m[string(metricName)] = struct{}{}
+			metricNamePrev = append(metricNamePrev[:0], metricName...)
+		}
+	}
+	if err := sr.Error(); err != nil {
+		if errors.Is(err, storage.ErrDeadlineExceeded) {
+			return 0, 0, fmt.Errorf("timeout exceeded during the query: %s", deadline.String())
+		}
+		return 0, 0, fmt.Errorf("search error after reading %d data blocks: %w", blocksRead, err)
+	}
+	qt.Printf("found unique series=%d, blocks=%d, samples=%d", len(m), blocksRead, samples)
+	return len(m), samples, nil
+}
+
 // ProcessSearchQuery performs sq until the given deadline.
 //
 // Results.RunParallel or Results.Cancel must be called on the returned Results.
@@ -1140,7 +1194,7 @@ func ProcessSearchQuery(qt *querytracer.Tracer, sq *storage.SearchQuery, deadlin
 
 	sr := getStorageSearch()
 	startTime := time.Now()
-	maxSeriesCount := sr.Init(qt, vmstorage.Storage, tfss, tr, sq.MaxMetrics, deadline.Deadline())
+	maxSeriesCount := sr.Init(qt, vmstorage.Storage, tfss, tr, sq.MaxMetrics, deadline.Deadline(), deadline.CancelFlag())
 	indexSearchDuration.UpdateDuration(startTime)
 	type blockRefs struct {
 		brs []blockRef
@@ -0,0 +1,61 @@
+// Code generated by qtc from "delete_series_response.qtpl". DO NOT EDIT.
+// See https://github.com/valyala/quicktemplate for details.
+
+// DeleteSeriesDryRunResponse generates response for /api/v1/admin/tsdb/delete_series?dry_run=1 .
+
+//line app/vmselect/prometheus/delete_series_response.qtpl:4
+package prometheus
+
+//line app/vmselect/prometheus/delete_series_response.qtpl:4
+import (
+	qtio422016 "io"
+
+	qt422016 "github.com/valyala/quicktemplate"
+)
+
+//line app/vmselect/prometheus/delete_series_response.qtpl:4
+var (
+	_ = qtio422016.Copy
+	_ = qt422016.AcquireByteBuffer
+)
+
+//line app/vmselect/prometheus/delete_series_response.qtpl:4
+func StreamDeleteSeriesDryRunResponse(qw422016 *qt422016.Writer, seriesCount, samplesCount int) {
+//line app/vmselect/prometheus/delete_series_response.qtpl:4
+	qw422016.N().S(`{"status":"success","data":{"seriesCount":`)
+//line app/vmselect/prometheus/delete_series_response.qtpl:8
+	qw422016.N().D(seriesCount)
+//line app/vmselect/prometheus/delete_series_response.qtpl:8
+	qw422016.N().S(`,"samplesCount":`)
+//line app/vmselect/prometheus/delete_series_response.qtpl:9
+	qw422016.N().D(samplesCount)
+//line app/vmselect/prometheus/delete_series_response.qtpl:9
+	qw422016.N().S(`}}`)
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+}
+
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+func WriteDeleteSeriesDryRunResponse(qq422016 qtio422016.Writer, seriesCount, samplesCount int) {
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+	StreamDeleteSeriesDryRunResponse(qw422016, seriesCount, samplesCount)
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+	qt422016.ReleaseWriter(qw422016)
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+}
+
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+func DeleteSeriesDryRunResponse(seriesCount, samplesCount int) string {
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+	qb422016 := qt422016.AcquireByteBuffer()
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+	WriteDeleteSeriesDryRunResponse(qb422016, seriesCount, samplesCount)
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+	qs422016 := string(qb422016.B)
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+	qt422016.ReleaseByteBuffer(qb422016)
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+	return qs422016
+//line app/vmselect/prometheus/delete_series_response.qtpl:12
+}
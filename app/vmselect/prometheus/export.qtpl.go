@@ -120,553 +120,583 @@ func streamexportCSVField(qw422016 *qt422016.Writer, mn *storage.MetricName, fie
 //line app/vmselect/prometheus/export.qtpl:42
 			qw422016.N().DL(timestamp)
 //line app/vmselect/prometheus/export.qtpl:43
-		case "unix_ns":
+		case "unix_us":
 //line app/vmselect/prometheus/export.qtpl:44
-			qw422016.N().DL(timestamp * 1e6)
+			qw422016.N().DL(timestamp * 1e3)
 //line app/vmselect/prometheus/export.qtpl:45
-		case "rfc3339":
+		case "unix_ns":
+//line app/vmselect/prometheus/export.qtpl:46
+			qw422016.N().DL(timestamp * 1e6)
 //line app/vmselect/prometheus/export.qtpl:47
+		case "rfc3339":
+//line app/vmselect/prometheus/export.qtpl:49
 			bb := quicktemplate.AcquireByteBuffer()
 			bb.B = time.Unix(timestamp/1000, (timestamp%1000)*1e6).AppendFormat(bb.B[:0], time.RFC3339)
 
-//line app/vmselect/prometheus/export.qtpl:50
-			qw422016.N().Z(bb.B)
 //line app/vmselect/prometheus/export.qtpl:52
+			qw422016.N().Z(bb.B)
+//line app/vmselect/prometheus/export.qtpl:54
 			quicktemplate.ReleaseByteBuffer(bb)
 
-//line app/vmselect/prometheus/export.qtpl:54
+//line app/vmselect/prometheus/export.qtpl:56
 		default:
-//line app/vmselect/prometheus/export.qtpl:55
-			if strings.HasPrefix(timeFormat, "custom:") {
 //line app/vmselect/prometheus/export.qtpl:57
+			if strings.HasPrefix(timeFormat, "custom:") {
+//line app/vmselect/prometheus/export.qtpl:59
 				layout := timeFormat[len("custom:"):]
 				bb := quicktemplate.AcquireByteBuffer()
 				bb.B = time.Unix(timestamp/1000, (timestamp%1000)*1e6).AppendFormat(bb.B[:0], layout)
 
-//line app/vmselect/prometheus/export.qtpl:61
-				if bytes.ContainsAny(bb.B, `"`+",\n") {
-//line app/vmselect/prometheus/export.qtpl:62
-					qw422016.E().QZ(bb.B)
 //line app/vmselect/prometheus/export.qtpl:63
-				} else {
-//line app/vmselect/prometheus/export.qtpl:64
-					qw422016.N().Z(bb.B)
+				streamexportCSVValue(qw422016, bb.B)
 //line app/vmselect/prometheus/export.qtpl:65
-				}
-//line app/vmselect/prometheus/export.qtpl:67
 				quicktemplate.ReleaseByteBuffer(bb)
 
-//line app/vmselect/prometheus/export.qtpl:69
+//line app/vmselect/prometheus/export.qtpl:67
 			} else {
-//line app/vmselect/prometheus/export.qtpl:69
+//line app/vmselect/prometheus/export.qtpl:67
 				qw422016.N().S(`Unsupported timeFormat=`)
-//line app/vmselect/prometheus/export.qtpl:70
+//line app/vmselect/prometheus/export.qtpl:68
 				qw422016.N().S(timeFormat)
-//line app/vmselect/prometheus/export.qtpl:71
+//line app/vmselect/prometheus/export.qtpl:69
 			}
-//line app/vmselect/prometheus/export.qtpl:72
+//line app/vmselect/prometheus/export.qtpl:70
 		}
-//line app/vmselect/prometheus/export.qtpl:73
+//line app/vmselect/prometheus/export.qtpl:71
 		return
-//line app/vmselect/prometheus/export.qtpl:74
-	}
-//line app/vmselect/prometheus/export.qtpl:75
-	v := mn.GetTagValue(fieldName)
-
-//line app/vmselect/prometheus/export.qtpl:76
-	if bytes.ContainsAny(v, `"`+",\n") {
-//line app/vmselect/prometheus/export.qtpl:77
-		qw422016.N().QZ(v)
-//line app/vmselect/prometheus/export.qtpl:78
-	} else {
-//line app/vmselect/prometheus/export.qtpl:79
-		qw422016.N().Z(v)
-//line app/vmselect/prometheus/export.qtpl:80
+//line app/vmselect/prometheus/export.qtpl:72
 	}
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:73
+	streamexportCSVValue(qw422016, mn.GetTagValue(fieldName))
+//line app/vmselect/prometheus/export.qtpl:74
 }
 
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 func writeexportCSVField(qq422016 qtio422016.Writer, mn *storage.MetricName, fieldName string, timestamp int64, value float64) {
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 	streamexportCSVField(qw422016, mn, fieldName, timestamp, value)
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 }
 
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 func exportCSVField(mn *storage.MetricName, fieldName string, timestamp int64, value float64) string {
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 	writeexportCSVField(qb422016, mn, fieldName, timestamp, value)
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 	qs422016 := string(qb422016.B)
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 	return qs422016
-//line app/vmselect/prometheus/export.qtpl:81
+//line app/vmselect/prometheus/export.qtpl:74
 }
 
+//line app/vmselect/prometheus/export.qtpl:80
+func streamexportCSVValue(qw422016 *qt422016.Writer, v []byte) {
+//line app/vmselect/prometheus/export.qtpl:81
+	if !bytes.ContainsAny(v, `"`+",\r\n") {
+//line app/vmselect/prometheus/export.qtpl:82
+		qw422016.N().Z(v)
 //line app/vmselect/prometheus/export.qtpl:83
-func StreamExportPrometheusLine(qw422016 *qt422016.Writer, xb *exportBlock) {
-//line app/vmselect/prometheus/export.qtpl:84
-	if len(xb.timestamps) == 0 {
-//line app/vmselect/prometheus/export.qtpl:84
 		return
 //line app/vmselect/prometheus/export.qtpl:84
 	}
+//line app/vmselect/prometheus/export.qtpl:84
+	qw422016.N().S(`"`)
 //line app/vmselect/prometheus/export.qtpl:85
-	bb := quicktemplate.AcquireByteBuffer()
+	qw422016.N().Z(bytes.ReplaceAll(v, []byte(`"`), []byte(`""`)))
+//line app/vmselect/prometheus/export.qtpl:85
+	qw422016.N().S(`"`)
+//line app/vmselect/prometheus/export.qtpl:86
+}
 
 //line app/vmselect/prometheus/export.qtpl:86
+func writeexportCSVValue(qq422016 qtio422016.Writer, v []byte) {
+//line app/vmselect/prometheus/export.qtpl:86
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line app/vmselect/prometheus/export.qtpl:86
+	streamexportCSVValue(qw422016, v)
+//line app/vmselect/prometheus/export.qtpl:86
+	qt422016.ReleaseWriter(qw422016)
+//line app/vmselect/prometheus/export.qtpl:86
+}
+
+//line app/vmselect/prometheus/export.qtpl:86
+func exportCSVValue(v []byte) string {
+//line app/vmselect/prometheus/export.qtpl:86
+	qb422016 := qt422016.AcquireByteBuffer()
+//line app/vmselect/prometheus/export.qtpl:86
+	writeexportCSVValue(qb422016, v)
+//line app/vmselect/prometheus/export.qtpl:86
+	qs422016 := string(qb422016.B)
+//line app/vmselect/prometheus/export.qtpl:86
+	qt422016.ReleaseByteBuffer(qb422016)
+//line app/vmselect/prometheus/export.qtpl:86
+	return qs422016
+//line app/vmselect/prometheus/export.qtpl:86
+}
+
+//line app/vmselect/prometheus/export.qtpl:88
+func StreamExportPrometheusLine(qw422016 *qt422016.Writer, xb *exportBlock) {
+//line app/vmselect/prometheus/export.qtpl:89
+	if len(xb.timestamps) == 0 {
+//line app/vmselect/prometheus/export.qtpl:89
+		return
+//line app/vmselect/prometheus/export.qtpl:89
+	}
+//line app/vmselect/prometheus/export.qtpl:90
+	bb := quicktemplate.AcquireByteBuffer()
+
+//line app/vmselect/prometheus/export.qtpl:91
 	writeprometheusMetricName(bb, xb.mn)
 
-//line app/vmselect/prometheus/export.qtpl:87
+//line app/vmselect/prometheus/export.qtpl:92
 	for i, ts := range xb.timestamps {
-//line app/vmselect/prometheus/export.qtpl:88
+//line app/vmselect/prometheus/export.qtpl:93
 		qw422016.N().Z(bb.B)
-//line app/vmselect/prometheus/export.qtpl:88
+//line app/vmselect/prometheus/export.qtpl:93
 		qw422016.N().S(` `)
-//line app/vmselect/prometheus/export.qtpl:89
+//line app/vmselect/prometheus/export.qtpl:94
 		qw422016.N().F(xb.values[i])
-//line app/vmselect/prometheus/export.qtpl:89
+//line app/vmselect/prometheus/export.qtpl:94
 		qw422016.N().S(` `)
-//line app/vmselect/prometheus/export.qtpl:90
+//line app/vmselect/prometheus/export.qtpl:95
 		qw422016.N().DL(ts)
-//line app/vmselect/prometheus/export.qtpl:90
+//line app/vmselect/prometheus/export.qtpl:95
 		qw422016.N().S(`
 `)
-//line app/vmselect/prometheus/export.qtpl:91
+//line app/vmselect/prometheus/export.qtpl:96
 	}
-//line app/vmselect/prometheus/export.qtpl:92
+//line app/vmselect/prometheus/export.qtpl:97
 	quicktemplate.ReleaseByteBuffer(bb)
 
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 }
 
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 func WriteExportPrometheusLine(qq422016 qtio422016.Writer, xb *exportBlock) {
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 	StreamExportPrometheusLine(qw422016, xb)
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 }
 
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 func ExportPrometheusLine(xb *exportBlock) string {
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 	WriteExportPrometheusLine(qb422016, xb)
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 	qs422016 := string(qb422016.B)
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 	return qs422016
-//line app/vmselect/prometheus/export.qtpl:93
+//line app/vmselect/prometheus/export.qtpl:98
 }
 
-//line app/vmselect/prometheus/export.qtpl:95
+//line app/vmselect/prometheus/export.qtpl:100
 func StreamExportJSONLine(qw422016 *qt422016.Writer, xb *exportBlock) {
-//line app/vmselect/prometheus/export.qtpl:96
+//line app/vmselect/prometheus/export.qtpl:101
 	if len(xb.timestamps) == 0 {
-//line app/vmselect/prometheus/export.qtpl:96
+//line app/vmselect/prometheus/export.qtpl:101
 		return
-//line app/vmselect/prometheus/export.qtpl:96
+//line app/vmselect/prometheus/export.qtpl:101
 	}
-//line app/vmselect/prometheus/export.qtpl:96
+//line app/vmselect/prometheus/export.qtpl:101
 	qw422016.N().S(`{"metric":`)
-//line app/vmselect/prometheus/export.qtpl:98
+//line app/vmselect/prometheus/export.qtpl:103
 	streammetricNameObject(qw422016, xb.mn)
-//line app/vmselect/prometheus/export.qtpl:98
+//line app/vmselect/prometheus/export.qtpl:103
 	qw422016.N().S(`,"values":[`)
-//line app/vmselect/prometheus/export.qtpl:100
+//line app/vmselect/prometheus/export.qtpl:105
 	if len(xb.values) > 0 {
-//line app/vmselect/prometheus/export.qtpl:101
+//line app/vmselect/prometheus/export.qtpl:106
 		values := xb.values
 
-//line app/vmselect/prometheus/export.qtpl:102
+//line app/vmselect/prometheus/export.qtpl:107
 		streamconvertValueToSpecialJSON(qw422016, values[0])
-//line app/vmselect/prometheus/export.qtpl:103
+//line app/vmselect/prometheus/export.qtpl:108
 		values = values[1:]
 
-//line app/vmselect/prometheus/export.qtpl:104
+//line app/vmselect/prometheus/export.qtpl:109
 		for _, v := range values {
-//line app/vmselect/prometheus/export.qtpl:104
+//line app/vmselect/prometheus/export.qtpl:109
 			qw422016.N().S(`,`)
-//line app/vmselect/prometheus/export.qtpl:105
+//line app/vmselect/prometheus/export.qtpl:110
 			streamconvertValueToSpecialJSON(qw422016, v)
-//line app/vmselect/prometheus/export.qtpl:106
+//line app/vmselect/prometheus/export.qtpl:111
 		}
-//line app/vmselect/prometheus/export.qtpl:107
+//line app/vmselect/prometheus/export.qtpl:112
 	}
-//line app/vmselect/prometheus/export.qtpl:107
+//line app/vmselect/prometheus/export.qtpl:112
 	qw422016.N().S(`],"timestamps":[`)
-//line app/vmselect/prometheus/export.qtpl:110
+//line app/vmselect/prometheus/export.qtpl:115
 	if len(xb.timestamps) > 0 {
-//line app/vmselect/prometheus/export.qtpl:111
+//line app/vmselect/prometheus/export.qtpl:116
 		timestamps := xb.timestamps
 
-//line app/vmselect/prometheus/export.qtpl:112
+//line app/vmselect/prometheus/export.qtpl:117
 		qw422016.N().DL(timestamps[0])
-//line app/vmselect/prometheus/export.qtpl:113
+//line app/vmselect/prometheus/export.qtpl:118
 		timestamps = timestamps[1:]
 
-//line app/vmselect/prometheus/export.qtpl:114
+//line app/vmselect/prometheus/export.qtpl:119
 		for _, ts := range timestamps {
-//line app/vmselect/prometheus/export.qtpl:114
+//line app/vmselect/prometheus/export.qtpl:119
 			qw422016.N().S(`,`)
-//line app/vmselect/prometheus/export.qtpl:115
+//line app/vmselect/prometheus/export.qtpl:120
 			qw422016.N().DL(ts)
-//line app/vmselect/prometheus/export.qtpl:116
+//line app/vmselect/prometheus/export.qtpl:121
 		}
-//line app/vmselect/prometheus/export.qtpl:117
+//line app/vmselect/prometheus/export.qtpl:122
 	}
-//line app/vmselect/prometheus/export.qtpl:117
+//line app/vmselect/prometheus/export.qtpl:122
 	qw422016.N().S(`]}`)
-//line app/vmselect/prometheus/export.qtpl:119
+//line app/vmselect/prometheus/export.qtpl:124
 	qw422016.N().S(`
 `)
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 }
 
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 func WriteExportJSONLine(qq422016 qtio422016.Writer, xb *exportBlock) {
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 	StreamExportJSONLine(qw422016, xb)
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 }
 
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 func ExportJSONLine(xb *exportBlock) string {
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 	WriteExportJSONLine(qb422016, xb)
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 	qs422016 := string(qb422016.B)
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 	return qs422016
-//line app/vmselect/prometheus/export.qtpl:120
+//line app/vmselect/prometheus/export.qtpl:125
 }
 
-//line app/vmselect/prometheus/export.qtpl:122
+//line app/vmselect/prometheus/export.qtpl:127
 func StreamExportPromAPILine(qw422016 *qt422016.Writer, xb *exportBlock) {
-//line app/vmselect/prometheus/export.qtpl:122
+//line app/vmselect/prometheus/export.qtpl:127
 	qw422016.N().S(`{"metric":`)
-//line app/vmselect/prometheus/export.qtpl:124
+//line app/vmselect/prometheus/export.qtpl:129
 	streammetricNameObject(qw422016, xb.mn)
-//line app/vmselect/prometheus/export.qtpl:124
+//line app/vmselect/prometheus/export.qtpl:129
 	qw422016.N().S(`,"values":`)
-//line app/vmselect/prometheus/export.qtpl:125
+//line app/vmselect/prometheus/export.qtpl:130
 	streamvaluesWithTimestamps(qw422016, xb.values, xb.timestamps)
-//line app/vmselect/prometheus/export.qtpl:125
+//line app/vmselect/prometheus/export.qtpl:130
 	qw422016.N().S(`}`)
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 }
 
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 func WriteExportPromAPILine(qq422016 qtio422016.Writer, xb *exportBlock) {
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 	StreamExportPromAPILine(qw422016, xb)
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 }
 
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 func ExportPromAPILine(xb *exportBlock) string {
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 	WriteExportPromAPILine(qb422016, xb)
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 	qs422016 := string(qb422016.B)
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 	return qs422016
-//line app/vmselect/prometheus/export.qtpl:127
+//line app/vmselect/prometheus/export.qtpl:132
 }
 
-//line app/vmselect/prometheus/export.qtpl:129
+//line app/vmselect/prometheus/export.qtpl:134
 func StreamExportPromAPIHeader(qw422016 *qt422016.Writer) {
-//line app/vmselect/prometheus/export.qtpl:129
+//line app/vmselect/prometheus/export.qtpl:134
 	qw422016.N().S(`{"status":"success","data":{"resultType":"matrix","result":[`)
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 }
 
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 func WriteExportPromAPIHeader(qq422016 qtio422016.Writer) {
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 	StreamExportPromAPIHeader(qw422016)
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 }
 
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 func ExportPromAPIHeader() string {
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 	WriteExportPromAPIHeader(qb422016)
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 	qs422016 := string(qb422016.B)
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 	return qs422016
-//line app/vmselect/prometheus/export.qtpl:135
+//line app/vmselect/prometheus/export.qtpl:140
 }
 
-//line app/vmselect/prometheus/export.qtpl:137
+//line app/vmselect/prometheus/export.qtpl:142
 func StreamExportPromAPIFooter(qw422016 *qt422016.Writer, qt *querytracer.Tracer) {
-//line app/vmselect/prometheus/export.qtpl:137
+//line app/vmselect/prometheus/export.qtpl:142
 	qw422016.N().S(`]}`)
-//line app/vmselect/prometheus/export.qtpl:141
+//line app/vmselect/prometheus/export.qtpl:146
 	qt.Donef("export format=promapi")
 
-//line app/vmselect/prometheus/export.qtpl:143
+//line app/vmselect/prometheus/export.qtpl:148
 	streamdumpQueryTrace(qw422016, qt)
-//line app/vmselect/prometheus/export.qtpl:143
+//line app/vmselect/prometheus/export.qtpl:148
 	qw422016.N().S(`}`)
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 }
 
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 func WriteExportPromAPIFooter(qq422016 qtio422016.Writer, qt *querytracer.Tracer) {
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 	StreamExportPromAPIFooter(qw422016, qt)
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 }
 
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 func ExportPromAPIFooter(qt *querytracer.Tracer) string {
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 	WriteExportPromAPIFooter(qb422016, qt)
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 	qs422016 := string(qb422016.B)
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 	return qs422016
-//line app/vmselect/prometheus/export.qtpl:145
+//line app/vmselect/prometheus/export.qtpl:150
 }
 
-//line app/vmselect/prometheus/export.qtpl:147
+//line app/vmselect/prometheus/export.qtpl:152
 func streamprometheusMetricName(qw422016 *qt422016.Writer, mn *storage.MetricName) {
-//line app/vmselect/prometheus/export.qtpl:148
+//line app/vmselect/prometheus/export.qtpl:153
 	qw422016.N().Z(mn.MetricGroup)
-//line app/vmselect/prometheus/export.qtpl:149
+//line app/vmselect/prometheus/export.qtpl:154
 	if len(mn.Tags) > 0 {
-//line app/vmselect/prometheus/export.qtpl:149
+//line app/vmselect/prometheus/export.qtpl:154
 		qw422016.N().S(`{`)
-//line app/vmselect/prometheus/export.qtpl:151
+//line app/vmselect/prometheus/export.qtpl:156
 		tags := mn.Tags
 
-//line app/vmselect/prometheus/export.qtpl:152
+//line app/vmselect/prometheus/export.qtpl:157
 		qw422016.N().Z(tags[0].Key)
-//line app/vmselect/prometheus/export.qtpl:152
+//line app/vmselect/prometheus/export.qtpl:157
 		qw422016.N().S(`=`)
-//line app/vmselect/prometheus/export.qtpl:152
+//line app/vmselect/prometheus/export.qtpl:157
 		streamescapePrometheusLabel(qw422016, tags[0].Value)
-//line app/vmselect/prometheus/export.qtpl:153
+//line app/vmselect/prometheus/export.qtpl:158
 		tags = tags[1:]
 
-//line app/vmselect/prometheus/export.qtpl:154
+//line app/vmselect/prometheus/export.qtpl:159
 		for i := range tags {
-//line app/vmselect/prometheus/export.qtpl:155
+//line app/vmselect/prometheus/export.qtpl:160
 			tag := &tags[i]
 
-//line app/vmselect/prometheus/export.qtpl:155
+//line app/vmselect/prometheus/export.qtpl:160
 			qw422016.N().S(`,`)
-//line app/vmselect/prometheus/export.qtpl:156
+//line app/vmselect/prometheus/export.qtpl:161
 			qw422016.N().Z(tag.Key)
-//line app/vmselect/prometheus/export.qtpl:156
+//line app/vmselect/prometheus/export.qtpl:161
 			qw422016.N().S(`=`)
-//line app/vmselect/prometheus/export.qtpl:156
+//line app/vmselect/prometheus/export.qtpl:161
 			streamescapePrometheusLabel(qw422016, tag.Value)
-//line app/vmselect/prometheus/export.qtpl:157
+//line app/vmselect/prometheus/export.qtpl:162
 		}
-//line app/vmselect/prometheus/export.qtpl:157
+//line app/vmselect/prometheus/export.qtpl:162
 		qw422016.N().S(`}`)
-//line app/vmselect/prometheus/export.qtpl:159
+//line app/vmselect/prometheus/export.qtpl:164
 	}
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 }
 
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 func writeprometheusMetricName(qq422016 qtio422016.Writer, mn *storage.MetricName) {
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 	streamprometheusMetricName(qw422016, mn)
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 }
 
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 func prometheusMetricName(mn *storage.MetricName) string {
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 	writeprometheusMetricName(qb422016, mn)
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 	qs422016 := string(qb422016.B)
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 	return qs422016
-//line app/vmselect/prometheus/export.qtpl:160
+//line app/vmselect/prometheus/export.qtpl:165
 }
 
-//line app/vmselect/prometheus/export.qtpl:162
+//line app/vmselect/prometheus/export.qtpl:167
 func streamconvertValueToSpecialJSON(qw422016 *qt422016.Writer, v float64) {
-//line app/vmselect/prometheus/export.qtpl:163
+//line app/vmselect/prometheus/export.qtpl:168
 	if math.IsNaN(v) {
-//line app/vmselect/prometheus/export.qtpl:163
+//line app/vmselect/prometheus/export.qtpl:168
 		qw422016.N().S(`null`)
-//line app/vmselect/prometheus/export.qtpl:165
+//line app/vmselect/prometheus/export.qtpl:170
 	} else if math.IsInf(v, 0) {
-//line app/vmselect/prometheus/export.qtpl:166
+//line app/vmselect/prometheus/export.qtpl:171
 		if v > 0 {
-//line app/vmselect/prometheus/export.qtpl:166
+//line app/vmselect/prometheus/export.qtpl:171
 			qw422016.N().S(`"Infinity"`)
-//line app/vmselect/prometheus/export.qtpl:168
+//line app/vmselect/prometheus/export.qtpl:173
 		} else {
-//line app/vmselect/prometheus/export.qtpl:168
+//line app/vmselect/prometheus/export.qtpl:173
 			qw422016.N().S(`"-Infinity"`)
-//line app/vmselect/prometheus/export.qtpl:170
+//line app/vmselect/prometheus/export.qtpl:175
 		}
-//line app/vmselect/prometheus/export.qtpl:171
+//line app/vmselect/prometheus/export.qtpl:176
 	} else {
-//line app/vmselect/prometheus/export.qtpl:172
+//line app/vmselect/prometheus/export.qtpl:177
 		qw422016.N().F(v)
-//line app/vmselect/prometheus/export.qtpl:173
+//line app/vmselect/prometheus/export.qtpl:178
 	}
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 }
 
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 func writeconvertValueToSpecialJSON(qq422016 qtio422016.Writer, v float64) {
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 	streamconvertValueToSpecialJSON(qw422016, v)
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 }
 
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 func convertValueToSpecialJSON(v float64) string {
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 	writeconvertValueToSpecialJSON(qb422016, v)
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 	qs422016 := string(qb422016.B)
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 	return qs422016
-//line app/vmselect/prometheus/export.qtpl:174
+//line app/vmselect/prometheus/export.qtpl:179
 }
 
-//line app/vmselect/prometheus/export.qtpl:176
+//line app/vmselect/prometheus/export.qtpl:181
 func streamescapePrometheusLabel(qw422016 *qt422016.Writer, b []byte) {
-//line app/vmselect/prometheus/export.qtpl:176
+//line app/vmselect/prometheus/export.qtpl:181
 	qw422016.N().S(`"`)
-//line app/vmselect/prometheus/export.qtpl:178
+//line app/vmselect/prometheus/export.qtpl:183
 	for len(b) > 0 {
-//line app/vmselect/prometheus/export.qtpl:179
+//line app/vmselect/prometheus/export.qtpl:184
 		n := bytes.IndexAny(b, "\\\n\"")
 
-//line app/vmselect/prometheus/export.qtpl:180
+//line app/vmselect/prometheus/export.qtpl:185
 		if n < 0 {
-//line app/vmselect/prometheus/export.qtpl:181
+//line app/vmselect/prometheus/export.qtpl:186
 			qw422016.N().Z(b)
-//line app/vmselect/prometheus/export.qtpl:182
+//line app/vmselect/prometheus/export.qtpl:187
 			break
-//line app/vmselect/prometheus/export.qtpl:183
+//line app/vmselect/prometheus/export.qtpl:188
 		}
-//line app/vmselect/prometheus/export.qtpl:184
+//line app/vmselect/prometheus/export.qtpl:189
 		qw422016.N().Z(b[:n])
-//line app/vmselect/prometheus/export.qtpl:185
+//line app/vmselect/prometheus/export.qtpl:190
 		switch b[n] {
-//line app/vmselect/prometheus/export.qtpl:186
+//line app/vmselect/prometheus/export.qtpl:191
 		case '\\':
-//line app/vmselect/prometheus/export.qtpl:186
+//line app/vmselect/prometheus/export.qtpl:191
 			qw422016.N().S(`\\`)
-//line app/vmselect/prometheus/export.qtpl:188
+//line app/vmselect/prometheus/export.qtpl:193
 		case '\n':
-//line app/vmselect/prometheus/export.qtpl:188
+//line app/vmselect/prometheus/export.qtpl:193
 			qw422016.N().S(`\n`)
-//line app/vmselect/prometheus/export.qtpl:190
+//line app/vmselect/prometheus/export.qtpl:195
 		case '"':
-//line app/vmselect/prometheus/export.qtpl:190
+//line app/vmselect/prometheus/export.qtpl:195
 			qw422016.N().S(`\"`)
-//line app/vmselect/prometheus/export.qtpl:192
+//line app/vmselect/prometheus/export.qtpl:197
 		}
-//line app/vmselect/prometheus/export.qtpl:193
+//line app/vmselect/prometheus/export.qtpl:198
 		b = b[n+1:]
 
-//line app/vmselect/prometheus/export.qtpl:194
+//line app/vmselect/prometheus/export.qtpl:199
 	}
-//line app/vmselect/prometheus/export.qtpl:194
+//line app/vmselect/prometheus/export.qtpl:199
 	qw422016.N().S(`"`)
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 }
 
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 func writeescapePrometheusLabel(qq422016 qtio422016.Writer, b []byte) {
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 	streamescapePrometheusLabel(qw422016, b)
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 }
 
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 func escapePrometheusLabel(b []byte) string {
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 	writeescapePrometheusLabel(qb422016, b)
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 	qs422016 := string(qb422016.B)
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 	return qs422016
-//line app/vmselect/prometheus/export.qtpl:196
+//line app/vmselect/prometheus/export.qtpl:201
 }
@@ -0,0 +1,47 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/storage"
+)
+
+func TestExportCSVLine(t *testing.T) {
+	f := func(format, resultExpected string) {
+		t.Helper()
+		xb := &exportBlock{
+			mn: &storage.MetricName{
+				MetricGroup: []byte("foo"),
+				Tags: []storage.Tag{
+					{
+						Key:   []byte("simple"),
+						Value: []byte("bar"),
+					},
+					{
+						Key:   []byte("quoted"),
+						Value: []byte(`a"b,c`),
+					},
+					{
+						Key:   []byte("multiline"),
+						Value: []byte("a\nb"),
+					},
+				},
+			},
+			timestamps: []int64{1700000000123},
+			values:     []float64{1.5},
+		}
+		result := ExportCSVLine(xb, strings.Split(format, ","))
+		if result != resultExpected {
+			t.Fatalf("unexpected result for format=%q\ngot\n%q\nwant\n%q", format, result, resultExpected)
+		}
+	}
+
+	f("__name__,__value__,__timestamp__", "foo,1.5,1700000000123\n")
+	f("__timestamp__:unix_s,__timestamp__:unix_ms,__timestamp__:unix_us,__timestamp__:unix_ns",
+		"1700000000,1700000000123,1700000000123000,1700000000123000000\n")
+	f(`__timestamp__:custom:05.000,__timestamp__:custom:"05"`, "20.123,\"\"\"20\"\"\"\n")
+
+	// label values with special chars must be quoted according to RFC4180
+	f("simple,quoted,multiline,missing", "bar,\"a\"\"b,c\",\"a\nb\",\n")
+}
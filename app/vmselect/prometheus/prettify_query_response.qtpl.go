@@ -0,0 +1,85 @@
+// Code generated by qtc from "prettify_query_response.qtpl". DO NOT EDIT.
+// See https://github.com/valyala/quicktemplate for details.
+
+//line app/vmselect/prometheus/prettify_query_response.qtpl:1
+package prometheus
+
+//line app/vmselect/prometheus/prettify_query_response.qtpl:1
+import (
+	"github.com/VictoriaMetrics/metricsql"
+)
+
+// PrettifyQueryResponse generates response for /prettify-query .
+
+//line app/vmselect/prometheus/prettify_query_response.qtpl:8
+import (
+	qtio422016 "io"
+
+	qt422016 "github.com/valyala/quicktemplate"
+)
+
+//line app/vmselect/prometheus/prettify_query_response.qtpl:8
+var (
+	_ = qtio422016.Copy
+	_ = qt422016.AcquireByteBuffer
+)
+
+//line app/vmselect/prometheus/prettify_query_response.qtpl:8
+func StreamPrettifyQueryResponse(qw422016 *qt422016.Writer, q string) {
+//line app/vmselect/prometheus/prettify_query_response.qtpl:9
+	if len(q) == 0 {
+//line app/vmselect/prometheus/prettify_query_response.qtpl:9
+		qw422016.N().S(`{"status":"error","msg":"query string cannot be empty"}`)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:14
+		return
+//line app/vmselect/prometheus/prettify_query_response.qtpl:15
+	}
+//line app/vmselect/prometheus/prettify_query_response.qtpl:15
+	qw422016.N().S(`{`)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:18
+	prettyQuery, err := metricsql.Prettify(q)
+
+//line app/vmselect/prometheus/prettify_query_response.qtpl:19
+	if err != nil {
+//line app/vmselect/prometheus/prettify_query_response.qtpl:19
+		qw422016.N().S(`"status":"error","msg":`)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:21
+		qw422016.N().Q(err.Error())
+//line app/vmselect/prometheus/prettify_query_response.qtpl:22
+	} else {
+//line app/vmselect/prometheus/prettify_query_response.qtpl:22
+		qw422016.N().S(`"status":"success","query":`)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:24
+		qw422016.N().Q(prettyQuery)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:25
+	}
+//line app/vmselect/prometheus/prettify_query_response.qtpl:25
+	qw422016.N().S(`}`)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+}
+
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+func WritePrettifyQueryResponse(qq422016 qtio422016.Writer, q string) {
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+	StreamPrettifyQueryResponse(qw422016, q)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+	qt422016.ReleaseWriter(qw422016)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+}
+
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+func PrettifyQueryResponse(q string) string {
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+	qb422016 := qt422016.AcquireByteBuffer()
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+	WritePrettifyQueryResponse(qb422016, q)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+	qs422016 := string(qb422016.B)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+	qt422016.ReleaseByteBuffer(qb422016)
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+	return qs422016
+//line app/vmselect/prometheus/prettify_query_response.qtpl:27
+}
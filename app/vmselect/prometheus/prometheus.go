@@ -1,9 +1,9 @@
 package prometheus
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"github.com/VictoriaMetrics/metricsql"
 	"math"
 	"net/http"
 	"runtime"
@@ -24,6 +24,7 @@ import (
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/flagutil"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httputils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/querytracer"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/storage"
 	"github.com/VictoriaMetrics/metrics"
@@ -36,7 +37,7 @@ var (
 		"Too small value can result in incomplete last points for query results")
 	maxQueryLen = flagutil.NewBytes("search.maxQueryLen", 16*1024, "The maximum search query length in bytes")
 	maxLookback = flag.Duration("search.maxLookback", 0, "Synonym to -search.lookback-delta from Prometheus. "+
-		"The value is dynamically detected from interval between time series datapoints if not set. It can be overridden on per-query basis via max_lookback arg. "+
+		"The value is dynamically detected from interval between time series datapoints if not set. It can be overridden on per-query basis via max_lookback arg or via lookback_delta arg. "+
 		"See also '-search.maxStalenessInterval' flag, which has the same meaning due to historical reasons")
 	maxStalenessInterval = flag.Duration("search.maxStalenessInterval", 0, "The maximum interval for staleness calculations. "+
 		"By default, it is automatically calculated from the median interval between samples. This flag could be useful for tuning "+
@@ -44,6 +45,9 @@ var (
 		"See also '-search.setLookbackToStep' flag")
 	setLookbackToStep = flag.Bool("search.setLookbackToStep", false, "Whether to fix lookback interval to 'step' query arg value. "+
 		"If set to true, the query model becomes closer to InfluxDB data model. If set to true, then -search.maxLookback and -search.maxStalenessInterval are ignored")
+	minStep = flag.Duration("search.minStep", 0, "The minimum step for /api/v1/query_range. Smaller 'step' query arg values are raised to -search.minStep, "+
+		"while bigger values are rounded up to the nearest multiple of -search.minStep. This protects from heavy queries with too small step "+
+		"over long time ranges. The minimum step isn't enforced if -search.minStep is set to 0. See also -search.maxPointsPerTimeseries")
 	maxStepForPointsAdjustment = flag.Duration("search.maxStepForPointsAdjustment", time.Minute, "The maximum step when /api/v1/query_range handler adjusts "+
 		"points with timestamps closer than -search.latencyOffset to the current time. The adjustment is needed because such points may contain incomplete data")
 
@@ -85,12 +89,7 @@ func PrettifyQuery(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	httpserver.EnableCORS(w, r)
 
-	prettyQuery, err := metricsql.Prettify(query)
-	if err != nil {
-		fmt.Fprintf(bw, `{"status": "error", "msg": %q}`, err)
-	} else {
-		fmt.Fprintf(bw, `{"status": "success", "query": %q}`, prettyQuery)
-	}
+	WritePrettifyQueryResponse(bw, query)
 	_ = bw.Flush()
 }
 
@@ -115,7 +114,7 @@ func FederateHandler(startTime time.Time, w http.ResponseWriter, r *http.Request
 	sq := storage.NewSearchQuery(cp.start, cp.end, cp.filterss, *maxFederateSeries)
 	rss, err := netstorage.ProcessSearchQuery(nil, sq, cp.deadline)
 	if err != nil {
-		return fmt.Errorf("cannot fetch data for %q: %w", sq, err)
+		return fmt.Errorf("cannot fetch data for %q: %w", sq, addMaxSeriesFlagHint(err, "search.maxFederateSeries"))
 	}
 
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
@@ -215,7 +214,7 @@ func ExportCSVHandler(startTime time.Time, w http.ResponseWriter, r *http.Reques
 	}
 	err = <-doneCh
 	if err != nil {
-		return fmt.Errorf("error during sending the exported csv data to remote client: %w", err)
+		return fmt.Errorf("error during sending the exported csv data to remote client: %w", addMaxSeriesFlagHint(err, "search.maxExportSeries"))
 	}
 	return sw.flush()
 }
@@ -270,7 +269,7 @@ func ExportNativeHandler(startTime time.Time, w http.ResponseWriter, r *http.Req
 		return sw.maybeFlushBuffer(bb)
 	})
 	if err != nil {
-		return fmt.Errorf("error during sending native data to remote client: %w", err)
+		return fmt.Errorf("error during sending native data to remote client: %w", addMaxSeriesFlagHint(err, "search.maxExportSeries"))
 	}
 	return sw.flush()
 }
@@ -291,7 +290,7 @@ func ExportHandler(startTime time.Time, w http.ResponseWriter, r *http.Request)
 	maxRowsPerLine := int(fastfloat.ParseInt64BestEffort(r.FormValue("max_rows_per_line")))
 	reduceMemUsage := httputils.GetBool(r, "reduce_mem_usage")
 	if err := exportHandler(nil, w, cp, format, maxRowsPerLine, reduceMemUsage); err != nil {
-		return fmt.Errorf("error when exporting data on the time range (start=%d, end=%d): %w", cp.start, cp.end, err)
+		return fmt.Errorf("error when exporting data on the time range (start=%d, end=%d): %w", cp.start, cp.end, addMaxSeriesFlagHint(err, "search.maxExportSeries"))
 	}
 	return nil
 }
@@ -462,7 +461,9 @@ var exportBlockPool = &sync.Pool{
 // DeleteHandler processes /api/v1/admin/tsdb/delete_series prometheus API request.
 //
 // See https://prometheus.io/docs/prometheus/latest/querying/api/#delete-series
-func DeleteHandler(startTime time.Time, r *http.Request) error {
+//
+// If dry_run=1 query arg is set, then the number of matching series and samples is returned without deleting them.
+func DeleteHandler(startTime time.Time, w http.ResponseWriter, r *http.Request) error {
 	defer deleteDuration.UpdateDuration(startTime)
 
 	cp, err := getCommonParams(r, startTime, true)
@@ -472,17 +473,44 @@ func DeleteHandler(startTime time.Time, r *http.Request) error {
 	if !cp.IsDefaultTimeRange() {
 		return fmt.Errorf("start=%d and end=%d args aren't supported. Remove these args from the query in order to delete all the matching metrics", cp.start, cp.end)
 	}
+	if httputils.GetBool(r, "dry_run") {
+		sq := storage.NewSearchQuery(cp.start, cp.end, cp.filterss, *maxUniqueTimeseries)
+		seriesCount, samplesCount, err := netstorage.GetSeriesStats(nil, sq, cp.deadline)
+		if err != nil {
+			return fmt.Errorf("cannot obtain stats for time series to delete: %w", addMaxSeriesFlagHint(err, "search.maxUniqueTimeseries"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		bw := bufferedwriter.Get(w)
+		defer bufferedwriter.Put(bw)
+		WriteDeleteSeriesDryRunResponse(bw, seriesCount, samplesCount)
+		if err := bw.Flush(); err != nil {
+			return fmt.Errorf("cannot send delete_series dry run response to remote client: %w", err)
+		}
+		return nil
+	}
 	sq := storage.NewSearchQuery(cp.start, cp.end, cp.filterss, 0)
 	deletedCount, err := netstorage.DeleteSeries(nil, sq, cp.deadline)
 	if err != nil {
 		return fmt.Errorf("cannot delete time series: %w", err)
 	}
+	logger.Infof("deleted %d time series matching %s; remoteAddr=%s, requestURI=%q%s",
+		deletedCount, sq, httpserver.GetQuotedRemoteAddr(r), httpserver.GetRequestURI(r), getAuthUsernameForLog(r))
 	if deletedCount > 0 {
 		promql.ResetRollupResultCache()
 	}
+	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
 
+// getAuthUsernameForLog returns username suffix for the log message if r contains Basic Auth credentials.
+func getAuthUsernameForLog(r *http.Request) string {
+	username, _, ok := r.BasicAuth()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(", username=%q", username)
+}
+
 var deleteDuration = metrics.NewSummary(`vm_request_duration_seconds{path="/api/v1/admin/tsdb/delete_series"}`)
 
 // LabelValuesHandler processes /api/v1/label/<labelName>/values request.
@@ -570,7 +598,7 @@ func TSDBStatusHandler(qt *querytracer.Tracer, startTime time.Time, w http.Respo
 	sq := storage.NewSearchQuery(start, end, cp.filterss, *maxTSDBStatusSeries)
 	status, err := netstorage.TSDBStatus(qt, sq, focusLabel, topN, cp.deadline)
 	if err != nil {
-		return fmt.Errorf("cannot obtain tsdb stats: %w", err)
+		return fmt.Errorf("cannot obtain tsdb stats: %w", addMaxSeriesFlagHint(err, "search.maxTSDBStatusSeries"))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -621,18 +649,18 @@ func LabelsHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseW
 var labelsDuration = metrics.NewSummary(`vm_request_duration_seconds{path="/api/v1/labels"}`)
 
 // SeriesCountHandler processes /api/v1/series/count request.
-func SeriesCountHandler(startTime time.Time, w http.ResponseWriter, r *http.Request) error {
+func SeriesCountHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseWriter, r *http.Request) error {
 	defer seriesCountDuration.UpdateDuration(startTime)
 
 	deadline := searchutils.GetDeadlineForStatusRequest(r, startTime)
-	n, err := netstorage.SeriesCount(nil, deadline)
+	n, err := netstorage.SeriesCount(qt, deadline)
 	if err != nil {
 		return fmt.Errorf("cannot obtain series count: %w", err)
 	}
 	w.Header().Set("Content-Type", "application/json")
 	bw := bufferedwriter.Get(w)
 	defer bufferedwriter.Put(bw)
-	WriteSeriesCountResponse(bw, n)
+	WriteSeriesCountResponse(bw, n, qt)
 	if err := bw.Flush(); err != nil {
 		return fmt.Errorf("cannot send series count response to remote client: %w", err)
 	}
@@ -664,7 +692,7 @@ func SeriesHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseW
 	sq := storage.NewSearchQuery(cp.start, cp.end, cp.filterss, *maxSeriesLimit)
 	metricNames, err := netstorage.SearchMetricNames(qt, sq, cp.deadline)
 	if err != nil {
-		return fmt.Errorf("cannot fetch time series for %q: %w", sq, err)
+		return fmt.Errorf("cannot fetch time series for %q: %w", sq, addMaxSeriesFlagHint(err, "search.maxSeries"))
 	}
 	w.Header().Set("Content-Type", "application/json")
 	bw := bufferedwriter.Get(w)
@@ -683,10 +711,16 @@ var seriesDuration = metrics.NewSummary(`vm_request_duration_seconds{path="/api/
 
 // QueryHandler processes /api/v1/query request.
 //
+// qs is updated with the query stats if it isn't nil.
+//
 // See https://prometheus.io/docs/prometheus/latest/querying/api/#instant-queries
-func QueryHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseWriter, r *http.Request) error {
+func QueryHandler(qs *promql.QueryStats, qt *querytracer.Tracer, startTime time.Time, w http.ResponseWriter, r *http.Request) error {
 	defer queryDuration.UpdateDuration(startTime)
 
+	if qs == nil {
+		qs = &promql.QueryStats{}
+	}
+
 	ct := startTime.UnixNano() / 1e6
 	deadline := searchutils.GetDeadlineForQuery(r, startTime)
 	mayCache := !httputils.GetBool(r, "nocache")
@@ -745,7 +779,7 @@ func QueryHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseWr
 			filterss: filterss,
 		}
 		if err := exportHandler(qt, w, cp, "promapi", 0, false); err != nil {
-			return fmt.Errorf("error when exporting data for query=%q on the time range (start=%d, end=%d): %w", childQuery, start, end, err)
+			return fmt.Errorf("error when exporting data for query=%q on the time range (start=%d, end=%d): %w", childQuery, start, end, addMaxSeriesFlagHint(err, "search.maxExportSeries"))
 		}
 		return nil
 	}
@@ -765,7 +799,7 @@ func QueryHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseWr
 		start -= offset
 		end := start
 		start = end - window
-		if err := queryRangeHandler(qt, startTime, w, childQuery, start, end, step, r, ct, etfs); err != nil {
+		if err := queryRangeHandler(qs, qt, startTime, w, childQuery, start, end, step, r, ct, etfs); err != nil {
 			return fmt.Errorf("error when executing query=%q on the time range (start=%d, end=%d, step=%d): %w", childQuery, start, end, step, err)
 		}
 		return nil
@@ -784,7 +818,6 @@ func QueryHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseWr
 	} else {
 		queryOffset = 0
 	}
-	qs := &promql.QueryStats{}
 	ec := &promql.EvalConfig{
 		Start:               start,
 		End:                 start,
@@ -805,7 +838,7 @@ func QueryHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseWr
 	}
 	result, err := promql.Exec(qt, ec, query, true)
 	if err != nil {
-		return fmt.Errorf("error when executing query=%q for (time=%d, step=%d): %w", query, start, step, err)
+		return fmt.Errorf("error when executing query=%q for (time=%d, step=%d): %w", query, start, step, addMaxSeriesFlagHint(err, "search.maxUniqueTimeseries"))
 	}
 	if queryOffset > 0 {
 		for i := range result {
@@ -838,10 +871,16 @@ var queryDuration = metrics.NewSummary(`vm_request_duration_seconds{path="/api/v
 
 // QueryRangeHandler processes /api/v1/query_range request.
 //
+// qs is updated with the query stats if it isn't nil.
+//
 // See https://prometheus.io/docs/prometheus/latest/querying/api/#range-queries
-func QueryRangeHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseWriter, r *http.Request) error {
+func QueryRangeHandler(qs *promql.QueryStats, qt *querytracer.Tracer, startTime time.Time, w http.ResponseWriter, r *http.Request) error {
 	defer queryRangeDuration.UpdateDuration(startTime)
 
+	if qs == nil {
+		qs = &promql.QueryStats{}
+	}
+
 	ct := startTime.UnixNano() / 1e6
 	query := r.FormValue("query")
 	if len(query) == 0 {
@@ -859,17 +898,44 @@ func QueryRangeHandler(qt *querytracer.Tracer, startTime time.Time, w http.Respo
 	if err != nil {
 		return err
 	}
+	step = adjustStep(step, minStep.Milliseconds())
 	etfs, err := searchutils.GetExtraTagFilters(r)
 	if err != nil {
 		return err
 	}
-	if err := queryRangeHandler(qt, startTime, w, query, start, end, step, r, ct, etfs); err != nil {
+	if err := queryRangeHandler(qs, qt, startTime, w, query, start, end, step, r, ct, etfs); err != nil {
 		return fmt.Errorf("error when executing query=%q on the time range (start=%d, end=%d, step=%d): %w", query, start, end, step, err)
 	}
 	return nil
 }
 
-func queryRangeHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseWriter, query string,
+// addMaxSeriesFlagHint adds the hint about the given command-line flag to err if err is caused by exceeding
+// the limit on the number of time series selected by the query.
+//
+// The flag depends on the endpoint, so the storage cannot mention it in the error.
+func addMaxSeriesFlagHint(err error, flagName string) error {
+	var tme *storage.TooManyTimeseriesError
+	if !errors.As(err, &tme) {
+		return err
+	}
+	return fmt.Errorf("%w; the limit is set via -%s command-line flag; possible solutions: increase -%s; reduce time range for the query; "+
+		"use more specific label filters in order to select fewer series", err, flagName, flagName)
+}
+
+// adjustStep returns step rounded up to the nearest multiple of minStep.
+//
+// The step is returned as is if minStep <= 0.
+func adjustStep(step, minStep int64) int64 {
+	if minStep <= 0 || step%minStep == 0 {
+		return step
+	}
+	if step < minStep {
+		return minStep
+	}
+	return (step/minStep + 1) * minStep
+}
+
+func queryRangeHandler(qs *promql.QueryStats, qt *querytracer.Tracer, startTime time.Time, w http.ResponseWriter, query string,
 	start, end, step int64, r *http.Request, ct int64, etfs [][]storage.TagFilter) error {
 	deadline := searchutils.GetDeadlineForQuery(r, startTime)
 	mayCache := !httputils.GetBool(r, "nocache")
@@ -892,7 +958,6 @@ func queryRangeHandler(qt *querytracer.Tracer, startTime time.Time, w http.Respo
 		start, end = promql.AdjustStartEnd(start, end, step)
 	}
 
-	qs := &promql.QueryStats{}
 	ec := &promql.EvalConfig{
 		Start:               start,
 		End:                 end,
@@ -913,7 +978,7 @@ func queryRangeHandler(qt *querytracer.Tracer, startTime time.Time, w http.Respo
 	}
 	result, err := promql.Exec(qt, ec, query, false)
 	if err != nil {
-		return err
+		return addMaxSeriesFlagHint(err, "search.maxUniqueTimeseries")
 	}
 	if step < maxStepForPointsAdjustment.Milliseconds() {
 		queryOffset, err := getLatencyOffsetMilliseconds(r)
@@ -1022,7 +1087,13 @@ func getMaxLookback(r *http.Request) (int64, error) {
 	if d == 0 {
 		d = maxStalenessInterval.Milliseconds()
 	}
-	maxLookback, err := httputils.GetDuration(r, "max_lookback", d)
+	argKey := "max_lookback"
+	if r.FormValue(argKey) == "" && r.FormValue("lookback_delta") != "" {
+		// Prometheus accepts lookback_delta query arg for the same purpose.
+		// See https://prometheus.io/docs/prometheus/latest/querying/api/#instant-queries
+		argKey = "lookback_delta"
+	}
+	maxLookback, err := httputils.GetDuration(r, argKey, d)
 	if err != nil {
 		return 0, err
 	}
@@ -1080,6 +1151,9 @@ func QueryStatsHandler(w http.ResponseWriter, r *http.Request) error {
 		if err != nil {
 			return fmt.Errorf("cannot parse `topN` arg %q: %w", topNStr, err)
 		}
+		if n < 0 {
+			return fmt.Errorf("`topN` arg cannot be negative; got %d", n)
+		}
 		topN = n
 	}
 	maxLifetimeMsecs, err := httputils.GetDuration(r, "maxLifetime", 10*60*1000)
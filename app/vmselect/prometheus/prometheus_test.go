@@ -1,12 +1,17 @@
 package prometheus
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmselect/netstorage"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/storage"
 )
 
 func TestRemoveEmptyValuesAndTimeseries(t *testing.T) {
@@ -229,3 +234,144 @@ func TestGetLatencyOffsetMillisecondsFailure(t *testing.T) {
 	}
 	f("http://localhost?latency_offset=foobar")
 }
+
+func TestGetMaxLookbackSuccess(t *testing.T) {
+	f := func(url string, expectedLookback int64) {
+		t.Helper()
+		r, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatalf("unexpected error in NewRequest(%q): %s", url, err)
+		}
+		lookback, err := getMaxLookback(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if lookback != expectedLookback {
+			t.Fatalf("unexpected lookback got %d; want %d", lookback, expectedLookback)
+		}
+	}
+	f("http://localhost", 0)
+	f("http://localhost?max_lookback=1h", 3600*1000)
+	f("http://localhost?lookback_delta=30s", 30*1000)
+	f("http://localhost?lookback_delta=15", 15*1000)
+
+	// max_lookback has priority over lookback_delta
+	f("http://localhost?max_lookback=1m&lookback_delta=30s", 60*1000)
+}
+
+func TestGetMaxLookbackFailure(t *testing.T) {
+	f := func(url string) {
+		t.Helper()
+		r, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatalf("unexpected error in NewRequest(%q): %s", url, err)
+		}
+		if _, err := getMaxLookback(r); err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+	f("http://localhost?max_lookback=foobar")
+	f("http://localhost?lookback_delta=foobar")
+	f("http://localhost?lookback_delta=-1s")
+}
+
+func TestPrettifyQueryResponse(t *testing.T) {
+	f := func(q string, respExpected map[string]string) {
+		t.Helper()
+		data := PrettifyQueryResponse(q)
+		var resp map[string]string
+		if err := json.Unmarshal([]byte(data), &resp); err != nil {
+			t.Fatalf("cannot parse response %q: %s", data, err)
+		}
+		if !reflect.DeepEqual(resp, respExpected) {
+			t.Fatalf("unexpected response\ngot\n%v\nwant\n%v", resp, respExpected)
+		}
+	}
+
+	f("", map[string]string{
+		"status": "error",
+		"msg":    "query string cannot be empty",
+	})
+	f(`sum(rate(foo{bar="baz"}[5m])) by (x)`, map[string]string{
+		"status": "success",
+		"query":  `sum(rate(foo{bar="baz"}[5m])) by(x)`,
+	})
+	f("with (x = foo) x + bar", map[string]string{
+		"status": "success",
+		"query":  "WITH (x = foo) x + bar",
+	})
+
+	// the response must be valid JSON for queries with special chars
+	f("foo{bar=`\x01`}", map[string]string{
+		"status": "success",
+		"query":  "foo{bar=`\x01`}",
+	})
+}
+
+func TestAdjustStep(t *testing.T) {
+	f := func(step, minStep, stepExpected int64) {
+		t.Helper()
+		result := adjustStep(step, minStep)
+		if result != stepExpected {
+			t.Fatalf("unexpected step for adjustStep(%d, %d); got %d; want %d", step, minStep, result, stepExpected)
+		}
+	}
+
+	// minStep is disabled
+	f(1, 0, 1)
+	f(15000, 0, 15000)
+
+	// step is raised to minStep
+	f(1, 1000, 1000)
+	f(999, 1000, 1000)
+
+	// step is a multiple of minStep
+	f(1000, 1000, 1000)
+	f(60000, 1000, 60000)
+
+	// step is rounded up to the nearest multiple of minStep
+	f(1500, 1000, 2000)
+	f(60001, 10000, 70000)
+}
+
+func TestGetAuthUsernameForLog(t *testing.T) {
+	f := func(username, password string, setAuth bool, resultExpected string) {
+		t.Helper()
+		r, err := http.NewRequest(http.MethodGet, "http://foo/api/v1/admin/tsdb/delete_series", nil)
+		if err != nil {
+			t.Fatalf("unexpected error when creating request: %s", err)
+		}
+		if setAuth {
+			r.SetBasicAuth(username, password)
+		}
+		result := getAuthUsernameForLog(r)
+		if result != resultExpected {
+			t.Fatalf("unexpected result; got %q; want %q", result, resultExpected)
+		}
+	}
+
+	// missing auth
+	f("", "", false, "")
+
+	// basic auth
+	f("foo", "secret", true, `, username="foo"`)
+	f("", "secret", true, `, username=""`)
+}
+
+func TestAddMaxSeriesFlagHint(t *testing.T) {
+	f := func(err error, flagName string, hintExpected bool) {
+		t.Helper()
+		result := addMaxSeriesFlagHint(err, flagName)
+		if !errors.Is(result, err) {
+			t.Fatalf("the resulting error %q must wrap the original error %q", result, err)
+		}
+		hint := fmt.Sprintf("the limit is set via -%s command-line flag", flagName)
+		if hasHint := strings.Contains(result.Error(), hint); hasHint != hintExpected {
+			t.Fatalf("unexpected hint presence in %q; got %v; want %v", result, hasHint, hintExpected)
+		}
+	}
+	tme := &storage.TooManyTimeseriesError{MaxMetrics: 10}
+	f(tme, "search.maxSeries", true)
+	f(fmt.Errorf("cannot select time series for foo: %w", tme), "search.maxUniqueTimeseries", true)
+	f(fmt.Errorf("some error"), "search.maxUniqueTimeseries", false)
+}
@@ -0,0 +1,352 @@
+package prometheus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmselect/netstorage"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmselect/searchutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/bufferedwriter"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/storage"
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+)
+
+// The maximum size of compressed remote read request.
+//
+// This is the same limit as Prometheus uses.
+const maxRemoteReadRequestSize = 32 * 1024 * 1024
+
+// The maximum number of samples per XOR chunk in STREAMED_XOR_CHUNKS response.
+//
+// This is the same limit as Prometheus uses.
+const maxSamplesPerChunk = 120
+
+// RemoteReadHandler processes /api/v1/read request.
+//
+// See https://prometheus.io/docs/prometheus/latest/querying/remote_read_api/
+func RemoteReadHandler(startTime time.Time, w http.ResponseWriter, r *http.Request) error {
+	defer remoteReadDuration.UpdateDuration(startTime)
+
+	req, err := readRemoteReadRequest(r)
+	if err != nil {
+		return err
+	}
+	etfs, err := searchutils.GetExtraTagFilters(r)
+	if err != nil {
+		return err
+	}
+	deadline := searchutils.GetDeadlineForExport(r, startTime)
+
+	bw := bufferedwriter.Get(w)
+	defer bufferedwriter.Put(bw)
+	if getRemoteReadResponseType(req.AcceptedResponseTypes) == prompb.ReadRequest_STREAMED_XOR_CHUNKS {
+		// Stream every series to the client as soon as it is obtained from the storage,
+		// so the memory usage doesn't depend on the number of matching series.
+		w.Header().Set("Content-Type", "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse")
+		flusher, _ := w.(http.Flusher)
+		var bwLock sync.Mutex
+		for i, q := range req.Queries {
+			queryIndex := int64(i)
+			err := remoteReadQuery(q, etfs, deadline, func(ts *prompb.TimeSeries) error {
+				bwLock.Lock()
+				defer bwLock.Unlock()
+				if err := writeChunkedReadResponse(bw, ts, queryIndex); err != nil {
+					return fmt.Errorf("cannot send remote read response to remote client: %w", err)
+				}
+				// Every frame must be flushed to the client, since it can process frames independently.
+				if err := bw.Flush(); err != nil {
+					return fmt.Errorf("cannot flush remote read response to remote client: %w", err)
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("cannot execute remote read query #%d: %w", i, err)
+			}
+		}
+		return bw.Flush()
+	}
+
+	// SAMPLES response type requires the whole response to be marshaled and compressed at once.
+	resp := &prompb.ReadResponse{
+		Results: make([]*prompb.QueryResult, len(req.Queries)),
+	}
+	for i, q := range req.Queries {
+		var tss []*prompb.TimeSeries
+		var tssLock sync.Mutex
+		err := remoteReadQuery(q, etfs, deadline, func(ts *prompb.TimeSeries) error {
+			tssLock.Lock()
+			tss = append(tss, ts)
+			tssLock.Unlock()
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot execute remote read query #%d: %w", i, err)
+		}
+		// Prometheus expects time series sorted by labels.
+		sort.Slice(tss, func(i, j int) bool {
+			return lessPrompbLabels(tss[i].Labels, tss[j].Labels)
+		})
+		resp.Results[i] = &prompb.QueryResult{
+			Timeseries: tss,
+		}
+	}
+	data, err := resp.Marshal()
+	if err != nil {
+		return fmt.Errorf("cannot marshal remote read response: %w", err)
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	if _, err := bw.Write(snappy.Encode(nil, data)); err != nil {
+		return fmt.Errorf("cannot send remote read response to remote client: %w", err)
+	}
+	return bw.Flush()
+}
+
+var remoteReadDuration = metrics.NewSummary(`vm_request_duration_seconds{path="/api/v1/read"}`)
+
+func readRemoteReadRequest(r *http.Request) (*prompb.ReadRequest, error) {
+	compressed, err := io.ReadAll(io.LimitReader(r.Body, maxRemoteReadRequestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read remote read request: %w", err)
+	}
+	if len(compressed) > maxRemoteReadRequestSize {
+		return nil, fmt.Errorf("too big remote read request; mustn't exceed %d bytes", maxRemoteReadRequestSize)
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decompress snappy-encoded remote read request: %w", err)
+	}
+	var req prompb.ReadRequest
+	if err := req.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal remote read request: %w", err)
+	}
+	return &req, nil
+}
+
+// getRemoteReadResponseType returns the first supported response type from accepted types.
+//
+// Accepted types are ordered by the client preference. SAMPLES is returned if accepted types are empty.
+func getRemoteReadResponseType(accepted []prompb.ReadRequest_ResponseType) prompb.ReadRequest_ResponseType {
+	for _, rt := range accepted {
+		switch rt {
+		case prompb.ReadRequest_SAMPLES, prompb.ReadRequest_STREAMED_XOR_CHUNKS:
+			return rt
+		}
+	}
+	return prompb.ReadRequest_SAMPLES
+}
+
+// remoteReadQuery calls f for every time series matching q.
+//
+// f may be called concurrently from multiple goroutines.
+func remoteReadQuery(q *prompb.Query, etfs [][]storage.TagFilter, deadline searchutils.Deadline, f func(ts *prompb.TimeSeries) error) error {
+	tfs, err := getTagFiltersFromLabelMatchers(q.Matchers)
+	if err != nil {
+		return err
+	}
+	tfss := searchutils.JoinTagFilterss([][]storage.TagFilter{tfs}, etfs)
+	sq := storage.NewSearchQuery(q.StartTimestampMs, q.EndTimestampMs, tfss, *maxExportSeries)
+	rss, err := netstorage.ProcessSearchQuery(nil, sq, deadline)
+	if err != nil {
+		return fmt.Errorf("cannot fetch data for %q: %w", sq, err)
+	}
+	return rss.RunParallel(nil, func(rs *netstorage.Result, workerID uint) error {
+		ts := &prompb.TimeSeries{
+			Labels:  getPrompbLabels(&rs.MetricName),
+			Samples: make([]prompb.Sample, len(rs.Timestamps)),
+		}
+		for i, timestamp := range rs.Timestamps {
+			ts.Samples[i] = prompb.Sample{
+				Timestamp: timestamp,
+				Value:     rs.Values[i],
+			}
+		}
+		return f(ts)
+	})
+}
+
+// getTagFiltersFromLabelMatchers returns tag filters for the given matchers.
+//
+// An error is returned if matchers are empty or all of them match empty label values,
+// since such matchers select all the series in the storage. Prometheus rejects such matchers too.
+func getTagFiltersFromLabelMatchers(matchers []*prompb.LabelMatcher) ([]storage.TagFilter, error) {
+	if len(matchers) == 0 {
+		return nil, fmt.Errorf("label matchers cannot be empty")
+	}
+	matchesEmpty := true
+	tfs := make([]storage.TagFilter, 0, len(matchers))
+	for _, m := range matchers {
+		ok, err := labelMatcherMatchesEmpty(m)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			matchesEmpty = false
+		}
+		var tf storage.TagFilter
+		if m.Name != "__name__" {
+			// An empty key is required for __name__ filter by storage.Search.
+			tf.Key = []byte(m.Name)
+		}
+		tf.Value = []byte(m.Value)
+		switch m.Type {
+		case prompb.LabelMatcher_EQ:
+		case prompb.LabelMatcher_NEQ:
+			tf.IsNegative = true
+		case prompb.LabelMatcher_RE:
+			tf.IsRegexp = true
+		case prompb.LabelMatcher_NRE:
+			tf.IsNegative = true
+			tf.IsRegexp = true
+		default:
+			return nil, fmt.Errorf("unsupported label matcher type %d for label %q", m.Type, m.Name)
+		}
+		tfs = append(tfs, tf)
+	}
+	if matchesEmpty {
+		return nil, fmt.Errorf("label matchers %s must contain at least one matcher, which doesn't match empty label value", labelMatchersString(matchers))
+	}
+	return tfs, nil
+}
+
+func labelMatcherMatchesEmpty(m *prompb.LabelMatcher) (bool, error) {
+	switch m.Type {
+	case prompb.LabelMatcher_EQ:
+		return m.Value == "", nil
+	case prompb.LabelMatcher_NEQ:
+		return m.Value != "", nil
+	case prompb.LabelMatcher_RE, prompb.LabelMatcher_NRE:
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return false, fmt.Errorf("cannot parse regexp %q for label %q: %w", m.Value, m.Name, err)
+		}
+		matches := re.MatchString("")
+		if m.Type == prompb.LabelMatcher_NRE {
+			matches = !matches
+		}
+		return matches, nil
+	default:
+		return false, fmt.Errorf("unsupported label matcher type %d for label %q", m.Type, m.Name)
+	}
+}
+
+func labelMatchersString(matchers []*prompb.LabelMatcher) string {
+	a := make([]string, len(matchers))
+	for i, m := range matchers {
+		op := "="
+		switch m.Type {
+		case prompb.LabelMatcher_NEQ:
+			op = "!="
+		case prompb.LabelMatcher_RE:
+			op = "=~"
+		case prompb.LabelMatcher_NRE:
+			op = "!~"
+		}
+		a[i] = fmt.Sprintf("%s%s%q", m.Name, op, m.Value)
+	}
+	return "{" + strings.Join(a, ",") + "}"
+}
+
+func getPrompbLabels(mn *storage.MetricName) []prompb.Label {
+	labels := make([]prompb.Label, 0, len(mn.Tags)+1)
+	if len(mn.MetricGroup) > 0 {
+		labels = append(labels, prompb.Label{
+			Name:  "__name__",
+			Value: string(mn.MetricGroup),
+		})
+	}
+	for _, tag := range mn.Tags {
+		labels = append(labels, prompb.Label{
+			Name:  string(tag.Key),
+			Value: string(tag.Value),
+		})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
+
+func lessPrompbLabels(a, b []prompb.Label) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].Name != b[i].Name {
+			return a[i].Name < b[i].Name
+		}
+		if a[i].Value != b[i].Value {
+			return a[i].Value < b[i].Value
+		}
+	}
+	return len(a) < len(b)
+}
+
+// writeChunkedReadResponse writes ts encoded into XOR chunks to w as a single frame of STREAMED_XOR_CHUNKS response.
+func writeChunkedReadResponse(w io.Writer, ts *prompb.TimeSeries, queryIndex int64) error {
+	chunks, err := getXORChunks(ts.Samples)
+	if err != nil {
+		return err
+	}
+	resp := &prompb.ChunkedReadResponse{
+		ChunkedSeries: []*prompb.ChunkedSeries{{
+			Labels: ts.Labels,
+			Chunks: chunks,
+		}},
+		QueryIndex: queryIndex,
+	}
+	data, err := resp.Marshal()
+	if err != nil {
+		return fmt.Errorf("cannot marshal chunked remote read response: %w", err)
+	}
+
+	// Every frame consists of uvarint-encoded data size, big-endian CRC32 Castagnoli checksum for the data and the data itself.
+	// See https://github.com/prometheus/prometheus/blob/main/prompb/remote.proto
+	var buf []byte
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	buf = binary.BigEndian.AppendUint32(buf, crc32.Checksum(data, castagnoliTable))
+	buf = append(buf, data...)
+	_, err = w.Write(buf)
+	return err
+}
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+func getXORChunks(samples []prompb.Sample) ([]prompb.Chunk, error) {
+	chunks := make([]prompb.Chunk, 0, (len(samples)+maxSamplesPerChunk-1)/maxSamplesPerChunk)
+	for len(samples) > 0 {
+		n := maxSamplesPerChunk
+		if n > len(samples) {
+			n = len(samples)
+		}
+		chunkSamples := samples[:n]
+		samples = samples[n:]
+
+		c := chunkenc.NewXORChunk()
+		app, err := c.Appender()
+		if err != nil {
+			return nil, fmt.Errorf("cannot create XOR chunk appender: %w", err)
+		}
+		for _, s := range chunkSamples {
+			app.Append(s.Timestamp, s.Value)
+		}
+		chunks = append(chunks, prompb.Chunk{
+			MinTimeMs: chunkSamples[0].Timestamp,
+			MaxTimeMs: chunkSamples[len(chunkSamples)-1].Timestamp,
+			Type:      prompb.Chunk_XOR,
+			Data:      c.Bytes(),
+		})
+	}
+	return chunks, nil
+}
@@ -0,0 +1,175 @@
+package prometheus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"reflect"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/storage"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+)
+
+func TestGetTagFiltersFromLabelMatchers(t *testing.T) {
+	matchers := []*prompb.LabelMatcher{
+		{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "foo"},
+		{Type: prompb.LabelMatcher_NEQ, Name: "a", Value: "b"},
+		{Type: prompb.LabelMatcher_RE, Name: "c", Value: "d.*"},
+		{Type: prompb.LabelMatcher_NRE, Name: "e", Value: "f|g"},
+	}
+	tfs, err := getTagFiltersFromLabelMatchers(matchers)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tfsExpected := []storage.TagFilter{
+		{Value: []byte("foo")},
+		{Key: []byte("a"), Value: []byte("b"), IsNegative: true},
+		{Key: []byte("c"), Value: []byte("d.*"), IsRegexp: true},
+		{Key: []byte("e"), Value: []byte("f|g"), IsNegative: true, IsRegexp: true},
+	}
+	if !reflect.DeepEqual(tfs, tfsExpected) {
+		t.Fatalf("unexpected tag filters\ngot\n%v\nwant\n%v", tfs, tfsExpected)
+	}
+
+	// unsupported matcher type
+	_, err = getTagFiltersFromLabelMatchers([]*prompb.LabelMatcher{{Type: 123, Name: "a", Value: "b"}})
+	if err == nil {
+		t.Fatalf("expecting non-nil error for unsupported matcher type")
+	}
+
+	// matchers selecting all the series
+	f := func(matchers []*prompb.LabelMatcher) {
+		t.Helper()
+		if _, err := getTagFiltersFromLabelMatchers(matchers); err == nil {
+			t.Fatalf("expecting non-nil error for matchers %s", labelMatchersString(matchers))
+		}
+	}
+	f(nil)
+	f([]*prompb.LabelMatcher{{Type: prompb.LabelMatcher_EQ, Name: "a", Value: ""}})
+	f([]*prompb.LabelMatcher{{Type: prompb.LabelMatcher_NEQ, Name: "a", Value: "b"}})
+	f([]*prompb.LabelMatcher{{Type: prompb.LabelMatcher_RE, Name: "__name__", Value: ".*"}})
+	f([]*prompb.LabelMatcher{
+		{Type: prompb.LabelMatcher_NRE, Name: "a", Value: "b.*"},
+		{Type: prompb.LabelMatcher_RE, Name: "c", Value: "d|"},
+	})
+
+	// invalid regexp
+	f([]*prompb.LabelMatcher{{Type: prompb.LabelMatcher_RE, Name: "a", Value: "("}})
+}
+
+func TestGetRemoteReadResponseType(t *testing.T) {
+	f := func(accepted []prompb.ReadRequest_ResponseType, rtExpected prompb.ReadRequest_ResponseType) {
+		t.Helper()
+		rt := getRemoteReadResponseType(accepted)
+		if rt != rtExpected {
+			t.Fatalf("unexpected response type for %v; got %v; want %v", accepted, rt, rtExpected)
+		}
+	}
+
+	f(nil, prompb.ReadRequest_SAMPLES)
+	f([]prompb.ReadRequest_ResponseType{prompb.ReadRequest_SAMPLES}, prompb.ReadRequest_SAMPLES)
+	f([]prompb.ReadRequest_ResponseType{prompb.ReadRequest_STREAMED_XOR_CHUNKS}, prompb.ReadRequest_STREAMED_XOR_CHUNKS)
+	f([]prompb.ReadRequest_ResponseType{prompb.ReadRequest_STREAMED_XOR_CHUNKS, prompb.ReadRequest_SAMPLES}, prompb.ReadRequest_STREAMED_XOR_CHUNKS)
+	f([]prompb.ReadRequest_ResponseType{123, prompb.ReadRequest_SAMPLES}, prompb.ReadRequest_SAMPLES)
+}
+
+func TestGetPrompbLabels(t *testing.T) {
+	mn := &storage.MetricName{
+		MetricGroup: []byte("foo"),
+		Tags: []storage.Tag{
+			{Key: []byte("job"), Value: []byte("x")},
+			{Key: []byte("Instance"), Value: []byte("y")},
+		},
+	}
+	labels := getPrompbLabels(mn)
+	labelsExpected := []prompb.Label{
+		{Name: "Instance", Value: "y"},
+		{Name: "__name__", Value: "foo"},
+		{Name: "job", Value: "x"},
+	}
+	if !reflect.DeepEqual(labels, labelsExpected) {
+		t.Fatalf("unexpected labels\ngot\n%v\nwant\n%v", labels, labelsExpected)
+	}
+}
+
+func TestWriteChunkedReadResponse(t *testing.T) {
+	samples := make([]prompb.Sample, 250)
+	for i := range samples {
+		samples[i] = prompb.Sample{
+			Timestamp: int64(i) * 1000,
+			Value:     float64(i),
+		}
+	}
+	ts := &prompb.TimeSeries{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "foo"}},
+		Samples: samples,
+	}
+	var bb bytes.Buffer
+	if err := writeChunkedReadResponse(&bb, ts, 3); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Verify the frame
+	data := bb.Bytes()
+	size, n := binary.Uvarint(data)
+	if n <= 0 {
+		t.Fatalf("cannot read frame size")
+	}
+	data = data[n:]
+	checksum := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if uint64(len(data)) != size {
+		t.Fatalf("unexpected frame size; got %d; want %d", len(data), size)
+	}
+	if checksum != crc32.Checksum(data, castagnoliTable) {
+		t.Fatalf("unexpected frame checksum")
+	}
+
+	// Verify the response
+	var resp prompb.ChunkedReadResponse
+	if err := resp.Unmarshal(data); err != nil {
+		t.Fatalf("cannot unmarshal response: %s", err)
+	}
+	if resp.QueryIndex != 3 {
+		t.Fatalf("unexpected query index; got %d; want 3", resp.QueryIndex)
+	}
+	if len(resp.ChunkedSeries) != 1 {
+		t.Fatalf("unexpected number of series; got %d; want 1", len(resp.ChunkedSeries))
+	}
+	cs := resp.ChunkedSeries[0]
+	if !reflect.DeepEqual(cs.Labels, ts.Labels) {
+		t.Fatalf("unexpected labels\ngot\n%v\nwant\n%v", cs.Labels, ts.Labels)
+	}
+	if len(cs.Chunks) != 3 {
+		t.Fatalf("unexpected number of chunks; got %d; want 3", len(cs.Chunks))
+	}
+	var result []prompb.Sample
+	for _, c := range cs.Chunks {
+		if c.Type != prompb.Chunk_XOR {
+			t.Fatalf("unexpected chunk type; got %v; want %v", c.Type, prompb.Chunk_XOR)
+		}
+		chk, err := chunkenc.FromData(chunkenc.EncXOR, c.Data)
+		if err != nil {
+			t.Fatalf("cannot read chunk: %s", err)
+		}
+		it := chk.Iterator(nil)
+		for it.Next() != chunkenc.ValNone {
+			timestamp, value := it.At()
+			result = append(result, prompb.Sample{
+				Timestamp: timestamp,
+				Value:     value,
+			})
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("cannot iterate over chunk: %s", err)
+		}
+		if c.MinTimeMs != result[len(result)-chk.NumSamples()].Timestamp || c.MaxTimeMs != result[len(result)-1].Timestamp {
+			t.Fatalf("unexpected chunk time range: [%d..%d]", c.MinTimeMs, c.MaxTimeMs)
+		}
+	}
+	if !reflect.DeepEqual(result, samples) {
+		t.Fatalf("unexpected samples\ngot\n%v\nwant\n%v", result, samples)
+	}
+}
@@ -1,57 +1,70 @@
 // Code generated by qtc from "series_count_response.qtpl". DO NOT EDIT.
 // See https://github.com/valyala/quicktemplate for details.
 
-// SeriesCountResponse generates response for /api/v1/series/count .
-
 //line app/vmselect/prometheus/series_count_response.qtpl:3
 package prometheus
 
 //line app/vmselect/prometheus/series_count_response.qtpl:3
+import (
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/querytracer"
+)
+
+// SeriesCountResponse generates response for /api/v1/series/count .
+
+//line app/vmselect/prometheus/series_count_response.qtpl:8
 import (
 	qtio422016 "io"
 
 	qt422016 "github.com/valyala/quicktemplate"
 )
 
-//line app/vmselect/prometheus/series_count_response.qtpl:3
+//line app/vmselect/prometheus/series_count_response.qtpl:8
 var (
 	_ = qtio422016.Copy
 	_ = qt422016.AcquireByteBuffer
 )
 
-//line app/vmselect/prometheus/series_count_response.qtpl:3
-func StreamSeriesCountResponse(qw422016 *qt422016.Writer, n uint64) {
-//line app/vmselect/prometheus/series_count_response.qtpl:3
+//line app/vmselect/prometheus/series_count_response.qtpl:8
+func StreamSeriesCountResponse(qw422016 *qt422016.Writer, n uint64, qt *querytracer.Tracer) {
+//line app/vmselect/prometheus/series_count_response.qtpl:8
 	qw422016.N().S(`{"status":"success","data":[`)
-//line app/vmselect/prometheus/series_count_response.qtpl:6
+//line app/vmselect/prometheus/series_count_response.qtpl:11
 	qw422016.N().DL(int64(n))
-//line app/vmselect/prometheus/series_count_response.qtpl:6
-	qw422016.N().S(`]}`)
-//line app/vmselect/prometheus/series_count_response.qtpl:8
+//line app/vmselect/prometheus/series_count_response.qtpl:11
+	qw422016.N().S(`]`)
+//line app/vmselect/prometheus/series_count_response.qtpl:13
+	qt.Printf("generate response for series count=%d", n)
+	qt.Done()
+
+//line app/vmselect/prometheus/series_count_response.qtpl:16
+	streamdumpQueryTrace(qw422016, qt)
+//line app/vmselect/prometheus/series_count_response.qtpl:16
+	qw422016.N().S(`}`)
+//line app/vmselect/prometheus/series_count_response.qtpl:18
 }
 
-//line app/vmselect/prometheus/series_count_response.qtpl:8
-func WriteSeriesCountResponse(qq422016 qtio422016.Writer, n uint64) {
-//line app/vmselect/prometheus/series_count_response.qtpl:8
+//line app/vmselect/prometheus/series_count_response.qtpl:18
+func WriteSeriesCountResponse(qq422016 qtio422016.Writer, n uint64, qt *querytracer.Tracer) {
+//line app/vmselect/prometheus/series_count_response.qtpl:18
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmselect/prometheus/series_count_response.qtpl:8
-	StreamSeriesCountResponse(qw422016, n)
-//line app/vmselect/prometheus/series_count_response.qtpl:8
+//line app/vmselect/prometheus/series_count_response.qtpl:18
+	StreamSeriesCountResponse(qw422016, n, qt)
+//line app/vmselect/prometheus/series_count_response.qtpl:18
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmselect/prometheus/series_count_response.qtpl:8
+//line app/vmselect/prometheus/series_count_response.qtpl:18
 }
 
-//line app/vmselect/prometheus/series_count_response.qtpl:8
-func SeriesCountResponse(n uint64) string {
-//line app/vmselect/prometheus/series_count_response.qtpl:8
+//line app/vmselect/prometheus/series_count_response.qtpl:18
+func SeriesCountResponse(n uint64, qt *querytracer.Tracer) string {
+//line app/vmselect/prometheus/series_count_response.qtpl:18
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmselect/prometheus/series_count_response.qtpl:8
-	WriteSeriesCountResponse(qb422016, n)
-//line app/vmselect/prometheus/series_count_response.qtpl:8
+//line app/vmselect/prometheus/series_count_response.qtpl:18
+	WriteSeriesCountResponse(qb422016, n, qt)
+//line app/vmselect/prometheus/series_count_response.qtpl:18
 	qs422016 := string(qb422016.B)
-//line app/vmselect/prometheus/series_count_response.qtpl:8
+//line app/vmselect/prometheus/series_count_response.qtpl:18
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmselect/prometheus/series_count_response.qtpl:8
+//line app/vmselect/prometheus/series_count_response.qtpl:18
 	return qs422016
-//line app/vmselect/prometheus/series_count_response.qtpl:8
+//line app/vmselect/prometheus/series_count_response.qtpl:18
 }
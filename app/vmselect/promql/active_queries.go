@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmselect/searchutils"
 )
 
 // ActiveQueriesHandler returns response to /api/v1/status/active_queries
@@ -32,6 +35,26 @@ func ActiveQueriesHandler(w http.ResponseWriter, _ *http.Request) {
 	fmt.Fprintf(w, `]}`)
 }
 
+// CancelActiveQueryHandler cancels the active query with the `id` query arg.
+//
+// The id must match the id returned from /api/v1/status/active_queries.
+func CancelActiveQueryHandler(w http.ResponseWriter, r *http.Request) error {
+	idStr := r.FormValue("id")
+	if idStr == "" {
+		return fmt.Errorf("missing `id` query arg")
+	}
+	qid, err := strconv.ParseUint(idStr, 16, 64)
+	if err != nil {
+		return fmt.Errorf("cannot parse `id` query arg %q: %w", idStr, err)
+	}
+	if !activeQueriesV.Cancel(qid) {
+		return fmt.Errorf("cannot find active query with id=%q", idStr)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"status":"ok"}`)
+	return nil
+}
+
 var activeQueriesV = newActiveQueries()
 
 type activeQueries struct {
@@ -47,6 +70,9 @@ type activeQueryEntry struct {
 	quotedRemoteAddr string
 	q                string
 	startTime        time.Time
+
+	// deadline is used for canceling the query via CancelActiveQueryHandler.
+	deadline searchutils.Deadline
 }
 
 func newActiveQueries() *activeQueries {
@@ -64,6 +90,7 @@ func (aq *activeQueries) Add(ec *EvalConfig, q string) uint64 {
 	aqe.quotedRemoteAddr = ec.QuotedRemoteAddr
 	aqe.q = q
 	aqe.startTime = time.Now()
+	aqe.deadline = ec.Deadline
 
 	aq.mu.Lock()
 	aq.m[aqe.qid] = aqe
@@ -77,6 +104,20 @@ func (aq *activeQueries) Remove(qid uint64) {
 	aq.mu.Unlock()
 }
 
+// Cancel cancels the query with the given qid.
+//
+// It returns false if there is no active query with the given qid.
+func (aq *activeQueries) Cancel(qid uint64) bool {
+	aq.mu.Lock()
+	aqe, ok := aq.m[qid]
+	aq.mu.Unlock()
+	if !ok {
+		return false
+	}
+	aqe.deadline.Cancel()
+	return true
+}
+
 func (aq *activeQueries) GetAll() []activeQueryEntry {
 	aq.mu.Lock()
 	aqes := make([]activeQueryEntry, 0, len(aq.m))
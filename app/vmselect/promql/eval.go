@@ -1,6 +1,7 @@
 package promql
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"math"
@@ -174,6 +175,8 @@ type QueryStats struct {
 	SeriesFetched int64
 	// ExecutionTimeMsec contains the number of milliseconds the query took to execute.
 	ExecutionTimeMsec int64
+	// SamplesScanned contains the number of raw samples scanned during the query evaluation.
+	SamplesScanned int64
 }
 
 func (qs *QueryStats) addSeriesFetched(n int) {
@@ -183,6 +186,13 @@ func (qs *QueryStats) addSeriesFetched(n int) {
 	atomic.AddInt64(&qs.SeriesFetched, int64(n))
 }
 
+func (qs *QueryStats) addSamplesScanned(n uint64) {
+	if qs == nil {
+		return
+	}
+	atomic.AddInt64(&qs.SamplesScanned, int64(n))
+}
+
 func (qs *QueryStats) addExecutionTimeMsec(startTime time.Time) {
 	if qs == nil {
 		return
@@ -810,7 +820,13 @@ func evalRollupFunc(qt *querytracer.Tracer, ec *EvalConfig, funcName string, rf
 			Err: fmt.Errorf("`@` modifier must return a single series; it returns %d series instead", len(tssAt)),
 		}
 	}
-	atTimestamp := int64(tssAt[0].Values[0] * 1000)
+	atValue := tssAt[0].Values[0]
+	if math.IsNaN(atValue) || math.IsInf(atValue, 0) {
+		return nil, &UserReadableError{
+			Err: fmt.Errorf("`@` modifier must return a finite timestamp at the first point of the selected time range; it returns %v instead", atValue),
+		}
+	}
+	atTimestamp := int64(atValue * 1000)
 	ecNew := copyEvalConfig(ec)
 	ecNew.Start = atTimestamp
 	ecNew.End = atTimestamp
@@ -977,6 +993,7 @@ func evalRollupFuncWithSubquery(qt *querytracer.Tracer, ec *EvalConfig, funcName
 	putTimeseriesByWorkerID(tsw)
 
 	rowsScannedPerQuery.Update(float64(samplesScannedTotal))
+	ec.QueryStats.addSamplesScanned(samplesScannedTotal)
 	qt.Printf("rollup %s() over %d series returned by subquery: series=%d, samplesScanned=%d", funcName, len(tssSQ), len(tss), samplesScannedTotal)
 	return tss, nil
 }
@@ -1698,6 +1715,11 @@ func evalRollupFuncNoCache(qt *querytracer.Tracer, ec *EvalConfig, funcName stri
 	sq := storage.NewSearchQuery(minTimestamp, ec.End, tfss, ec.MaxSeries)
 	rss, err := netstorage.ProcessSearchQuery(qt, sq, ec.Deadline)
 	if err != nil {
+		var tme *storage.TooManyTimeseriesError
+		if errors.As(err, &tme) {
+			// The limit is set by the caller via ec.MaxSeries, so the caller is responsible for mentioning the corresponding command-line flag.
+			return nil, fmt.Errorf("cannot select time series for %s: %w", expr.AppendString(nil), err)
+		}
 		return nil, err
 	}
 	rssLen := rss.Len()
@@ -1763,9 +1785,9 @@ func evalRollupFuncNoCache(qt *querytracer.Tracer, ec *EvalConfig, funcName stri
 	// Evaluate rollup
 	keepMetricNames := getKeepMetricNames(expr)
 	if iafc != nil {
-		return evalRollupWithIncrementalAggregate(qt, funcName, keepMetricNames, iafc, rss, rcs, preFunc, sharedTimestamps)
+		return evalRollupWithIncrementalAggregate(qt, ec.QueryStats, funcName, keepMetricNames, iafc, rss, rcs, preFunc, sharedTimestamps)
 	}
-	return evalRollupNoIncrementalAggregate(qt, funcName, keepMetricNames, rss, rcs, preFunc, sharedTimestamps)
+	return evalRollupNoIncrementalAggregate(qt, ec.QueryStats, funcName, keepMetricNames, rss, rcs, preFunc, sharedTimestamps)
 }
 
 var (
@@ -1838,7 +1860,7 @@ func needSilenceIntervalForRollupFunc(funcName string) bool {
 	}
 }
 
-func evalRollupWithIncrementalAggregate(qt *querytracer.Tracer, funcName string, keepMetricNames bool,
+func evalRollupWithIncrementalAggregate(qt *querytracer.Tracer, qs *QueryStats, funcName string, keepMetricNames bool,
 	iafc *incrementalAggrFuncContext, rss *netstorage.Results, rcs []*rollupConfig,
 	preFunc func(values []float64, timestamps []int64), sharedTimestamps []int64) ([]*timeseries, error) {
 	qt = qt.NewChild("rollup %s() with incremental aggregation %s() over %d series; rollupConfigs=%s", funcName, iafc.ae.Name, rss.Len(), rcs)
@@ -1874,11 +1896,12 @@ func evalRollupWithIncrementalAggregate(qt *querytracer.Tracer, funcName string,
 	}
 	tss := iafc.finalizeTimeseries()
 	rowsScannedPerQuery.Update(float64(samplesScannedTotal))
+	qs.addSamplesScanned(samplesScannedTotal)
 	qt.Printf("series after aggregation with %s(): %d; samplesScanned=%d", iafc.ae.Name, len(tss), samplesScannedTotal)
 	return tss, nil
 }
 
-func evalRollupNoIncrementalAggregate(qt *querytracer.Tracer, funcName string, keepMetricNames bool, rss *netstorage.Results, rcs []*rollupConfig,
+func evalRollupNoIncrementalAggregate(qt *querytracer.Tracer, qs *QueryStats, funcName string, keepMetricNames bool, rss *netstorage.Results, rcs []*rollupConfig,
 	preFunc func(values []float64, timestamps []int64), sharedTimestamps []int64) ([]*timeseries, error) {
 	qt = qt.NewChild("rollup %s() over %d series; rollupConfigs=%s", funcName, rss.Len(), rcs)
 	defer qt.Done()
@@ -1914,6 +1937,7 @@ func evalRollupNoIncrementalAggregate(qt *querytracer.Tracer, funcName string, k
 	putTimeseriesByWorkerID(tsw)
 
 	rowsScannedPerQuery.Update(float64(samplesScannedTotal))
+	qs.addSamplesScanned(samplesScannedTotal)
 	qt.Printf("samplesScanned=%d", samplesScannedTotal)
 	return tss, nil
 }
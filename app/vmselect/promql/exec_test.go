@@ -109,6 +109,28 @@ func TestExecSuccess(t *testing.T) {
 		resultExpected := []netstorage.Result{r}
 		f(q, resultExpected)
 	})
+	t.Run("with-func-template", func(t *testing.T) {
+		t.Parallel()
+		q := `with (f(x, y) = x*y + 1, c = 2) f(time(), c)`
+		r := netstorage.Result{
+			MetricName: metricNameExpected,
+			Values:     []float64{2001, 2401, 2801, 3201, 3601, 4001},
+			Timestamps: timestampsExpected,
+		}
+		resultExpected := []netstorage.Result{r}
+		f(q, resultExpected)
+	})
+	t.Run("with-nested-templates", func(t *testing.T) {
+		t.Parallel()
+		q := `with (x = time() - 1000, f(a) = with (b = a*2) b + x) f(10)`
+		r := netstorage.Result{
+			MetricName: metricNameExpected,
+			Values:     []float64{20, 220, 420, 620, 820, 1020},
+			Timestamps: timestampsExpected,
+		}
+		resultExpected := []netstorage.Result{r}
+		f(q, resultExpected)
+	})
 	t.Run("duration-constant", func(t *testing.T) {
 		t.Parallel()
 		q := `1h23m5S`
@@ -2527,6 +2549,18 @@ func TestExecSuccess(t *testing.T) {
 		resultExpected := []netstorage.Result{r1, r2, r3}
 		f(q, resultExpected)
 	})
+	t.Run(`label_graphite_group(reorder)`, func(t *testing.T) {
+		t.Parallel()
+		q := `label_graphite_group(alias(1, "foo.bar.baz"), 2, 0, 1)`
+		r := netstorage.Result{
+			MetricName: metricNameExpected,
+			Values:     []float64{1, 1, 1, 1, 1, 1},
+			Timestamps: timestampsExpected,
+		}
+		r.MetricName.MetricGroup = []byte("baz.foo.bar")
+		resultExpected := []netstorage.Result{r}
+		f(q, resultExpected)
+	})
 	t.Run(`limit_offset`, func(t *testing.T) {
 		t.Parallel()
 		q := `limit_offset(1, 1, sort_by_label((
@@ -7469,6 +7503,28 @@ func TestExecSuccess(t *testing.T) {
 		resultExpected := []netstorage.Result{r}
 		f(q, resultExpected)
 	})
+	t.Run(`min_over_time(max_over_time(time()[200s:])[400s:])`, func(t *testing.T) {
+		t.Parallel()
+		q := `min_over_time(max_over_time(time()[200s:])[400s:])`
+		r := netstorage.Result{
+			MetricName: metricNameExpected,
+			Values:     []float64{800, 1000, 1200, 1400, 1600, 1800},
+			Timestamps: timestampsExpected,
+		}
+		resultExpected := []netstorage.Result{r}
+		f(q, resultExpected)
+	})
+	t.Run(`sum(min_over_time(max_over_time(time()[200s:])[400s:]))`, func(t *testing.T) {
+		t.Parallel()
+		q := `sum(min_over_time(max_over_time(time()[200s:])[400s:]))`
+		r := netstorage.Result{
+			MetricName: metricNameExpected,
+			Values:     []float64{800, 1000, 1200, 1400, 1600, 1800},
+			Timestamps: timestampsExpected,
+		}
+		resultExpected := []netstorage.Result{r}
+		f(q, resultExpected)
+	})
 	t.Run(`increase_pure(time())`, func(t *testing.T) {
 		t.Parallel()
 		q := `increase_pure(time())`
@@ -9008,6 +9064,11 @@ func TestExecError(t *testing.T) {
 	// Non-existing func
 	f(`nonexisting()`)
 
+	// Invalid `@` modifier
+	f(`time() @ (time() > 1e9)`)
+	f(`time() @ (1/0)`)
+	f(`time() @ (label_set(time(), "foo", "bar") or label_set(time(), "x", "y"))`)
+
 	// Invalid number of args
 	f(`range_stddev()`)
 	f(`range_stdvar()`)
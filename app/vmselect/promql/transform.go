@@ -864,7 +864,7 @@ func transformHistogramQuantiles(tfa *transformFuncArg) ([]*timeseries, error) {
 	// Calculate quantile individually per each phi.
 	var rvs []*timeseries
 	for i, phiArg := range phiArgs {
-		phis, err := getScalar(phiArg, i)
+		phis, err := getScalar(phiArg, i+1)
 		if err != nil {
 			return nil, fmt.Errorf("cannot parse phi: %w", err)
 		}
@@ -2166,9 +2166,12 @@ func transformLabelGraphiteGroup(tfa *transformFuncArg) ([]*timeseries, error) {
 		}
 		groupIDs[i] = groupID
 	}
+	var groupName []byte
 	for _, ts := range tss {
+		// groups refer to ts.MetricName.MetricGroup, so the new name must be built in a separate buffer.
+		// Otherwise the groups may be overwritten when groupIDs aren't sorted in ascending order.
 		groups := bytes.Split(ts.MetricName.MetricGroup, dotSeparator)
-		groupName := ts.MetricName.MetricGroup[:0]
+		groupName = groupName[:0]
 		for j, groupID := range groupIDs {
 			if groupID >= 0 && groupID < len(groups) {
 				groupName = append(groupName, groups[groupID]...)
@@ -2177,7 +2180,7 @@ func transformLabelGraphiteGroup(tfa *transformFuncArg) ([]*timeseries, error) {
 				groupName = append(groupName, '.')
 			}
 		}
-		ts.MetricName.MetricGroup = groupName
+		ts.MetricName.MetricGroup = append(ts.MetricName.MetricGroup[:0], groupName...)
 	}
 	return tss, nil
 }
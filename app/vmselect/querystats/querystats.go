@@ -162,7 +162,10 @@ func (qst *queryStatsTracker) getTopByCount(topN int, maxLifetime time.Duration)
 		})
 	}
 	sort.Slice(a, func(i, j int) bool {
-		return a[i].count > a[j].count
+		if a[i].count != a[j].count {
+			return a[i].count > a[j].count
+		}
+		return lessQueryStatKey(a[i].query, a[i].timeRangeSecs, a[j].query, a[j].timeRangeSecs)
 	})
 	if len(a) > topN {
 		a = a[:topN]
@@ -205,7 +208,10 @@ func (qst *queryStatsTracker) getTopByAvgDuration(topN int, maxLifetime time.Dur
 		})
 	}
 	sort.Slice(a, func(i, j int) bool {
-		return a[i].duration > a[j].duration
+		if a[i].duration != a[j].duration {
+			return a[i].duration > a[j].duration
+		}
+		return lessQueryStatKey(a[i].query, a[i].timeRangeSecs, a[j].query, a[j].timeRangeSecs)
 	})
 	if len(a) > topN {
 		a = a[:topN]
@@ -220,6 +226,14 @@ type queryStatByDuration struct {
 	count         int
 }
 
+// lessQueryStatKey is used for sorting entries with equal stats in a deterministic order.
+func lessQueryStatKey(queryA string, timeRangeSecsA int64, queryB string, timeRangeSecsB int64) bool {
+	if queryA != queryB {
+		return queryA < queryB
+	}
+	return timeRangeSecsA < timeRangeSecsB
+}
+
 func (qst *queryStatsTracker) getTopBySumDuration(topN int, maxLifetime time.Duration) []queryStatByDuration {
 	currentTime := time.Now()
 	qst.mu.Lock()
@@ -249,7 +263,10 @@ func (qst *queryStatsTracker) getTopBySumDuration(topN int, maxLifetime time.Dur
 		})
 	}
 	sort.Slice(a, func(i, j int) bool {
-		return a[i].duration > a[j].duration
+		if a[i].duration != a[j].duration {
+			return a[i].duration > a[j].duration
+		}
+		return lessQueryStatKey(a[i].query, a[i].timeRangeSecs, a[j].query, a[j].timeRangeSecs)
 	})
 	if len(a) > topN {
 		a = a[:topN]
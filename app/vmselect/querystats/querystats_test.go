@@ -0,0 +1,70 @@
+package querystats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQueryStatsTrackerGetTop(t *testing.T) {
+	qst := &queryStatsTracker{
+		a: make([]queryStatRecord, 10),
+	}
+	now := time.Now()
+	register := func(query string, timeRangeSecs int64, duration time.Duration) {
+		qst.registerQuery(query, timeRangeSecs*1000, now.Add(-duration))
+	}
+	register("foo", 60, time.Second)
+	register("foo", 60, 3*time.Second)
+	register("bar", 60, 1500*time.Millisecond)
+	register("baz", 60, 2500*time.Millisecond)
+	register("foo", 3600, 10*time.Second)
+
+	roundDuration := func(d time.Duration) time.Duration {
+		return d.Round(100 * time.Millisecond)
+	}
+
+	// top by count
+	topByCount := qst.getTopByCount(3, time.Minute)
+	topByCountExpected := []queryStatByCount{
+		{query: "foo", timeRangeSecs: 60, count: 2},
+		{query: "bar", timeRangeSecs: 60, count: 1},
+		{query: "baz", timeRangeSecs: 60, count: 1},
+	}
+	if !reflect.DeepEqual(topByCount, topByCountExpected) {
+		t.Fatalf("unexpected topByCount\ngot\n%+v\nwant\n%+v", topByCount, topByCountExpected)
+	}
+
+	// top by avg duration
+	topByAvgDuration := qst.getTopByAvgDuration(10, time.Minute)
+	for i := range topByAvgDuration {
+		topByAvgDuration[i].duration = roundDuration(topByAvgDuration[i].duration)
+	}
+	topByAvgDurationExpected := []queryStatByDuration{
+		{query: "foo", timeRangeSecs: 3600, duration: 10 * time.Second, count: 1},
+		{query: "baz", timeRangeSecs: 60, duration: 2500 * time.Millisecond, count: 1},
+		{query: "foo", timeRangeSecs: 60, duration: 2 * time.Second, count: 2},
+		{query: "bar", timeRangeSecs: 60, duration: 1500 * time.Millisecond, count: 1},
+	}
+	if !reflect.DeepEqual(topByAvgDuration, topByAvgDurationExpected) {
+		t.Fatalf("unexpected topByAvgDuration\ngot\n%+v\nwant\n%+v", topByAvgDuration, topByAvgDurationExpected)
+	}
+
+	// top by sum duration
+	topBySumDuration := qst.getTopBySumDuration(2, time.Minute)
+	for i := range topBySumDuration {
+		topBySumDuration[i].duration = roundDuration(topBySumDuration[i].duration)
+	}
+	topBySumDurationExpected := []queryStatByDuration{
+		{query: "foo", timeRangeSecs: 3600, duration: 10 * time.Second, count: 1},
+		{query: "foo", timeRangeSecs: 60, duration: 4 * time.Second, count: 2},
+	}
+	if !reflect.DeepEqual(topBySumDuration, topBySumDurationExpected) {
+		t.Fatalf("unexpected topBySumDuration\ngot\n%+v\nwant\n%+v", topBySumDuration, topBySumDurationExpected)
+	}
+
+	// zero topN
+	if a := qst.getTopByCount(0, time.Minute); len(a) != 0 {
+		t.Fatalf("expecting empty result for zero topN; got %+v", a)
+	}
+}
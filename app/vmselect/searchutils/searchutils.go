@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
@@ -68,6 +69,9 @@ type Deadline struct {
 
 	timeout  time.Duration
 	flagHint string
+
+	// canceled is shared among all the copies of the Deadline, so Cancel call is visible to all of them.
+	canceled *atomic.Bool
 }
 
 // NewDeadline returns deadline for the given timeout.
@@ -79,14 +83,40 @@ func NewDeadline(startTime time.Time, timeout time.Duration, flagHint string) De
 		deadline: uint64(startTime.Add(timeout).Unix()),
 		timeout:  timeout,
 		flagHint: flagHint,
+		canceled: &atomic.Bool{},
 	}
 }
 
-// Exceeded returns true if deadline is exceeded.
+// Exceeded returns true if deadline is exceeded or if it has been canceled via Cancel.
 func (d *Deadline) Exceeded() bool {
+	if d.IsCanceled() {
+		return true
+	}
 	return fasttime.UnixTimestamp() > d.deadline
 }
 
+// Cancel cancels the deadline, so Exceeded returns true for it and for all its copies.
+//
+// Cancel is no-op for deadlines without NewDeadline call.
+func (d *Deadline) Cancel() {
+	if d.canceled != nil {
+		d.canceled.Store(true)
+	}
+}
+
+// IsCanceled returns true if d has been canceled via Cancel.
+func (d *Deadline) IsCanceled() bool {
+	return d.canceled != nil && d.canceled.Load()
+}
+
+// CancelFlag returns the flag, which is set to true on Cancel call.
+//
+// It must be passed to storage search together with Deadline, so the search is stopped on Cancel call.
+// nil is returned for deadlines without NewDeadline call.
+func (d *Deadline) CancelFlag() *atomic.Bool {
+	return d.canceled
+}
+
 // Deadline returns deadline in unix timestamp seconds.
 func (d *Deadline) Deadline() uint64 {
 	return d.deadline
@@ -96,6 +126,9 @@ func (d *Deadline) Deadline() uint64 {
 func (d *Deadline) String() string {
 	startTime := time.Unix(int64(d.deadline), 0).Add(-d.timeout)
 	elapsed := time.Since(startTime)
+	if d.IsCanceled() {
+		return fmt.Sprintf("the query has been canceled after %.3f seconds", elapsed.Seconds())
+	}
 	msg := fmt.Sprintf("%.3f seconds (elapsed %.3f seconds)", d.timeout.Seconds(), elapsed.Seconds())
 	if float64(elapsed)/float64(d.timeout) > 0.9 && d.flagHint != "" {
 		msg += fmt.Sprintf("; the timeout can be adjusted with `%s` command-line flag", d.flagHint)
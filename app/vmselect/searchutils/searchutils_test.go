@@ -6,11 +6,44 @@ import (
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/storage"
 )
 
+func TestDeadlineCancel(t *testing.T) {
+	d := NewDeadline(time.Now(), time.Hour, "")
+	dCopy := d
+	if d.Exceeded() {
+		t.Fatalf("deadline mustn't be exceeded")
+	}
+	dCopy.Cancel()
+	if !d.Exceeded() {
+		t.Fatalf("deadline must be exceeded after canceling its copy")
+	}
+	if !d.IsCanceled() {
+		t.Fatalf("deadline must be canceled")
+	}
+	if cf := d.CancelFlag(); cf == nil || !cf.Load() {
+		t.Fatalf("cancel flag must be set for canceled deadline")
+	}
+	if s := d.String(); !strings.Contains(s, "canceled") {
+		t.Fatalf("unexpected string representation for canceled deadline: %q", s)
+	}
+
+	// Cancel must be no-op for zero deadline
+	var dZero Deadline
+	dZero.Cancel()
+	if dZero.IsCanceled() {
+		t.Fatalf("zero deadline mustn't be canceled")
+	}
+	if cf := dZero.CancelFlag(); cf != nil {
+		t.Fatalf("cancel flag must be nil for zero deadline")
+	}
+}
+
 func TestGetExtraTagFilters(t *testing.T) {
 	httpReqWithForm := func(qs string) *http.Request {
 		q, err := url.ParseQuery(qs)
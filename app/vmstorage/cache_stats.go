@@ -0,0 +1,117 @@
+package vmstorage
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/storage"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+// cacheStats contains statistics for a single cache.
+//
+// evictions is tracked only by block caches. Other caches overwrite old entries when the size limit is reached
+// without tracking evictions, so it is always 0 for them.
+type cacheStats struct {
+	name         string
+	entries      uint64
+	sizeBytes    uint64
+	maxSizeBytes uint64
+	requests     uint64
+	misses       uint64
+	evictions    uint64
+}
+
+func (cs *cacheStats) hitRatio() float64 {
+	if cs.requests == 0 {
+		return 0
+	}
+	return 1 - float64(cs.misses)/float64(cs.requests)
+}
+
+// getCacheStats returns statistics for the main caches used by strg.
+func getCacheStats(strg *storage.Storage) []cacheStats {
+	var m storage.Metrics
+	strg.UpdateMetrics(&m)
+	tm := &m.TableMetrics
+	idbm := &m.IndexDBMetrics
+	return []cacheStats{
+		{
+			name:         "storage/tsid",
+			entries:      m.TSIDCacheSize,
+			sizeBytes:    m.TSIDCacheSizeBytes,
+			maxSizeBytes: m.TSIDCacheSizeMaxBytes,
+			requests:     m.TSIDCacheRequests,
+			misses:       m.TSIDCacheMisses,
+		},
+		{
+			name:         "storage/metricIDs",
+			entries:      m.MetricIDCacheSize,
+			sizeBytes:    m.MetricIDCacheSizeBytes,
+			maxSizeBytes: m.MetricIDCacheSizeMaxBytes,
+			requests:     m.MetricIDCacheRequests,
+			misses:       m.MetricIDCacheMisses,
+		},
+		{
+			name:         "storage/metricName",
+			entries:      m.MetricNameCacheSize,
+			sizeBytes:    m.MetricNameCacheSizeBytes,
+			maxSizeBytes: m.MetricNameCacheSizeMaxBytes,
+			requests:     m.MetricNameCacheRequests,
+			misses:       m.MetricNameCacheMisses,
+		},
+		{
+			name:         "storage/indexBlocks",
+			entries:      tm.IndexBlocksCacheSize,
+			sizeBytes:    tm.IndexBlocksCacheSizeBytes,
+			maxSizeBytes: tm.IndexBlocksCacheSizeMaxBytes,
+			requests:     tm.IndexBlocksCacheRequests,
+			misses:       tm.IndexBlocksCacheMisses,
+			evictions:    tm.IndexBlocksCacheEvictions,
+		},
+		{
+			name:         "indexdb/dataBlocks",
+			entries:      idbm.DataBlocksCacheSize,
+			sizeBytes:    idbm.DataBlocksCacheSizeBytes,
+			maxSizeBytes: idbm.DataBlocksCacheSizeMaxBytes,
+			requests:     idbm.DataBlocksCacheRequests,
+			misses:       idbm.DataBlocksCacheMisses,
+			evictions:    idbm.DataBlocksCacheEvictions,
+		},
+		{
+			name:         "indexdb/indexBlocks",
+			entries:      idbm.IndexBlocksCacheSize,
+			sizeBytes:    idbm.IndexBlocksCacheSizeBytes,
+			maxSizeBytes: idbm.IndexBlocksCacheSizeMaxBytes,
+			requests:     idbm.IndexBlocksCacheRequests,
+			misses:       idbm.IndexBlocksCacheMisses,
+			evictions:    idbm.IndexBlocksCacheEvictions,
+		},
+		{
+			name:         "indexdb/tagFiltersToMetricIDs",
+			entries:      idbm.TagFiltersToMetricIDsCacheSize,
+			sizeBytes:    idbm.TagFiltersToMetricIDsCacheSizeBytes,
+			maxSizeBytes: idbm.TagFiltersToMetricIDsCacheSizeMaxBytes,
+			requests:     idbm.TagFiltersToMetricIDsCacheRequests,
+			misses:       idbm.TagFiltersToMetricIDsCacheMisses,
+		},
+	}
+}
+
+// writeCacheStatsResponse writes cache statistics in JSON to w.
+func writeCacheStatsResponse(w http.ResponseWriter, css []cacheStats) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"status":"success","data":[`)
+	for i := range css {
+		cs := &css[i]
+		if i > 0 {
+			fmt.Fprintf(w, ",")
+		}
+		fmt.Fprintf(w, `{"name":%q,"entries":%d,"sizeBytes":%d,"maxSizeBytes":%d,"requests":%d,"misses":%d,"evictions":%d,"hitRatio":%.4f}`,
+			cs.name, cs.entries, cs.sizeBytes, cs.maxSizeBytes, cs.requests, cs.misses, cs.evictions, cs.hitRatio())
+	}
+	fmt.Fprintf(w, `]}`)
+}
+
+var statusCachesRequests = metrics.NewCounter(`vm_http_requests_total{path="/api/v1/status/caches"}`)
@@ -26,21 +26,31 @@ import (
 )
 
 var (
-	retentionPeriod       = flagutil.NewDuration("retentionPeriod", "1", "Data with timestamps outside the retentionPeriod is automatically deleted. The minimum retentionPeriod is 24h or 1d. See also -retentionFilter")
-	snapshotAuthKey       = flagutil.NewPassword("snapshotAuthKey", "authKey, which must be passed in query string to /snapshot* pages")
-	forceMergeAuthKey     = flagutil.NewPassword("forceMergeAuthKey", "authKey, which must be passed in query string to /internal/force_merge pages")
-	forceFlushAuthKey     = flagutil.NewPassword("forceFlushAuthKey", "authKey, which must be passed in query string to /internal/force_flush pages")
-	snapshotsMaxAge       = flagutil.NewDuration("snapshotsMaxAge", "0", "Automatically delete snapshots older than -snapshotsMaxAge if it is set to non-zero duration. Make sure that backup process has enough time to finish the backup before the corresponding snapshot is automatically deleted")
-	snapshotCreateTimeout = flag.Duration("snapshotCreateTimeout", 0, "The timeout for creating new snapshot. If set, make sure that timeout is lower than backup period")
+	retentionPeriod  = flagutil.NewDuration("retentionPeriod", "1", "Data with timestamps outside the retentionPeriod is automatically deleted. The minimum retentionPeriod is 24h or 1d. See also -retentionFilter")
+	retentionFilters = flagutil.NewArrayString("retentionFilter", "Retention filter in the format 'filter:retention'. For example, '{env=\"dev\"}:3d' configures the retention "+
+		"for time series with env=\"dev\" label to 3 days. See https://docs.victoriametrics.com/#retention-filters for details")
+	snapshotAuthKey        = flagutil.NewPassword("snapshotAuthKey", "authKey, which must be passed in query string to /snapshot* pages")
+	forceMergeAuthKey      = flagutil.NewPassword("forceMergeAuthKey", "authKey, which must be passed in query string to /internal/force_merge pages")
+	forceFlushAuthKey      = flagutil.NewPassword("forceFlushAuthKey", "authKey, which must be passed in query string to /internal/force_flush pages")
+	snapshotsMaxAge        = flagutil.NewDuration("snapshotsMaxAge", "0", "Automatically delete snapshots older than -snapshotsMaxAge if it is set to non-zero duration. Make sure that backup process has enough time to finish the backup before the corresponding snapshot is automatically deleted")
+	snapshotCreateTimeout  = flag.Duration("snapshotCreateTimeout", 0, "The timeout for creating new snapshot. If set, make sure that timeout is lower than backup period")
+	snapshotCreateInterval = flag.Duration("snapshotCreateInterval", 0, "The interval for automatic creation of snapshots. Snapshots aren't created automatically if it is set to zero. "+
+		"See also -snapshotsMaxAge for automatic deletion of old snapshots. See https://docs.victoriametrics.com/#how-to-work-with-snapshots")
 
 	precisionBits = flag.Int("precisionBits", 64, "The number of precision bits to store per each value. Lower precision bits improves data compression at the cost of precision loss")
 
 	// DataPath is a path to storage data.
 	DataPath = flag.String("storageDataPath", "victoria-metrics-data", "Path to storage data")
 
-	_ = flag.Duration("finalMergeDelay", 0, "Deprecated: this flag does nothing")
-	_ = flag.Int("bigMergeConcurrency", 0, "Deprecated: this flag does nothing")
-	_ = flag.Int("smallMergeConcurrency", 0, "Deprecated: this flag does nothing")
+	_                   = flag.Duration("finalMergeDelay", 0, "Deprecated: this flag does nothing")
+	bigMergeConcurrency = flag.Int("bigMergeConcurrency", 0, "The maximum number of concurrent merges for big parts. "+
+		"The default value depends on the number of available CPU cores. Smaller values may help reducing disk IO usage at the cost of bigger number of parts. "+
+		"See https://docs.victoriametrics.com/#merge-throttling")
+	smallMergeConcurrency = flag.Int("smallMergeConcurrency", 0, "The maximum number of concurrent merges for small parts. "+
+		"The default value depends on the number of available CPU cores. Smaller values may help reducing disk IO usage at the cost of bigger number of parts. "+
+		"See https://docs.victoriametrics.com/#merge-throttling")
+	mergeMaxBytesPerSecond = flagutil.NewBytes("storage.mergeMaxBytesPerSecond", 0, "The maximum speed for writing data to disk during background merges. "+
+		"There is no limit if it is set to 0. This may be useful for disks with limited IOPS or bandwidth. See https://docs.victoriametrics.com/#merge-throttling")
 
 	retentionTimezoneOffset = flag.Duration("retentionTimezoneOffset", 0, "The offset for performing indexdb rotation. "+
 		"If set to 0, then the indexdb rotation is performed at 4am UTC time per each -retentionPeriod. "+
@@ -95,6 +105,9 @@ func Init(resetCacheIfNeeded func(mrs []storage.MetricRow)) {
 	storage.SetLogNewSeries(*logNewSeries)
 	storage.SetRetentionTimezoneOffset(*retentionTimezoneOffset)
 	storage.SetFreeDiskSpaceLimit(minFreeDiskSpaceBytes.N)
+	storage.SetSmallMergeConcurrency(*smallMergeConcurrency)
+	storage.SetBigMergeConcurrency(*bigMergeConcurrency)
+	storage.SetMergeMaxBytesPerSecond(mergeMaxBytesPerSecond.IntN())
 	storage.SetTSIDCacheSize(cacheSizeStorageTSID.IntN())
 	storage.SetTagFiltersCacheSize(cacheSizeIndexDBTagFilters.IntN())
 	mergeset.SetIndexBlocksCacheSize(cacheSizeIndexDBIndexBlocks.IntN())
@@ -103,12 +116,23 @@ func Init(resetCacheIfNeeded func(mrs []storage.MetricRow)) {
 	if retentionPeriod.Duration() < 24*time.Hour {
 		logger.Fatalf("-retentionPeriod cannot be smaller than a day; got %s", retentionPeriod)
 	}
+	rfs, err := storage.ParseRetentionFilters(*retentionFilters, retentionPeriod.Duration())
+	if err != nil {
+		logger.Fatalf("cannot parse -retentionFilter: %s", err)
+	}
+	storage.SetRetentionFilters(rfs)
+	if *maxHourlySeries > 0 && *maxDailySeries > 0 && *maxDailySeries < *maxHourlySeries {
+		logger.Warnf("-storage.maxDailySeries=%d is smaller than -storage.maxHourlySeries=%d; "+
+			"this means that the number of unique series during the last hour is limited by -storage.maxDailySeries; "+
+			"see https://docs.victoriametrics.com/#cardinality-limiter", *maxDailySeries, *maxHourlySeries)
+	}
 	logger.Infof("opening storage at %q with -retentionPeriod=%s", *DataPath, retentionPeriod)
 	startTime := time.Now()
 	WG = syncwg.WaitGroup{}
 	strg := storage.MustOpenStorage(*DataPath, retentionPeriod.Duration(), *maxHourlySeries, *maxDailySeries)
 	Storage = strg
 	initStaleSnapshotsRemover(strg)
+	initSnapshotsCreator(strg)
 
 	var m storage.Metrics
 	strg.UpdateMetrics(&m)
@@ -245,6 +269,7 @@ func Stop() {
 	logger.Infof("gracefully closing the storage at %s", *DataPath)
 	startTime := time.Now()
 	WG.WaitAndBlock()
+	stopSnapshotsCreator()
 	stopStaleSnapshotsRemover()
 	Storage.MustClose()
 	logger.Infof("successfully closed the storage in %.3f seconds", time.Since(startTime).Seconds())
@@ -255,6 +280,12 @@ func Stop() {
 // RequestHandler is a storage request handler.
 func RequestHandler(w http.ResponseWriter, r *http.Request) bool {
 	path := r.URL.Path
+	if path == "/api/v1/status/caches" {
+		statusCachesRequests.Inc()
+		css := getCacheStats(Storage)
+		writeCacheStatsResponse(w, css)
+		return true
+	}
 	if path == "/internal/force_merge" {
 		if !httpserver.CheckAuthFlag(w, r, forceMergeAuthKey.Get(), "forceMergeAuthKey") {
 			return true
@@ -424,6 +455,52 @@ var (
 	staleSnapshotsRemoverWG sync.WaitGroup
 )
 
+func initSnapshotsCreator(strg *storage.Storage) {
+	snapshotsCreatorCh = make(chan struct{})
+	if *snapshotCreateInterval <= 0 {
+		return
+	}
+	snapshotsCreatorWG.Add(1)
+	go func() {
+		defer snapshotsCreatorWG.Done()
+		t := time.NewTicker(*snapshotCreateInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-snapshotsCreatorCh:
+				return
+			case <-t.C:
+			}
+			deadline := uint64(0)
+			if *snapshotCreateTimeout > 0 {
+				deadline = fasttime.UnixTimestamp() + uint64(snapshotCreateTimeout.Seconds())
+			}
+			snapshotPath, err := strg.CreateSnapshot(deadline)
+			if err != nil {
+				// Use logger.Errorf instead of logger.Fatalf in the hope the error is temporary.
+				logger.Errorf("cannot create scheduled snapshot: %s", err)
+				scheduledSnapshotsCreateErrorsTotal.Inc()
+				continue
+			}
+			scheduledSnapshotsCreateTotal.Inc()
+			logger.Infof("created scheduled snapshot %q according to -snapshotCreateInterval=%s", snapshotPath, *snapshotCreateInterval)
+		}
+	}()
+}
+
+func stopSnapshotsCreator() {
+	close(snapshotsCreatorCh)
+	snapshotsCreatorWG.Wait()
+}
+
+var (
+	snapshotsCreatorCh chan struct{}
+	snapshotsCreatorWG sync.WaitGroup
+
+	scheduledSnapshotsCreateTotal       = metrics.NewCounter(`vm_scheduled_snapshots_created_total`)
+	scheduledSnapshotsCreateErrorsTotal = metrics.NewCounter(`vm_scheduled_snapshots_create_errors_total`)
+)
+
 var (
 	activeForceMerges = metrics.NewCounter("vm_active_force_merges")
 
@@ -549,6 +626,7 @@ func writeStorageMetrics(w io.Writer, strg *storage.Storage) {
 
 	metrics.WriteCounterUint64(w, `vm_timestamps_blocks_merged_total`, m.TimestampsBlocksMerged)
 	metrics.WriteCounterUint64(w, `vm_timestamps_bytes_saved_total`, m.TimestampsBytesSaved)
+	metrics.WriteCounterFloat64(w, `vm_merge_throttled_seconds_total`, float64(m.MergeThrottledMsecs)/1e3)
 
 	metrics.WriteGaugeUint64(w, `vm_rows{type="storage/inmemory"}`, tm.InmemoryRowsCount)
 	metrics.WriteGaugeUint64(w, `vm_rows{type="storage/small"}`, tm.SmallRowsCount)
@@ -1,2 +1,7 @@
 export const getActiveQueries = (server: string): string =>
   `${server}/api/v1/status/active_queries`;
+
+export const getCancelActiveQuery = (server: string, id: string, authKey?: string): string => {
+  const authKeyParam = authKey ? `&authKey=${encodeURIComponent(authKey)}` : "";
+  return `${server}/api/v1/status/active_queries/cancel?id=${encodeURIComponent(id)}${authKeyParam}`;
+};
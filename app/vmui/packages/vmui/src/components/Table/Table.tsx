@@ -1,4 +1,4 @@
-import React, { useState, useMemo } from "react";
+import React, { useState, useMemo, ReactNode } from "react";
 import classNames from "classnames";
 import { ArrowDropDownIcon, CopyIcon, DoneIcon } from "../Main/Icons";
 import { getComparator, stableSort } from "./helpers";
@@ -11,6 +11,8 @@ interface TableProps<T> {
   columns: { title?: string, key: keyof Partial<T>, className?: string }[];
   defaultOrderBy: keyof T;
   copyToClipboard?: keyof T;
+  // rowActions renders additional controls for the given row in the last column.
+  rowActions?: (row: T) => ReactNode;
   // TODO: Remove when pagination is implemented on the backend.
   paginationOffset: {
     startIndex: number;
@@ -18,7 +20,14 @@ interface TableProps<T> {
   }
 }
 
-const Table = <T extends object>({ rows, columns, defaultOrderBy, copyToClipboard, paginationOffset }: TableProps<T>) => {
+const Table = <T extends object>({
+  rows,
+  columns,
+  defaultOrderBy,
+  copyToClipboard,
+  rowActions,
+  paginationOffset
+}: TableProps<T>) => {
   const [orderBy, setOrderBy] = useState<keyof T>(defaultOrderBy);
   const [orderDir, setOrderDir] = useState<"asc" | "desc">("desc");
   const [copied, setCopied] = useState<number | null>(null);
@@ -79,6 +88,7 @@ const Table = <T extends object>({ rows, columns, defaultOrderBy, copyToClipboar
               </div>
             </th>
           ))}
+          {rowActions && <th className="vm-table-cell vm-table-cell_header"/>}
           {copyToClipboard && <th className="vm-table-cell vm-table-cell_header"/>}
         </tr>
       </thead>
@@ -99,6 +109,13 @@ const Table = <T extends object>({ rows, columns, defaultOrderBy, copyToClipboar
                 {row[col.key] || "-"}
               </td>
             ))}
+            {rowActions && (
+              <td className="vm-table-cell vm-table-cell_right">
+                <div className="vm-table-cell__content">
+                  {rowActions(row)}
+                </div>
+              </td>
+            )}
             {copyToClipboard && (
               <td className="vm-table-cell vm-table-cell_right">
                 {row[copyToClipboard] && (
@@ -0,0 +1,40 @@
+import { useState } from "preact/compat";
+import { getCancelActiveQuery } from "../../../api/active-queries";
+import { useAppState } from "../../../state/common/StateContext";
+import { ErrorTypes } from "../../../types";
+
+interface CancelActiveQuery {
+  cancelingId?: string;
+  error?: ErrorTypes | string;
+  cancelQuery: (id: string, authKey?: string) => Promise<boolean>;
+}
+
+export const useCancelActiveQuery = (): CancelActiveQuery => {
+  const { serverUrl } = useAppState();
+
+  const [cancelingId, setCancelingId] = useState<string>();
+  const [error, setError] = useState<ErrorTypes | string>();
+
+  const cancelQuery = async (id: string, authKey?: string) => {
+    setCancelingId(id);
+    let ok = false;
+    try {
+      const response = await fetch(getCancelActiveQuery(serverUrl, id, authKey), { method: "POST" });
+      if (response.ok) {
+        setError(undefined);
+        ok = true;
+      } else {
+        const text = await response.text();
+        setError(`Cannot cancel query with id=${id}: ${text}`);
+      }
+    } catch (e) {
+      if (e instanceof Error) {
+        setError(`${e.name}: ${e.message}`);
+      }
+    }
+    setCancelingId(undefined);
+    return ok;
+  };
+
+  return { cancelingId, error, cancelQuery };
+};
@@ -1,5 +1,6 @@
-import React, { FC, useMemo } from "preact/compat";
+import React, { FC, useMemo, useState } from "preact/compat";
 import { useFetchActiveQueries } from "./hooks/useFetchActiveQueries";
+import { useCancelActiveQuery } from "./hooks/useCancelActiveQuery";
 import Alert from "../../components/Main/Alert/Alert";
 import Spinner from "../../components/Main/Spinner/Spinner";
 import Table from "../../components/Table/Table";
@@ -9,7 +10,9 @@ import { useTimeState } from "../../state/time/TimeStateContext";
 import useDeviceDetect from "../../hooks/useDeviceDetect";
 import classNames from "classnames";
 import Button from "../../components/Main/Button/Button";
-import { RefreshIcon } from "../../components/Main/Icons";
+import { CloseIcon, RefreshIcon } from "../../components/Main/Icons";
+import TextField from "../../components/Main/TextField/TextField";
+import Tooltip from "../../components/Main/Tooltip/Tooltip";
 import "./style.scss";
 import { DATE_TIME_FORMAT } from "../../constants/date";
 import { roundStep } from "../../utils/time";
@@ -19,18 +22,29 @@ const ActiveQueries: FC = () => {
   const { timezone } = useTimeState();
 
   const { data, lastUpdated, isLoading, error, fetchData } = useFetchActiveQueries();
+  const { cancelingId, error: cancelError, cancelQuery } = useCancelActiveQuery();
 
-  const activeQueries = useMemo(() => data.map((item: ActiveQueriesType) => {
-    const from = dayjs(item.start).tz().format(DATE_TIME_FORMAT);
-    const to = dayjs(item.end).tz().format(DATE_TIME_FORMAT);
-    return {
-      duration: item.duration,
-      remote_addr: item.remote_addr,
-      query: item.query,
-      args: `${from} to ${to}, step=${roundStep(item.step)}`,
-      data: JSON.stringify(item, null, 2),
-    } as ActiveQueriesType;
-  }), [data, timezone]);
+  const [filter, setFilter] = useState("");
+  const [authKey, setAuthKey] = useState("");
+
+  const activeQueries = useMemo(() => {
+    const filterLower = filter.toLowerCase();
+    return data.filter((item: ActiveQueriesType) => {
+      if (!filterLower) return true;
+      return item.query.toLowerCase().includes(filterLower) || item.remote_addr.toLowerCase().includes(filterLower);
+    }).map((item: ActiveQueriesType) => {
+      const from = dayjs(item.start).tz().format(DATE_TIME_FORMAT);
+      const to = dayjs(item.end).tz().format(DATE_TIME_FORMAT);
+      return {
+        duration: item.duration,
+        remote_addr: item.remote_addr,
+        query: item.query,
+        args: `${from} to ${to}, step=${roundStep(item.step)}`,
+        id: item.id,
+        data: JSON.stringify(item, null, 2),
+      } as ActiveQueriesType;
+    });
+  }, [data, timezone, filter]);
 
   const columns = useMemo(() => {
     if (!activeQueries?.length) return [];
@@ -39,7 +53,7 @@ const ActiveQueries: FC = () => {
     const titles: Partial<Record<keyof ActiveQueriesType, string>> = {
       remote_addr: "client address",
     };
-    const hideColumns = ["data"];
+    const hideColumns = ["data", "id"];
 
     return keys.filter((col) => !hideColumns.includes(col)).map((key) => ({
       key: key,
@@ -51,12 +65,43 @@ const ActiveQueries: FC = () => {
     fetchData().catch(console.error);
   };
 
+  const createCancelHandler = (id: string) => async () => {
+    const ok = await cancelQuery(id, authKey);
+    if (ok) fetchData().catch(console.error);
+  };
+
+  const renderRowActions = (row: ActiveQueriesType) => (
+    <Tooltip title="Cancel query">
+      <Button
+        variant="text"
+        color="error"
+        size="small"
+        startIcon={<CloseIcon/>}
+        disabled={cancelingId === row.id}
+        onClick={createCancelHandler(row.id)}
+        ariaLabel="cancel query"
+      />
+    </Tooltip>
+  );
+
   return (
     <div className="vm-active-queries">
       {isLoading && <Spinner />}
       <div className="vm-active-queries-header">
-        {!activeQueries.length && !error && <Alert variant="info">There are currently no active queries running</Alert>}
-        {error && <Alert variant="error">{error}</Alert>}
+        <div className="vm-active-queries-header-filters">
+          <TextField
+            label="Filter by query or client address"
+            value={filter}
+            onChange={setFilter}
+          />
+          <TextField
+            label="Auth key for canceling queries"
+            type="password"
+            value={authKey}
+            helperText="Required if -search.cancelQueryAuthKey is set"
+            onChange={setAuthKey}
+          />
+        </div>
         <div className="vm-active-queries-header-controls">
           <Button
             variant="contained"
@@ -69,6 +114,13 @@ const ActiveQueries: FC = () => {
             Last updated: {lastUpdated}
           </div>
         </div>
+        {!activeQueries.length && !error && (
+          <Alert variant="info">
+            {data.length ? "No active queries match the filter" : "There are currently no active queries running"}
+          </Alert>
+        )}
+        {error && <Alert variant="error">{error}</Alert>}
+        {cancelError && <Alert variant="error">{cancelError}</Alert>}
       </div>
       {!!activeQueries.length && (
         <div
@@ -82,6 +134,7 @@ const ActiveQueries: FC = () => {
             columns={columns}
             defaultOrderBy={"duration"}
             copyToClipboard={"data"}
+            rowActions={renderRowActions}
             paginationOffset={{ startIndex: 0, endIndex: Infinity }}
           />
         </div>
@@ -36,6 +36,12 @@ type Restore struct {
 	//
 	// This may be needed for restoring from old backups with missing `backup complete` file.
 	SkipBackupCompleteCheck bool
+
+	// PartFilter is an optional filter for the restored parts.
+	//
+	// Only parts for which PartFilter returns true are restored if PartFilter is set.
+	// Other parts are deleted from Dst.
+	PartFilter func(p common.Part) bool
 }
 
 // Run runs r with the provided settings.
@@ -109,6 +115,12 @@ func (r *Restore) Run() error {
 		offset += p.Size
 	}
 
+	if r.PartFilter != nil {
+		srcParts = filterParts(srcParts, r.PartFilter)
+		logger.Infof("restoring %d bytes out of %d bytes after applying the filter", getPartsSize(srcParts), backupSize)
+		backupSize = getPartsSize(srcParts)
+	}
+
 	partsToDelete := common.PartsDifference(dstParts, srcParts)
 	deleteSize := uint64(0)
 	if len(partsToDelete) > 0 {
@@ -192,6 +204,16 @@ func (r *Restore) Run() error {
 	return removeRestoreLock(r.Dst.Dir)
 }
 
+func filterParts(parts []common.Part, f func(p common.Part) bool) []common.Part {
+	var result []common.Part
+	for _, p := range parts {
+		if f(p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
 type statWriter struct {
 	w            io.Writer
 	bytesWritten *uint64
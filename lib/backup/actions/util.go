@@ -13,6 +13,7 @@ import (
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/fsremote"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/gcsremote"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/s3remote"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/sftpremote"
 )
 
 var (
@@ -27,6 +28,9 @@ var (
 	s3StorageClass   = flag.String("s3StorageClass", "", "The Storage Class applied to objects uploaded to AWS S3. Supported values are: GLACIER, "+
 		"DEEP_ARCHIVE, GLACIER_IR, INTELLIGENT_TIERING, ONEZONE_IA, OUTPOSTS, REDUCED_REDUNDANCY, STANDARD, STANDARD_IA.\n"+
 		"See https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-class-intro.html")
+	sftpKeyFile                   = flag.String("sftp.keyFile", "", "Path to the private key file for key-based authentication at sftp:// remote storage")
+	sftpKnownHostsFile            = flag.String("sftp.knownHostsFile", "", "Path to known_hosts file for verifying the host key of sftp:// remote storage. ~/.ssh/known_hosts is used if not set")
+	sftpInsecureSkipHostKeyVerify = flag.Bool("sftp.insecureSkipHostKeyVerify", false, "Whether to skip verification of the host key of sftp:// remote storage. This is insecure, since it allows man-in-the-middle attacks")
 )
 
 func runParallel(concurrency int, parts []common.Part, f func(p common.Part) error, progress func(elapsed time.Duration)) error {
@@ -188,7 +192,7 @@ func NewRemoteFS(path string) (common.RemoteFS, error) {
 	}
 	n := strings.Index(path, "://")
 	if n < 0 {
-		return nil, fmt.Errorf("Missing scheme in path %q. Supported schemes: `gs://`, `s3://`, `azblob://`, `fs://`", path)
+		return nil, fmt.Errorf("Missing scheme in path %q. Supported schemes: `gs://`, `s3://`, `azblob://`, `fs://`, `sftp://`", path)
 	}
 	scheme := path[:n]
 	dir := path[n+len("://"):]
@@ -253,7 +257,43 @@ func NewRemoteFS(path string) (common.RemoteFS, error) {
 			return nil, fmt.Errorf("cannot initialize connection to s3: %w", err)
 		}
 		return fs, nil
+	case "sftp":
+		user, host, dir, err := parseSFTPPath(dir)
+		if err != nil {
+			return nil, err
+		}
+		fs := &sftpremote.FS{
+			User:                      user,
+			Host:                      host,
+			Dir:                       dir,
+			KeyFile:                   *sftpKeyFile,
+			KnownHostsFile:            *sftpKnownHostsFile,
+			InsecureSkipHostKeyVerify: *sftpInsecureSkipHostKeyVerify,
+		}
+		if err := fs.Init(); err != nil {
+			return nil, fmt.Errorf("cannot initialize connection to sftp: %w", err)
+		}
+		return fs, nil
 	default:
 		return nil, fmt.Errorf("unsupported scheme %q", scheme)
 	}
 }
+
+// parseSFTPPath parses sftp path in the form `user@host[:port]/path/to/dir` without `sftp://` prefix.
+func parseSFTPPath(s string) (string, string, string, error) {
+	n := strings.Index(s, "/")
+	if n < 0 {
+		return "", "", "", fmt.Errorf("missing directory on the sftp host %q", s)
+	}
+	host := s[:n]
+	dir := s[n:]
+	var user string
+	if n := strings.LastIndexByte(host, '@'); n >= 0 {
+		user = host[:n]
+		host = host[n+1:]
+	}
+	if host == "" {
+		return "", "", "", fmt.Errorf("missing host in sftp path %q", s)
+	}
+	return user, host, dir, nil
+}
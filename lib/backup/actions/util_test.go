@@ -0,0 +1,48 @@
+package actions
+
+import (
+	"testing"
+)
+
+func TestParseSFTPPathSuccess(t *testing.T) {
+	f := func(s, userExpected, hostExpected, dirExpected string) {
+		t.Helper()
+		user, host, dir, err := parseSFTPPath(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if user != userExpected {
+			t.Fatalf("unexpected user for %q; got %q; want %q", s, user, userExpected)
+		}
+		if host != hostExpected {
+			t.Fatalf("unexpected host for %q; got %q; want %q", s, host, hostExpected)
+		}
+		if dir != dirExpected {
+			t.Fatalf("unexpected dir for %q; got %q; want %q", s, dir, dirExpected)
+		}
+	}
+	f("user@host/path/to/backup", "user", "host", "/path/to/backup")
+	f("user@host:2222/path", "user", "host:2222", "/path")
+	f("user@host/", "user", "host", "/")
+	f("host/path", "", "host", "/path")
+
+	// the last @ separates user from host
+	f("user@example.com@host/path", "user@example.com", "host", "/path")
+
+	// double slash is used for absolute paths in URLs such as sftp://user@host//path
+	f("user@host//path", "user", "host", "//path")
+}
+
+func TestParseSFTPPathFailure(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		_, _, _, err := parseSFTPPath(s)
+		if err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+	f("")
+	f("user@host")
+	f("user@/path")
+	f("/path")
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
@@ -27,6 +28,7 @@ const (
 	envStorageAcctName           = "AZURE_STORAGE_ACCOUNT_NAME"
 	envStorageAccKey             = "AZURE_STORAGE_ACCOUNT_KEY"
 	envStorageAccCs              = "AZURE_STORAGE_ACCOUNT_CONNECTION_STRING"
+	envUseDefaultCredential      = "AZURE_USE_DEFAULT_CREDENTIAL"
 	storageErrorCodeBlobNotFound = "BlobNotFound"
 )
 
@@ -82,8 +84,27 @@ func (fs *FS) Init() error {
 		}
 	}
 
+	if v, ok := envtemplate.LookupEnv(envUseDefaultCredential); sc == nil && ok && v == "true" {
+		if !ok1 {
+			return fmt.Errorf("missing storage account name at %q; it is required when %q is set", envStorageAcctName, envUseDefaultCredential)
+		}
+		// DefaultAzureCredential obtains credentials from environment variables, workload identity or managed identity.
+		// See https://learn.microsoft.com/en-us/azure/developer/go/azure-sdk-authentication
+		creds, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return fmt.Errorf("failed to create default AZBlob credentials: %w", err)
+		}
+		serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", accountName)
+
+		sc, err = service.NewClient(serviceURL, creds, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create AZBlob service client from default credentials: %w", err)
+		}
+	}
+
 	if sc == nil {
-		return fmt.Errorf(`failed to detect any credentials type for AZBlob. Ensure there is connection string set at %q, or shared key at %q and %q`, envStorageAccCs, envStorageAcctName, envStorageAccKey)
+		return fmt.Errorf(`failed to detect any credentials type for AZBlob. Ensure there is connection string set at %q, or shared key at %q and %q, `+
+			`or %q is set to "true" together with %q`, envStorageAccCs, envStorageAcctName, envStorageAccKey, envUseDefaultCredential, envStorageAcctName)
 	}
 
 	containerClient := sc.NewContainerClient(fs.Container)
@@ -0,0 +1,377 @@
+package sftpremote
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/common"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/fscommon"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+)
+
+// FS represents filesystem for backups on a remote host accessible via SFTP.
+//
+// Init must be called before calling other FS methods.
+type FS struct {
+	// User is the user name for connecting to Host.
+	User string
+
+	// Host is the remote host in the form host[:port]. The default port is 22.
+	Host string
+
+	// Dir is a path to remote directory with backup data.
+	Dir string
+
+	// KeyFile is a path to the private key file for key-based authentication.
+	KeyFile string
+
+	// KnownHostsFile is a path to known_hosts file for verifying the remote host key.
+	//
+	// ~/.ssh/known_hosts is used if KnownHostsFile is empty.
+	KnownHostsFile string
+
+	// InsecureSkipHostKeyVerify disables verification of the remote host key.
+	InsecureSkipHostKeyVerify bool
+
+	conn   *ssh.Client
+	client *sftp.Client
+}
+
+// Init initializes fs.
+//
+// The returned fs must be stopped when no longer needed with MustStop call.
+func (fs *FS) Init() error {
+	if fs.conn != nil {
+		logger.Panicf("BUG: Init is already called")
+	}
+	fs.Dir = path.Clean(fs.Dir)
+	if !path.IsAbs(fs.Dir) {
+		return fmt.Errorf("dir must be absolute; got %q", fs.Dir)
+	}
+	if fs.User == "" {
+		return fmt.Errorf("missing user name; it must be passed in the form sftp://user@host/path")
+	}
+	if fs.KeyFile == "" {
+		return fmt.Errorf("missing path to private key file for connecting to %q", fs.Host)
+	}
+	keyData, err := os.ReadFile(fs.KeyFile)
+	if err != nil {
+		return fmt.Errorf("cannot read private key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(keyData)
+	if err != nil {
+		return fmt.Errorf("cannot parse private key at %q: %w", fs.KeyFile, err)
+	}
+	hostKeyCallback, err := fs.newHostKeyCallback()
+	if err != nil {
+		return err
+	}
+	cfg := &ssh.ClientConfig{
+		User:            fs.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	}
+	addr := fs.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "22")
+	}
+	conn, err := ssh.Dial("tcp", addr, cfg)
+	if err != nil {
+		return fmt.Errorf("cannot connect to %q: %w", addr, err)
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("cannot start sftp session at %q: %w", addr, err)
+	}
+	fs.conn = conn
+	fs.client = client
+	return nil
+}
+
+func (fs *FS) newHostKeyCallback() (ssh.HostKeyCallback, error) {
+	if fs.InsecureSkipHostKeyVerify {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	knownHostsFile := fs.KnownHostsFile
+	if knownHostsFile == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("cannot determine home directory for reading known_hosts file: %w", err)
+		}
+		knownHostsFile = filepath.Join(homeDir, ".ssh", "known_hosts")
+	}
+	cb, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read known hosts: %w", err)
+	}
+	return cb, nil
+}
+
+// MustStop stops fs.
+func (fs *FS) MustStop() {
+	if err := fs.client.Close(); err != nil {
+		logger.Errorf("cannot close sftp session for %s: %s", fs, err)
+	}
+	_ = fs.conn.Close()
+	fs.client = nil
+	fs.conn = nil
+}
+
+// String returns human-readable description for fs.
+func (fs *FS) String() string {
+	return fmt.Sprintf("SFTP{user: %q, host: %q, dir: %q}", fs.User, fs.Host, fs.Dir)
+}
+
+// ListParts returns all the parts for fs.
+func (fs *FS) ListParts() ([]common.Part, error) {
+	dir := fs.Dir
+	if _, err := fs.client.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			// Return empty part list for non-existing directory.
+			// The directory will be created later.
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot stat %q: %w", dir, err)
+	}
+	var parts []common.Part
+	dir = getDirPrefix(dir)
+	w := fs.client.Walk(fs.Dir)
+	for w.Step() {
+		if err := w.Err(); err != nil {
+			return nil, fmt.Errorf("cannot list %q: %w", fs.Dir, err)
+		}
+		fi := w.Stat()
+		if fi.IsDir() {
+			continue
+		}
+		file := w.Path()
+		if !strings.HasPrefix(file, dir) {
+			logger.Panicf("BUG: unexpected prefix for file %q; want %q", file, dir)
+		}
+		if fscommon.IgnorePath(file) {
+			continue
+		}
+		var p common.Part
+		if !p.ParseFromRemotePath(file[len(dir):]) {
+			logger.Infof("skipping unknown file %s", file)
+			continue
+		}
+		p.ActualSize = uint64(fi.Size())
+		parts = append(parts, p)
+	}
+	return parts, nil
+}
+
+// getDirPrefix returns the prefix for paths of files inside the given dir.
+//
+// dir must be cleaned with path.Clean.
+func getDirPrefix(dir string) string {
+	// Root dir already ends with slash, so the slash mustn't be added to it.
+	return strings.TrimSuffix(dir, "/") + "/"
+}
+
+// DeletePart deletes the given part p from fs.
+func (fs *FS) DeletePart(p common.Part) error {
+	path := fs.path(p)
+	if err := fs.client.Remove(path); err != nil {
+		return fmt.Errorf("cannot remove %q at %s: %w", path, fs, err)
+	}
+	return nil
+}
+
+// RemoveEmptyDirs recursively removes all the empty directories in fs.
+func (fs *FS) RemoveEmptyDirs() error {
+	if _, err := fs.client.Stat(fs.Dir); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("cannot stat %q: %w", fs.Dir, err)
+	}
+	var dirs []string
+	w := fs.client.Walk(fs.Dir)
+	for w.Step() {
+		if err := w.Err(); err != nil {
+			return fmt.Errorf("cannot list %q: %w", fs.Dir, err)
+		}
+		if w.Stat().IsDir() && w.Path() != fs.Dir {
+			dirs = append(dirs, w.Path())
+		}
+	}
+	// Remove the deepest dirs at first, so parent dirs become empty after removing their empty subdirs.
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], "/") > strings.Count(dirs[j], "/")
+	})
+	for _, dir := range dirs {
+		fis, err := fs.client.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("cannot read directory %q: %w", dir, err)
+		}
+		if len(fis) > 0 {
+			continue
+		}
+		if err := fs.client.RemoveDirectory(dir); err != nil {
+			return fmt.Errorf("cannot remove empty directory %q: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// CopyPart copies the part p from srcFS to fs.
+//
+// srcFS must have *FS type.
+func (fs *FS) CopyPart(srcFS common.OriginFS, p common.Part) error {
+	src, ok := srcFS.(*FS)
+	if !ok {
+		return fmt.Errorf("cannot perform server-side copying from %s to %s: both of them must be SFTP", srcFS, fs)
+	}
+	srcPath := src.path(p)
+	dstPath := fs.path(p)
+	if err := fs.mkdirAll(dstPath); err != nil {
+		return err
+	}
+	if src.User == fs.User && src.Host == fs.Host {
+		// Attempt to create hardlink from srcPath to dstPath.
+		if err := fs.client.Link(srcPath, dstPath); err == nil {
+			return nil
+		}
+	}
+
+	// Cannot create hardlink. Just copy file contents
+	r, err := src.client.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("cannot open source file %q at %s: %w", srcPath, src, err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	return fs.uploadFile(dstPath, r, p.Size)
+}
+
+// DownloadPart downloads part p from fs to w.
+func (fs *FS) DownloadPart(p common.Part, w io.Writer) error {
+	path := fs.path(p)
+	r, err := fs.client.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot open %q at %s: %w", path, fs, err)
+	}
+	n, err := io.Copy(w, r)
+	if err1 := r.Close(); err1 != nil && err == nil {
+		err = err1
+	}
+	if err != nil {
+		return fmt.Errorf("cannot download data from %q at %s: %w", path, fs, err)
+	}
+	if uint64(n) != p.Size {
+		return fmt.Errorf("wrong data size downloaded from %q at %s; got %d bytes; want %d bytes", path, fs, n, p.Size)
+	}
+	return nil
+}
+
+// UploadPart uploads part p from r to fs.
+func (fs *FS) UploadPart(p common.Part, r io.Reader) error {
+	path := fs.path(p)
+	if err := fs.mkdirAll(path); err != nil {
+		return err
+	}
+	return fs.uploadFile(path, r, p.Size)
+}
+
+func (fs *FS) uploadFile(path string, r io.Reader, size uint64) error {
+	w, err := fs.client.Create(path)
+	if err != nil {
+		return fmt.Errorf("cannot create file %q at %s: %w", path, fs, err)
+	}
+	n, err := io.Copy(w, r)
+	if err1 := w.Close(); err1 != nil && err == nil {
+		err = err1
+	}
+	if err != nil {
+		_ = fs.client.Remove(path)
+		return fmt.Errorf("cannot upload data to %q at %s: %w", path, fs, err)
+	}
+	if uint64(n) != size {
+		_ = fs.client.Remove(path)
+		return fmt.Errorf("wrong data size uploaded to %q at %s; got %d bytes; want %d bytes", path, fs, n, size)
+	}
+	return nil
+}
+
+func (fs *FS) mkdirAll(filePath string) error {
+	dir := path.Dir(filePath)
+	if err := fs.client.MkdirAll(dir); err != nil {
+		return fmt.Errorf("cannot create directory %q at %s: %w", dir, fs, err)
+	}
+	return nil
+}
+
+func (fs *FS) path(p common.Part) string {
+	return p.RemotePath(fs.Dir)
+}
+
+// DeleteFile deletes filePath at fs.
+//
+// The function does nothing if the filePath doesn't exist.
+func (fs *FS) DeleteFile(filePath string) error {
+	fullPath := path.Join(fs.Dir, filePath)
+	err := fs.client.Remove(fullPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot remove %q at %s: %w", fullPath, fs, err)
+	}
+	return nil
+}
+
+// CreateFile creates filePath at fs and puts data into it.
+//
+// The file is overwritten if it exists.
+func (fs *FS) CreateFile(filePath string, data []byte) error {
+	fullPath := path.Join(fs.Dir, filePath)
+	if err := fs.mkdirAll(fullPath); err != nil {
+		return err
+	}
+	return fs.uploadFile(fullPath, bytes.NewReader(data), uint64(len(data)))
+}
+
+// HasFile returns true if filePath exists at fs.
+func (fs *FS) HasFile(filePath string) (bool, error) {
+	fullPath := path.Join(fs.Dir, filePath)
+	fi, err := fs.client.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("cannot stat %q at %s: %w", fullPath, fs, err)
+	}
+	if fi.IsDir() {
+		return false, fmt.Errorf("%q at %s is directory, while file is needed", fullPath, fs)
+	}
+	return true, nil
+}
+
+// ReadFile returns the content of filePath at fs.
+func (fs *FS) ReadFile(filePath string) ([]byte, error) {
+	fullPath := path.Join(fs.Dir, filePath)
+	r, err := fs.client.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %q at %s: %w", fullPath, fs, err)
+	}
+	data, err := io.ReadAll(r)
+	_ = r.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %q at %s: %w", fullPath, fs, err)
+	}
+	return data, nil
+}
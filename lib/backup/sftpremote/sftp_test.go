@@ -0,0 +1,71 @@
+package sftpremote
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/backup/common"
+)
+
+func TestGetDirPrefix(t *testing.T) {
+	f := func(dir, prefixExpected string) {
+		t.Helper()
+		prefix := getDirPrefix(dir)
+		if prefix != prefixExpected {
+			t.Fatalf("unexpected prefix for dir %q; got %q; want %q", dir, prefix, prefixExpected)
+		}
+	}
+	f("/", "/")
+	f("/foo", "/foo/")
+	f("/foo/bar", "/foo/bar/")
+}
+
+func TestPartPathRoundtrip(t *testing.T) {
+	f := func(dir string) {
+		t.Helper()
+		fs := &FS{
+			Dir: dir,
+		}
+		p := common.Part{
+			Path:     "data/small/2023_04/part/values.bin",
+			FileSize: 123,
+			Offset:   0,
+			Size:     123,
+		}
+		remotePath := fs.path(p)
+		prefix := getDirPrefix(dir)
+		if !strings.HasPrefix(remotePath, prefix) {
+			t.Fatalf("unexpected remote path %q for dir %q; it must start with %q", remotePath, dir, prefix)
+		}
+		var pParsed common.Part
+		if !pParsed.ParseFromRemotePath(remotePath[len(prefix):]) {
+			t.Fatalf("cannot parse remote path %q for dir %q", remotePath, dir)
+		}
+		if pParsed != p {
+			t.Fatalf("unexpected part parsed from %q; got %+v; want %+v", remotePath, &pParsed, &p)
+		}
+	}
+	f("/")
+	f("/foo")
+	f("/foo/bar")
+}
+
+func TestInitFailure(t *testing.T) {
+	f := func(fs *FS, dirExpected, errExpected string) {
+		t.Helper()
+		err := fs.Init()
+		if err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+		if !strings.Contains(err.Error(), errExpected) {
+			t.Fatalf("unexpected error; got %q; want it containing %q", err, errExpected)
+		}
+		if fs.Dir != dirExpected {
+			t.Fatalf("unexpected dir; got %q; want %q", fs.Dir, dirExpected)
+		}
+	}
+	f(&FS{User: "foo", Host: "bar", Dir: "baz"}, "baz", "dir must be absolute")
+	f(&FS{Host: "bar", Dir: "/baz"}, "/baz", "missing user name")
+	f(&FS{User: "foo", Host: "bar", Dir: "//baz//x/"}, "/baz/x", "missing path to private key file")
+	f(&FS{User: "foo", Host: "bar", Dir: "/"}, "/", "missing path to private key file")
+}
@@ -138,6 +138,17 @@ func (c *Cache) Misses() uint64 {
 	return n
 }
 
+// Evictions returns the number of blocks evicted from c because of exceeding the cache size limit.
+//
+// Blocks removed because of access timeout or part removal aren't counted.
+func (c *Cache) Evictions() uint64 {
+	n := uint64(0)
+	for _, shard := range c.shards {
+		n += shard.Evictions()
+	}
+	return n
+}
+
 func (c *Cache) cleaner() {
 	d := timeutil.AddJitterToDuration(time.Minute)
 	ticker := time.NewTicker(d)
@@ -175,8 +186,9 @@ type cache struct {
 	// Atomically updated fields must go first in the struct, so they are properly
 	// aligned to 8 bytes on 32-bit architectures.
 	// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/212
-	requests uint64
-	misses   uint64
+	requests  uint64
+	misses    uint64
+	evictions uint64
 
 	// sizeBytes contains an approximate size for all the blocks stored in the cache.
 	sizeBytes int64
@@ -339,6 +351,7 @@ func (c *cache) PutBlock(k Key, b Block) {
 	maxSizeBytes := c.getMaxSizeBytes()
 	for c.SizeBytes() > maxSizeBytes && len(c.lah) > 0 {
 		c.removeLeastRecentlyAccessedItem()
+		atomic.AddUint64(&c.evictions, 1)
 	}
 }
 
@@ -382,6 +395,10 @@ func (c *cache) Misses() uint64 {
 	return atomic.LoadUint64(&c.misses)
 }
 
+func (c *cache) Evictions() uint64 {
+	return atomic.LoadUint64(&c.evictions)
+}
+
 // lastAccessHeap implements heap.Interface
 type lastAccessHeap []*cacheEntry
 
@@ -121,6 +121,33 @@ func TestCache(t *testing.T) {
 	if n := c.SizeBytes(); n != blockSize {
 		t.Fatalf("unexpected SizeBytes(); got %d; want %d", n, blockSize)
 	}
+	if n := c.Evictions(); n != 0 {
+		t.Fatalf("unexpected number of evictions; got %d; want %d", n, 0)
+	}
+}
+
+func TestCacheEvictions(t *testing.T) {
+	getMaxSize := func() int {
+		return 0
+	}
+	c := NewCache(getMaxSize)
+	defer c.MustStop()
+	k := Key{
+		Offset: 1234,
+		Part:   (interface{})("foobar"),
+	}
+	var b testBlock
+	// The block must be evicted immediately, since it exceeds the cache size limit
+	c.PutBlock(k, &b)
+	if n := c.Len(); n != 0 {
+		t.Fatalf("unexpected number of items in the cache; got %d; want %d", n, 0)
+	}
+	if n := c.SizeBytes(); n != 0 {
+		t.Fatalf("unexpected SizeBytes(); got %d; want %d", n, 0)
+	}
+	if n := c.Evictions(); n != 1 {
+		t.Fatalf("unexpected number of evictions; got %d; want %d", n, 1)
+	}
 }
 
 func TestCacheConcurrentAccess(_ *testing.T) {
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
 )
 
 var version = flag.Bool("version", false, "Show VictoriaMetrics version")
@@ -30,3 +31,40 @@ func init() {
 func printVersion() {
 	fmt.Fprintf(flag.CommandLine.Output(), "%s\n", Version)
 }
+
+// Revision returns git commit hash from Version.
+//
+// An empty string is returned if Version doesn't contain git commit hash.
+func Revision() string {
+	return getRevision(Version)
+}
+
+// BuildDate returns the build date from Version in the format YYYYMMDD-HH:MM:SS.
+//
+// An empty string is returned if Version doesn't contain the build date.
+func BuildDate() string {
+	return getBuildDate(Version)
+}
+
+// Regexps for parsing Version, which is set at build time to `app-YYYYMMDD-HHMMSS-<git describe --long --all>[-dirty-<hash>]`,
+// e.g. `victoria-metrics-20240301-123456-tags-v1.98.0-0-g0123abcd`. See Makefile.
+var (
+	revisionRegexp  = regexp.MustCompile(`-g([0-9a-f]{7,40})(?:-dirty-[0-9a-f]+)?$`)
+	buildDateRegexp = regexp.MustCompile(`-(\d{8})-(\d{2})(\d{2})(\d{2})-`)
+)
+
+func getRevision(version string) string {
+	m := revisionRegexp.FindStringSubmatch(version)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+func getBuildDate(version string) string {
+	m := buildDateRegexp.FindStringSubmatch(version)
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s:%s:%s", m[1], m[2], m[3], m[4])
+}
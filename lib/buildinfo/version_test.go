@@ -0,0 +1,22 @@
+package buildinfo
+
+import (
+	"testing"
+)
+
+func TestGetRevisionAndBuildDate(t *testing.T) {
+	f := func(version, revisionExpected, buildDateExpected string) {
+		t.Helper()
+		if revision := getRevision(version); revision != revisionExpected {
+			t.Fatalf("unexpected revision for %q; got %q; want %q", version, revision, revisionExpected)
+		}
+		if buildDate := getBuildDate(version); buildDate != buildDateExpected {
+			t.Fatalf("unexpected build date for %q; got %q; want %q", version, buildDate, buildDateExpected)
+		}
+	}
+	f("", "", "")
+	f("foobar", "", "")
+	f("victoria-metrics-20240301-123456-tags-v1.98.0-0-g0123abcd", "0123abcd", "20240301-12:34:56")
+	f("vmagent-20240102-030405-heads-master-0-gabcdef1234-dirty-f00dbeef", "abcdef1234", "20240102-03:04:05")
+	f("victoria-metrics-20240301-123456-tags-v1.98.0-cluster-3-g0123abcd", "0123abcd", "20240301-12:34:56")
+}
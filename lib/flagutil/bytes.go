@@ -133,3 +133,19 @@ func normalizeBytesString(s string) string {
 	s = strings.ToUpper(s)
 	return strings.ReplaceAll(s, "I", "i")
 }
+
+// UnmarshalYAML implements yaml.Unmarshaler interface.
+//
+// This allows using Bytes in YAML configs.
+func (b *Bytes) UnmarshalYAML(f func(interface{}) error) error {
+	var s string
+	if err := f(&s); err != nil {
+		return err
+	}
+	return b.Set(s)
+}
+
+// MarshalYAML implements yaml.Marshaler interface.
+func (b *Bytes) MarshalYAML() (interface{}, error) {
+	return b.valueString, nil
+}
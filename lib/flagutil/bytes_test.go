@@ -2,6 +2,8 @@ package flagutil
 
 import (
 	"testing"
+
+	"gopkg.in/yaml.v2"
 )
 
 func TestBytesSetFailure(t *testing.T) {
@@ -54,3 +56,36 @@ func TestBytesSetSuccess(t *testing.T) {
 	f("0.25GB", 0.25*1000*1000*1000)
 	f("1.25TB", 1.25*1000*1000*1000*1000)
 }
+
+func TestBytesUnmarshalYAML(t *testing.T) {
+	f := func(data string, expectedResult int64) {
+		t.Helper()
+		var b Bytes
+		if err := yaml.Unmarshal([]byte(data), &b); err != nil {
+			t.Fatalf("unexpected error when unmarshaling %q: %s", data, err)
+		}
+		if b.N != expectedResult {
+			t.Fatalf("unexpected result; got %d; want %d", b.N, expectedResult)
+		}
+		result, err := yaml.Marshal(&b)
+		if err != nil {
+			t.Fatalf("cannot marshal %q: %s", data, err)
+		}
+		var b2 Bytes
+		if err := yaml.Unmarshal(result, &b2); err != nil {
+			t.Fatalf("cannot unmarshal the marshaled result %q: %s", result, err)
+		}
+		if b2.N != b.N {
+			t.Fatalf("unexpected result after marshaling and unmarshaling back; got %d; want %d", b2.N, b.N)
+		}
+	}
+	f("1234", 1234)
+	f("10KiB", 10*1024)
+	f("1.5MB", 1.5*1000*1000)
+
+	// invalid value
+	var b Bytes
+	if err := yaml.Unmarshal([]byte("foobar"), &b); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
@@ -1,6 +1,7 @@
 package flagutil
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -18,3 +19,27 @@ func WriteFlags(w io.Writer) {
 		fmt.Fprintf(w, "-%s=%q\n", f.Name, value)
 	})
 }
+
+// WriteFlagsJSON writes all the flags with their effective values to w in Prometheus-compatible JSON format.
+//
+// See https://prometheus.io/docs/prometheus/latest/querying/api/#flags
+func WriteFlagsJSON(w io.Writer) {
+	writeFlagsJSON(w, flag.CommandLine)
+}
+
+func writeFlagsJSON(w io.Writer, fs *flag.FlagSet) {
+	m := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		lname := strings.ToLower(f.Name)
+		value := f.Value.String()
+		if IsSecretFlag(lname) && value != "" {
+			value = "secret"
+		}
+		m[f.Name] = value
+	})
+	data, err := json.Marshal(m)
+	if err != nil {
+		panic(fmt.Errorf("BUG: cannot marshal flags to JSON: %w", err))
+	}
+	fmt.Fprintf(w, `{"status":"success","data":%s}`, data)
+}
@@ -0,0 +1,26 @@
+package flagutil
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+)
+
+func TestWriteFlagsJSON(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("foo", "bar", "")
+	fs.String("httpAuth.password", "", "")
+	fs.String("authKey", "", "")
+	fs.Int("num", 0, "")
+	if err := fs.Parse([]string{"-foo=a\"b", "-authKey=qwerty", "-num=42"}); err != nil {
+		t.Fatalf("cannot parse flags: %s", err)
+	}
+
+	var bb bytes.Buffer
+	writeFlagsJSON(&bb, fs)
+	result := bb.String()
+	resultExpected := `{"status":"success","data":{"authKey":"secret","foo":"a\"b","httpAuth.password":"","num":"42"}}`
+	if result != resultExpected {
+		t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+}
@@ -41,14 +41,25 @@ var (
 	tlsMinVersion   = flagutil.NewArrayString("tlsMinVersion", "Optional minimum TLS version to use for the corresponding -httpListenAddr if -tls is set. "+
 		"Supported values: TLS10, TLS11, TLS12, TLS13")
 
+	mtlsEnable = flagutil.NewArrayBool("mtls", "Whether to require valid client certificate for https requests to the corresponding -httpListenAddr . "+
+		"This flag works only if -tls flag is set. See also -mtlsCAFile")
+	mtlsCAFile = flagutil.NewArrayString("mtlsCAFile", "Optional path to TLS Root CA for verifying client certificates at the corresponding -httpListenAddr when -mtls is enabled. "+
+		"By default the host system TLS Root CA is used for client certificate verification. The provided CA file is automatically re-read every second, so it can be dynamically updated")
+
 	pathPrefix = flag.String("http.pathPrefix", "", "An optional prefix to add to all the paths handled by http server. For example, if '-http.pathPrefix=/foo/bar' is set, "+
 		"then all the http requests will be handled on '/foo/bar/*' paths. This may be useful for proxied requests. "+
 		"See https://www.robustperception.io/using-external-urls-and-proxies-with-prometheus")
-	httpAuthUsername = flag.String("httpAuth.username", "", "Username for HTTP server's Basic Auth. The authentication is disabled if empty. See also -httpAuth.password")
-	httpAuthPassword = flagutil.NewPassword("httpAuth.password", "Password for HTTP server's Basic Auth. The authentication is disabled if -httpAuth.username is empty")
-	metricsAuthKey   = flagutil.NewPassword("metricsAuthKey", "Auth key for /metrics endpoint. It must be passed via authKey query arg. It overrides httpAuth.* settings")
-	flagsAuthKey     = flagutil.NewPassword("flagsAuthKey", "Auth key for /flags endpoint. It must be passed via authKey query arg. It overrides httpAuth.* settings")
-	pprofAuthKey     = flagutil.NewPassword("pprofAuthKey", "Auth key for /debug/pprof/* endpoints. It must be passed via authKey query arg. It overrides httpAuth.* settings")
+	httpAuthUsername    = flag.String("httpAuth.username", "", "Username for HTTP server's Basic Auth. The authentication is disabled if empty. See also -httpAuth.password")
+	httpAuthPassword    = flagutil.NewPassword("httpAuth.password", "Password for HTTP server's Basic Auth. The authentication is disabled if -httpAuth.username is empty")
+	httpAuthBearerToken = flagutil.NewPassword("httpAuth.bearerToken", "Optional bearer token for HTTP server's authentication. It must be passed via 'Authorization: Bearer <token>' request header. "+
+		"Requests with either valid -httpAuth.bearerToken or valid -httpAuth.username and -httpAuth.password are accepted if both are set")
+	metricsAuthKey = flagutil.NewPassword("metricsAuthKey", "Auth key for /metrics endpoint. It must be passed via authKey query arg. It overrides httpAuth.* settings")
+	flagsAuthKey   = flagutil.NewPassword("flagsAuthKey", "Auth key for /flags and /api/v1/status/flags endpoints. It must be passed via authKey query arg. It overrides httpAuth.* settings")
+	pprofAuthKey   = flagutil.NewPassword("pprofAuthKey", "Auth key for /debug/pprof/* endpoints. It must be passed via authKey query arg. It overrides httpAuth.* settings")
+
+	internalListenAddr = flag.String("http.internalListenAddr", "", "Optional TCP address to listen for internal http requests to /metrics, /flags, /api/v1/status/flags and /debug/pprof/* endpoints. "+
+		"If set, then these endpoints are served only at -http.internalListenAddr and aren't served at -httpListenAddr, so they could be protected from external access by firewall. "+
+		"Health check endpoints such as /health are served at both addresses")
 
 	disableResponseCompression  = flag.Bool("http.disableResponseCompression", false, "Disable compression of HTTP responses to save CPU resources. By default, compression is enabled to save network bandwidth")
 	maxGracefulShutdownDuration = flag.Duration("http.maxGracefulShutdownDuration", 7*time.Second, `The maximum duration for a graceful shutdown of the HTTP server. A highly loaded server may require increased value for a graceful shutdown`)
@@ -69,6 +80,9 @@ var (
 type server struct {
 	shutdownDelayDeadline int64
 	s                     *http.Server
+
+	// isInternal is set to true for the server started at -http.internalListenAddr
+	isInternal bool
 }
 
 // RequestHandler must serve the given request r and write response to w.
@@ -97,12 +111,36 @@ func Serve(addrs []string, useProxyProtocol *flagutil.ArrayBool, rh RequestHandl
 		if addr == "" {
 			continue
 		}
+		if addr == *internalListenAddr {
+			logger.Fatalf("-http.internalListenAddr=%q must differ from -httpListenAddr", addr)
+		}
 		useProxyProto := false
 		if useProxyProtocol != nil {
 			useProxyProto = useProxyProtocol.GetOptionalArg(idx)
 		}
 		go serve(addr, useProxyProto, rh, idx)
 	}
+	if *internalListenAddr != "" {
+		go serveInternal(*internalListenAddr)
+	}
+}
+
+// serveInternal starts http server for internal endpoints at the given addr.
+func serveInternal(addr string) {
+	hostAddr := addr
+	if strings.HasPrefix(hostAddr, ":") {
+		hostAddr = "127.0.0.1" + hostAddr
+	}
+	logger.Infof("starting internal server at http://%s/", hostAddr)
+	logger.Infof("pprof handlers are exposed at http://%s/debug/pprof/", hostAddr)
+	ln, err := netutil.NewTCPListener("http-internal", addr, false, nil)
+	if err != nil {
+		logger.Fatalf("cannot start internal http server at -http.internalListenAddr=%s: %s", addr, err)
+	}
+	rh := func(w http.ResponseWriter, r *http.Request) bool {
+		return false
+	}
+	serveWithListener(addr, ln, rh, true)
 }
 
 func serve(addr string, useProxyProtocol bool, rh RequestHandler, idx int) {
@@ -115,7 +153,9 @@ func serve(addr string, useProxyProtocol bool, rh RequestHandler, idx int) {
 		hostAddr = "127.0.0.1" + hostAddr
 	}
 	logger.Infof("starting server at %s://%s/", scheme, hostAddr)
-	logger.Infof("pprof handlers are exposed at %s://%s/debug/pprof/", scheme, hostAddr)
+	if *internalListenAddr == "" {
+		logger.Infof("pprof handlers are exposed at %s://%s/debug/pprof/", scheme, hostAddr)
+	}
 	var tlsConfig *tls.Config
 	if tlsEnable.GetOptionalArg(idx) {
 		certFile := tlsCertFile.GetOptionalArg(idx)
@@ -125,17 +165,26 @@ func serve(addr string, useProxyProtocol bool, rh RequestHandler, idx int) {
 		if err != nil {
 			logger.Fatalf("cannot load TLS cert from -tlsCertFile=%q, -tlsKeyFile=%q, -tlsMinVersion=%q, -tlsCipherSuites=%q: %s", certFile, keyFile, minVersion, *tlsCipherSuites, err)
 		}
+		if mtlsEnable.GetOptionalArg(idx) {
+			caFile := mtlsCAFile.GetOptionalArg(idx)
+			if err := netutil.SetServerMTLSConfig(tc, caFile); err != nil {
+				logger.Fatalf("cannot enable mTLS with -mtlsCAFile=%q: %s", caFile, err)
+			}
+		}
 		tlsConfig = tc
+	} else if mtlsEnable.GetOptionalArg(idx) {
+		logger.Fatalf("-mtls flag requires -tls flag to be set for -httpListenAddr=%q", addr)
 	}
 	ln, err := netutil.NewTCPListener(scheme, addr, useProxyProtocol, tlsConfig)
 	if err != nil {
 		logger.Fatalf("cannot start http server at %s: %s", addr, err)
 	}
-	serveWithListener(addr, ln, rh)
+	serveWithListener(addr, ln, rh, false)
 }
 
-func serveWithListener(addr string, ln net.Listener, rh RequestHandler) {
+func serveWithListener(addr string, ln net.Listener, rh RequestHandler, isInternal bool) {
 	var s server
+	s.isInternal = isInternal
 	s.s = &http.Server{
 		Handler: gzipHandler(&s, rh),
 
@@ -206,6 +255,22 @@ func Stop(addrs []string) error {
 			wg.Done()
 		}(addr)
 	}
+	if *internalListenAddr != "" {
+		serversLock.Lock()
+		_, ok := servers[*internalListenAddr]
+		serversLock.Unlock()
+		if ok {
+			wg.Add(1)
+			go func() {
+				if err := stop(*internalListenAddr); err != nil {
+					errGlobalLock.Lock()
+					errGlobal = err
+					errGlobalLock.Unlock()
+				}
+				wg.Done()
+			}()
+		}
+	}
 	wg.Wait()
 
 	return errGlobal
@@ -364,6 +429,9 @@ func handlerWrapper(s *server, w http.ResponseWriter, r *http.Request, rh Reques
 		return
 	case "/metrics":
 		metricsRequests.Inc()
+		if !isInternalPathAllowed(s, w, r) {
+			return
+		}
 		if !CheckAuthFlag(w, r, metricsAuthKey.Get(), "metricsAuthKey") {
 			return
 		}
@@ -373,12 +441,27 @@ func handlerWrapper(s *server, w http.ResponseWriter, r *http.Request, rh Reques
 		metricsHandlerDuration.UpdateDuration(startTime)
 		return
 	case "/flags":
+		if !isInternalPathAllowed(s, w, r) {
+			return
+		}
 		if !CheckAuthFlag(w, r, flagsAuthKey.Get(), "flagsAuthKey") {
 			return
 		}
 		h.Set("Content-Type", "text/plain; charset=utf-8")
 		flagutil.WriteFlags(w)
 		return
+	case "/api/v1/status/flags":
+		// This is needed for Prometheus compatibility
+		// See https://prometheus.io/docs/prometheus/latest/querying/api/#flags
+		if !isInternalPathAllowed(s, w, r) {
+			return
+		}
+		if !CheckAuthFlag(w, r, flagsAuthKey.Get(), "flagsAuthKey") {
+			return
+		}
+		h.Set("Content-Type", "application/json")
+		flagutil.WriteFlagsJSON(w)
+		return
 	case "/-/healthy":
 		// This is needed for Prometheus compatibility
 		// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/1833
@@ -397,6 +480,9 @@ func handlerWrapper(s *server, w http.ResponseWriter, r *http.Request, rh Reques
 	default:
 		if strings.HasPrefix(r.URL.Path, "/debug/pprof/") {
 			pprofRequests.Inc()
+			if !isInternalPathAllowed(s, w, r) {
+				return
+			}
 			if !CheckAuthFlag(w, r, pprofAuthKey.Get(), "pprofAuthKey") {
 				return
 			}
@@ -421,6 +507,17 @@ func handlerWrapper(s *server, w http.ResponseWriter, r *http.Request, rh Reques
 	}
 }
 
+// isInternalPathAllowed returns false and sends error response if the internal endpoint is requested
+// at -httpListenAddr while it must be served only at -http.internalListenAddr.
+func isInternalPathAllowed(s *server, w http.ResponseWriter, r *http.Request) bool {
+	if *internalListenAddr == "" || s.isInternal {
+		return true
+	}
+	internalPathRequestErrors.Inc()
+	http.Error(w, fmt.Sprintf("%q is served only at -http.internalListenAddr", r.URL.Path), http.StatusNotFound)
+	return false
+}
+
 // CheckAuthFlag checks whether the given authKey is set and valid
 //
 // Falls back to checkBasicAuth if authKey is not set
@@ -439,23 +536,42 @@ func CheckAuthFlag(w http.ResponseWriter, r *http.Request, flagValue string, fla
 // CheckBasicAuth validates credentials provided in request if httpAuth.* flags are set
 // returns true if credentials are valid or httpAuth.* flags are not set
 func CheckBasicAuth(w http.ResponseWriter, r *http.Request) bool {
-	if len(*httpAuthUsername) == 0 {
-		// HTTP Basic Auth is disabled.
+	bearerToken := httpAuthBearerToken.Get()
+	if len(*httpAuthUsername) == 0 && len(bearerToken) == 0 {
+		// HTTP auth is disabled.
 		return true
 	}
-	username, password, ok := r.BasicAuth()
-	if ok {
-		if username == *httpAuthUsername && password == httpAuthPassword.Get() {
-			return true
+	if len(bearerToken) > 0 {
+		if token, ok := getBearerToken(r); ok {
+			if token == bearerToken {
+				return true
+			}
+			authBearerRequestErrors.Inc()
 		}
-		authBasicRequestErrors.Inc()
 	}
-
-	w.Header().Set("WWW-Authenticate", `Basic realm="VictoriaMetrics"`)
+	if len(*httpAuthUsername) > 0 {
+		username, password, ok := r.BasicAuth()
+		if ok {
+			if username == *httpAuthUsername && password == httpAuthPassword.Get() {
+				return true
+			}
+			authBasicRequestErrors.Inc()
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="VictoriaMetrics"`)
+	}
 	http.Error(w, "", http.StatusUnauthorized)
 	return false
 }
 
+func getBearerToken(r *http.Request) (string, bool) {
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+	return auth[len(prefix):], true
+}
+
 // EnableCORS enables https://developer.mozilla.org/en-US/docs/Web/HTTP/CORS
 // on the response.
 func EnableCORS(w http.ResponseWriter, _ *http.Request) {
@@ -504,9 +620,11 @@ var (
 	pprofDefaultRequests = metrics.NewCounter(`vm_http_requests_total{path="/debug/pprof/default"}`)
 	faviconRequests      = metrics.NewCounter(`vm_http_requests_total{path="*/favicon.ico"}`)
 
-	authBasicRequestErrors   = metrics.NewCounter(`vm_http_request_errors_total{path="*", reason="wrong_basic_auth"}`)
-	authKeyRequestErrors     = metrics.NewCounter(`vm_http_request_errors_total{path="*", reason="wrong_auth_key"}`)
-	unsupportedRequestErrors = metrics.NewCounter(`vm_http_request_errors_total{path="*", reason="unsupported"}`)
+	authBasicRequestErrors    = metrics.NewCounter(`vm_http_request_errors_total{path="*", reason="wrong_basic_auth"}`)
+	authBearerRequestErrors   = metrics.NewCounter(`vm_http_request_errors_total{path="*", reason="wrong_bearer_token"}`)
+	internalPathRequestErrors = metrics.NewCounter(`vm_http_request_errors_total{path="*", reason="internal_path"}`)
+	authKeyRequestErrors      = metrics.NewCounter(`vm_http_request_errors_total{path="*", reason="wrong_auth_key"}`)
+	unsupportedRequestErrors  = metrics.NewCounter(`vm_http_request_errors_total{path="*", reason="unsupported"}`)
 
 	requestsTotal = metrics.NewCounter(`vm_http_requests_all_total`)
 )
@@ -81,6 +81,58 @@ func TestBasicAuthMetrics(t *testing.T) {
 	f("wrong", "wrong", 200)
 }
 
+func TestBearerTokenAuth(t *testing.T) {
+	origUsername := *httpAuthUsername
+	origPasswd := httpAuthPassword.Get()
+	origBearerToken := httpAuthBearerToken.Get()
+	defer func() {
+		if err := httpAuthPassword.Set(origPasswd); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := httpAuthBearerToken.Set(origBearerToken); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		*httpAuthUsername = origUsername
+	}()
+
+	f := func(authHeader string, expCode int) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+
+		w := httptest.NewRecorder()
+		CheckBasicAuth(w, req)
+
+		res := w.Result()
+		_ = res.Body.Close()
+		if expCode != res.StatusCode {
+			t.Fatalf("wanted status code: %d, got: %d\n", expCode, res.StatusCode)
+		}
+	}
+
+	*httpAuthUsername = ""
+	if err := httpAuthBearerToken.Set("secret"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f("Bearer secret", 200)
+	f("bearer secret", 200)
+	f("Bearer wrong", 401)
+	f("Basic dGVzdDpwYXNz", 401)
+	f("", 401)
+
+	// Both bearer token and basic auth are accepted
+	*httpAuthUsername = "test"
+	if err := httpAuthPassword.Set("pass"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f("Bearer secret", 200)
+	f("Basic dGVzdDpwYXNz", 200)
+	f("Bearer wrong", 401)
+	f("", 401)
+}
+
 func TestAuthKeyMetrics(t *testing.T) {
 	origUsername := *httpAuthUsername
 	origPasswd := httpAuthPassword.Get()
@@ -162,3 +214,44 @@ func TestHandlerWrapper(t *testing.T) {
 		t.Errorf("CSP header not set")
 	}
 }
+
+func TestHandlerWrapperInternalListenAddr(t *testing.T) {
+	*internalListenAddr = ":8429"
+	defer func() {
+		*internalListenAddr = ""
+	}()
+
+	f := func(path string, isInternal bool, expCode int) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		srv := &server{
+			s:          &http.Server{},
+			isInternal: isInternal,
+		}
+		w := httptest.NewRecorder()
+		handlerWrapper(srv, w, req, func(w http.ResponseWriter, _ *http.Request) bool {
+			w.WriteHeader(http.StatusAccepted)
+			return true
+		})
+		res := w.Result()
+		_ = res.Body.Close()
+		if expCode != res.StatusCode {
+			t.Fatalf("unexpected status code for %q at internal=%v; got %d; want %d", path, isInternal, res.StatusCode, expCode)
+		}
+	}
+
+	// internal endpoints
+	f("/metrics", false, http.StatusNotFound)
+	f("/metrics", true, http.StatusOK)
+	f("/flags", false, http.StatusNotFound)
+	f("/flags", true, http.StatusOK)
+	f("/debug/pprof/cmdline", false, http.StatusNotFound)
+	f("/debug/pprof/cmdline", true, http.StatusOK)
+
+	// health checks are served at both addresses
+	f("/health", false, http.StatusOK)
+	f("/health", true, http.StatusOK)
+
+	// data-plane requests are served by the request handler
+	f("/api/v1/query", false, http.StatusAccepted)
+}
@@ -40,6 +40,7 @@ func Init() {
 	setLoggerJSONFields()
 	setLoggerOutput()
 	validateLoggerLevel()
+	initScopeLevels()
 	validateLoggerFormat()
 	initTimezone()
 	go logLimiterCleaner()
@@ -70,9 +71,7 @@ func setLoggerOutput() {
 var output io.Writer = os.Stderr
 
 func validateLoggerLevel() {
-	switch *loggerLevel {
-	case "INFO", "WARN", "ERROR", "FATAL", "PANIC":
-	default:
+	if !isValidLevel(*loggerLevel) {
 		// We cannot use logger.Panicf here, since the logger isn't initialized yet.
 		panic(fmt.Errorf("FATAL: unsupported `-loggerLevel` value: %q; supported values are: INFO, WARN, ERROR, FATAL, PANIC", *loggerLevel))
 	}
@@ -134,7 +133,12 @@ func logLevel(level, format string, args []interface{}) {
 }
 
 func logLevelSkipframes(skipframes int, level, format string, args []interface{}) {
-	if shouldSkipLog(level) {
+	minLevel := *loggerLevel
+	if len(scopeLevels) > 0 {
+		file, _ := getCaller(2 + skipframes)
+		minLevel = getMinLevel(scopeLevels, file)
+	}
+	if shouldSkipLog(minLevel, level) {
 		return
 	}
 	msg := formatLogMessage(*maxLogArgLen, format, args)
@@ -227,15 +231,7 @@ func logMessage(level, msg string, skipframes int) {
 		timestamp = time.Now().In(timezone).Format("2006-01-02T15:04:05.000Z0700")
 	}
 	levelLowercase := strings.ToLower(level)
-	_, file, line, ok := runtime.Caller(skipframes)
-	if !ok {
-		file = "???"
-		line = 0
-	}
-	if n := strings.Index(file, "/VictoriaMetrics/"); n >= 0 {
-		// Strip /VictoriaMetrics/ prefix
-		file = file[n+len("/VictoriaMetrics/"):]
-	}
+	file, line := getCaller(skipframes)
 	location := fmt.Sprintf("%s:%d", file, line)
 
 	// rate limit ERROR and WARN log messages with given limit.
@@ -306,8 +302,21 @@ func logMessage(level, msg string, skipframes int) {
 
 var mu sync.Mutex
 
-func shouldSkipLog(level string) bool {
-	switch *loggerLevel {
+// getCaller returns the source file path without /VictoriaMetrics/ prefix and the line for the caller at the given skipframes.
+func getCaller(skipframes int) (string, int) {
+	_, file, line, ok := runtime.Caller(skipframes + 1)
+	if !ok {
+		return "???", 0
+	}
+	if n := strings.Index(file, "/VictoriaMetrics/"); n >= 0 {
+		// Strip /VictoriaMetrics/ prefix
+		file = file[n+len("/VictoriaMetrics/"):]
+	}
+	return file, line
+}
+
+func shouldSkipLog(minLevel, level string) bool {
+	switch minLevel {
 	case "WARN":
 		switch level {
 		case "WARN", "ERROR", "FATAL", "PANIC":
@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -23,3 +25,91 @@ func TestFormatLogMessage(t *testing.T) {
 	// Format args exceeding the maxArgLen
 	f("foo: %s, %q, %s", []interface{}{"abcde", fmt.Errorf("foo bar baz"), "xx"}, 4, `foo: a..e, "f..z", xx`)
 }
+
+func TestParseScopeLevels(t *testing.T) {
+	f := func(a []string, resultExpected string) {
+		t.Helper()
+		sls, err := parseScopeLevels(a)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		var items []string
+		for _, sl := range sls {
+			items = append(items, sl.scope+"="+sl.level)
+		}
+		result := strings.Join(items, ",")
+		if result != resultExpected {
+			t.Fatalf("unexpected result; got %q; want %q", result, resultExpected)
+		}
+	}
+
+	f(nil, "")
+	f([]string{"lib/promscrape=WARN"}, "lib/promscrape=WARN")
+	f([]string{"lib=ERROR", "lib/promscrape/discovery=INFO", "lib/promscrape=WARN"}, "lib/promscrape/discovery=INFO,lib/promscrape=WARN,lib=ERROR")
+}
+
+func TestParseScopeLevelsFailure(t *testing.T) {
+	f := func(a []string) {
+		t.Helper()
+		if _, err := parseScopeLevels(a); err == nil {
+			t.Fatalf("expecting non-nil error for %q", a)
+		}
+	}
+
+	f([]string{"lib/promscrape"})
+	f([]string{"=WARN"})
+	f([]string{"lib/promscrape="})
+	f([]string{"lib/promscrape=warn"})
+	f([]string{"lib/promscrape=FATAL"})
+	f([]string{"lib/promscrape=PANIC"})
+}
+
+func TestGetMinLevel(t *testing.T) {
+	f := func(file, levelExpected string) {
+		t.Helper()
+		sls, err := parseScopeLevels([]string{"lib=ERROR", "lib/promscrape=WARN", "app/vmagent/remotewrite/client.go=INFO"})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		level := getMinLevel(sls, file)
+		if level != levelExpected {
+			t.Fatalf("unexpected level for %q; got %q; want %q", file, level, levelExpected)
+		}
+	}
+
+	f("lib/promscrape/scrapework.go", "WARN")
+	f("lib/promscrape/discovery/kubernetes/api.go", "WARN")
+	f("lib/storage/storage.go", "ERROR")
+	f("app/vmagent/remotewrite/client.go", "INFO")
+	f("app/vmagent/main.go", *loggerLevel)
+}
+
+func TestLogScopeLevel(t *testing.T) {
+	var bb bytes.Buffer
+	SetOutputForTests(&bb)
+	defer ResetOutputForTest()
+
+	// The caller path isn't stripped when the repository is cloned outside VictoriaMetrics directory
+	file, _ := getCaller(0)
+	scope := strings.TrimSuffix(file, "logger_test.go")
+	sls, err := parseScopeLevels([]string{scope + "=ERROR"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	scopeLevels = sls
+	defer func() {
+		scopeLevels = nil
+	}()
+
+	Infof("info message")
+	Warnf("warn message")
+	Errorf("error message")
+
+	result := bb.String()
+	if strings.Contains(result, "info message") || strings.Contains(result, "warn message") {
+		t.Fatalf("unexpected messages below ERROR level for lib/logger scope in the output:\n%s", result)
+	}
+	if !strings.Contains(result, "\t"+file+":") || !strings.Contains(result, "error message") {
+		t.Fatalf("missing error message for lib/logger scope in the output:\n%s", result)
+	}
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/flagutil"
+)
+
+var loggerScopeLevel = flagutil.NewArrayString("loggerScopeLevel", "Optional minimum level of messages to log for the given scope in the form `scope=LEVEL`. "+
+	"It overrides -loggerLevel for messages from the given scope. The scope is a prefix of the source file path shown in the caller field of log messages. "+
+	"For example, -loggerScopeLevel=lib/promscrape=WARN logs only WARN and higher level messages from lib/promscrape and its subpackages. "+
+	"The longest matching scope is used if multiple scopes match. Possible levels: INFO, WARN, ERROR. "+
+	"FATAL and PANIC messages are always logged, since they terminate the app")
+
+type scopeLevel struct {
+	scope string
+	level string
+}
+
+// scopeLevels contains parsed -loggerScopeLevel items sorted by scope length in descending order.
+var scopeLevels []scopeLevel
+
+func initScopeLevels() {
+	sls, err := parseScopeLevels(*loggerScopeLevel)
+	if err != nil {
+		// We cannot use logger.Panicf here, since the logger isn't initialized yet.
+		panic(fmt.Errorf("FATAL: cannot parse -loggerScopeLevel: %w", err))
+	}
+	scopeLevels = sls
+}
+
+func parseScopeLevels(a []string) ([]scopeLevel, error) {
+	var sls []scopeLevel
+	for _, s := range a {
+		n := strings.LastIndexByte(s, '=')
+		if n <= 0 {
+			return nil, fmt.Errorf("missing `scope=LEVEL` format in %q", s)
+		}
+		scope, level := s[:n], s[n+1:]
+		if !isValidScopeLevel(level) {
+			return nil, fmt.Errorf("unsupported level %q for scope %q; supported values are: INFO, WARN, ERROR", level, scope)
+		}
+		sls = append(sls, scopeLevel{
+			scope: scope,
+			level: level,
+		})
+	}
+	sort.SliceStable(sls, func(i, j int) bool {
+		return len(sls[i].scope) > len(sls[j].scope)
+	})
+	return sls, nil
+}
+
+// getMinLevel returns the minimum level of messages to log for the given source file.
+func getMinLevel(sls []scopeLevel, file string) string {
+	for _, sl := range sls {
+		if strings.HasPrefix(file, sl.scope) {
+			return sl.level
+		}
+	}
+	return *loggerLevel
+}
+
+// isValidScopeLevel returns true if the level can be used in -loggerScopeLevel.
+//
+// FATAL and PANIC aren't allowed, since FATAL and PANIC messages mustn't be skipped -
+// otherwise the app continues running after logger.Fatalf and logger.Panicf calls.
+func isValidScopeLevel(level string) bool {
+	switch level {
+	case "INFO", "WARN", "ERROR":
+		return true
+	default:
+		return false
+	}
+}
+
+func isValidLevel(level string) bool {
+	switch level {
+	case "INFO", "WARN", "ERROR", "FATAL", "PANIC":
+		return true
+	default:
+		return false
+	}
+}
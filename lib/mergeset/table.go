@@ -538,12 +538,14 @@ type TableMetrics struct {
 	DataBlocksCacheSizeMaxBytes uint64
 	DataBlocksCacheRequests     uint64
 	DataBlocksCacheMisses       uint64
+	DataBlocksCacheEvictions    uint64
 
 	IndexBlocksCacheSize         uint64
 	IndexBlocksCacheSizeBytes    uint64
 	IndexBlocksCacheSizeMaxBytes uint64
 	IndexBlocksCacheRequests     uint64
 	IndexBlocksCacheMisses       uint64
+	IndexBlocksCacheEvictions    uint64
 
 	PartsRefCount uint64
 }
@@ -597,12 +599,14 @@ func (tb *Table) UpdateMetrics(m *TableMetrics) {
 	m.DataBlocksCacheSizeMaxBytes = uint64(ibCache.SizeMaxBytes())
 	m.DataBlocksCacheRequests = ibCache.Requests()
 	m.DataBlocksCacheMisses = ibCache.Misses()
+	m.DataBlocksCacheEvictions = ibCache.Evictions()
 
 	m.IndexBlocksCacheSize = uint64(idxbCache.Len())
 	m.IndexBlocksCacheSizeBytes = uint64(idxbCache.SizeBytes())
 	m.IndexBlocksCacheSizeMaxBytes = uint64(idxbCache.SizeMaxBytes())
 	m.IndexBlocksCacheRequests = idxbCache.Requests()
 	m.IndexBlocksCacheMisses = idxbCache.Misses()
+	m.IndexBlocksCacheEvictions = idxbCache.Evictions()
 }
 
 // AddItems adds the given items to the tb.
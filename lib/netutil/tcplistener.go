@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
@@ -21,19 +24,18 @@ var enableTCP6 = flag.Bool("enableTCP6", false, "Whether to enable IPv6 for list
 //
 // If useProxyProtocol is set to true, then the returned listener accepts TCP connections via proxy protocol.
 // See https://www.haproxy.org/download/1.8/doc/proxy-protocol.txt
+//
+// If addr starts with `unix:`, then the returned listener accepts connections at the given unix socket path.
 func NewTCPListener(name, addr string, useProxyProtocol bool, tlsConfig *tls.Config) (*TCPListener, error) {
-	network := GetTCPNetwork()
-	ln, err := net.Listen(network, addr)
+	ln, err := listen(addr)
 	if err != nil {
 		return nil, err
 	}
-	if tlsConfig != nil {
-		ln = tls.NewListener(ln, tlsConfig)
-	}
 	ms := metrics.GetDefaultSet()
 	tln := &TCPListener{
 		Listener:         ln,
 		useProxyProtocol: useProxyProtocol,
+		tlsConfig:        tlsConfig,
 
 		accepts:      ms.NewCounter(fmt.Sprintf(`vm_tcplistener_accepts_total{name=%q, addr=%q}`, name, addr)),
 		acceptErrors: ms.NewCounter(fmt.Sprintf(`vm_tcplistener_errors_total{name=%q, addr=%q, type="accept"}`, name, addr)),
@@ -42,6 +44,38 @@ func NewTCPListener(name, addr string, useProxyProtocol bool, tlsConfig *tls.Con
 	return tln, err
 }
 
+func listen(addr string) (net.Listener, error) {
+	path, ok := GetUnixSocketPath(addr)
+	if !ok {
+		return net.Listen(GetTCPNetwork(), addr)
+	}
+	// Remove the socket file left after unclean shutdown, since otherwise listen fails with `address already in use` error.
+	// The socket is considered stale only if nobody accepts connections at it, so the socket of another running process isn't removed.
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		c, err := net.DialTimeout("unix", path, time.Second)
+		if err == nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("cannot listen at unix socket %q, since it is already in use by another process", path)
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("cannot remove stale unix socket %q: %w", path, err)
+			}
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// GetUnixSocketPath returns unix socket path for addr in the form `unix:/path/to/socket`.
+//
+// false is returned if addr doesn't refer to unix socket.
+func GetUnixSocketPath(addr string) (string, bool) {
+	if !strings.HasPrefix(addr, "unix:") {
+		return "", false
+	}
+	return addr[len("unix:"):], true
+}
+
 // TCP6Enabled returns true if dialing and listening for IPv4 TCP is enabled.
 func TCP6Enabled() bool {
 	return *enableTCP6
@@ -76,6 +110,10 @@ type TCPListener struct {
 
 	useProxyProtocol bool
 
+	// tlsConfig is applied on top of the accepted connections, so http.Server could detect TLS connections
+	// and the proxy protocol header could be read before the TLS handshake.
+	tlsConfig *tls.Config
+
 	connMetrics
 }
 
@@ -112,6 +150,9 @@ func (ln *TCPListener) Accept() (net.Conn, error) {
 			Conn: conn,
 			cm:   &ln.connMetrics,
 		}
+		if ln.tlsConfig != nil {
+			return tls.Server(sc, ln.tlsConfig), nil
+		}
 		return sc, nil
 	}
 }
@@ -0,0 +1,90 @@
+package netutil
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewTCPListenerUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+
+	// Create stale socket file, which must be removed by NewTCPListener
+	staleLn, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("cannot create unix socket: %s", err)
+	}
+	staleLn.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = staleLn.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("missing stale unix socket: %s", err)
+	}
+
+	ln, err := NewTCPListener("test_unix_socket", "unix:"+path, false, nil)
+	if err != nil {
+		t.Fatalf("cannot create listener: %s", err)
+	}
+	defer ln.Close()
+
+	go func() {
+		c, err := net.Dial("unix", path)
+		if err != nil {
+			t.Errorf("cannot dial unix socket: %s", err)
+			return
+		}
+		_, _ = c.Write([]byte("foo"))
+		_ = c.Close()
+	}()
+	c, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("cannot accept connection: %s", err)
+	}
+	data, err := io.ReadAll(c)
+	_ = c.Close()
+	if err != nil {
+		t.Fatalf("cannot read data: %s", err)
+	}
+	if string(data) != "foo" {
+		t.Fatalf("unexpected data read; got %q; want %q", data, "foo")
+	}
+}
+
+func TestNewTCPListenerUnixSocketInUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+
+	ln, err := NewTCPListener("test_unix_socket_in_use", "unix:"+path, false, nil)
+	if err != nil {
+		t.Fatalf("cannot create listener: %s", err)
+	}
+	defer ln.Close()
+
+	// The socket of the running listener mustn't be removed
+	if _, err := NewTCPListener("test_unix_socket_in_use_2", "unix:"+path, false, nil); err == nil {
+		t.Fatalf("expecting non-nil error when listening at the socket in use")
+	}
+	c, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("cannot dial unix socket: %s", err)
+	}
+	_ = c.Close()
+}
+
+func TestGetUnixSocketPath(t *testing.T) {
+	f := func(addr, pathExpected string, okExpected bool) {
+		t.Helper()
+		path, ok := GetUnixSocketPath(addr)
+		if ok != okExpected {
+			t.Fatalf("unexpected ok for %q; got %v; want %v", addr, ok, okExpected)
+		}
+		if path != pathExpected {
+			t.Fatalf("unexpected path for %q; got %q; want %q", addr, path, pathExpected)
+		}
+	}
+
+	f(":8428", "", false)
+	f("127.0.0.1:8428", "", false)
+	f("unix:/var/run/vm.sock", "/var/run/vm.sock", true)
+	f("unix:vm.sock", "vm.sock", true)
+}
@@ -1,8 +1,11 @@
 package netutil
 
 import (
+	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -50,6 +53,61 @@ func GetServerTLSConfig(tlsCertFile, tlsKeyFile, tlsMinVersion string, tlsCipher
 	return cfg, nil
 }
 
+// SetServerMTLSConfig configures cfg for requiring and verifying client certificates.
+//
+// Client certificates are verified with the TLS Root CA from caFile.
+// The host system TLS Root CA is used if caFile is empty.
+// The caFile is re-read at most once per second, so the TLS Root CA can be updated without restart.
+func SetServerMTLSConfig(cfg *tls.Config, caFile string) error {
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	if caFile == "" {
+		return nil
+	}
+	data, cp, err := loadCertPool(caFile)
+	if err != nil {
+		return err
+	}
+	cfg.ClientCAs = cp
+
+	// base mustn't contain GetConfigForClient callback, since it is returned from this callback.
+	base := cfg.Clone()
+	var caLock sync.Mutex
+	caDeadline := fasttime.UnixTimestamp() + 1
+	caData := data
+	current := base
+	cfg.GetConfigForClient = func(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+		caLock.Lock()
+		defer caLock.Unlock()
+		if fasttime.UnixTimestamp() > caDeadline {
+			data, cp, err := loadCertPool(caFile)
+			if err != nil {
+				return nil, err
+			}
+			caDeadline = fasttime.UnixTimestamp() + 1
+			if !bytes.Equal(data, caData) {
+				c := base.Clone()
+				c.ClientCAs = cp
+				current = c
+				caData = data
+			}
+		}
+		return current, nil
+	}
+	return nil
+}
+
+func loadCertPool(caFile string) ([]byte, *x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read TLS Root CA from %q: %w", caFile, err)
+	}
+	cp := x509.NewCertPool()
+	if !cp.AppendCertsFromPEM(data) {
+		return nil, nil, fmt.Errorf("cannot parse TLS Root CA from %q", caFile)
+	}
+	return data, cp, nil
+}
+
 func cipherSuitesFromNames(cipherSuiteNames []string) ([]uint16, error) {
 	if len(cipherSuiteNames) == 0 {
 		return nil, nil
@@ -1,9 +1,20 @@
 package netutil
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestCipherSuitesFromNamesSucces(t *testing.T) {
@@ -106,3 +117,99 @@ func TestParseTLSVersionFailure(t *testing.T) {
 	// incorrect tls version in tlsName
 	f("TLS14")
 }
+
+func TestSetServerMTLSConfig(t *testing.T) {
+	var cfg tls.Config
+	if err := SetServerMTLSConfig(&cfg, ""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cfg.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Fatalf("unexpected ClientAuth; got %v; want %v", cfg.ClientAuth, tls.RequireAndVerifyClientCert)
+	}
+	if cfg.ClientCAs != nil {
+		t.Fatalf("expecting nil ClientCAs for empty caFile")
+	}
+
+	// missing file
+	if err := SetServerMTLSConfig(&cfg, "non-existing-file"); err == nil {
+		t.Fatalf("expecting non-nil error for missing caFile")
+	}
+
+	// invalid file contents
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("foobar"), 0644); err != nil {
+		t.Fatalf("cannot write %q: %s", caFile, err)
+	}
+	if err := SetServerMTLSConfig(&cfg, caFile); err == nil {
+		t.Fatalf("expecting non-nil error for invalid caFile")
+	}
+}
+
+func TestSetServerMTLSConfigReload(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeTestCA(t, caFile, "ca1")
+
+	var cfg tls.Config
+	if err := SetServerMTLSConfig(&cfg, caFile); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cfg.GetConfigForClient == nil {
+		t.Fatalf("expecting non-nil GetConfigForClient")
+	}
+	getSubjects := func() []string {
+		t.Helper()
+		c, err := cfg.GetConfigForClient(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if c.ClientAuth != tls.RequireAndVerifyClientCert {
+			t.Fatalf("unexpected ClientAuth; got %v; want %v", c.ClientAuth, tls.RequireAndVerifyClientCert)
+		}
+		var subjects []string
+		for _, cert := range c.ClientCAs.Subjects() { //nolint:staticcheck
+			var name pkix.RDNSequence
+			if _, err := asn1.Unmarshal(cert, &name); err != nil {
+				t.Fatalf("cannot parse subject: %s", err)
+			}
+			var n pkix.Name
+			n.FillFromRDNSequence(&name)
+			subjects = append(subjects, n.CommonName)
+		}
+		return subjects
+	}
+	if subjects := getSubjects(); !reflect.DeepEqual(subjects, []string{"ca1"}) {
+		t.Fatalf("unexpected ClientCAs; got %q; want %q", subjects, []string{"ca1"})
+	}
+
+	// The updated CA must be picked up after the file is re-read.
+	writeTestCA(t, caFile, "ca2")
+	time.Sleep(2100 * time.Millisecond)
+	if subjects := getSubjects(); !reflect.DeepEqual(subjects, []string{"ca2"}) {
+		t.Fatalf("unexpected ClientCAs after update; got %q; want %q", subjects, []string{"ca2"})
+	}
+}
+
+func writeTestCA(t *testing.T, path, commonName string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate key: %s", err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("cannot create certificate: %s", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("cannot write %q: %s", path, err)
+	}
+}
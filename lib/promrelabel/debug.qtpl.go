@@ -360,78 +360,104 @@ func StreamRelabelDebugStepsJSON(qw422016 *qt422016.Writer, targetURL, targetID
 //line lib/promrelabel/debug.qtpl:149
 			qw422016.N().Q(mustFormatLabels(dss[len(dss)-1].Out))
 //line lib/promrelabel/debug.qtpl:149
-			qw422016.N().S(`,`)
+			qw422016.N().S(`,"originalLabelsRaw":`)
 //line lib/promrelabel/debug.qtpl:150
-		}
+			qw422016.N().Q(dss[0].In)
 //line lib/promrelabel/debug.qtpl:150
-		qw422016.N().S(`"steps": [`)
+			qw422016.N().S(`,"resultingLabelsRaw":`)
+//line lib/promrelabel/debug.qtpl:151
+			qw422016.N().Q(dss[len(dss)-1].Out)
+//line lib/promrelabel/debug.qtpl:151
+			qw422016.N().S(`,`)
 //line lib/promrelabel/debug.qtpl:152
-		for i, ds := range dss {
+		}
+//line lib/promrelabel/debug.qtpl:153
+		if targetURL != "" {
+//line lib/promrelabel/debug.qtpl:153
+			qw422016.N().S(`"targetURL":`)
+//line lib/promrelabel/debug.qtpl:154
+			qw422016.N().Q(targetURL)
 //line lib/promrelabel/debug.qtpl:154
+			qw422016.N().S(`,`)
+//line lib/promrelabel/debug.qtpl:155
+		}
+//line lib/promrelabel/debug.qtpl:155
+		qw422016.N().S(`"steps": [`)
+//line lib/promrelabel/debug.qtpl:157
+		for i, ds := range dss {
+//line lib/promrelabel/debug.qtpl:159
 			inLabels := promutils.MustNewLabelsFromString(ds.In)
 			outLabels := promutils.MustNewLabelsFromString(ds.Out)
 			changedLabels := getChangedLabelNames(inLabels, outLabels)
 
-//line lib/promrelabel/debug.qtpl:157
+//line lib/promrelabel/debug.qtpl:162
 			qw422016.N().S(`{"inLabels":`)
-//line lib/promrelabel/debug.qtpl:159
+//line lib/promrelabel/debug.qtpl:164
 			qw422016.N().Q(labelsWithHighlight(inLabels, changedLabels, "red"))
-//line lib/promrelabel/debug.qtpl:159
+//line lib/promrelabel/debug.qtpl:164
 			qw422016.N().S(`,"outLabels":`)
-//line lib/promrelabel/debug.qtpl:160
+//line lib/promrelabel/debug.qtpl:165
 			qw422016.N().Q(labelsWithHighlight(outLabels, changedLabels, "blue"))
-//line lib/promrelabel/debug.qtpl:160
+//line lib/promrelabel/debug.qtpl:165
+			qw422016.N().S(`,"inLabelsRaw":`)
+//line lib/promrelabel/debug.qtpl:166
+			qw422016.N().Q(ds.In)
+//line lib/promrelabel/debug.qtpl:166
+			qw422016.N().S(`,"outLabelsRaw":`)
+//line lib/promrelabel/debug.qtpl:167
+			qw422016.N().Q(ds.Out)
+//line lib/promrelabel/debug.qtpl:167
 			qw422016.N().S(`,"rule":`)
-//line lib/promrelabel/debug.qtpl:161
+//line lib/promrelabel/debug.qtpl:168
 			qw422016.N().Q(ds.Rule)
-//line lib/promrelabel/debug.qtpl:161
+//line lib/promrelabel/debug.qtpl:168
 			qw422016.N().S(`}`)
-//line lib/promrelabel/debug.qtpl:163
+//line lib/promrelabel/debug.qtpl:170
 			if i != len(dss)-1 {
-//line lib/promrelabel/debug.qtpl:163
+//line lib/promrelabel/debug.qtpl:170
 				qw422016.N().S(`,`)
-//line lib/promrelabel/debug.qtpl:163
+//line lib/promrelabel/debug.qtpl:170
 			}
-//line lib/promrelabel/debug.qtpl:164
+//line lib/promrelabel/debug.qtpl:171
 		}
-//line lib/promrelabel/debug.qtpl:164
+//line lib/promrelabel/debug.qtpl:171
 		qw422016.N().S(`]`)
-//line lib/promrelabel/debug.qtpl:166
+//line lib/promrelabel/debug.qtpl:173
 	}
-//line lib/promrelabel/debug.qtpl:166
+//line lib/promrelabel/debug.qtpl:173
 	qw422016.N().S(`}`)
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 }
 
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 func WriteRelabelDebugStepsJSON(qq422016 qtio422016.Writer, targetURL, targetID string, dss []DebugStep, metric, relabelConfigs string, err error) {
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 	StreamRelabelDebugStepsJSON(qw422016, targetURL, targetID, dss, metric, relabelConfigs, err)
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 	qt422016.ReleaseWriter(qw422016)
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 }
 
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 func RelabelDebugStepsJSON(targetURL, targetID string, dss []DebugStep, metric, relabelConfigs string, err error) string {
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 	qb422016 := qt422016.AcquireByteBuffer()
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 	WriteRelabelDebugStepsJSON(qb422016, targetURL, targetID, dss, metric, relabelConfigs, err)
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 	qs422016 := string(qb422016.B)
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 	qt422016.ReleaseByteBuffer(qb422016)
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 	return qs422016
-//line lib/promrelabel/debug.qtpl:168
+//line lib/promrelabel/debug.qtpl:175
 }
 
-//line lib/promrelabel/debug.qtpl:170
+//line lib/promrelabel/debug.qtpl:177
 func streamlabelsWithHighlight(qw422016 *qt422016.Writer, labels *promutils.Labels, highlight map[string]struct{}, color string) {
-//line lib/promrelabel/debug.qtpl:172
+//line lib/promrelabel/debug.qtpl:179
 	labelsList := labels.GetLabels()
 	metricName := ""
 	for i, label := range labelsList {
@@ -442,137 +468,137 @@ func streamlabelsWithHighlight(qw422016 *qt422016.Writer, labels *promutils.Labe
 		}
 	}
 
-//line lib/promrelabel/debug.qtpl:182
+//line lib/promrelabel/debug.qtpl:189
 	if metricName != "" {
-//line lib/promrelabel/debug.qtpl:183
+//line lib/promrelabel/debug.qtpl:190
 		if _, ok := highlight["__name__"]; ok {
-//line lib/promrelabel/debug.qtpl:183
+//line lib/promrelabel/debug.qtpl:190
 			qw422016.N().S(`<span style="font-weight:bold;color:`)
-//line lib/promrelabel/debug.qtpl:184
+//line lib/promrelabel/debug.qtpl:191
 			qw422016.E().S(color)
-//line lib/promrelabel/debug.qtpl:184
+//line lib/promrelabel/debug.qtpl:191
 			qw422016.N().S(`">`)
-//line lib/promrelabel/debug.qtpl:184
+//line lib/promrelabel/debug.qtpl:191
 			qw422016.E().S(metricName)
-//line lib/promrelabel/debug.qtpl:184
+//line lib/promrelabel/debug.qtpl:191
 			qw422016.N().S(`</span>`)
-//line lib/promrelabel/debug.qtpl:185
+//line lib/promrelabel/debug.qtpl:192
 		} else {
-//line lib/promrelabel/debug.qtpl:186
+//line lib/promrelabel/debug.qtpl:193
 			qw422016.E().S(metricName)
-//line lib/promrelabel/debug.qtpl:187
+//line lib/promrelabel/debug.qtpl:194
 		}
-//line lib/promrelabel/debug.qtpl:188
+//line lib/promrelabel/debug.qtpl:195
 		if len(labelsList) == 0 {
-//line lib/promrelabel/debug.qtpl:188
+//line lib/promrelabel/debug.qtpl:195
 			return
-//line lib/promrelabel/debug.qtpl:188
+//line lib/promrelabel/debug.qtpl:195
 		}
-//line lib/promrelabel/debug.qtpl:189
+//line lib/promrelabel/debug.qtpl:196
 	}
-//line lib/promrelabel/debug.qtpl:189
+//line lib/promrelabel/debug.qtpl:196
 	qw422016.N().S(`{`)
-//line lib/promrelabel/debug.qtpl:191
+//line lib/promrelabel/debug.qtpl:198
 	for i, label := range labelsList {
-//line lib/promrelabel/debug.qtpl:192
+//line lib/promrelabel/debug.qtpl:199
 		if _, ok := highlight[label.Name]; ok {
-//line lib/promrelabel/debug.qtpl:192
+//line lib/promrelabel/debug.qtpl:199
 			qw422016.N().S(`<span style="font-weight:bold;color:`)
-//line lib/promrelabel/debug.qtpl:193
+//line lib/promrelabel/debug.qtpl:200
 			qw422016.E().S(color)
-//line lib/promrelabel/debug.qtpl:193
+//line lib/promrelabel/debug.qtpl:200
 			qw422016.N().S(`">`)
-//line lib/promrelabel/debug.qtpl:193
+//line lib/promrelabel/debug.qtpl:200
 			qw422016.E().S(label.Name)
-//line lib/promrelabel/debug.qtpl:193
+//line lib/promrelabel/debug.qtpl:200
 			qw422016.N().S(`=`)
-//line lib/promrelabel/debug.qtpl:193
+//line lib/promrelabel/debug.qtpl:200
 			qw422016.E().Q(label.Value)
-//line lib/promrelabel/debug.qtpl:193
+//line lib/promrelabel/debug.qtpl:200
 			qw422016.N().S(`</span>`)
-//line lib/promrelabel/debug.qtpl:194
+//line lib/promrelabel/debug.qtpl:201
 		} else {
-//line lib/promrelabel/debug.qtpl:195
+//line lib/promrelabel/debug.qtpl:202
 			qw422016.E().S(label.Name)
-//line lib/promrelabel/debug.qtpl:195
+//line lib/promrelabel/debug.qtpl:202
 			qw422016.N().S(`=`)
-//line lib/promrelabel/debug.qtpl:195
+//line lib/promrelabel/debug.qtpl:202
 			qw422016.E().Q(label.Value)
-//line lib/promrelabel/debug.qtpl:196
+//line lib/promrelabel/debug.qtpl:203
 		}
-//line lib/promrelabel/debug.qtpl:197
+//line lib/promrelabel/debug.qtpl:204
 		if i < len(labelsList)-1 {
-//line lib/promrelabel/debug.qtpl:197
+//line lib/promrelabel/debug.qtpl:204
 			qw422016.N().S(`,`)
-//line lib/promrelabel/debug.qtpl:197
+//line lib/promrelabel/debug.qtpl:204
 			qw422016.N().S(` `)
-//line lib/promrelabel/debug.qtpl:197
+//line lib/promrelabel/debug.qtpl:204
 		}
-//line lib/promrelabel/debug.qtpl:198
+//line lib/promrelabel/debug.qtpl:205
 	}
-//line lib/promrelabel/debug.qtpl:198
+//line lib/promrelabel/debug.qtpl:205
 	qw422016.N().S(`}`)
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 }
 
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 func writelabelsWithHighlight(qq422016 qtio422016.Writer, labels *promutils.Labels, highlight map[string]struct{}, color string) {
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 	streamlabelsWithHighlight(qw422016, labels, highlight, color)
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 	qt422016.ReleaseWriter(qw422016)
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 }
 
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 func labelsWithHighlight(labels *promutils.Labels, highlight map[string]struct{}, color string) string {
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 	qb422016 := qt422016.AcquireByteBuffer()
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 	writelabelsWithHighlight(qb422016, labels, highlight, color)
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 	qs422016 := string(qb422016.B)
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 	qt422016.ReleaseByteBuffer(qb422016)
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 	return qs422016
-//line lib/promrelabel/debug.qtpl:200
+//line lib/promrelabel/debug.qtpl:207
 }
 
-//line lib/promrelabel/debug.qtpl:202
+//line lib/promrelabel/debug.qtpl:209
 func streammustFormatLabels(qw422016 *qt422016.Writer, s string) {
-//line lib/promrelabel/debug.qtpl:203
+//line lib/promrelabel/debug.qtpl:210
 	labels := promutils.MustNewLabelsFromString(s)
 
-//line lib/promrelabel/debug.qtpl:204
+//line lib/promrelabel/debug.qtpl:211
 	streamlabelsWithHighlight(qw422016, labels, nil, "")
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 }
 
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 func writemustFormatLabels(qq422016 qtio422016.Writer, s string) {
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 	streammustFormatLabels(qw422016, s)
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 	qt422016.ReleaseWriter(qw422016)
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 }
 
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 func mustFormatLabels(s string) string {
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 	qb422016 := qt422016.AcquireByteBuffer()
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 	writemustFormatLabels(qb422016, s)
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 	qs422016 := string(qb422016.B)
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 	qt422016.ReleaseByteBuffer(qb422016)
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 	return qs422016
-//line lib/promrelabel/debug.qtpl:205
+//line lib/promrelabel/debug.qtpl:212
 }
@@ -0,0 +1,99 @@
+package promrelabel
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestWriteRelabelDebugJSON(t *testing.T) {
+	type step struct {
+		Rule         string `json:"rule"`
+		InLabelsRaw  string `json:"inLabelsRaw"`
+		OutLabelsRaw string `json:"outLabelsRaw"`
+	}
+	type response struct {
+		Status             string `json:"status"`
+		Error              string `json:"error"`
+		OriginalLabelsRaw  string `json:"originalLabelsRaw"`
+		ResultingLabelsRaw string `json:"resultingLabelsRaw"`
+		TargetURL          string `json:"targetURL"`
+		Steps              []step `json:"steps"`
+	}
+	f := func(isTargetRelabel bool, metric, relabelConfigs string, respExpected *response) {
+		t.Helper()
+		var bb bytes.Buffer
+		writeRelabelDebug(&bb, isTargetRelabel, "", metric, relabelConfigs, "json", nil)
+		var resp response
+		if err := json.Unmarshal(bb.Bytes(), &resp); err != nil {
+			t.Fatalf("cannot parse response %q: %s", bb.String(), err)
+		}
+		if !reflect.DeepEqual(&resp, respExpected) {
+			t.Fatalf("unexpected response\ngot\n%+v\nwant\n%+v", &resp, respExpected)
+		}
+	}
+
+	// Metric relabeling
+	f(false, `foo{bar="baz",__tmp="x"}`, `
+- target_label: job
+  replacement: abc
+- action: labeldrop
+  regex: bar
+`, &response{
+		Status:             "success",
+		OriginalLabelsRaw:  `foo{__tmp="x",bar="baz"}`,
+		ResultingLabelsRaw: `foo{job="abc"}`,
+		Steps: []step{
+			{
+				Rule:         "target_label: job\nreplacement: abc\n",
+				InLabelsRaw:  `foo{__tmp="x",bar="baz"}`,
+				OutLabelsRaw: `foo{__tmp="x",bar="baz",job="abc"}`,
+			},
+			{
+				Rule:         "action: labeldrop\nregex: bar\n",
+				InLabelsRaw:  `foo{__tmp="x",bar="baz",job="abc"}`,
+				OutLabelsRaw: `foo{__tmp="x",job="abc"}`,
+			},
+			{
+				Rule:         "remove labels with __ prefix except of __name__",
+				InLabelsRaw:  `foo{__tmp="x",job="abc"}`,
+				OutLabelsRaw: `foo{job="abc"}`,
+			},
+		},
+	})
+
+	// Target relabeling
+	f(true, `{__address__="foo:1234",job="bar"}`, `
+- target_label: __metrics_path__
+  replacement: /abc
+`, &response{
+		Status:             "success",
+		OriginalLabelsRaw:  `{__address__="foo:1234",job="bar"}`,
+		ResultingLabelsRaw: `{instance="foo:1234",job="bar"}`,
+		TargetURL:          "http://foo:1234/abc",
+		Steps: []step{
+			{
+				Rule:         "target_label: __metrics_path__\nreplacement: /abc\n",
+				InLabelsRaw:  `{__address__="foo:1234",job="bar"}`,
+				OutLabelsRaw: `{__address__="foo:1234",__metrics_path__="/abc",job="bar"}`,
+			},
+			{
+				Rule:         "add missing instance label from __address__ label",
+				InLabelsRaw:  `{__address__="foo:1234",__metrics_path__="/abc",job="bar"}`,
+				OutLabelsRaw: `{__address__="foo:1234",__metrics_path__="/abc",instance="foo:1234",job="bar"}`,
+			},
+			{
+				Rule:         "remove labels with __ prefix",
+				InLabelsRaw:  `{__address__="foo:1234",__metrics_path__="/abc",instance="foo:1234",job="bar"}`,
+				OutLabelsRaw: `{instance="foo:1234",job="bar"}`,
+			},
+		},
+	})
+
+	// Invalid relabel configs
+	f(false, `foo`, `- action: foobar`, &response{
+		Status: "error",
+		Error:  "Error: cannot parse relabel configs: error when parsing `relabel_config` #1: unknown `action` \"foobar\"",
+	})
+}
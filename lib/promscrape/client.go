@@ -75,10 +75,19 @@ func newClient(ctx context.Context, sw *ScrapeWork) (*client, error) {
 	if pu := sw.ProxyURL.GetURL(); pu != nil {
 		proxyURLFunc = http.ProxyURL(pu)
 	}
+	var getProxyConnectHeader func(ctx context.Context, proxyURL *url.URL, target string) (http.Header, error)
+	if isTLS && proxyURL.IsHTTPOrHTTPS() {
+		// Proxy auth and proxy_headers must be passed in CONNECT request for https targets,
+		// since the request to the target is encrypted and isn't visible to the proxy.
+		getProxyConnectHeader = func(_ context.Context, _ *url.URL, _ string) (http.Header, error) {
+			return proxyURL.GetConnectHeader(sw.ProxyAuthConfig)
+		}
+	}
 	hc := &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig:        tlsCfg,
 			Proxy:                  proxyURLFunc,
+			GetProxyConnectHeader:  getProxyConnectHeader,
 			TLSHandshakeTimeout:    10 * time.Second,
 			IdleConnTimeout:        2 * sw.ScrapeInterval,
 			DisableCompression:     *disableCompression || sw.DisableCompression,
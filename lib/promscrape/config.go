@@ -96,7 +96,11 @@ func mustInitClusterMemberID() {
 	}
 	if n < 0 || n >= *clusterMembersCount {
 		logger.Fatalf("-promscrape.cluster.memberNum must be in the range [0..%d] according to -promscrape.cluster.membersCount=%d",
-			*clusterMembersCount, *clusterMembersCount)
+			*clusterMembersCount-1, *clusterMembersCount)
+	}
+	if *clusterReplicationFactor > *clusterMembersCount {
+		logger.Warnf("-promscrape.cluster.replicationFactor=%d exceeds -promscrape.cluster.membersCount=%d; every target will be scraped by all the %d members",
+			*clusterReplicationFactor, *clusterMembersCount, *clusterMembersCount)
 	}
 	clusterMemberID = n
 }
@@ -1069,6 +1073,10 @@ func getClusterMemberNumsForScrapeWork(key string, membersCount, replicasCount i
 	if replicasCount < 1 {
 		replicasCount = 1
 	}
+	if replicasCount > membersCount {
+		// Every member already scrapes the target, so there is no sense in assigning it multiple times to the same member.
+		replicasCount = membersCount
+	}
 	memberNums := make([]int, replicasCount)
 	for i := 0; i < replicasCount; i++ {
 		memberNums[i] = idx
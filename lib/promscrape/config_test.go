@@ -134,6 +134,10 @@ func TestGetClusterMemberNumsForScrapeWork(t *testing.T) {
 	f("abc", 3, 2, []int{0, 1})
 	f("bar", 3, 2, []int{1, 2})
 	f("foo", 3, 2, []int{2, 0})
+
+	// A cluster with 2 nodes with replicationFactor exceeding the number of nodes
+	f("baz", 2, 3, []int{0, 1})
+	f("foo", 2, 3, []int{1, 0})
 }
 
 func TestLoadStaticConfigs(t *testing.T) {
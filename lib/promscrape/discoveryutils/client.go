@@ -120,12 +120,19 @@ func NewClient(apiServer string, ac *promauth.Config, proxyURL *proxy.URL, proxy
 	if pu := proxyURL.GetURL(); pu != nil {
 		proxyURLFunc = http.ProxyURL(pu)
 	}
+	var getProxyConnectHeader func(ctx context.Context, proxyURL *url.URL, target string) (http.Header, error)
+	if isTLS && proxyAC != nil && proxyURL.IsHTTPOrHTTPS() {
+		getProxyConnectHeader = func(_ context.Context, _ *url.URL, _ string) (http.Header, error) {
+			return proxyURL.GetConnectHeader(proxyAC)
+		}
+	}
 
 	client := &http.Client{
 		Timeout: DefaultClientReadTimeout,
 		Transport: &http.Transport{
 			TLSClientConfig:       tlsCfg,
 			Proxy:                 proxyURLFunc,
+			GetProxyConnectHeader: getProxyConnectHeader,
 			TLSHandshakeTimeout:   10 * time.Second,
 			MaxIdleConnsPerHost:   *maxConcurrency,
 			ResponseHeaderTimeout: DefaultClientReadTimeout,
@@ -137,6 +144,7 @@ func NewClient(apiServer string, ac *promauth.Config, proxyURL *proxy.URL, proxy
 		Transport: &http.Transport{
 			TLSClientConfig:       tlsCfg,
 			Proxy:                 proxyURLFunc,
+			GetProxyConnectHeader: getProxyConnectHeader,
 			TLSHandshakeTimeout:   10 * time.Second,
 			MaxIdleConnsPerHost:   1000,
 			ResponseHeaderTimeout: BlockingClientReadTimeout,
@@ -33,6 +33,7 @@ import (
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/yandexcloud"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
 	"github.com/VictoriaMetrics/metrics"
+	"github.com/cespare/xxhash/v2"
 )
 
 var (
@@ -97,6 +98,11 @@ func WriteConfigData(w io.Writer) {
 	_, _ = w.Write(*p)
 }
 
+func storeConfigData(data []byte) {
+	configData.Store(&data)
+	configHash.Set(float64(xxhash.Sum64(data)))
+}
+
 func runScraper(configFile string, pushData func(at *auth.Token, wr *prompbmarshal.WriteRequest), globalStopCh <-chan struct{}) {
 	if configFile == "" {
 		// Nothing to scrape.
@@ -115,8 +121,7 @@ func runScraper(configFile string, pushData func(at *auth.Token, wr *prompbmarsh
 	if err != nil {
 		logger.Fatalf("cannot read %q: %s", configFile, err)
 	}
-	marshaledData := cfg.marshal()
-	configData.Store(&marshaledData)
+	storeConfigData(cfg.marshal())
 	cfg.mustStart()
 
 	configSuccess.Set(1)
@@ -168,8 +173,7 @@ func runScraper(configFile string, pushData func(at *auth.Token, wr *prompbmarsh
 				goto waitForChans
 			}
 			cfg = cfgNew
-			marshaledData = cfg.marshal()
-			configData.Store(&marshaledData)
+			storeConfigData(cfg.marshal())
 			configReloads.Inc()
 			configTimestamp.Set(fasttime.UnixTimestamp())
 		case <-tickerCh:
@@ -185,8 +189,7 @@ func runScraper(configFile string, pushData func(at *auth.Token, wr *prompbmarsh
 				goto waitForChans
 			}
 			cfg = cfgNew
-			marshaledData = cfg.marshal()
-			configData.Store(&marshaledData)
+			storeConfigData(cfg.marshal())
 			configReloads.Inc()
 			configTimestamp.Set(fasttime.UnixTimestamp())
 		case <-globalStopCh:
@@ -206,6 +209,7 @@ var (
 	configReloadErrors = configMetricsSet.NewCounter(`vm_promscrape_config_reloads_errors_total`)
 	configSuccess      = configMetricsSet.NewGauge(`vm_promscrape_config_last_reload_successful`, nil)
 	configTimestamp    = configMetricsSet.NewCounter(`vm_promscrape_config_last_reload_success_timestamp_seconds`)
+	configHash         = configMetricsSet.NewGauge(`vm_promscrape_config_hash`, nil)
 )
 
 type scrapeConfigs struct {
@@ -392,6 +392,7 @@ var (
 	scrapedSamples              = metrics.NewHistogram("vm_promscrape_scraped_samples")
 	scrapesSkippedBySampleLimit = metrics.NewCounter("vm_promscrape_scrapes_skipped_by_sample_limit_total")
 	scrapesFailed               = metrics.NewCounter("vm_promscrape_scrapes_failed_total")
+	seriesLimitSamplesDropped   = metrics.NewCounter("vm_promscrape_series_limit_samples_dropped_total")
 	pushDataDuration            = metrics.NewHistogram("vm_promscrape_push_data_duration_seconds")
 )
 
@@ -569,6 +570,8 @@ func (sw *scrapeWork) processDataInStreamMode(scrapeTimestamp, realTimestamp int
 		// to remote storage. This makes the logic compatible with Prometheus.
 		up = 0
 		scrapesFailed.Inc()
+		// Send stale markers for all the metrics scraped last time in the same way as processDataOneShot does.
+		bodyString = ""
 	}
 	seriesAdded := 0
 	if !areIdenticalSeries {
@@ -719,7 +722,8 @@ func (sw *scrapeWork) applySeriesLimit(wc *writeRequestCtx) int {
 	}
 	prompbmarshal.ResetTimeSeries(wc.writeRequest.Timeseries[len(dstSeries):])
 	wc.writeRequest.Timeseries = dstSeries
-	if samplesDropped > 0 && !sw.seriesLimitExceeded {
+	if samplesDropped > 0 {
+		seriesLimitSamplesDropped.Add(samplesDropped)
 		sw.seriesLimitExceeded = true
 	}
 	return samplesDropped
@@ -8,6 +8,7 @@ import (
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/auth"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/bytesutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/decimal"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promrelabel"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
@@ -746,6 +747,47 @@ func TestSendStaleSeries(t *testing.T) {
 	f(generateScrape(20000), generateScrape(10), 19990)
 }
 
+func TestScrapeWorkSendStaleMarkersOnFailedScrape(t *testing.T) {
+	f := func(streamParse bool) {
+		t.Helper()
+		var sw scrapeWork
+		sw.Config = &ScrapeWork{
+			ScrapeTimeout: time.Second * 42,
+			SampleLimit:   1,
+			StreamParse:   streamParse,
+		}
+		sw.storeLastScrape([]byte("foo 1\n"))
+		sw.ReadData = func(dst *bytesutil.ByteBuffer) error {
+			dst.B = append(dst.B, "foo 1\nbar 2\n"...)
+			return nil
+		}
+		common.StartUnmarshalWorkers()
+		defer common.StopUnmarshalWorkers()
+
+		var staleMetrics []string
+		sw.PushData = func(at *auth.Token, wr *prompbmarshal.WriteRequest) {
+			for _, ts := range wr.Timeseries {
+				if decimal.IsStaleNaN(ts.Samples[0].Value) {
+					staleMetrics = append(staleMetrics, promrelabel.LabelsToString(ts.Labels))
+				}
+			}
+		}
+		timestamp := int64(123000)
+		tsmGlobal.Register(&sw)
+		err := sw.scrapeInternal(timestamp, timestamp)
+		tsmGlobal.Unregister(&sw)
+		if err == nil || !strings.Contains(err.Error(), "sample_limit") {
+			t.Fatalf("expecting sample_limit error; got %v", err)
+		}
+		if len(staleMetrics) != 1 || staleMetrics[0] != "foo" {
+			t.Fatalf("unexpected stale markers; got %q; want %q", staleMetrics, []string{"foo"})
+		}
+	}
+
+	f(false)
+	f(true)
+}
+
 func parsePromRow(data string) *parser.Row {
 	var rows parser.Rows
 	errLogger := func(s string) {
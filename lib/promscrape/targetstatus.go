@@ -66,19 +66,21 @@ func WriteServiceDiscovery(w http.ResponseWriter, r *http.Request) {
 }
 
 // WriteAPIV1Targets writes /api/v1/targets to w according to https://prometheus.io/docs/prometheus/latest/querying/api/#targets
-func WriteAPIV1Targets(w io.Writer, state string) {
+//
+// If scrapePool isn't empty, then only targets for the given job_name are returned.
+func WriteAPIV1Targets(w io.Writer, state, scrapePool string) {
 	if state == "" {
 		state = "any"
 	}
 	fmt.Fprintf(w, `{"status":"success","data":{"activeTargets":`)
 	if state == "active" || state == "any" {
-		tsmGlobal.WriteActiveTargetsJSON(w)
+		tsmGlobal.WriteActiveTargetsJSON(w, scrapePool)
 	} else {
 		fmt.Fprintf(w, `[]`)
 	}
 	fmt.Fprintf(w, `,"droppedTargets":`)
 	if state == "dropped" || state == "any" {
-		droppedTargetsMap.WriteDroppedTargetsJSON(w)
+		droppedTargetsMap.WriteDroppedTargetsJSON(w, scrapePool)
 	} else {
 		fmt.Fprintf(w, `[]`)
 	}
@@ -251,8 +253,19 @@ func (tsm *targetStatusMap) getActiveTargetStatuses() []targetStatus {
 }
 
 // WriteActiveTargetsJSON writes `activeTargets` contents to w according to https://prometheus.io/docs/prometheus/latest/querying/api/#targets
-func (tsm *targetStatusMap) WriteActiveTargetsJSON(w io.Writer) {
+//
+// If scrapePool isn't empty, then only targets for the given job_name are written.
+func (tsm *targetStatusMap) WriteActiveTargetsJSON(w io.Writer, scrapePool string) {
 	tss := tsm.getActiveTargetStatuses()
+	if scrapePool != "" {
+		tssFiltered := tss[:0]
+		for _, ts := range tss {
+			if ts.sw.Config.Job() == scrapePool {
+				tssFiltered = append(tssFiltered, ts)
+			}
+		}
+		tss = tssFiltered
+	}
 	fmt.Fprintf(w, `[`)
 	for i, ts := range tss {
 		fmt.Fprintf(w, `{"discoveredLabels":`)
@@ -261,6 +274,7 @@ func (tsm *targetStatusMap) WriteActiveTargetsJSON(w io.Writer) {
 		writeLabelsJSON(w, ts.sw.Config.Labels)
 		fmt.Fprintf(w, `,"scrapePool":%q`, ts.sw.Config.Job())
 		fmt.Fprintf(w, `,"scrapeUrl":%q`, ts.sw.Config.ScrapeURL)
+		fmt.Fprintf(w, `,"globalUrl":%q`, ts.sw.Config.ScrapeURL)
 		errMsg := ""
 		if ts.err != nil {
 			errMsg = ts.err.Error()
@@ -269,6 +283,8 @@ func (tsm *targetStatusMap) WriteActiveTargetsJSON(w io.Writer) {
 		fmt.Fprintf(w, `,"lastScrape":%q`, time.Unix(ts.scrapeTime/1000, (ts.scrapeTime%1000)*1e6).Format(time.RFC3339Nano))
 		fmt.Fprintf(w, `,"lastScrapeDuration":%g`, (time.Millisecond * time.Duration(ts.scrapeDuration)).Seconds())
 		fmt.Fprintf(w, `,"lastSamplesScraped":%d`, ts.samplesScraped)
+		fmt.Fprintf(w, `,"scrapeInterval":%q`, formatPrometheusDuration(ts.sw.Config.ScrapeInterval))
+		fmt.Fprintf(w, `,"scrapeTimeout":%q`, formatPrometheusDuration(ts.sw.Config.ScrapeTimeout))
 		state := "up"
 		if !ts.up {
 			state = "down"
@@ -281,6 +297,36 @@ func (tsm *targetStatusMap) WriteActiveTargetsJSON(w io.Writer) {
 	fmt.Fprintf(w, `]`)
 }
 
+// formatPrometheusDuration returns d in the format used by Prometheus such as `1m`, `1m30s` or `500ms`.
+//
+// Go formats durations as `1m0s`, which isn't recognized by some third-party tools consuming /api/v1/targets.
+func formatPrometheusDuration(d time.Duration) string {
+	ms := d.Milliseconds()
+	if ms <= 0 {
+		return "0s"
+	}
+	var b []byte
+	f := func(unit string, mult int64, exact bool) {
+		if exact && ms%mult != 0 {
+			return
+		}
+		if n := ms / mult; n > 0 {
+			b = strconv.AppendInt(b, n, 10)
+			b = append(b, unit...)
+			ms -= n * mult
+		}
+	}
+	// Only multiples of years and weeks are formatted with the corresponding units in the same way as Prometheus does.
+	f("y", 1000*60*60*24*365, true)
+	f("w", 1000*60*60*24*7, true)
+	f("d", 1000*60*60*24, false)
+	f("h", 1000*60*60, false)
+	f("m", 1000*60, false)
+	f("s", 1000, false)
+	f("ms", 1, false)
+	return string(b)
+}
+
 func writeLabelsJSON(w io.Writer, labels *promutils.Labels) {
 	fmt.Fprintf(w, `{`)
 	labelsList := labels.GetLabels()
@@ -410,8 +456,19 @@ var xxhashPool = &sync.Pool{
 }
 
 // WriteDroppedTargetsJSON writes `droppedTargets` contents to w according to https://prometheus.io/docs/prometheus/latest/querying/api/#targets
-func (dt *droppedTargets) WriteDroppedTargetsJSON(w io.Writer) {
+//
+// If scrapePool isn't empty, then only targets with the given `job` discovered label are written.
+func (dt *droppedTargets) WriteDroppedTargetsJSON(w io.Writer, scrapePool string) {
 	dts := dt.getTargetsList()
+	if scrapePool != "" {
+		dtsFiltered := dts[:0]
+		for _, dt := range dts {
+			if dt.originalLabels.Get("job") == scrapePool {
+				dtsFiltered = append(dtsFiltered, dt)
+			}
+		}
+		dts = dtsFiltered
+	}
 	fmt.Fprintf(w, `[`)
 	for i, dt := range dts {
 		fmt.Fprintf(w, `{"discoveredLabels":`)
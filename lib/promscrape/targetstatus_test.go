@@ -0,0 +1,110 @@
+package promscrape
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+func TestFormatPrometheusDuration(t *testing.T) {
+	f := func(d time.Duration, resultExpected string) {
+		t.Helper()
+		result := formatPrometheusDuration(d)
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %q; want %q", d, result, resultExpected)
+		}
+	}
+	f(0, "0s")
+	f(500*time.Millisecond, "500ms")
+	f(15*time.Second, "15s")
+	f(time.Minute, "1m")
+	f(90*time.Second, "1m30s")
+	f(time.Hour+1500*time.Millisecond, "1h1s500ms")
+	f(25*time.Hour, "1d1h")
+	f(14*24*time.Hour, "2w")
+	f(15*24*time.Hour, "15d")
+	f(365*24*time.Hour, "1y")
+}
+
+func TestWriteTargetsJSONScrapePool(t *testing.T) {
+	tsm := newTargetStatusMap()
+	dt := &droppedTargets{
+		m: make(map[uint64]droppedTarget),
+	}
+	for _, target := range []struct {
+		job  string
+		addr string
+	}{
+		{"foo", "host1:80"},
+		{"bar", "host2:80"},
+		{"foo", "host3:80"},
+	} {
+		originalLabels := promutils.NewLabelsFromMap(map[string]string{
+			"__address__": target.addr,
+			"job":         target.job,
+		})
+		tsm.Register(&scrapeWork{
+			Config: &ScrapeWork{
+				ScrapeURL:       "http://" + target.addr + "/metrics",
+				Labels:          promutils.NewLabelsFromMap(map[string]string{"job": target.job}),
+				OriginalLabels:  originalLabels,
+				jobNameOriginal: target.job,
+			},
+		})
+		dt.Register(originalLabels, nil, targetDropReasonRelabeling, nil)
+	}
+
+	f := func(scrapePool string, addrsExpected []string) {
+		t.Helper()
+
+		var bb bytes.Buffer
+		tsm.WriteActiveTargetsJSON(&bb, scrapePool)
+		var activeTargets []struct {
+			DiscoveredLabels map[string]string `json:"discoveredLabels"`
+			ScrapePool       string            `json:"scrapePool"`
+		}
+		if err := json.Unmarshal(bb.Bytes(), &activeTargets); err != nil {
+			t.Fatalf("cannot parse active targets %q: %s", bb.String(), err)
+		}
+		var addrs []string
+		for _, ts := range activeTargets {
+			if scrapePool != "" && ts.ScrapePool != scrapePool {
+				t.Fatalf("unexpected scrapePool for active target; got %q; want %q", ts.ScrapePool, scrapePool)
+			}
+			addrs = append(addrs, ts.DiscoveredLabels["__address__"])
+		}
+		if !reflect.DeepEqual(addrs, addrsExpected) {
+			t.Fatalf("unexpected active targets; got %q; want %q", addrs, addrsExpected)
+		}
+
+		bb.Reset()
+		dt.WriteDroppedTargetsJSON(&bb, scrapePool)
+		var dropped []struct {
+			DiscoveredLabels map[string]string `json:"discoveredLabels"`
+		}
+		if err := json.Unmarshal(bb.Bytes(), &dropped); err != nil {
+			t.Fatalf("cannot parse dropped targets %q: %s", bb.String(), err)
+		}
+		addrs = addrs[:0]
+		for _, d := range dropped {
+			addrs = append(addrs, d.DiscoveredLabels["__address__"])
+		}
+		if !reflect.DeepEqual(addrs, addrsExpected) {
+			t.Fatalf("unexpected dropped targets; got %q; want %q", addrs, addrsExpected)
+		}
+	}
+
+	// empty scrapePool returns all the targets
+	f("", []string{"host1:80", "host2:80", "host3:80"})
+
+	// non-empty scrapePool returns only targets for the given job
+	f("foo", []string{"host1:80", "host3:80"})
+	f("bar", []string{"host2:80"})
+
+	// unknown scrapePool
+	f("baz", nil)
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/encoding/zstd"
+)
+
+func TestZstdReader(t *testing.T) {
+	data := []byte("foo bar baz")
+	compressed := zstd.CompressLevel(nil, data, 1)
+
+	zr, err := GetZstdReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("cannot create zstd reader: %s", err)
+	}
+	result, err := io.ReadAll(zr)
+	PutZstdReader(zr)
+	if err != nil {
+		t.Fatalf("cannot read zstd data: %s", err)
+	}
+	if !bytes.Equal(result, data) {
+		t.Fatalf("unexpected data; got %q; want %q", result, data)
+	}
+
+	// The reader obtained from the pool must be usable
+	zr, err = GetZstdReader(bytes.NewReader([]byte("invalid zstd data")))
+	if err != nil {
+		t.Fatalf("cannot create zstd reader: %s", err)
+	}
+	if _, err := io.ReadAll(zr); err == nil {
+		t.Fatalf("expecting non-nil error when reading invalid zstd data")
+	}
+	PutZstdReader(zr)
+}
+
+func TestZstdReaderLimit(t *testing.T) {
+	// Highly compressible data must be decompressed only up to the limit
+	data := make([]byte, 64*1024*1024)
+	compressed := zstd.CompressLevel(nil, data, 1)
+
+	zr, err := GetZstdReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("cannot create zstd reader: %s", err)
+	}
+	defer PutZstdReader(zr)
+
+	const limit = 1024
+	n, err := io.Copy(io.Discard, io.LimitReader(zr, limit+1))
+	if err != nil {
+		t.Fatalf("cannot read zstd data: %s", err)
+	}
+	if n != limit+1 {
+		t.Fatalf("unexpected number of bytes read; got %d; want %d", n, limit+1)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
 )
 
@@ -32,6 +33,22 @@ func GetExtraLabels(req *http.Request) ([]prompbmarshal.Label, error) {
 	return labels, nil
 }
 
+// CheckPushgatewayMethod returns an error with 405 status code for Pushgateway DELETE requests.
+//
+// Push groups aren't kept, so they cannot be deleted. PUT requests must be processed in the same way as POST requests,
+// since PUT is the default method for the most of Pushgateway clients.
+// See https://github.com/prometheus/pushgateway#delete-method
+func CheckPushgatewayMethod(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodDelete {
+		return nil
+	}
+	w.Header().Set("Allow", "POST, PUT")
+	return &httpserver.ErrorWithStatusCode{
+		Err:        fmt.Errorf("DELETE method isn't supported for Pushgateway requests, since push groups aren't kept"),
+		StatusCode: http.StatusMethodNotAllowed,
+	}
+}
+
 func getPushgatewayLabels(path string) ([]prompbmarshal.Label, error) {
 	n := strings.Index(path, "/metrics/job")
 	if n < 0 {
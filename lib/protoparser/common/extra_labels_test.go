@@ -3,6 +3,7 @@ package common
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"sort"
 	"strings"
 	"testing"
@@ -92,3 +93,21 @@ func getLabelsString(labels []prompbmarshal.Label) string {
 	sort.Strings(a)
 	return "{" + strings.Join(a, ",") + "}"
 }
+
+func TestCheckPushgatewayMethod(t *testing.T) {
+	f := func(method string, isErrorExpected bool) {
+		t.Helper()
+		r, err := http.NewRequest(method, "http://localhost:8428/api/v1/import/prometheus/metrics/job/foo", nil)
+		if err != nil {
+			t.Fatalf("cannot create request: %s", err)
+		}
+		w := httptest.NewRecorder()
+		err = CheckPushgatewayMethod(w, r)
+		if isErrorExpected != (err != nil) {
+			t.Fatalf("unexpected error for %s method: %v; want error: %v", method, err, isErrorExpected)
+		}
+	}
+	f(http.MethodPost, false)
+	f(http.MethodPut, false)
+	f(http.MethodDelete, true)
+}
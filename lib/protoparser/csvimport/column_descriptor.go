@@ -2,6 +2,7 @@ package csvimport
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -45,8 +46,9 @@ const maxColumnsPerRow = 64 * 1024
 //   - <column_pos> is numeric csv column position. The first column has position 1.
 //   - <column_type> is one of the following types:
 //   - time - the corresponding column contains timestamp. Timestamp format is determined by <extension>. The following formats are supported:
-//   - - unix_s - unix timestamp in seconds
+//   - - unix_s - unix timestamp in seconds. Fractional seconds such as 1700000000.123 are supported
 //   - - unix_ms - unix timestamp in milliseconds
+//   - - unix_us - unix timestamp in microseconds
 //   - - unix_ns - unix_timestamp in nanoseconds
 //   - - rfc3339 - RFC3339 format in the form `2006-01-02T15:04:05Z07:00`
 //   - label - the corresponding column contains metric label with the name set in <extension>.
@@ -130,19 +132,35 @@ func parseTimeFormat(format string) (func(s string) (int64, error), error) {
 		return parseUnixTimestampSeconds, nil
 	case "unix_ms":
 		return parseUnixTimestampMilliseconds, nil
+	case "unix_us":
+		return parseUnixTimestampMicroseconds, nil
 	case "unix_ns":
 		return parseUnixTimestampNanoseconds, nil
 	case "rfc3339":
 		return parseRFC3339, nil
 	default:
-		return nil, fmt.Errorf("unknown format for time parsing: %q; supported formats: unix_s, unix_ms, unix_ns, rfc3339", format)
+		return nil, fmt.Errorf("unknown format for time parsing: %q; supported formats: unix_s, unix_ms, unix_us, unix_ns, rfc3339", format)
 	}
 }
 
 func parseUnixTimestampSeconds(s string) (int64, error) {
 	n, err := fastfloat.ParseInt64(s)
 	if err != nil {
-		return 0, fmt.Errorf("cannot parse timestamp seconds from %q: %w", s, err)
+		if strings.IndexByte(s, '.') < 0 {
+			return 0, fmt.Errorf("cannot parse timestamp seconds from %q: %w", s, err)
+		}
+		// Try parsing timestamp with fractional seconds such as 1700000000.123
+		f, errFloat := fastfloat.Parse(s)
+		if errFloat != nil {
+			return 0, fmt.Errorf("cannot parse timestamp seconds from %q: %w", s, errFloat)
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, fmt.Errorf("timestamp seconds cannot be NaN or Inf; got %q", s)
+		}
+		if math.Abs(f) > float64(int64(1<<63-1)/1e3) {
+			return 0, fmt.Errorf("too big unix timestamp in seconds: %s; must be smaller than %d", s, int64(1<<63-1)/1e3)
+		}
+		return int64(math.Round(f * 1e3)), nil
 	}
 	if n > int64(1<<63-1)/1e3 {
 		return 0, fmt.Errorf("too big unix timestamp in seconds: %d; must be smaller than %d", n, int64(1<<63-1)/1e3)
@@ -158,6 +176,14 @@ func parseUnixTimestampMilliseconds(s string) (int64, error) {
 	return n, nil
 }
 
+func parseUnixTimestampMicroseconds(s string) (int64, error) {
+	n, err := fastfloat.ParseInt64(s)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse timestamp microseconds from %q: %w", s, err)
+	}
+	return n / 1e3, nil
+}
+
 func parseUnixTimestampNanoseconds(s string) (int64, error) {
 	n, err := fastfloat.ParseInt64(s)
 	if err != nil {
@@ -121,6 +121,26 @@ func TestParseUnixTimestampSeconds(t *testing.T) {
 	f("0", 0)
 	f("123", 123000)
 	f("-123", -123000)
+
+	// fractional seconds
+	f("123.456", 123456)
+	f("-123.456", -123456)
+	f("1700000000.1234", 1700000000123)
+}
+
+func TestParseUnixTimestampSecondsFailure(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		if _, err := parseUnixTimestampSeconds(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+	f("")
+	f("foo")
+	f("1e3")
+	f("12.foo")
+	f("1.2.3")
+	f("123456789012345678901.5")
 }
 
 func TestParseUnixTimestampMilliseconds(t *testing.T) {
@@ -139,6 +159,23 @@ func TestParseUnixTimestampMilliseconds(t *testing.T) {
 	f("-123", -123)
 }
 
+func TestParseUnixTimestampMicroseconds(t *testing.T) {
+	f := func(s string, tsExpected int64) {
+		t.Helper()
+		ts, err := parseUnixTimestampMicroseconds(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if ts != tsExpected {
+			t.Fatalf("unexpected ts when parsing %q; got %d; want %d", s, ts, tsExpected)
+		}
+	}
+	f("0", 0)
+	f("123", 0)
+	f("12343", 12)
+	f("-12343", -12)
+}
+
 func TestParseUnixTimestampNanoseconds(t *testing.T) {
 	f := func(s string, tsExpected int64) {
 		t.Helper()
@@ -39,6 +39,14 @@ func Parse(r io.Reader, contentEncoding string, callback func(series []datadogv1
 		}
 		defer common.PutZlibReader(zlr)
 		r = zlr
+	case "zstd":
+		// DataDog agent may send zstd-compressed data when serializer_compressor_kind=zstd is set.
+		zsr, err := common.GetZstdReader(r)
+		if err != nil {
+			return fmt.Errorf("cannot read zstd-encoded DataDog data: %w", err)
+		}
+		defer common.PutZstdReader(zsr)
+		r = zsr
 	}
 
 	ctx := getPushCtx(r)
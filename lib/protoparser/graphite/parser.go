@@ -2,8 +2,10 @@ package graphite
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/bytesutil"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
 	"github.com/VictoriaMetrics/metrics"
 	"github.com/valyala/fastjson/fastfloat"
@@ -240,3 +242,21 @@ func stripLeadingWhitespace(s string) string {
 	}
 	return ""
 }
+
+// SanitizeMetricName performs Graphite-compatible sanitizing for metric names.
+//
+// It collapses repeated dots and replaces chars other than a-zA-Z0-9:_.- with underscores,
+// so the resulting names match the names produced by carbon-relay-like tools.
+func SanitizeMetricName(name string) string {
+	return metricNameSanitizer.Transform(name)
+}
+
+var metricNameSanitizer = bytesutil.NewFastStringTransformer(func(s string) string {
+	s = repeatedDots.ReplaceAllLiteralString(s, ".")
+	return unsupportedGraphiteChars.ReplaceAllLiteralString(s, "_")
+})
+
+var (
+	repeatedDots             = regexp.MustCompile(`\.{2,}`)
+	unsupportedGraphiteChars = regexp.MustCompile(`[^a-zA-Z0-9:_.\-]`)
+)
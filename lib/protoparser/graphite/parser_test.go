@@ -380,3 +380,19 @@ func TestRowsUnmarshalSuccess(t *testing.T) {
 		}},
 	})
 }
+
+func TestSanitizeMetricName(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		result := SanitizeMetricName(s)
+		if result != resultExpected {
+			t.Fatalf("unexpected result for SanitizeMetricName(%q); got %q; want %q", s, result, resultExpected)
+		}
+	}
+	f("", "")
+	f("foo.bar", "foo.bar")
+	f("foo.bar-baz:aaa_bbb", "foo.bar-baz:aaa_bbb")
+	f("foo..bar...baz", "foo.bar.baz")
+	f("foo bar/baz(x)", "foo_bar_baz_x_")
+	f("привет.мир", "______.___")
+}
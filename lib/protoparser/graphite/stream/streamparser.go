@@ -20,6 +20,8 @@ import (
 var (
 	trimTimestamp = flag.Duration("graphiteTrimTimestamp", time.Second, "Trim timestamps for Graphite data to this duration. "+
 		"Minimum practical duration is 1s. Higher duration (i.e. 1m) may be used for reducing disk space usage for timestamp data")
+	sanitizeMetricName = flag.Bool("graphite.sanitizeMetricName", false, "Sanitize metric names for the ingested Graphite data. "+
+		"See https://docs.victoriametrics.com/#how-to-send-data-from-graphite-compatible-agents-such-as-statsd")
 )
 
 // Parse parses Graphite lines from r and calls callback for the parsed rows.
@@ -198,6 +200,14 @@ func (uw *unmarshalWork) Unmarshal() {
 		}
 	}
 
+	// Sanitize metric names if required.
+	if *sanitizeMetricName {
+		for i := range rows {
+			row := &rows[i]
+			row.Metric = graphite.SanitizeMetricName(row.Metric)
+		}
+	}
+
 	uw.runCallback(rows)
 	putUnmarshalWork(uw)
 }
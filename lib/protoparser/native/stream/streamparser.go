@@ -50,6 +50,13 @@ func Parse(r io.Reader, isGzip bool, callback func(block *Block) error) error {
 
 	ctx := &streamContext{}
 	for {
+		// Stop reading the remaining data on the first processing error,
+		// since the error is returned to the client anyway.
+		if err := ctx.Error(); err != nil {
+			ctx.wg.Wait()
+			return err
+		}
+
 		uw := getUnmarshalWork()
 		uw.tr = tr
 		uw.ctx = ctx
@@ -61,7 +68,7 @@ func Parse(r io.Reader, isGzip bool, callback func(block *Block) error) error {
 				// End of stream
 				putUnmarshalWork(uw)
 				ctx.wg.Wait()
-				return ctx.err
+				return ctx.Error()
 			}
 			readErrors.Inc()
 			ctx.wg.Wait()
@@ -116,6 +123,14 @@ type streamContext struct {
 	err     error
 }
 
+// Error returns the first error occurred during processing native blocks.
+func (ctx *streamContext) Error() error {
+	ctx.errLock.Lock()
+	err := ctx.err
+	ctx.errLock.Unlock()
+	return err
+}
+
 // Block is a single block from `/api/v1/import/native` request.
 type Block struct {
 	MetricName storage.MetricName
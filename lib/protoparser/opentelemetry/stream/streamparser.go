@@ -3,6 +3,7 @@ package stream
 import (
 	"fmt"
 	"io"
+	"math"
 	"strconv"
 	"sync"
 
@@ -81,6 +82,14 @@ func (wr *writeContext) appendSamplesFromScopeMetrics(sc *pb.ScopeMetrics) {
 			for _, p := range m.Histogram.DataPoints {
 				wr.appendSamplesFromHistogram(m.Name, p)
 			}
+		case m.ExponentialHistogram != nil:
+			if m.ExponentialHistogram.AggregationTemporality != pb.AggregationTemporalityCumulative {
+				rowsDroppedUnsupportedExponentialHistogram.Inc()
+				continue
+			}
+			for _, p := range m.ExponentialHistogram.DataPoints {
+				wr.appendSamplesFromExponentialHistogram(m.Name, p)
+			}
 		default:
 			rowsDroppedUnsupportedMetricType.Inc()
 			logger.Warnf("unsupported type for metric %q", m.Name)
@@ -155,6 +164,58 @@ func (wr *writeContext) appendSamplesFromHistogram(metricName string, p *pb.Hist
 	wr.appendSampleWithExtraLabel(metricName+"_bucket", "le", "+Inf", t, float64(cumulative), isStale)
 }
 
+// appendSamplesFromExponentialHistogram appends exponential histogram p to wr.tss
+//
+// Buckets are converted to VictoriaMetrics histogram buckets with `vmrange` labels,
+// so they can be used in histogram_quantile() and other histogram functions.
+// See https://valyala.medium.com/improving-histogram-usability-for-prometheus-and-grafana-bc7e5df0e350
+func (wr *writeContext) appendSamplesFromExponentialHistogram(metricName string, p *pb.ExponentialHistogramDataPoint) {
+	t := int64(p.TimeUnixNano / 1e6)
+	isStale := (p.Flags)&uint32(1) != 0
+	wr.pointLabels = appendAttributesToPromLabels(wr.pointLabels[:0], p.Attributes)
+	wr.appendSample(metricName+"_count", t, float64(p.Count), isStale)
+	if p.Sum != nil {
+		wr.appendSample(metricName+"_sum", t, *p.Sum, isStale)
+	}
+
+	bucketName := metricName + "_bucket"
+	if p.ZeroCount > 0 || isStale {
+		vmrange := formatVMRange(-p.ZeroThreshold, p.ZeroThreshold)
+		wr.appendSampleWithExtraLabel(bucketName, "vmrange", vmrange, t, float64(p.ZeroCount), isStale)
+	}
+	// The bucket with index i covers (base^i, base^(i+1)] range for positive values
+	// and [-base^(i+1), -base^i) range for negative values, where base=2^(2^-scale).
+	// See https://opentelemetry.io/docs/specs/otel/metrics/data-model/#exponentialhistogram
+	scaleFactor := math.Exp2(-float64(p.Scale))
+	if b := p.Positive; b != nil {
+		for i, count := range b.BucketCounts {
+			if count == 0 && !isStale {
+				continue
+			}
+			idx := float64(int(b.Offset) + i)
+			vmrange := formatVMRange(math.Exp2(idx*scaleFactor), math.Exp2((idx+1)*scaleFactor))
+			wr.appendSampleWithExtraLabel(bucketName, "vmrange", vmrange, t, float64(count), isStale)
+		}
+	}
+	if b := p.Negative; b != nil {
+		for i, count := range b.BucketCounts {
+			if count == 0 && !isStale {
+				continue
+			}
+			idx := float64(int(b.Offset) + i)
+			vmrange := formatVMRange(-math.Exp2((idx+1)*scaleFactor), -math.Exp2(idx*scaleFactor))
+			wr.appendSampleWithExtraLabel(bucketName, "vmrange", vmrange, t, float64(count), isStale)
+		}
+	}
+}
+
+func formatVMRange(start, end float64) string {
+	if start == 0 && end == 0 {
+		return "0...0"
+	}
+	return strconv.FormatFloat(start, 'g', -1, 64) + "..." + strconv.FormatFloat(end, 'g', -1, 64)
+}
+
 // appendSample appends sample with the given metricName to wr.tss
 func (wr *writeContext) appendSample(metricName string, t int64, v float64, isStale bool) {
 	wr.appendSampleWithExtraLabel(metricName, "", "", t, v, isStale)
@@ -297,8 +358,9 @@ func putWriteContext(wr *writeContext) {
 }
 
 var (
-	rowsRead                         = metrics.NewCounter(`vm_protoparser_rows_read_total{type="opentelemetry"}`)
-	rowsDroppedUnsupportedHistogram  = metrics.NewCounter(`vm_protoparser_rows_dropped_total{type="opentelemetry",reason="unsupported_histogram_aggregation"}`)
-	rowsDroppedUnsupportedSum        = metrics.NewCounter(`vm_protoparser_rows_dropped_total{type="opentelemetry",reason="unsupported_sum_aggregation"}`)
-	rowsDroppedUnsupportedMetricType = metrics.NewCounter(`vm_protoparser_rows_dropped_total{type="opentelemetry",reason="unsupported_metric_type"}`)
+	rowsRead                                   = metrics.NewCounter(`vm_protoparser_rows_read_total{type="opentelemetry"}`)
+	rowsDroppedUnsupportedHistogram            = metrics.NewCounter(`vm_protoparser_rows_dropped_total{type="opentelemetry",reason="unsupported_histogram_aggregation"}`)
+	rowsDroppedUnsupportedExponentialHistogram = metrics.NewCounter(`vm_protoparser_rows_dropped_total{type="opentelemetry",reason="unsupported_exponential_histogram_aggregation"}`)
+	rowsDroppedUnsupportedSum                  = metrics.NewCounter(`vm_protoparser_rows_dropped_total{type="opentelemetry",reason="unsupported_sum_aggregation"}`)
+	rowsDroppedUnsupportedMetricType           = metrics.NewCounter(`vm_protoparser_rows_dropped_total{type="opentelemetry",reason="unsupported_metric_type"}`)
 )
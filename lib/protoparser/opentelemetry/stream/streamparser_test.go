@@ -76,6 +76,12 @@ func TestParseStream(t *testing.T) {
 			Value: value,
 		}
 	}
+	vmrangeLabel := func(value string) prompbmarshal.Label {
+		return prompbmarshal.Label{
+			Name:  "vmrange",
+			Value: value,
+		}
+	}
 	kvLabel := func(k, v string) prompbmarshal.Label {
 		return prompbmarshal.Label{
 			Name:  k,
@@ -117,6 +123,21 @@ func TestParseStream(t *testing.T) {
 			newPromPBTs("my-gauge", 15000, 15.0, jobLabelValue, kvLabel("label1", "value1")),
 		},
 	)
+
+	// Test exponential histogram
+	f(
+		[]*pb.Metric{
+			generateExponentialHistogram("my-histogram"),
+		},
+		[]prompbmarshal.TimeSeries{
+			newPromPBTs("my-histogram_count", 30000, 7.0, jobLabelValue, kvLabel("label3", "value3")),
+			newPromPBTs("my-histogram_sum", 30000, 10.0, jobLabelValue, kvLabel("label3", "value3")),
+			newPromPBTs("my-histogram_bucket", 30000, 1.0, jobLabelValue, kvLabel("label3", "value3"), vmrangeLabel("0...0")),
+			newPromPBTs("my-histogram_bucket", 30000, 2.0, jobLabelValue, kvLabel("label3", "value3"), vmrangeLabel("1...2")),
+			newPromPBTs("my-histogram_bucket", 30000, 3.0, jobLabelValue, kvLabel("label3", "value3"), vmrangeLabel("4...8")),
+			newPromPBTs("my-histogram_bucket", 30000, 1.0, jobLabelValue, kvLabel("label3", "value3"), vmrangeLabel("-4...-2")),
+		},
+	)
 }
 
 func checkParseStream(data []byte, checkSeries func(tss []prompbmarshal.TimeSeries) error) error {
@@ -190,6 +211,34 @@ func generateHistogram(name string) *pb.Metric {
 	}
 }
 
+func generateExponentialHistogram(name string) *pb.Metric {
+	points := []*pb.ExponentialHistogramDataPoint{
+		{
+			Attributes: attributesFromKV("label3", "value3"),
+			Count:      7,
+			Sum:        func() *float64 { v := 10.0; return &v }(),
+			Scale:      0,
+			ZeroCount:  1,
+			Positive: &pb.Buckets{
+				Offset:       0,
+				BucketCounts: []uint64{2, 0, 3},
+			},
+			Negative: &pb.Buckets{
+				Offset:       1,
+				BucketCounts: []uint64{1},
+			},
+			TimeUnixNano: uint64(30 * time.Second),
+		},
+	}
+	return &pb.Metric{
+		Name: name,
+		ExponentialHistogram: &pb.ExponentialHistogram{
+			AggregationTemporality: pb.AggregationTemporalityCumulative,
+			DataPoints:             points,
+		},
+	}
+}
+
 func generateSum(name string) *pb.Metric {
 	d := float64(15.5)
 	points := []*pb.NumberDataPoint{
@@ -0,0 +1,295 @@
+package statsd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/valyala/fastjson/fastfloat"
+)
+
+// Rows contains parsed statsd rows.
+type Rows struct {
+	Rows []Row
+
+	tagsPool     []Tag
+	valuesPool   []float64
+	relativePool []bool
+}
+
+// Reset resets rs.
+func (rs *Rows) Reset() {
+	// Reset items, so they can be GC'ed
+
+	for i := range rs.Rows {
+		rs.Rows[i].reset()
+	}
+	rs.Rows = rs.Rows[:0]
+
+	for i := range rs.tagsPool {
+		rs.tagsPool[i].reset()
+	}
+	rs.tagsPool = rs.tagsPool[:0]
+
+	rs.valuesPool = rs.valuesPool[:0]
+	rs.relativePool = rs.relativePool[:0]
+}
+
+// Unmarshal unmarshals statsd plaintext protocol rows from s.
+//
+// See https://github.com/statsd/statsd/blob/master/docs/metric_types.md
+// and https://docs.datadoghq.com/developers/dogstatsd/datagram_shell/ for tags extension.
+//
+// s shouldn't be modified when rs is in use.
+func (rs *Rows) Unmarshal(s string) {
+	p := &rowPools{
+		tags:     rs.tagsPool[:0],
+		values:   rs.valuesPool[:0],
+		relative: rs.relativePool[:0],
+	}
+	rs.Rows = unmarshalRows(rs.Rows[:0], s, p)
+	rs.tagsPool, rs.valuesPool, rs.relativePool = p.tags, p.values, p.relative
+}
+
+// rowPools contains pools for Row fields.
+type rowPools struct {
+	tags     []Tag
+	values   []float64
+	relative []bool
+}
+
+// Row is a single statsd row.
+type Row struct {
+	Metric string
+	Tags   []Tag
+
+	// Type is statsd metric type such as c, g, ms, h or d
+	Type string
+
+	// Values contains the values for the given row.
+	//
+	// Counter values are already adjusted by the sample rate.
+	Values []float64
+
+	// Relative contains flags for gauge values starting with `+` or `-`.
+	//
+	// Such values must be added to the current gauge value instead of replacing it.
+	// It is nil for other metric types.
+	Relative []bool
+
+	// Timestamp is the optional timestamp in seconds. It is set to zero if the timestamp is missing.
+	Timestamp int64
+}
+
+func (r *Row) reset() {
+	r.Metric = ""
+	r.Tags = nil
+	r.Type = ""
+	r.Values = nil
+	r.Relative = nil
+	r.Timestamp = 0
+}
+
+func (r *Row) unmarshal(s string, p *rowPools) error {
+	r.reset()
+	sOrig := s
+
+	n := strings.IndexByte(s, '|')
+	if n < 0 {
+		return fmt.Errorf("cannot find metric type in %q", sOrig)
+	}
+	metricAndValues := s[:n]
+	s = s[n+1:]
+
+	n = strings.IndexByte(s, '|')
+	if n < 0 {
+		r.Type = s
+		s = ""
+	} else {
+		r.Type = s[:n]
+		s = s[n+1:]
+	}
+	switch r.Type {
+	case "c", "g", "ms", "h", "d":
+	default:
+		return fmt.Errorf("unsupported metric type %q in %q; supported types: c, g, ms, h, d", r.Type, sOrig)
+	}
+
+	n = strings.IndexByte(metricAndValues, ':')
+	if n < 0 {
+		return fmt.Errorf("cannot find separator between metric and value in %q", sOrig)
+	}
+	r.Metric = metricAndValues[:n]
+	if len(r.Metric) == 0 {
+		return fmt.Errorf("metric cannot be empty in %q", sOrig)
+	}
+	valuesStr := metricAndValues[n+1:]
+
+	// Parse optional fields: sample rate, tags and timestamp.
+	sampleRate := float64(1)
+	tagsStart := len(p.tags)
+	for len(s) > 0 {
+		field := s
+		n = strings.IndexByte(s, '|')
+		if n < 0 {
+			s = ""
+		} else {
+			field = s[:n]
+			s = s[n+1:]
+		}
+		if len(field) == 0 {
+			continue
+		}
+		switch field[0] {
+		case '@':
+			v, err := fastfloat.Parse(field[1:])
+			if err != nil {
+				return fmt.Errorf("cannot unmarshal sample rate from %q: %w; original line: %q", field[1:], err, sOrig)
+			}
+			if v <= 0 || v > 1 {
+				return fmt.Errorf("sample rate must be in the range (0..1]; got %v; original line: %q", v, sOrig)
+			}
+			sampleRate = v
+		case '#':
+			p.tags = unmarshalTags(p.tags, field[1:])
+		case 'T':
+			ts, err := fastfloat.ParseInt64(field[1:])
+			if err != nil {
+				return fmt.Errorf("cannot unmarshal timestamp from %q: %w; original line: %q", field[1:], err, sOrig)
+			}
+			r.Timestamp = ts
+		default:
+			// Ignore unknown extensions such as container id (c:...) for forward compatibility.
+		}
+	}
+	if tags := p.tags[tagsStart:]; len(tags) > 0 {
+		r.Tags = tags[:len(tags):len(tags)]
+	}
+
+	valuesStart := len(p.values)
+	relativeStart := len(p.relative)
+	for {
+		valueStr := valuesStr
+		n = strings.IndexByte(valuesStr, ':')
+		if n >= 0 {
+			valueStr = valuesStr[:n]
+			valuesStr = valuesStr[n+1:]
+		}
+		// Gauge values with sign prefix are relative updates.
+		// See https://github.com/statsd/statsd/blob/master/docs/metric_types.md#gauges
+		isRelative := r.Type == "g" && len(valueStr) > 0 && (valueStr[0] == '+' || valueStr[0] == '-')
+		// fastfloat.Parse doesn't accept `+` prefix.
+		v, err := fastfloat.Parse(strings.TrimPrefix(valueStr, "+"))
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal value from %q: %w; original line: %q", valueStr, err, sOrig)
+		}
+		switch r.Type {
+		case "c":
+			v /= sampleRate
+		case "g":
+			p.relative = append(p.relative, isRelative)
+		}
+		p.values = append(p.values, v)
+		if n < 0 {
+			break
+		}
+	}
+	values := p.values[valuesStart:]
+	r.Values = values[:len(values):len(values)]
+	if relative := p.relative[relativeStart:]; len(relative) > 0 {
+		r.Relative = relative[:len(relative):len(relative)]
+	}
+	return nil
+}
+
+func unmarshalRows(dst []Row, s string, p *rowPools) []Row {
+	for len(s) > 0 {
+		n := strings.IndexByte(s, '\n')
+		if n < 0 {
+			// The last line.
+			return unmarshalRow(dst, s, p)
+		}
+		dst = unmarshalRow(dst, s[:n], p)
+		s = s[n+1:]
+	}
+	return dst
+}
+
+func unmarshalRow(dst []Row, s string, p *rowPools) []Row {
+	if len(s) > 0 && s[len(s)-1] == '\r' {
+		s = s[:len(s)-1]
+	}
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		// Skip empty line
+		return dst
+	}
+	if strings.HasPrefix(s, "_e{") || strings.HasPrefix(s, "_sc|") {
+		// Skip DogStatsD events and service checks, since they cannot be converted to samples.
+		return dst
+	}
+	if cap(dst) > len(dst) {
+		dst = dst[:len(dst)+1]
+	} else {
+		dst = append(dst, Row{})
+	}
+	r := &dst[len(dst)-1]
+	if err := r.unmarshal(s, p); err != nil {
+		dst = dst[:len(dst)-1]
+		logger.Errorf("cannot unmarshal statsd line %q: %s", s, err)
+		invalidLines.Inc()
+	}
+	return dst
+}
+
+var invalidLines = metrics.NewCounter(`vm_rows_invalid_total{type="statsd"}`)
+
+func unmarshalTags(dst []Tag, s string) []Tag {
+	for len(s) > 0 {
+		tagStr := s
+		n := strings.IndexByte(s, ',')
+		if n < 0 {
+			s = ""
+		} else {
+			tagStr = s[:n]
+			s = s[n+1:]
+		}
+		if cap(dst) > len(dst) {
+			dst = dst[:len(dst)+1]
+		} else {
+			dst = append(dst, Tag{})
+		}
+		tag := &dst[len(dst)-1]
+		tag.unmarshal(tagStr)
+		if len(tag.Key) == 0 {
+			// Skip tag without key
+			dst = dst[:len(dst)-1]
+		}
+	}
+	return dst
+}
+
+// Tag is a statsd tag.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+func (t *Tag) reset() {
+	t.Key = ""
+	t.Value = ""
+}
+
+func (t *Tag) unmarshal(s string) {
+	t.reset()
+	n := strings.IndexByte(s, ':')
+	if n < 0 {
+		// Empty tag value.
+		t.Key = s
+		t.Value = s[len(s):]
+	} else {
+		t.Key = s[:n]
+		t.Value = s[n+1:]
+	}
+}
@@ -0,0 +1,164 @@
+package statsd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRowsUnmarshalFailure(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		var rows Rows
+		rows.Unmarshal(s)
+		if len(rows.Rows) != 0 {
+			t.Fatalf("expecting zero rows; got %d rows", len(rows.Rows))
+		}
+
+		// Try again
+		rows.Unmarshal(s)
+		if len(rows.Rows) != 0 {
+			t.Fatalf("expecting zero rows; got %d rows", len(rows.Rows))
+		}
+	}
+
+	// Missing metric type
+	f("aaa")
+	f("aaa:1")
+
+	// Missing value
+	f("aaa|c")
+	f("aaa:|c")
+
+	// Empty metric
+	f(":1|c")
+
+	// Invalid value
+	f("aaa:foo|c")
+	f("aaa:1:bar|ms")
+
+	// Unsupported metric type
+	f("aaa:1|x")
+	f("users.uniques:765|s")
+
+	// Invalid sample rate
+	f("aaa:1|c|@foo")
+	f("aaa:1|c|@0")
+	f("aaa:1|c|@1.5")
+
+	// Invalid timestamp
+	f("aaa:1|c|Tfoo")
+
+	// Events and service checks
+	f("_e{5,4}:title|text|#foo:bar")
+	f("_sc|name|0|#foo:bar")
+}
+
+func TestRowsUnmarshalSuccess(t *testing.T) {
+	f := func(s string, rowsExpected *Rows) {
+		t.Helper()
+		var rows Rows
+		rows.Unmarshal(s)
+		if !reflect.DeepEqual(rows.Rows, rowsExpected.Rows) {
+			t.Fatalf("unexpected rows;\ngot\n%+v;\nwant\n%+v", rows.Rows, rowsExpected.Rows)
+		}
+
+		// Try unmarshaling again
+		rows.Unmarshal(s)
+		if !reflect.DeepEqual(rows.Rows, rowsExpected.Rows) {
+			t.Fatalf("unexpected rows;\ngot\n%+v;\nwant\n%+v", rows.Rows, rowsExpected.Rows)
+		}
+
+		rows.Reset()
+		if len(rows.Rows) != 0 {
+			t.Fatalf("non-empty rows after reset: %+v", rows.Rows)
+		}
+	}
+
+	// Empty line
+	f("", &Rows{})
+	f("\r", &Rows{})
+	f("\n\n", &Rows{})
+	f("\n\r\n", &Rows{})
+
+	// Counter
+	f("foo.bar:123|c", &Rows{
+		Rows: []Row{{
+			Metric: "foo.bar",
+			Type:   "c",
+			Values: []float64{123},
+		}},
+	})
+
+	// Counter with sample rate
+	f("foo:2|c|@0.1", &Rows{
+		Rows: []Row{{
+			Metric: "foo",
+			Type:   "c",
+			Values: []float64{20},
+		}},
+	})
+
+	// Gauge with tags
+	f("foo:-1.5|g|#bar:baz,x:y,empty,:skipped", &Rows{
+		Rows: []Row{{
+			Metric: "foo",
+			Type:   "g",
+			Tags: []Tag{
+				{
+					Key:   "bar",
+					Value: "baz",
+				},
+				{
+					Key:   "x",
+					Value: "y",
+				},
+				{
+					Key:   "empty",
+					Value: "",
+				},
+			},
+			Values:   []float64{-1.5},
+			Relative: []bool{true},
+		}},
+	})
+
+	// Gauge with absolute and relative values
+	f("foo:3:+2:-1|g", &Rows{
+		Rows: []Row{{
+			Metric:   "foo",
+			Type:     "g",
+			Values:   []float64{3, 2, -1},
+			Relative: []bool{false, true, true},
+		}},
+	})
+
+	// Timer with multiple values, sample rate, tags, timestamp and unknown extension
+	f("req.duration:10:20.5|ms|@0.5|#env:prod|c:container-id|T1700000000", &Rows{
+		Rows: []Row{{
+			Metric: "req.duration",
+			Type:   "ms",
+			Tags: []Tag{{
+				Key:   "env",
+				Value: "prod",
+			}},
+			Values:    []float64{10, 20.5},
+			Timestamp: 1700000000,
+		}},
+	})
+
+	// Multiple lines with invalid line in the middle
+	f("foo:1|h\nbar:x|c\r\nbaz:3|d\n", &Rows{
+		Rows: []Row{
+			{
+				Metric: "foo",
+				Type:   "h",
+				Values: []float64{1},
+			},
+			{
+				Metric: "baz",
+				Type:   "d",
+				Values: []float64{3},
+			},
+		},
+	})
+}
@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/protoparser/statsd"
+)
+
+func Test_streamContext_Read(t *testing.T) {
+	f := func(s string, rowsExpected *statsd.Rows) {
+		t.Helper()
+		ctx := getStreamContext(strings.NewReader(s))
+		if !ctx.Read() {
+			t.Fatalf("expecting successful read")
+		}
+		uw := getUnmarshalWork()
+		callbackCalls := 0
+		uw.ctx = ctx
+		tsMin := int64(fasttime.UnixTimestamp()) * 1000
+		uw.callback = func(rows []statsd.Row) error {
+			callbackCalls++
+			tsMax := int64(fasttime.UnixTimestamp()) * 1000
+			rows = append([]statsd.Row{}, rows...)
+			for i := range rows {
+				if i >= len(rowsExpected.Rows) || rowsExpected.Rows[i].Timestamp != 0 {
+					continue
+				}
+				// Zero timestamp in rowsExpected means the row must get the current timestamp
+				if ts := rows[i].Timestamp; ts < tsMin || ts > tsMax {
+					t.Fatalf("unexpected timestamp for row #%d; got %d; want in the range [%d, %d]", i, ts, tsMin, tsMax)
+				}
+				rows[i].Timestamp = 0
+			}
+			if !reflect.DeepEqual(rows, rowsExpected.Rows) {
+				t.Fatalf("unexpected rows;\ngot\n%+v;\nwant\n%+v", rows, rowsExpected.Rows)
+			}
+			return nil
+		}
+		uw.reqBuf = append(uw.reqBuf[:0], ctx.reqBuf...)
+		ctx.wg.Add(1)
+		uw.Unmarshal()
+		if callbackCalls != 1 {
+			t.Fatalf("unexpected number of callback calls; got %d; want 1", callbackCalls)
+		}
+	}
+
+	// Line with timestamp
+	f("aaa:1123|c|#x:y|T345", &statsd.Rows{
+		Rows: []statsd.Row{{
+			Metric: "aaa",
+			Tags: []statsd.Tag{{
+				Key:   "x",
+				Value: "y",
+			}},
+			Type:      "c",
+			Values:    []float64{1123},
+			Timestamp: 345 * 1000,
+		}},
+	})
+	// missing timestamp.
+	f("aaa:1123|g", &statsd.Rows{
+		Rows: []statsd.Row{{
+			Metric:   "aaa",
+			Type:     "g",
+			Values:   []float64{1123},
+			Relative: []bool{false},
+		}},
+	})
+}
@@ -73,9 +73,26 @@ func (u *URL) SetHeaders(ac *promauth.Config, req *http.Request) error {
 	if ah != "" {
 		req.Header.Set("Proxy-Authorization", ah)
 	}
+	if ac == nil {
+		return nil
+	}
 	return ac.SetHeaders(req, false)
 }
 
+// GetConnectHeader returns headers for CONNECT requests to the proxy according to u and ac configs.
+//
+// CONNECT requests are used for establishing tunnels to https targets via http and https proxies.
+// Headers from SetHeaders aren't sent to the proxy in this case, since they are encrypted.
+func (u *URL) GetConnectHeader(ac *promauth.Config) (http.Header, error) {
+	req := &http.Request{
+		Header: make(http.Header),
+	}
+	if err := u.SetHeaders(ac, req); err != nil {
+		return nil, err
+	}
+	return req.Header, nil
+}
+
 // getAuthHeader returns Proxy-Authorization auth header for the given u and ac.
 func (u *URL) getAuthHeader(ac *promauth.Config) (string, error) {
 	authHeader := ""
@@ -7,6 +7,7 @@ import (
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/decimal"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/encoding"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
 )
 
@@ -150,8 +151,8 @@ func (b *Block) tooBig() bool {
 }
 
 func (b *Block) deduplicateSamplesDuringMerge() {
-	if !isDedupEnabled() {
-		// Deduplication is disabled
+	if !isDedupEnabled() && !isDownsamplingEnabled() {
+		// Deduplication and downsampling are disabled
 		return
 	}
 	// Unmarshal block if it isn't unmarshaled yet in order to apply the de-duplication to unmarshaled samples.
@@ -163,13 +164,13 @@ func (b *Block) deduplicateSamplesDuringMerge() {
 		// Nothing to dedup.
 		return
 	}
-	dedupInterval := GetDedupInterval()
-	if dedupInterval <= 0 {
-		// Deduplication is disabled.
-		return
-	}
 	srcValues := b.values[b.nextIdx:]
-	timestamps, values := deduplicateSamplesDuringMerge(srcTimestamps, srcValues, dedupInterval)
+	var timestamps, values []int64
+	if isDownsamplingEnabled() {
+		timestamps, values = downsampleSamplesDuringMerge(srcTimestamps, srcValues, int64(fasttime.UnixTimestamp())*1000)
+	} else {
+		timestamps, values = deduplicateSamplesDuringMerge(srcTimestamps, srcValues, GetDedupInterval())
+	}
 	dedups := len(srcTimestamps) - len(timestamps)
 	atomic.AddUint64(&dedupsDuringMerge, uint64(dedups))
 	b.timestamps = b.timestamps[:b.nextIdx+len(timestamps)]
@@ -16,6 +16,11 @@ type blockStreamMerger struct {
 	// Blocks with smaller timestamps are removed because of retention.
 	retentionDeadline int64
 
+	// Retention deadlines for time series matching retention filters.
+	//
+	// They are sorted in descending order.
+	retentionFilterDeadlines []retentionFilterDeadline
+
 	// Whether the call to NextBlock must be no-op.
 	nextBlockNoop bool
 
@@ -32,14 +37,16 @@ func (bsm *blockStreamMerger) reset() {
 	bsm.bsrHeap = bsm.bsrHeap[:0]
 
 	bsm.retentionDeadline = 0
+	bsm.retentionFilterDeadlines = nil
 	bsm.nextBlockNoop = false
 	bsm.err = nil
 }
 
 // Init initializes bsm with the given bsrs.
-func (bsm *blockStreamMerger) Init(bsrs []*blockStreamReader, retentionDeadline int64) {
+func (bsm *blockStreamMerger) Init(bsrs []*blockStreamReader, retentionDeadline int64, retentionFilterDeadlines []retentionFilterDeadline) {
 	bsm.reset()
 	bsm.retentionDeadline = retentionDeadline
+	bsm.retentionFilterDeadlines = retentionFilterDeadlines
 	for _, bsr := range bsrs {
 		if bsr.NextBlock() {
 			bsm.bsrHeap = append(bsm.bsrHeap, bsr)
@@ -61,7 +68,16 @@ func (bsm *blockStreamMerger) Init(bsrs []*blockStreamReader, retentionDeadline
 	bsm.nextBlockNoop = true
 }
 
-func (bsm *blockStreamMerger) getRetentionDeadline(_ *blockHeader) int64 {
+func (bsm *blockStreamMerger) getRetentionDeadline(bh *blockHeader) int64 {
+	for _, rfd := range bsm.retentionFilterDeadlines {
+		if rfd.deadline <= bsm.retentionDeadline {
+			// The remaining retention filters have bigger retention than the global retention.
+			break
+		}
+		if rfd.metricIDs.Has(bh.TSID.MetricID) {
+			return rfd.deadline
+		}
+	}
 	return bsm.retentionDeadline
 }
 
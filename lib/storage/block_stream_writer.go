@@ -16,6 +16,9 @@ import (
 type blockStreamWriter struct {
 	compressLevel int
 
+	// Whether to limit the write speed for bsw according to SetMergeMaxBytesPerSecond.
+	limitBandwidth bool
+
 	timestampsWriter filestream.WriteCloser
 	valuesWriter     filestream.WriteCloser
 	indexWriter      filestream.WriteCloser
@@ -44,6 +47,7 @@ type blockStreamWriter struct {
 // Init initializes bsw with the given writers.
 func (bsw *blockStreamWriter) reset() {
 	bsw.compressLevel = 0
+	bsw.limitBandwidth = false
 
 	bsw.timestampsWriter = nil
 	bsw.valuesWriter = nil
@@ -130,6 +134,11 @@ func (bsw *blockStreamWriter) MustClose() {
 	bsw.reset()
 }
 
+// bytesWritten returns the number of bytes written to timestamps, values and index files of bsw.
+func (bsw *blockStreamWriter) bytesWritten() uint64 {
+	return bsw.timestampsBlockOffset + bsw.valuesBlockOffset + bsw.indexBlockOffset
+}
+
 // WriteExternalBlock writes b to bsw and updates ph and rowsMerged.
 func (bsw *blockStreamWriter) WriteExternalBlock(b *Block, ph *partHeader, rowsMerged *uint64) {
 	atomic.AddUint64(rowsMerged, uint64(b.rowsCount()))
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+// DownsamplingPeriod defines the deduplication interval for samples older than the given offset.
+type DownsamplingPeriod struct {
+	// Offset is the minimum age in milliseconds for samples to be downsampled.
+	Offset int64
+
+	// Interval is the downsampling interval in milliseconds.
+	Interval int64
+}
+
+// ParseDownsamplingPeriods parses downsampling periods from a.
+//
+// Every item in a must have the format `offset:interval`, for example, `30d:5m`.
+// Intervals must be multiples of each other and they must increase with offsets.
+// Intervals must be multiples of dedupInterval in milliseconds if it is set.
+func ParseDownsamplingPeriods(a []string, dedupInterval int64) ([]DownsamplingPeriod, error) {
+	periods := make([]DownsamplingPeriod, 0, len(a))
+	for _, s := range a {
+		n := strings.IndexByte(s, ':')
+		if n < 0 {
+			return nil, fmt.Errorf("missing ':' in downsampling period %q; it must have the format 'offset:interval'", s)
+		}
+		offset, err := promutils.ParseDuration(s[:n])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse offset in downsampling period %q: %w", s, err)
+		}
+		if offset < 0 {
+			return nil, fmt.Errorf("offset in downsampling period %q cannot be negative", s)
+		}
+		interval, err := promutils.ParseDuration(s[n+1:])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse interval in downsampling period %q: %w", s, err)
+		}
+		if interval.Milliseconds() <= 0 {
+			return nil, fmt.Errorf("interval in downsampling period %q must be positive", s)
+		}
+		periods = append(periods, DownsamplingPeriod{
+			Offset:   offset.Milliseconds(),
+			Interval: interval.Milliseconds(),
+		})
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].Offset < periods[j].Offset
+	})
+	prevInterval := dedupInterval
+	for i, p := range periods {
+		if i > 0 && p.Offset == periods[i-1].Offset {
+			return nil, fmt.Errorf("duplicate offset %dms in downsampling periods", p.Offset)
+		}
+		if prevInterval > 0 {
+			if p.Interval < prevInterval {
+				return nil, fmt.Errorf("downsampling interval %dms for offset %dms cannot be smaller than the interval %dms for smaller offsets or -dedup.minScrapeInterval",
+					p.Interval, p.Offset, prevInterval)
+			}
+			if p.Interval%prevInterval != 0 {
+				return nil, fmt.Errorf("downsampling interval %dms for offset %dms must be multiple of the interval %dms for smaller offsets or -dedup.minScrapeInterval",
+					p.Interval, p.Offset, prevInterval)
+			}
+		}
+		prevInterval = p.Interval
+	}
+	return periods, nil
+}
+
+// SetDownsamplingPeriods sets downsampling periods, which are applied to raw samples during background merges.
+//
+// This function must be called before initializing the storage.
+func SetDownsamplingPeriods(periods []DownsamplingPeriod) {
+	downsamplingPeriods = append([]DownsamplingPeriod{}, periods...)
+}
+
+// downsamplingPeriods contains downsampling periods sorted by Offset.
+var downsamplingPeriods []DownsamplingPeriod
+
+func isDownsamplingEnabled() bool {
+	return len(downsamplingPeriods) > 0
+}
+
+// getDedupIntervalForTimestamp returns the deduplication interval in milliseconds for samples with the given timestamp
+// according to the dedup interval and downsampling periods.
+//
+// currentTimestamp is the current unix timestamp in milliseconds.
+func getDedupIntervalForTimestamp(timestamp, currentTimestamp int64) int64 {
+	dedupInterval := globalDedupInterval
+	age := currentTimestamp - timestamp
+	for _, p := range downsamplingPeriods {
+		if age < p.Offset {
+			break
+		}
+		if p.Interval > dedupInterval {
+			dedupInterval = p.Interval
+		}
+	}
+	return dedupInterval
+}
+
+// GetDedupIntervalForTimeRange returns the maximum deduplication interval in milliseconds for samples on the given tr
+// according to the dedup interval and downsampling periods.
+//
+// This interval must be applied to raw samples at query time in order to get consistent results for time ranges
+// covering multiple downsampling levels.
+func GetDedupIntervalForTimeRange(tr TimeRange) int64 {
+	return getDedupIntervalForTimestamp(tr.MinTimestamp, int64(fasttime.UnixTimestamp())*1000)
+}
+
+// downsampleSamplesDuringMerge deduplicates samples in src* according to the dedup interval and downsampling periods.
+//
+// Older samples may be deduplicated with bigger intervals. srcTimestamps must be sorted in ascending order.
+func downsampleSamplesDuringMerge(srcTimestamps, srcValues []int64, currentTimestamp int64) ([]int64, []int64) {
+	dstTimestamps := srcTimestamps[:0]
+	dstValues := srcValues[:0]
+	for len(srcTimestamps) > 0 {
+		// Dedup intervals decrease with timestamps, so the samples with the same dedup interval are contiguous.
+		dedupInterval := getDedupIntervalForTimestamp(srcTimestamps[0], currentTimestamp)
+		n := sort.Search(len(srcTimestamps), func(i int) bool {
+			return getDedupIntervalForTimestamp(srcTimestamps[i], currentTimestamp) < dedupInterval
+		})
+		timestamps, values := deduplicateSamplesDuringMerge(srcTimestamps[:n], srcValues[:n], dedupInterval)
+		dstTimestamps = append(dstTimestamps, timestamps...)
+		dstValues = append(dstValues, values...)
+		srcTimestamps = srcTimestamps[n:]
+		srcValues = srcValues[n:]
+	}
+	return dstTimestamps, dstValues
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDownsamplingPeriodsSuccess(t *testing.T) {
+	f := func(a []string, dedupInterval int64, periodsExpected []DownsamplingPeriod) {
+		t.Helper()
+		periods, err := ParseDownsamplingPeriods(a, dedupInterval)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(periods, periodsExpected) {
+			t.Fatalf("unexpected periods;\ngot\n%+v\nwant\n%+v", periods, periodsExpected)
+		}
+	}
+
+	f(nil, 0, []DownsamplingPeriod{})
+	f([]string{"30d:5m"}, 0, []DownsamplingPeriod{
+		{Offset: 30 * 24 * 3600 * 1000, Interval: 5 * 60 * 1000},
+	})
+
+	// periods are sorted by offset
+	f([]string{"180d:1h", "30d:5m"}, 0, []DownsamplingPeriod{
+		{Offset: 30 * 24 * 3600 * 1000, Interval: 5 * 60 * 1000},
+		{Offset: 180 * 24 * 3600 * 1000, Interval: 3600 * 1000},
+	})
+
+	// zero offset
+	f([]string{"0s:1m"}, 30*1000, []DownsamplingPeriod{
+		{Offset: 0, Interval: 60 * 1000},
+	})
+}
+
+func TestParseDownsamplingPeriodsFailure(t *testing.T) {
+	f := func(a []string, dedupInterval int64) {
+		t.Helper()
+		if _, err := ParseDownsamplingPeriods(a, dedupInterval); err == nil {
+			t.Fatalf("expecting non-nil error for %q", a)
+		}
+	}
+
+	// missing interval
+	f([]string{"30d"}, 0)
+
+	// invalid durations
+	f([]string{"foo:5m"}, 0)
+	f([]string{"30d:bar"}, 0)
+
+	// zero or negative values
+	f([]string{"30d:0s"}, 0)
+	f([]string{"-1d:5m"}, 0)
+
+	// duplicate offsets
+	f([]string{"30d:5m", "30d:10m"}, 0)
+
+	// intervals aren't multiples of each other
+	f([]string{"30d:5m", "180d:7m"}, 0)
+
+	// interval decreases with offset
+	f([]string{"30d:1h", "180d:5m"}, 0)
+
+	// interval isn't multiple of dedup interval
+	f([]string{"30d:90s"}, 60*1000)
+}
+
+func TestDownsampleSamplesDuringMerge(t *testing.T) {
+	defer SetDownsamplingPeriods(nil)
+
+	f := func(periods []DownsamplingPeriod, currentTimestamp int64, timestamps, timestampsExpected []int64) {
+		t.Helper()
+		SetDownsamplingPeriods(periods)
+		values := make([]int64, len(timestamps))
+		for i := range values {
+			values[i] = int64(i)
+		}
+		valuesExpected := make([]int64, len(timestampsExpected))
+		for i, tsExpected := range timestampsExpected {
+			for j, ts := range timestamps {
+				if ts == tsExpected {
+					valuesExpected[i] = values[j]
+				}
+			}
+		}
+		timestampsCopy := append([]int64{}, timestamps...)
+		resultTimestamps, resultValues := downsampleSamplesDuringMerge(timestampsCopy, values, currentTimestamp)
+		if !reflect.DeepEqual(resultTimestamps, timestampsExpected) {
+			t.Fatalf("unexpected timestamps for %d;\ngot\n%d\nwant\n%d", timestamps, resultTimestamps, timestampsExpected)
+		}
+		if !reflect.DeepEqual(resultValues, valuesExpected) {
+			t.Fatalf("unexpected values for %d;\ngot\n%d\nwant\n%d", timestamps, resultValues, valuesExpected)
+		}
+	}
+
+	timestamps := []int64{0, 5, 10, 15, 20, 25, 30, 35, 40, 45, 50, 55, 60, 65, 70, 75, 80, 85, 90, 95}
+
+	// no downsampling periods
+	f(nil, 100, timestamps, timestamps)
+
+	// all the samples are too new for downsampling
+	f([]DownsamplingPeriod{{Offset: 200, Interval: 20}}, 100, timestamps, timestamps)
+
+	// all the samples are downsampled
+	f([]DownsamplingPeriod{{Offset: 0, Interval: 20}}, 100, timestamps, []int64{0, 20, 40, 60, 80, 95})
+
+	// only old samples are downsampled
+	f([]DownsamplingPeriod{{Offset: 50, Interval: 20}}, 100, timestamps, []int64{0, 20, 40, 50, 55, 60, 65, 70, 75, 80, 85, 90, 95})
+
+	// multi-level downsampling
+	f([]DownsamplingPeriod{
+		{Offset: 40, Interval: 10},
+		{Offset: 70, Interval: 30},
+	}, 100, timestamps, []int64{0, 30, 40, 50, 60, 65, 70, 75, 80, 85, 90, 95})
+}
+
+func TestGetDedupIntervalForTimestamp(t *testing.T) {
+	defer SetDedupInterval(0)
+	defer SetDownsamplingPeriods(nil)
+
+	SetDownsamplingPeriods([]DownsamplingPeriod{
+		{Offset: 100, Interval: 20},
+		{Offset: 200, Interval: 40},
+	})
+	f := func(timestamp, dedupIntervalExpected int64) {
+		t.Helper()
+		dedupInterval := getDedupIntervalForTimestamp(timestamp, 1000)
+		if dedupInterval != dedupIntervalExpected {
+			t.Fatalf("unexpected dedup interval for timestamp=%d; got %d; want %d", timestamp, dedupInterval, dedupIntervalExpected)
+		}
+	}
+
+	f(1000, 0)
+	f(901, 0)
+	f(900, 20)
+	f(801, 20)
+	f(800, 40)
+	f(0, 40)
+
+	// dedup interval is used for new samples
+	globalDedupInterval = 10
+	f(1000, 10)
+	f(900, 20)
+	f(800, 40)
+}
@@ -481,6 +481,9 @@ type indexSearch struct {
 
 	// deadline in unix timestamp seconds for the given search.
 	deadline uint64
+
+	// canceled is an optional flag for canceling the given search before the deadline.
+	canceled *atomic.Bool
 }
 
 func (db *indexDB) getIndexSearch(deadline uint64) *indexSearch {
@@ -501,6 +504,7 @@ func (db *indexDB) putIndexSearch(is *indexSearch) {
 	is.kb.Reset()
 	is.mp.Reset()
 	is.deadline = 0
+	is.canceled = nil
 
 	db.indexSearchPool.Put(is)
 }
@@ -694,7 +698,7 @@ func (is *indexSearch) searchLabelNamesWithFiltersOnDate(qt *querytracer.Tracer,
 	ts.Seek(prefix)
 	for len(lns) < maxLabelNames && ts.NextItem() {
 		if loopsPaceLimiter&paceLimiterFastIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(is.deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 				return err
 			}
 		}
@@ -895,7 +899,7 @@ func (is *indexSearch) searchLabelValuesWithFiltersOnDate(qt *querytracer.Tracer
 	ts.Seek(prefix)
 	for len(lvs) < maxLabelValues && ts.NextItem() {
 		if loopsPaceLimiter&paceLimiterFastIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(is.deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 				return err
 			}
 		}
@@ -1080,7 +1084,7 @@ func (is *indexSearch) searchTagValueSuffixesForPrefix(tvss map[string]struct{},
 	ts.Seek(prefix)
 	for len(tvss) < maxTagValueSuffixes && ts.NextItem() {
 		if loopsPaceLimiter&paceLimiterFastIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(is.deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 				return err
 			}
 		}
@@ -1158,7 +1162,7 @@ func (is *indexSearch) getSeriesCount() (uint64, error) {
 	ts.Seek(kb.B)
 	for ts.NextItem() {
 		if loopsPaceLimiter&paceLimiterFastIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(is.deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 				return 0, err
 			}
 		}
@@ -1248,7 +1252,7 @@ func (is *indexSearch) getTSDBStatus(qt *querytracer.Tracer, tfss []*TagFilters,
 	ts.Seek(prefix)
 	for ts.NextItem() {
 		if loopsPaceLimiter&paceLimiterFastIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(is.deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 				return nil, err
 			}
 		}
@@ -1592,7 +1596,7 @@ func (is *indexSearch) loadDeletedMetricIDs() (*uint64set.Set, error) {
 // searchMetricIDs returns metricIDs for the given tfss and tr.
 //
 // The returned metricIDs are sorted.
-func (db *indexDB) searchMetricIDs(qt *querytracer.Tracer, tfss []*TagFilters, tr TimeRange, maxMetrics int, deadline uint64) ([]uint64, error) {
+func (db *indexDB) searchMetricIDs(qt *querytracer.Tracer, tfss []*TagFilters, tr TimeRange, maxMetrics int, deadline uint64, canceled *atomic.Bool) ([]uint64, error) {
 	qt = qt.NewChild("search for matching metricIDs: filters=%s, timeRange=%s", tfss, &tr)
 	defer qt.Done()
 
@@ -1612,11 +1616,16 @@ func (db *indexDB) searchMetricIDs(qt *querytracer.Tracer, tfss []*TagFilters, t
 	if ok {
 		// Fast path - metricIDs found in the cache
 		qtChild.Done()
+		if len(metricIDs) > maxMetrics {
+			// The cache may contain metricIDs found with bigger maxMetrics limit.
+			return nil, &TooManyTimeseriesError{MaxMetrics: maxMetrics}
+		}
 		return metricIDs, nil
 	}
 
 	// Slow path - search for metricIDs in the db and extDB.
 	is := db.getIndexSearch(deadline)
+	is.canceled = canceled
 	localMetricIDs, err := is.searchMetricIDs(qtChild, tfss, tr, maxMetrics)
 	db.putIndexSearch(is)
 	if err != nil {
@@ -1640,8 +1649,13 @@ func (db *indexDB) searchMetricIDs(qt *querytracer.Tracer, tfss []*TagFilters, t
 			return
 		}
 		is := extDB.getIndexSearch(deadline)
+		is.canceled = canceled
 		extMetricIDs, err = is.searchMetricIDs(qtChild, tfss, tr, maxMetrics)
 		extDB.putIndexSearch(is)
+		if err != nil {
+			// Do not cache incomplete results, e.g. when maxMetrics limit is exceeded.
+			return
+		}
 		extDB.putMetricIDsToTagFiltersCache(qtChild, extMetricIDs, tfKeyExtBuf.B)
 	})
 	if err != nil {
@@ -1653,6 +1667,11 @@ func (db *indexDB) searchMetricIDs(qt *querytracer.Tracer, tfss []*TagFilters, t
 	qt.Printf("merge %d metricIDs from the current indexdb with %d metricIDs from the previous indexdb; result: %d metricIDs",
 		len(localMetricIDs), len(extMetricIDs), len(metricIDs))
 
+	if len(metricIDs) > maxMetrics {
+		// Do not cache metricIDs exceeding the limit, since they cannot be used by the query.
+		return nil, &TooManyTimeseriesError{MaxMetrics: maxMetrics}
+	}
+
 	// Store metricIDs in the cache.
 	db.putMetricIDsToTagFiltersCache(qt, metricIDs, tfKeyBuf.B)
 
@@ -1687,7 +1706,7 @@ func mergeSortedMetricIDs(a, b []uint64) []uint64 {
 	}
 }
 
-func (db *indexDB) getTSIDsFromMetricIDs(qt *querytracer.Tracer, metricIDs []uint64, deadline uint64) ([]TSID, error) {
+func (db *indexDB) getTSIDsFromMetricIDs(qt *querytracer.Tracer, metricIDs []uint64, deadline uint64, canceled *atomic.Bool) ([]TSID, error) {
 	qt = qt.NewChild("obtain tsids from %d metricIDs", len(metricIDs))
 	defer qt.Done()
 
@@ -1701,10 +1720,11 @@ func (db *indexDB) getTSIDsFromMetricIDs(qt *querytracer.Tracer, metricIDs []uin
 	i := 0
 	err := func() error {
 		is := db.getIndexSearch(deadline)
+		is.canceled = canceled
 		defer db.putIndexSearch(is)
 		for loopsPaceLimiter, metricID := range metricIDs {
 			if loopsPaceLimiter&paceLimiterSlowIterationsMask == 0 {
-				if err := checkSearchDeadlineAndPace(is.deadline); err != nil {
+				if err := checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 					return err
 				}
 			}
@@ -1740,10 +1760,11 @@ func (db *indexDB) getTSIDsFromMetricIDs(qt *querytracer.Tracer, metricIDs []uin
 		// Search for extMetricIDs in the previous indexdb (aka extDB)
 		db.doExtDB(func(extDB *indexDB) {
 			is := extDB.getIndexSearch(deadline)
+			is.canceled = canceled
 			defer extDB.putIndexSearch(is)
 			for loopsPaceLimiter, metricID := range extMetricIDs {
 				if loopsPaceLimiter&paceLimiterSlowIterationsMask == 0 {
-					if err = checkSearchDeadlineAndPace(is.deadline); err != nil {
+					if err = checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 						return
 					}
 				}
@@ -1923,7 +1944,7 @@ func (is *indexSearch) updateMetricIDsByMetricNameMatch(qt *querytracer.Tracer,
 	defer PutMetricName(mn)
 	for loopsPaceLimiter, metricID := range sortedMetricIDs {
 		if loopsPaceLimiter&paceLimiterSlowIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(is.deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 				return err
 			}
 		}
@@ -2187,13 +2208,24 @@ func (is *indexSearch) searchMetricIDsInternal(qt *querytracer.Tracer, tfss []*T
 			return nil, err
 		}
 		if metricIDs.Len() > maxMetrics {
-			return nil, fmt.Errorf("the number of matching timeseries exceeds %d; either narrow down the search "+
-				"or increase -search.max* command-line flag values at vmselect; see https://docs.victoriametrics.com/#resource-usage-limits", maxMetrics)
+			return nil, &TooManyTimeseriesError{MaxMetrics: maxMetrics}
 		}
 	}
 	return metricIDs, nil
 }
 
+// TooManyTimeseriesError is returned when the number of time series matching the search exceeds MaxMetrics.
+type TooManyTimeseriesError struct {
+	// MaxMetrics is the limit on the number of matching time series, which has been exceeded.
+	MaxMetrics int
+}
+
+// Error implements error interface.
+func (e *TooManyTimeseriesError) Error() string {
+	return fmt.Sprintf("the number of matching timeseries exceeds %d; either narrow down the search "+
+		"or increase -search.max* command-line flag values at vmselect; see https://docs.victoriametrics.com/#resource-usage-limits", e.MaxMetrics)
+}
+
 func (is *indexSearch) updateMetricIDsForTagFilters(qt *querytracer.Tracer, metricIDs *uint64set.Set, tfs *TagFilters, tr TimeRange, maxMetrics int) error {
 	err := is.tryUpdatingMetricIDsForDateRange(qt, metricIDs, tfs, tr, maxMetrics)
 	if err == nil {
@@ -2210,8 +2242,7 @@ func (is *indexSearch) updateMetricIDsForTagFilters(qt *querytracer.Tracer, metr
 	m, err := is.getMetricIDsForDateAndFilters(qt, 0, tfs, maxMetrics)
 	if err != nil {
 		if errors.Is(err, errFallbackToGlobalSearch) {
-			return fmt.Errorf("the number of matching timeseries exceeds %d; either narrow down the search "+
-				"or increase -search.max* command-line flag values at vmselect; see https://docs.victoriametrics.com/#resource-usage-limits", maxMetrics)
+			return &TooManyTimeseriesError{MaxMetrics: maxMetrics}
 		}
 		return err
 	}
@@ -2262,7 +2293,7 @@ func (is *indexSearch) getMetricIDsForTagFilterSlow(tf *tagFilter, f func(metric
 	ts.Seek(prefix)
 	for ts.NextItem() {
 		if loopsPaceLimiter&paceLimiterMediumIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(is.deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 				return loopsCount, err
 			}
 		}
@@ -2366,7 +2397,7 @@ func (is *indexSearch) updateMetricIDsForOrSuffix(prefix []byte, metricIDs *uint
 	ts.Seek(prefix)
 	for metricIDs.Len() < maxMetrics && ts.NextItem() {
 		if loopsPaceLimiter&paceLimiterFastIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(is.deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 				return loopsCount, err
 			}
 		}
@@ -2429,6 +2460,7 @@ func (is *indexSearch) tryUpdatingMetricIDsForDateRange(qt *querytracer.Tracer,
 				wg.Done()
 			}()
 			isLocal := is.db.getIndexSearch(is.deadline)
+			isLocal.canceled = is.canceled
 			m, err := isLocal.getMetricIDsForDateAndFilters(qtChild, date, tfs, maxMetrics)
 			is.db.putIndexSearch(isLocal)
 			mu.Lock()
@@ -2917,7 +2949,7 @@ func (is *indexSearch) updateMetricIDsForPrefix(prefix []byte, metricIDs *uint64
 	ts.Seek(prefix)
 	for ts.NextItem() {
 		if loopsPaceLimiter&paceLimiterFastIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(is.deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 				return err
 			}
 		}
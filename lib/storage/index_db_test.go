@@ -970,11 +970,11 @@ func testIndexDBCheckTSIDByName(db *indexDB, mns []MetricName, tsids []TSID, isC
 }
 
 func searchTSIDsInTest(db *indexDB, tfs []*TagFilters, tr TimeRange) ([]TSID, error) {
-	metricIDs, err := db.searchMetricIDs(nil, tfs, tr, 1e5, noDeadline)
+	metricIDs, err := db.searchMetricIDs(nil, tfs, tr, 1e5, noDeadline, nil)
 	if err != nil {
 		return nil, err
 	}
-	return db.getTSIDsFromMetricIDs(nil, metricIDs, noDeadline)
+	return db.getTSIDsFromMetricIDs(nil, metricIDs, noDeadline, nil)
 }
 
 func testHasTSID(tsids []TSID, tsid *TSID) bool {
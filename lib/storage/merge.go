@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/decimal"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
@@ -18,8 +19,12 @@ func mergeBlockStreams(ph *partHeader, bsw *blockStreamWriter, bsrs []*blockStre
 	rowsMerged, rowsDeleted *uint64) error {
 	ph.Reset()
 
+	var rfds []retentionFilterDeadline
+	if s != nil {
+		rfds = s.getRetentionFilterDeadlines(timestampFromTime(time.Now()))
+	}
 	bsm := bsmPool.Get().(*blockStreamMerger)
-	bsm.Init(bsrs, retentionDeadline)
+	bsm.Init(bsrs, retentionDeadline, rfds)
 	err := mergeBlockStreamsInternal(ph, bsw, bsm, stopCh, s, rowsMerged, rowsDeleted)
 	bsm.reset()
 	bsmPool.Put(bsm)
@@ -45,12 +50,20 @@ func mergeBlockStreamsInternal(ph *partHeader, bsw *blockStreamWriter, bsm *bloc
 	defer putBlock(pendingBlock)
 	tmpBlock := getBlock()
 	defer putBlock(tmpBlock)
+	bytesWritten := uint64(0)
 	for bsm.NextBlock() {
 		select {
 		case <-stopCh:
 			return errForciblyStopped
 		default:
 		}
+		if bsw.limitBandwidth {
+			n := bsw.bytesWritten()
+			if !mergeBandwidthLimiter.waitForQuota(n-bytesWritten, stopCh) {
+				return errForciblyStopped
+			}
+			bytesWritten = n
+		}
 		b := bsm.Block
 		if dmis.Has(b.bh.TSID.MetricID) {
 			// Skip blocks for deleted metrics.
@@ -63,6 +76,15 @@ func mergeBlockStreamsInternal(ph *partHeader, bsw *blockStreamWriter, bsm *bloc
 			atomic.AddUint64(rowsDeleted, uint64(b.bh.RowsCount))
 			continue
 		}
+		if b.bh.MinTimestamp < retentionDeadline && retentionDeadline > bsm.retentionDeadline {
+			// The block contains samples outside the retention configured via retention filters.
+			// Skip these samples, since they may remain in the partition for a long time otherwise.
+			if err := b.UnmarshalData(); err != nil {
+				return fmt.Errorf("cannot unmarshal block for applying retention filters: %w", err)
+			}
+			skipSamplesOutsideRetention(b, retentionDeadline, rowsDeleted)
+			b.fixupTimestamps()
+		}
 		if pendingBlockIsEmpty {
 			// Load the next block if pendingBlock is empty.
 			pendingBlock.CopyFrom(b)
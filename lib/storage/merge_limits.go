@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/timerpool"
+)
+
+// SetSmallMergeConcurrency sets the maximum number of concurrent merges for small parts.
+//
+// The default concurrency is used if n <= 0. This function must be called before opening the storage.
+func SetSmallMergeConcurrency(n int) {
+	if n <= 0 {
+		n = getSmallPartsConcurrency()
+	}
+	smallPartsConcurrencyCh = make(chan struct{}, n)
+}
+
+// SetBigMergeConcurrency sets the maximum number of concurrent merges for big parts.
+//
+// The default concurrency is used if n <= 0. This function must be called before opening the storage.
+func SetBigMergeConcurrency(n int) {
+	if n <= 0 {
+		n = getBigPartsConcurrency()
+	}
+	bigPartsConcurrencyCh = make(chan struct{}, n)
+}
+
+// SetMergeMaxBytesPerSecond sets the maximum speed for writing data to disk during background merges.
+//
+// The limit isn't applied to flushing in-memory data to disk, so it doesn't slow down data ingestion.
+// There is no limit if maxBytesPerSecond <= 0. This function must be called before opening the storage.
+func SetMergeMaxBytesPerSecond(maxBytesPerSecond int) {
+	mergeBandwidthLimiter.perSecondLimit = maxBytesPerSecond
+}
+
+func isMergeBandwidthLimited() bool {
+	return mergeBandwidthLimiter.perSecondLimit > 0
+}
+
+var mergeBandwidthLimiter bandwidthLimiter
+
+// mergeThrottledMsecs is the total duration in milliseconds merges were paused by mergeBandwidthLimiter.
+var mergeThrottledMsecs uint64
+
+// bandwidthLimiter limits the write speed shared among concurrently running merges.
+type bandwidthLimiter struct {
+	perSecondLimit int
+
+	mu sync.Mutex
+
+	// deadline is the time when the already registered writes fit the perSecondLimit.
+	deadline time.Time
+}
+
+// minThrottleDuration is the minimum duration to pause merges for.
+//
+// Smaller pauses are accumulated in order to reduce the overhead on timers.
+const minThrottleDuration = 10 * time.Millisecond
+
+// waitForQuota registers n written bytes at bl and waits until the write speed fits the limit.
+//
+// false is returned if stopCh is closed while waiting.
+func (bl *bandwidthLimiter) waitForQuota(n uint64, stopCh <-chan struct{}) bool {
+	if bl.perSecondLimit <= 0 || n == 0 {
+		return true
+	}
+	d := time.Duration(float64(n) / float64(bl.perSecondLimit) * float64(time.Second))
+
+	bl.mu.Lock()
+	now := time.Now()
+	if bl.deadline.Before(now) {
+		bl.deadline = now
+	}
+	bl.deadline = bl.deadline.Add(d)
+	waitDuration := bl.deadline.Sub(now)
+	bl.mu.Unlock()
+
+	if waitDuration < minThrottleDuration {
+		return true
+	}
+	atomic.AddUint64(&mergeThrottledMsecs, uint64(waitDuration.Milliseconds()))
+	t := timerpool.Get(waitDuration)
+	defer timerpool.Put(t)
+	select {
+	case <-stopCh:
+		return false
+	case <-t.C:
+		return true
+	}
+}
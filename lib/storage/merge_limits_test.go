@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBandwidthLimiterWaitForQuota(t *testing.T) {
+	bl := &bandwidthLimiter{
+		perSecondLimit: 1000,
+	}
+	stopCh := make(chan struct{})
+
+	// Writes below minThrottleDuration must not be throttled.
+	startTime := time.Now()
+	if !bl.waitForQuota(1, stopCh) {
+		t.Fatalf("unexpected stop")
+	}
+	if d := time.Since(startTime); d > minThrottleDuration {
+		t.Fatalf("unexpected throttling for small write; got %s", d)
+	}
+
+	// Writes above the limit must be throttled.
+	startTime = time.Now()
+	for i := 0; i < 5; i++ {
+		if !bl.waitForQuota(50, stopCh) {
+			t.Fatalf("unexpected stop")
+		}
+	}
+	if d := time.Since(startTime); d < 200*time.Millisecond {
+		t.Fatalf("too small duration for throttled writes; got %s; want at least 200ms", d)
+	}
+
+	// Closed stopCh must interrupt the wait.
+	close(stopCh)
+	if bl.waitForQuota(1000, stopCh) {
+		t.Fatalf("expecting stop when stopCh is closed")
+	}
+
+	// Zero limit disables throttling.
+	bl = &bandwidthLimiter{}
+	if !bl.waitForQuota(1e9, nil) {
+		t.Fatalf("unexpected stop for disabled limiter")
+	}
+}
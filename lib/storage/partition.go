@@ -309,6 +309,7 @@ type partitionMetrics struct {
 	IndexBlocksCacheSizeMaxBytes uint64
 	IndexBlocksCacheRequests     uint64
 	IndexBlocksCacheMisses       uint64
+	IndexBlocksCacheEvictions    uint64
 
 	InmemorySizeBytes uint64
 	SmallSizeBytes    uint64
@@ -391,6 +392,7 @@ func (pt *partition) UpdateMetrics(m *partitionMetrics) {
 	m.IndexBlocksCacheSizeMaxBytes = uint64(ibCache.SizeMaxBytes())
 	m.IndexBlocksCacheRequests = ibCache.Requests()
 	m.IndexBlocksCacheMisses = ibCache.Misses()
+	m.IndexBlocksCacheEvictions = ibCache.Evictions()
 
 	m.ActiveInmemoryMerges += atomic.LoadUint64(&pt.activeInmemoryMerges)
 	m.ActiveSmallMerges += atomic.LoadUint64(&pt.activeSmallMerges)
@@ -1270,6 +1272,15 @@ func getMaxOutBytes(path string, workersCount int) uint64 {
 	return maxOutBytes
 }
 
+func hasFileParts(pws []*partWrapper) bool {
+	for _, pw := range pws {
+		if pw.mp == nil {
+			return true
+		}
+	}
+	return false
+}
+
 func assertIsInMerge(pws []*partWrapper) {
 	for _, pw := range pws {
 		if !pw.isInMerge {
@@ -1309,7 +1320,8 @@ func (pt *partition) isFinalDedupNeeded() bool {
 func (pt *partition) getRequiredDedupInterval() (int64, int64) {
 	pws := pt.GetParts(nil, false)
 	defer pt.PutParts(pws)
-	dedupInterval := GetDedupInterval()
+	// All the samples in the partition must be deduplicated with the interval for the newest samples in the partition.
+	dedupInterval := getDedupIntervalForTimestamp(pt.tr.MaxTimestamp, timestampFromTime(time.Now()))
 	minDedupInterval := getMinDedupInterval(pws)
 	return dedupInterval, minDedupInterval
 }
@@ -1355,7 +1367,7 @@ func (pt *partition) mergeParts(pws []*partWrapper, stopCh <-chan struct{}, isFi
 	mergeIdx := pt.nextMergeIdx()
 	dstPartPath := pt.getDstPartPath(dstPartType, mergeIdx)
 
-	if !isDedupEnabled() && isFinal && len(pws) == 1 && pws[0].mp != nil {
+	if !isDedupEnabled() && !isDownsamplingEnabled() && !isRetentionFiltersEnabled() && isFinal && len(pws) == 1 && pws[0].mp != nil {
 		// Fast path: flush a single in-memory part to disk.
 		mp := pws[0].mp
 		mp.MustStoreToDisk(dstPartPath)
@@ -1389,6 +1401,8 @@ func (pt *partition) mergeParts(pws []*partWrapper, stopCh <-chan struct{}, isFi
 		}
 		nocache := dstPartType == partBig
 		bsw.MustInitFromFilePart(dstPartPath, nocache, compressLevel)
+		// Do not limit the write speed when flushing in-memory parts to disk, since this may slow down data ingestion.
+		bsw.limitBandwidth = isMergeBandwidthLimited() && hasFileParts(pws)
 	}
 
 	// Merge source parts to destination part.
@@ -1538,7 +1552,8 @@ func (pt *partition) mergePartsInternal(dstPartPath string, bsw *blockStreamWrit
 		return nil, fmt.Errorf("cannot merge %d parts to %s: %w", len(bsrs), dstPartPath, err)
 	}
 	if dstPartPath != "" {
-		ph.MinDedupInterval = GetDedupInterval()
+		// The newest samples in the part are deduplicated with the smallest interval.
+		ph.MinDedupInterval = getDedupIntervalForTimestamp(ph.MaxTimestamp, timestampFromTime(time.Now()))
 		ph.MustWriteMetadata(dstPartPath)
 	}
 	return &ph, nil
@@ -0,0 +1,334 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/timeutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/uint64set"
+	"github.com/VictoriaMetrics/metricsql"
+)
+
+// RetentionFilter defines the retention for time series matching the given filters.
+type RetentionFilter struct {
+	// TagFilterss contains or-delimited lists of tag filters for the matching time series.
+	TagFilterss [][]TagFilter
+
+	// Retention is the retention for the matching time series.
+	Retention time.Duration
+}
+
+// ParseRetentionFilters parses retention filters in the format 'series_selector:retention' from a.
+//
+// The retention for every filter cannot exceed maxRetention.
+func ParseRetentionFilters(a []string, maxRetention time.Duration) ([]RetentionFilter, error) {
+	rfs := make([]RetentionFilter, 0, len(a))
+	for _, s := range a {
+		n := strings.LastIndexByte(s, ':')
+		if n < 0 {
+			return nil, fmt.Errorf("missing ':' in retention filter %q; it must have the format 'series_selector:retention'", s)
+		}
+		tfss, err := parseRetentionFilterSelector(s[:n])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse series selector in retention filter %q: %w", s, err)
+		}
+		retention, err := promutils.ParseDuration(s[n+1:])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse retention in retention filter %q: %w", s, err)
+		}
+		if retention < 24*time.Hour {
+			return nil, fmt.Errorf("retention in retention filter %q cannot be smaller than a day", s)
+		}
+		if retention > maxRetention {
+			return nil, fmt.Errorf("retention in retention filter %q cannot exceed -retentionPeriod=%s", s, maxRetention)
+		}
+		rfs = append(rfs, RetentionFilter{
+			TagFilterss: tfss,
+			Retention:   retention,
+		})
+	}
+	return rfs, nil
+}
+
+func parseRetentionFilterSelector(s string) ([][]TagFilter, error) {
+	expr, err := metricsql.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	me, ok := expr.(*metricsql.MetricExpr)
+	if !ok {
+		return nil, fmt.Errorf("expecting series selector; got %q", expr.AppendString(nil))
+	}
+	if len(me.LabelFilterss) == 0 {
+		return nil, fmt.Errorf("series selector cannot be empty")
+	}
+	tfss := make([][]TagFilter, len(me.LabelFilterss))
+	for i, lfs := range me.LabelFilterss {
+		tfs := make([]TagFilter, len(lfs))
+		for j, lf := range lfs {
+			tf := &tfs[j]
+			if lf.Label != "__name__" {
+				// An empty key is required for __name__ filter by Search.
+				tf.Key = []byte(lf.Label)
+			}
+			tf.Value = []byte(lf.Value)
+			tf.IsRegexp = lf.IsRegexp
+			tf.IsNegative = lf.IsNegative
+		}
+		tfss[i] = tfs
+	}
+	return tfss, nil
+}
+
+// SetRetentionFilters sets retention filters, which are applied to the matching time series during background merges.
+//
+// This function must be called before initializing the storage.
+func SetRetentionFilters(rfs []RetentionFilter) {
+	retentionFilters = append([]RetentionFilter{}, rfs...)
+
+	// Sort filters by retention, so the smallest retention is applied to time series matching multiple filters.
+	sort.SliceStable(retentionFilters, func(i, j int) bool {
+		return retentionFilters[i].Retention < retentionFilters[j].Retention
+	})
+}
+
+var retentionFilters []RetentionFilter
+
+func isRetentionFiltersEnabled() bool {
+	return len(retentionFilters) > 0
+}
+
+// retentionFilterMatch contains metricIDs for time series matching the retention filter with the given retentionMsecs.
+type retentionFilterMatch struct {
+	retentionMsecs int64
+	metricIDs      *uint64set.Set
+}
+
+// retentionFilterDeadline contains retentionDeadline for time series with the given metricIDs.
+type retentionFilterDeadline struct {
+	deadline  int64
+	metricIDs *uint64set.Set
+}
+
+// getRetentionFilterDeadlines returns retention deadlines for the configured retention filters at the given currentTimestamp.
+//
+// The returned deadlines are sorted in descending order.
+func (s *Storage) getRetentionFilterDeadlines(currentTimestamp int64) []retentionFilterDeadline {
+	p := s.retentionFilterMatches.Load()
+	if p == nil {
+		return nil
+	}
+	rfms := *p
+	rfds := make([]retentionFilterDeadline, len(rfms))
+	for i, rfm := range rfms {
+		rfds[i] = retentionFilterDeadline{
+			deadline:  currentTimestamp - rfm.retentionMsecs,
+			metricIDs: rfm.metricIDs,
+		}
+	}
+	return rfds
+}
+
+func (s *Storage) startRetentionFiltersUpdater() {
+	if !isRetentionFiltersEnabled() {
+		return
+	}
+	s.retentionFiltersUpdaterWG.Add(1)
+	go func() {
+		s.retentionFiltersUpdater()
+		s.retentionFiltersUpdaterWG.Done()
+	}()
+}
+
+// retentionFiltersUpdater periodically updates metricIDs for time series matching the configured retention filters,
+// so the retention filters are applied to newly registered time series.
+func (s *Storage) retentionFiltersUpdater() {
+	// Search for the matching time series over all the time on startup.
+	// Subsequent updates search only for time series registered since the previous update.
+	minTimestamp := int64(0)
+	lastUpdateTimestamp := timestampFromTime(time.Now())
+	s.updateRetentionFilterMatches(minTimestamp)
+	d := timeutil.AddJitterToDuration(10 * time.Minute)
+	ticker := time.NewTicker(d)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			// Overlap the previous search a bit in order to catch time series registered during it.
+			minTimestamp = lastUpdateTimestamp - time.Hour.Milliseconds()
+			lastUpdateTimestamp = timestampFromTime(time.Now())
+			s.updateRetentionFilterMatches(minTimestamp)
+		}
+	}
+}
+
+// updateRetentionFilterMatches adds time series with samples on the time range [minTimestamp ... now],
+// which match the configured retention filters, to s.retentionFilterMatches.
+func (s *Storage) updateRetentionFilterMatches(minTimestamp int64) {
+	var rfmsPrev []retentionFilterMatch
+	if p := s.retentionFilterMatches.Load(); p != nil {
+		rfmsPrev = *p
+	}
+	rfms := make([]retentionFilterMatch, 0, len(retentionFilters))
+	tr := TimeRange{
+		MinTimestamp: minTimestamp,
+		MaxTimestamp: timestampFromTime(time.Now()),
+	}
+	for i, rf := range retentionFilters {
+		metricIDs, err := s.searchRetentionFilterMetricIDs(rf, tr)
+		if err != nil {
+			logger.Errorf("cannot obtain time series for retention filter %s: %s; the previously obtained time series will be used", rf.String(), err)
+			return
+		}
+		if i < len(rfmsPrev) {
+			// Create a new set instead of updating the previous one, since it may be in use by concurrent merges.
+			m := rfmsPrev[i].metricIDs.Clone()
+			m.Union(metricIDs)
+			metricIDs = m
+		}
+		rfms = append(rfms, retentionFilterMatch{
+			retentionMsecs: rf.Retention.Milliseconds(),
+			metricIDs:      metricIDs,
+		})
+	}
+	s.retentionFilterMatches.Store(&rfms)
+}
+
+func (s *Storage) searchRetentionFilterMetricIDs(rf RetentionFilter, tr TimeRange) (*uint64set.Set, error) {
+	tfss := make([]*TagFilters, 0, len(rf.TagFilterss))
+	for _, tagFilters := range rf.TagFilterss {
+		tfs := NewTagFilters()
+		for _, tf := range tagFilters {
+			if err := tfs.Add(tf.Key, tf.Value, tf.IsNegative, tf.IsRegexp); err != nil {
+				return nil, fmt.Errorf("cannot parse tag filter %s: %w", tf.String(), err)
+			}
+		}
+		tfss = append(tfss, tfs)
+	}
+	metricIDs, err := s.idb().searchMetricIDs(nil, tfss, tr, 2e9, noDeadline, nil)
+	if err != nil {
+		return nil, err
+	}
+	var m uint64set.Set
+	m.AddMulti(metricIDs)
+	return &m, nil
+}
+
+// String returns human-readable representation of rf.
+func (rf *RetentionFilter) String() string {
+	a := make([]string, 0, len(rf.TagFilterss))
+	for _, tfs := range rf.TagFilterss {
+		a = append(a, tagFiltersToString(tfs))
+	}
+	return fmt.Sprintf("%s:%s", strings.Join(a, " or "), rf.Retention)
+}
+
+// isRetentionFiltersMergeNeeded returns true if pt contains samples outside the retention configured via retention filters,
+// which weren't deleted by the previous merges.
+//
+// Such samples are deleted during the merge of all the parts in pt. The merge is performed only when the whole pt
+// gets outside the retention for some filter or when the time range outside the retention for some filter has been increased
+// by retentionFiltersMergeStep since the previous merge. This limits the number of merges per partition,
+// since every merge re-writes all the data in the partition.
+func (pt *partition) isRetentionFiltersMergeNeeded(currentTimestamp int64) bool {
+	if pt.s.retentionFilterMatches.Load() == nil {
+		// Time series matching retention filters aren't obtained yet.
+		return false
+	}
+	deadlinesApplied := pt.getRetentionFiltersDeadlines()
+	mergeStep := pt.getRetentionFiltersMergeStep()
+	for _, rf := range retentionFilters {
+		deadline := currentTimestamp - rf.Retention.Milliseconds()
+		if deadline <= pt.tr.MinTimestamp {
+			// The partition has no samples outside the retention for the given filter.
+			continue
+		}
+		deadlineApplied, ok := deadlinesApplied[rf.String()]
+		if !ok || deadlineApplied < pt.tr.MinTimestamp {
+			deadlineApplied = pt.tr.MinTimestamp
+		}
+		if deadlineApplied >= pt.tr.MaxTimestamp {
+			// All the samples for the given filter have been already deleted by the previous merge.
+			continue
+		}
+		if deadline >= pt.tr.MaxTimestamp {
+			// All the samples for the given filter are outside the retention.
+			return true
+		}
+		if deadline-deadlineApplied >= mergeStep {
+			return true
+		}
+	}
+	return false
+}
+
+// getRetentionFiltersMergeStep returns the minimum increase of the time range outside retention filters
+// since the previous merge, which triggers a new merge for pt.
+func (pt *partition) getRetentionFiltersMergeStep() int64 {
+	step := (pt.tr.MaxTimestamp - pt.tr.MinTimestamp) / 4
+	if minStep := retentionFiltersMinMergeInterval.Milliseconds(); step < minStep {
+		step = minStep
+	}
+	return step
+}
+
+// runRetentionFiltersMerge merges all the parts in pt in order to delete samples outside the retention configured via retention filters.
+func (pt *partition) runRetentionFiltersMerge(currentTimestamp int64) error {
+	t := time.Now()
+	logger.Infof("starting merge for partition %s in order to apply retention filters", pt.bigPartsPath)
+	if err := pt.ForceMergeAllParts(); err != nil {
+		return fmt.Errorf("cannot apply retention filters to partition %s: %w", pt.bigPartsPath, err)
+	}
+	deadlines := make(map[string]int64, len(retentionFilters))
+	for _, rf := range retentionFilters {
+		deadlines[rf.String()] = currentTimestamp - rf.Retention.Milliseconds()
+	}
+	pt.mustWriteRetentionFiltersDeadlines(deadlines)
+	logger.Infof("retention filters for partition %s have been applied in %.3f seconds", pt.bigPartsPath, time.Since(t).Seconds())
+	return nil
+}
+
+// getRetentionFiltersDeadlines returns retention deadlines per each retention filter, which have been applied by the previous merge of pt.
+//
+// The deadlines are persisted in pt directory, so they survive restarts.
+func (pt *partition) getRetentionFiltersDeadlines() map[string]int64 {
+	path := filepath.Join(pt.bigPartsPath, retentionFiltersDeadlinesFilename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Errorf("cannot read %s: %s; applying retention filters to the whole partition", path, err)
+		}
+		return nil
+	}
+	var deadlines map[string]int64
+	if err := json.Unmarshal(data, &deadlines); err != nil {
+		logger.Errorf("cannot parse %s: %s; applying retention filters to the whole partition", path, err)
+		return nil
+	}
+	return deadlines
+}
+
+func (pt *partition) mustWriteRetentionFiltersDeadlines(deadlines map[string]int64) {
+	data, err := json.Marshal(deadlines)
+	if err != nil {
+		logger.Panicf("BUG: cannot marshal retention filters deadlines to JSON: %s", err)
+	}
+	path := filepath.Join(pt.bigPartsPath, retentionFiltersDeadlinesFilename)
+	fs.MustWriteAtomic(path, data, true)
+}
+
+// retentionFiltersDeadlinesFilename is the name of the file with retention deadlines applied to the partition by the previous merge.
+const retentionFiltersDeadlinesFilename = "retention_filters.json"
+
+// The minimum interval between merges of the same partition for applying retention filters.
+const retentionFiltersMinMergeInterval = 24 * time.Hour
@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
+)
+
+func TestStorageRetentionFilters(t *testing.T) {
+	SetRetentionFilters([]RetentionFilter{{
+		TagFilterss: [][]TagFilter{{{
+			Key:   []byte("env"),
+			Value: []byte("dev"),
+		}}},
+		Retention: 2 * 24 * time.Hour,
+	}})
+	defer SetRetentionFilters(nil)
+
+	path := "TestStorageRetentionFilters"
+	s := MustOpenStorage(path, 31*24*time.Hour, 0, 0)
+
+	currentTime := timestampFromTime(time.Now())
+	var mrs []MetricRow
+	for _, env := range []string{"dev", "prod"} {
+		mn := MetricName{
+			MetricGroup: []byte("foo"),
+			Tags: []Tag{
+				{[]byte("env"), []byte(env)},
+			},
+		}
+		metricNameRaw := mn.marshalRaw(nil)
+		for i := 0; i < 5*24; i++ {
+			mrs = append(mrs, MetricRow{
+				MetricNameRaw: metricNameRaw,
+				Timestamp:     currentTime - int64(i)*3600*1000,
+				Value:         float64(i),
+			})
+		}
+	}
+	if err := s.AddRows(mrs, defaultPrecisionBits); err != nil {
+		t.Fatalf("unexpected error when adding rows: %s", err)
+	}
+	s.DebugFlush()
+
+	// Obtain time series matching the retention filters and apply them.
+	s.updateRetentionFilterMatches(0)
+	if err := s.ForceMergePartitions(""); err != nil {
+		t.Fatalf("cannot force merge partitions: %s", err)
+	}
+
+	minTimestamps := make(map[string]int64)
+	rowsCounts := make(map[string]int)
+	tfs := NewTagFilters()
+	if err := tfs.Add(nil, []byte("foo"), false, false); err != nil {
+		t.Fatalf("unexpected error in TagFilters.Add: %s", err)
+	}
+	tr := TimeRange{
+		MinTimestamp: 0,
+		MaxTimestamp: currentTime,
+	}
+	var sr Search
+	sr.Init(nil, s, []*TagFilters{tfs}, tr, 1e5, noDeadline, nil)
+	var mn MetricName
+	for sr.NextMetricBlock() {
+		if err := mn.Unmarshal(sr.MetricBlockRef.MetricName); err != nil {
+			t.Fatalf("cannot unmarshal MetricName: %s", err)
+		}
+		env := string(mn.GetTagValue("env"))
+		var b Block
+		sr.MetricBlockRef.BlockRef.MustReadBlock(&b)
+		rb := newTestRawBlock(&b, tr)
+		if n, ok := minTimestamps[env]; !ok || rb.Timestamps[0] < n {
+			minTimestamps[env] = rb.Timestamps[0]
+		}
+		rowsCounts[env] += len(rb.Timestamps)
+	}
+	if err := sr.Error(); err != nil {
+		t.Fatalf("search error: %s", err)
+	}
+	sr.MustClose()
+
+	// The retention filter must be applied only to env="dev" series.
+	if n := rowsCounts["prod"]; n != 5*24 {
+		t.Fatalf("unexpected number of rows for env=\"prod\"; got %d; want %d", n, 5*24)
+	}
+	if n := rowsCounts["dev"]; n == 0 || n > 2*24+1 {
+		t.Fatalf("unexpected number of rows for env=\"dev\"; got %d; want up to %d", n, 2*24+1)
+	}
+	if minTimestamp := minTimestamps["dev"]; minTimestamp < currentTime-2*24*3600*1000 {
+		t.Fatalf("unexpected samples outside the retention for env=\"dev\"; min timestamp is %d; want at least %d", minTimestamp, currentTime-2*24*3600*1000)
+	}
+
+	s.MustClose()
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatalf("cannot remove %q: %s", path, err)
+	}
+}
+
+func TestParseRetentionFilters(t *testing.T) {
+	f := func(a []string, resultExpected string) {
+		t.Helper()
+		rfs, err := ParseRetentionFilters(a, 365*24*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		var ss []string
+		for _, rf := range rfs {
+			ss = append(ss, rf.String())
+		}
+		result := strings.Join(ss, ";")
+		if result != resultExpected {
+			t.Fatalf("unexpected result;\ngot\n%s\nwant\n%s", result, resultExpected)
+		}
+	}
+	f(nil, "")
+	f([]string{`{env="dev"}:7d`}, `{env="dev"}:168h0m0s`)
+	f([]string{`{__name__=~"debug_.*"}:3d`, `foo{a!="b" or c!~"d"}:1w`}, `{__name__=~"debug_.*"}:72h0m0s;{__name__="foo",a!="b"} or {__name__="foo",c!~"d"}:168h0m0s`)
+
+	fError := func(a []string) {
+		t.Helper()
+		if _, err := ParseRetentionFilters(a, 365*24*time.Hour); err == nil {
+			t.Fatalf("expecting non-nil error for %q", a)
+		}
+	}
+	// missing retention
+	fError([]string{`{env="dev"}`})
+	// invalid series selector
+	fError([]string{`{env="dev":7d`})
+	fError([]string{`sum(foo):7d`})
+	fError([]string{`{}:7d`})
+	// invalid retention
+	fError([]string{`{env="dev"}:foo`})
+	// too small retention
+	fError([]string{`{env="dev"}:1h`})
+	// too big retention
+	fError([]string{`{env="dev"}:2y`})
+}
+
+func TestPartitionIsRetentionFiltersMergeNeeded(t *testing.T) {
+	SetRetentionFilters([]RetentionFilter{{
+		TagFilterss: [][]TagFilter{{{
+			Key:   []byte("env"),
+			Value: []byte("dev"),
+		}}},
+		Retention: 2 * 24 * time.Hour,
+	}})
+	defer SetRetentionFilters(nil)
+
+	path := "TestPartitionIsRetentionFiltersMergeNeeded"
+	fs.MustMkdirIfNotExist(path)
+	defer fs.MustRemoveAll(path)
+
+	var s Storage
+	pt := &partition{
+		bigPartsPath: path,
+		s:            &s,
+	}
+	if err := pt.tr.fromPartitionName("2024_01"); err != nil {
+		t.Fatalf("cannot parse partition name: %s", err)
+	}
+	day := (24 * time.Hour).Milliseconds()
+	retention := 2 * day
+
+	f := func(currentTimestamp int64, resultExpected bool) {
+		t.Helper()
+		result := pt.isRetentionFiltersMergeNeeded(currentTimestamp)
+		if result != resultExpected {
+			t.Fatalf("unexpected result at %d; got %v; want %v", currentTimestamp, result, resultExpected)
+		}
+	}
+
+	// Time series matching retention filters aren't obtained yet
+	f(pt.tr.MaxTimestamp+retention+1, false)
+
+	rfms := []retentionFilterMatch{}
+	s.retentionFilterMatches.Store(&rfms)
+
+	// The partition has no samples outside the retention
+	f(pt.tr.MinTimestamp+retention, false)
+
+	// Too small time range outside the retention
+	f(pt.tr.MinTimestamp+retention+day, false)
+
+	// The time range outside the retention exceeds the merge step
+	mergeStep := pt.getRetentionFiltersMergeStep()
+	currentTimestamp := pt.tr.MinTimestamp + retention + mergeStep
+	f(currentTimestamp, true)
+	if err := pt.runRetentionFiltersMerge(currentTimestamp); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The previous merge must be taken into account
+	f(currentTimestamp+day, false)
+	f(currentTimestamp+mergeStep, true)
+
+	// The whole partition is outside the retention
+	currentTimestamp = pt.tr.MaxTimestamp + retention
+	f(currentTimestamp, true)
+	if err := pt.runRetentionFiltersMerge(currentTimestamp); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// All the samples outside the retention have been already deleted
+	f(currentTimestamp+mergeStep, false)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync/atomic"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/bytesutil"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/encoding"
@@ -110,6 +111,9 @@ type Search struct {
 	// deadline in unix timestamp seconds for the current search.
 	deadline uint64
 
+	// canceled is an optional flag for canceling the current search before the deadline.
+	canceled *atomic.Bool
+
 	err error
 
 	needClosing bool
@@ -129,6 +133,7 @@ func (s *Search) reset() {
 	s.tr = TimeRange{}
 	s.tfss = nil
 	s.deadline = 0
+	s.canceled = nil
 	s.err = nil
 	s.needClosing = false
 	s.loops = 0
@@ -139,8 +144,10 @@ func (s *Search) reset() {
 //
 // MustClose must be called when the search is done.
 //
+// The search is stopped with ErrDeadlineExceeded error after the deadline or after setting the optional canceled flag to true.
+//
 // Init returns the upper bound on the number of found time series.
-func (s *Search) Init(qt *querytracer.Tracer, storage *Storage, tfss []*TagFilters, tr TimeRange, maxMetrics int, deadline uint64) int {
+func (s *Search) Init(qt *querytracer.Tracer, storage *Storage, tfss []*TagFilters, tr TimeRange, maxMetrics int, deadline uint64, canceled *atomic.Bool) int {
 	qt = qt.NewChild("init series search: filters=%s, timeRange=%s", tfss, &tr)
 	defer qt.Done()
 	if s.needClosing {
@@ -154,14 +161,15 @@ func (s *Search) Init(qt *querytracer.Tracer, storage *Storage, tfss []*TagFilte
 	s.tr = tr
 	s.tfss = tfss
 	s.deadline = deadline
+	s.canceled = canceled
 	s.needClosing = true
 
 	var tsids []TSID
-	metricIDs, err := s.idb.searchMetricIDs(qt, tfss, tr, maxMetrics, deadline)
+	metricIDs, err := s.idb.searchMetricIDs(qt, tfss, tr, maxMetrics, deadline, canceled)
 	if err == nil {
-		tsids, err = s.idb.getTSIDsFromMetricIDs(qt, metricIDs, deadline)
+		tsids, err = s.idb.getTSIDsFromMetricIDs(qt, metricIDs, deadline, canceled)
 		if err == nil {
-			err = storage.prefetchMetricNames(qt, metricIDs, deadline)
+			err = storage.prefetchMetricNames(qt, metricIDs, deadline, canceled)
 		}
 	}
 	// It is ok to call Init on non-nil err.
@@ -200,7 +208,7 @@ func (s *Search) NextMetricBlock() bool {
 	}
 	for s.ts.NextBlock() {
 		if s.loops&paceLimiterSlowIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(s.deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(s.deadline, s.canceled); err != nil {
 				s.err = err
 				return false
 			}
@@ -444,8 +452,9 @@ func (sq *SearchQuery) Unmarshal(src []byte) ([]byte, error) {
 	return src, nil
 }
 
-func checkSearchDeadlineAndPace(deadline uint64) error {
-	if fasttime.UnixTimestamp() > deadline {
+// checkSearchDeadlineAndPace returns ErrDeadlineExceeded if the deadline is exceeded or if the optional canceled flag is set.
+func checkSearchDeadlineAndPace(deadline uint64, canceled *atomic.Bool) error {
+	if fasttime.UnixTimestamp() > deadline || canceled != nil && canceled.Load() {
 		return ErrDeadlineExceeded
 	}
 	return nil
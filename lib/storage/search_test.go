@@ -2,12 +2,14 @@ package storage
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"reflect"
 	"regexp"
 	"sort"
+	"sync/atomic"
 	"testing"
 	"testing/quick"
 	"time"
@@ -153,6 +155,24 @@ func TestSearch(t *testing.T) {
 			t.Fatalf("unexpected error: %s", firstError)
 		}
 	})
+
+	t.Run("canceled", func(t *testing.T) {
+		tfs := NewTagFilters()
+		if err := tfs.Add([]byte("job"), []byte("super-service"), false, false); err != nil {
+			t.Fatalf("cannot add tag filter: %s", err)
+		}
+		var canceled atomic.Bool
+		canceled.Store(true)
+		var s Search
+		s.Init(nil, st, []*TagFilters{tfs}, tr, 1e5, noDeadline, &canceled)
+		if s.NextMetricBlock() {
+			t.Fatalf("canceled search mustn't return metric blocks")
+		}
+		if err := s.Error(); !errors.Is(err, ErrDeadlineExceeded) {
+			t.Fatalf("unexpected error for canceled search; got %v; want %v", err, ErrDeadlineExceeded)
+		}
+		s.MustClose()
+	})
 }
 
 func testSearchInternal(st *Storage, tr TimeRange, mrs []MetricRow) error {
@@ -195,7 +215,7 @@ func testSearchInternal(st *Storage, tr TimeRange, mrs []MetricRow) error {
 		}
 
 		// Search
-		s.Init(nil, st, []*TagFilters{tfs}, tr, 1e5, noDeadline)
+		s.Init(nil, st, []*TagFilters{tfs}, tr, 1e5, noDeadline, nil)
 		var mbs []metricBlock
 		for s.NextMetricBlock() {
 			var b Block
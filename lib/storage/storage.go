@@ -149,6 +149,12 @@ type Storage struct {
 	deletedMetricIDs           atomic.Pointer[uint64set.Set]
 	deletedMetricIDsUpdateLock sync.Mutex
 
+	// retentionFilterMatches contains metricIDs for time series matching the configured retention filters.
+	//
+	// It is periodically updated by retentionFiltersUpdater.
+	retentionFilterMatches    atomic.Pointer[[]retentionFilterMatch]
+	retentionFiltersUpdaterWG sync.WaitGroup
+
 	isReadOnly uint32
 }
 
@@ -275,6 +281,7 @@ func MustOpenStorage(path string, retention time.Duration, maxHourlySeries, maxD
 	s.startCurrHourMetricIDsUpdater()
 	s.startNextDayMetricIDsUpdater()
 	s.startRetentionWatcher()
+	s.startRetentionFiltersUpdater()
 
 	return s
 }
@@ -489,6 +496,8 @@ type Metrics struct {
 	TimestampsBlocksMerged uint64
 	TimestampsBytesSaved   uint64
 
+	MergeThrottledMsecs uint64
+
 	TSIDCacheSize         uint64
 	TSIDCacheSizeBytes    uint64
 	TSIDCacheSizeMaxBytes uint64
@@ -565,6 +574,8 @@ func (s *Storage) UpdateMetrics(m *Metrics) {
 	m.TimestampsBlocksMerged = atomic.LoadUint64(&timestampsBlocksMerged)
 	m.TimestampsBytesSaved = atomic.LoadUint64(&timestampsBytesSaved)
 
+	m.MergeThrottledMsecs = atomic.LoadUint64(&mergeThrottledMsecs)
+
 	var cs fastcache.Stats
 	s.tsidCache.UpdateStats(&cs)
 	m.TSIDCacheSize += cs.EntriesCount
@@ -844,6 +855,7 @@ func (s *Storage) MustClose() {
 
 	s.freeDiskSpaceWatcherWG.Wait()
 	s.retentionWatcherWG.Wait()
+	s.retentionFiltersUpdaterWG.Wait()
 	s.currHourMetricIDsUpdaterWG.Wait()
 	s.nextDayMetricIDsUpdaterWG.Wait()
 
@@ -1118,14 +1130,14 @@ func (s *Storage) SearchMetricNames(qt *querytracer.Tracer, tfss []*TagFilters,
 	qt = qt.NewChild("search for matching metric names: filters=%s, timeRange=%s", tfss, &tr)
 	defer qt.Done()
 
-	metricIDs, err := s.idb().searchMetricIDs(qt, tfss, tr, maxMetrics, deadline)
+	metricIDs, err := s.idb().searchMetricIDs(qt, tfss, tr, maxMetrics, deadline, nil)
 	if err != nil {
 		return nil, err
 	}
 	if len(metricIDs) == 0 {
 		return nil, nil
 	}
-	if err = s.prefetchMetricNames(qt, metricIDs, deadline); err != nil {
+	if err = s.prefetchMetricNames(qt, metricIDs, deadline, nil); err != nil {
 		return nil, err
 	}
 	idb := s.idb()
@@ -1134,7 +1146,7 @@ func (s *Storage) SearchMetricNames(qt *querytracer.Tracer, tfss []*TagFilters,
 	var metricName []byte
 	for i, metricID := range metricIDs {
 		if i&paceLimiterSlowIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(deadline, nil); err != nil {
 				return nil, err
 			}
 		}
@@ -1161,7 +1173,7 @@ func (s *Storage) SearchMetricNames(qt *querytracer.Tracer, tfss []*TagFilters,
 // This should speed-up further searchMetricNameWithCache calls for srcMetricIDs from tsids.
 //
 // It is expected that srcMetricIDs are already sorted by the caller. Otherwise the pre-fetching may be slow.
-func (s *Storage) prefetchMetricNames(qt *querytracer.Tracer, srcMetricIDs []uint64, deadline uint64) error {
+func (s *Storage) prefetchMetricNames(qt *querytracer.Tracer, srcMetricIDs []uint64, deadline uint64, canceled *atomic.Bool) error {
 	qt = qt.NewChild("prefetch metric names for %d metricIDs", len(srcMetricIDs))
 	defer qt.Done()
 
@@ -1195,10 +1207,11 @@ func (s *Storage) prefetchMetricNames(qt *querytracer.Tracer, srcMetricIDs []uin
 	var err error
 	idb := s.idb()
 	is := idb.getIndexSearch(deadline)
+	is.canceled = canceled
 	defer idb.putIndexSearch(is)
 	for loops, metricID := range metricIDs {
 		if loops&paceLimiterSlowIterationsMask == 0 {
-			if err := checkSearchDeadlineAndPace(is.deadline); err != nil {
+			if err := checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 				return err
 			}
 		}
@@ -1211,10 +1224,11 @@ func (s *Storage) prefetchMetricNames(qt *querytracer.Tracer, srcMetricIDs []uin
 	}
 	idb.doExtDB(func(extDB *indexDB) {
 		is := extDB.getIndexSearch(deadline)
+		is.canceled = canceled
 		defer extDB.putIndexSearch(is)
 		for loops, metricID := range missingMetricIDs {
 			if loops&paceLimiterSlowIterationsMask == 0 {
-				if err = checkSearchDeadlineAndPace(is.deadline); err != nil {
+				if err = checkSearchDeadlineAndPace(is.deadline, is.canceled); err != nil {
 					return
 				}
 			}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -698,7 +699,7 @@ func testStorageDeleteSeries(s *Storage, workerNum int) error {
 	metricBlocksCount := func(tfs *TagFilters) int {
 		// Verify the number of blocks
 		n := 0
-		sr.Init(nil, s, []*TagFilters{tfs}, tr, 1e5, noDeadline)
+		sr.Init(nil, s, []*TagFilters{tfs}, tr, 1e5, noDeadline, nil)
 		for sr.NextMetricBlock() {
 			n++
 		}
@@ -1277,3 +1278,65 @@ func TestStorageSeriesAreNotCreatedOnStaleMarkers(t *testing.T) {
 		t.Fatalf("cannot remove %q: %s", path, err)
 	}
 }
+
+func TestStorageSearchMetricNamesMaxMetrics(t *testing.T) {
+	path := "TestStorageSearchMetricNamesMaxMetrics"
+	s := MustOpenStorage(path, -1, 1e5, 1e6)
+
+	tr := TimeRange{MinTimestamp: 0, MaxTimestamp: 2e10}
+	tfsAll := NewTagFilters()
+	if err := tfsAll.Add([]byte("__name__"), []byte(".*"), false, true); err != nil {
+		t.Fatalf("unexpected error in TagFilters.Add: %s", err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	mrs := testGenerateMetricRows(rng, 10, tr.MinTimestamp, tr.MaxTimestamp)
+	if err := s.AddRows(mrs, defaultPrecisionBits); err != nil {
+		t.Fatalf("error when adding mrs: %s", err)
+	}
+	s.DebugFlush()
+
+	isCached := func() bool {
+		idb := s.idb()
+		tfss := []*TagFilters{tfsAll}
+		if tr.MinTimestamp >= idb.s.minTimestampForCompositeIndex {
+			tfss = convertToCompositeTagFilterss(tfss)
+		}
+		key := marshalTagFiltersKey(nil, tfss, tr, true)
+		_, ok := idb.getMetricIDsFromTagFiltersCache(nil, key)
+		return ok
+	}
+
+	// the limit is exceeded
+	_, err := s.SearchMetricNames(nil, []*TagFilters{tfsAll}, tr, 5, noDeadline)
+	var tme *TooManyTimeseriesError
+	if !errors.As(err, &tme) {
+		t.Fatalf("expecting TooManyTimeseriesError; got %v", err)
+	}
+	if tme.MaxMetrics != 5 {
+		t.Fatalf("unexpected MaxMetrics; got %d; want 5", tme.MaxMetrics)
+	}
+	// the result exceeding the limit mustn't be cached
+	if isCached() {
+		t.Fatalf("metricIDs exceeding the limit mustn't be cached")
+	}
+
+	// the limit isn't exceeded
+	if _, err := s.SearchMetricNames(nil, []*TagFilters{tfsAll}, tr, 10, noDeadline); err != nil {
+		t.Fatalf("unexpected error when searching metric names: %s", err)
+	}
+	if !isCached() {
+		t.Fatalf("metricIDs must be cached")
+	}
+
+	// the limit must be applied to the cached result
+	_, err = s.SearchMetricNames(nil, []*TagFilters{tfsAll}, tr, 5, noDeadline)
+	if !errors.As(err, &tme) {
+		t.Fatalf("expecting TooManyTimeseriesError for the cached result; got %v", err)
+	}
+
+	s.MustClose()
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatalf("cannot remove %q: %s", path, err)
+	}
+}
@@ -440,8 +440,8 @@ func (tb *table) startFinalDedupWatcher() {
 }
 
 func (tb *table) finalDedupWatcher() {
-	if !isDedupEnabled() {
-		// Deduplication is disabled.
+	if !isDedupEnabled() && !isDownsamplingEnabled() && !isRetentionFiltersEnabled() {
+		// Deduplication, downsampling and retention filters are disabled.
 		return
 	}
 	f := func() {
@@ -450,13 +450,18 @@ func (tb *table) finalDedupWatcher() {
 		timestamp := timestampFromTime(time.Now())
 		currentPartitionName := timestampToPartitionName(timestamp)
 		for _, ptw := range ptws {
-			if ptw.pt.name == currentPartitionName || !ptw.pt.isFinalDedupNeeded() {
-				// Do not run final dedup for the current month.
+			// Do not run final dedup for the current month.
+			if ptw.pt.name != currentPartitionName && ptw.pt.isFinalDedupNeeded() {
+				if err := ptw.pt.runFinalDedup(); err != nil {
+					logger.Errorf("cannot run final dedup for partition %s: %s", ptw.pt.name, err)
+				}
 				continue
 			}
-			if err := ptw.pt.runFinalDedup(); err != nil {
-				logger.Errorf("cannot run final dedup for partition %s: %s", ptw.pt.name, err)
-				continue
+			// Retention filters are applied to the current month too, since it may contain samples outside the retention for the filters.
+			if ptw.pt.isRetentionFiltersMergeNeeded(timestamp) {
+				if err := ptw.pt.runRetentionFiltersMerge(timestamp); err != nil {
+					logger.Errorf("cannot apply retention filters to partition %s: %s", ptw.pt.name, err)
+				}
 			}
 		}
 	}
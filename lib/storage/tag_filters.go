@@ -580,6 +580,8 @@ func newMatchFuncForOrSuffixes(orValues []string) (reMatch func(b []byte) bool,
 //	'.*literal.+'
 //	'.+literal.*'
 //	'.+literal.+'
+//	'(literal1|...|literalN).*'
+//	'(literal1|...|literalN).+'
 //
 // It returns reMatch if it cannot find optimized function.
 //
@@ -708,6 +710,27 @@ func getOptimizedReMatchFuncExt(reMatch func(b []byte) bool, sre *syntax.Regexp)
 				}
 			}
 		}
+		if len(sre.Sub) >= 2 {
+			lastSub := sre.Sub[len(sre.Sub)-1]
+			if isDotStar(lastSub) || isDotPlus(lastSub) {
+				prefixes := getOrPrefixes(sre.Sub[:len(sre.Sub)-1])
+				if reCost := uint64(len(prefixes)) * prefixMatchCost; len(prefixes) > 0 && reCost < reMatchCost {
+					minSuffixLen := 0
+					if !isDotStar(lastSub) {
+						minSuffixLen = 1
+					}
+					// '(prefix1|...|prefixN).*' or '(prefix1|...|prefixN).+'
+					return func(b []byte) bool {
+						for _, prefix := range prefixes {
+							if len(b) >= len(prefix)+minSuffixLen && bytes.HasPrefix(b, prefix) {
+								return true
+							}
+						}
+						return false
+					}, "", reCost
+				}
+			}
+		}
 		// Verify that the string matches all the literals found in the regexp
 		// before applying the regexp.
 		// This should optimize the case when the regexp doesn't match the string.
@@ -744,6 +767,29 @@ func getOptimizedReMatchFuncExt(reMatch func(b []byte) bool, sre *syntax.Regexp)
 	}
 }
 
+// getOrPrefixes returns the list of plaintext strings matching the concatenation of subs.
+//
+// For example, it returns ["a/users/", "b/users/"] for '(a|b)/users/'.
+// It returns nil if subs cannot be converted into a limited list of plaintext strings.
+func getOrPrefixes(subs []*syntax.Regexp) [][]byte {
+	sre := subs[0]
+	if len(subs) > 1 {
+		sre = &syntax.Regexp{
+			Op:  syntax.OpConcat,
+			Sub: subs,
+		}
+	}
+	orValues := regexutil.GetOrValues(sre.String())
+	if len(orValues) == 0 {
+		return nil
+	}
+	prefixes := make([][]byte, len(orValues))
+	for i, orValue := range orValues {
+		prefixes[i] = []byte(orValue)
+	}
+	return prefixes
+}
+
 func isDotStar(sre *syntax.Regexp) bool {
 	switch sre.Op {
 	case syntax.OpCapture:
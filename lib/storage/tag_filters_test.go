@@ -722,6 +722,11 @@ func TestGetRegexpFromCache(t *testing.T) {
 	f("(a|b)", []string{"a", "b"}, []string{"a", "b"}, []string{"xa", "bx", "xab", ""}, "")
 	f("(a|b)foo(c|d)", []string{"afooc", "afood", "bfooc", "bfood"}, []string{"afooc", "bfood"}, []string{"foo", "", "afoo", "fooc", "xfood"}, "")
 	f("foo.+", nil, []string{"foox", "foobar"}, []string{"foo", "afoox", "afoo", ""}, "")
+	f("(a|b)foo.*", nil, []string{"afoo", "bfoox"}, []string{"foo", "cfoo", "xafoo", "afo", ""}, "")
+	f("(a|b)foo.+", nil, []string{"afoox", "bfoobar"}, []string{"afoo", "bfoo", "cfoox", "xafoox", ""}, "")
+	f("[ab]/users/.*", nil, []string{"a/users/", "b/users/123"}, []string{"c/users/", "a/users", "xa/users/", ""}, "")
+	f("/api/(v1|v2)/users/.*", nil, []string{"/api/v1/users/", "/api/v2/users/123"}, []string{"/api/v3/users/", "/api/v1/user", "x/api/v1/users/"}, "")
+	f("(?i)(a|b)foo.*", nil, []string{"afoo", "BFOOx", "aFoo"}, []string{"cfoo", "xafoo", ""}, "")
 	f(".*foo.*bar", nil, []string{"foobar", "xfoobar", "xfooxbar", "fooxbar"}, []string{"", "foobarx", "afoobarx", "aaa"}, "bar")
 	f("foo.*bar", nil, []string{"foobar", "fooxbar"}, []string{"xfoobar", "", "foobarx", "aaa"}, "bar")
 	f("foo.*bar.*", nil, []string{"foobar", "fooxbar", "foobarx", "fooxbarx"}, []string{"", "afoobarx", "aaa", "afoobar"}, "")